@@ -7,7 +7,8 @@ import (
 	"github.com/zoninnik89/messenger/chat-client/internal/logging"
 	kafkaProducer "github.com/zoninnik89/messenger/chat-client/internal/producer"
 	"github.com/zoninnik89/messenger/common/discovery"
-	"github.com/zoninnik89/messenger/common/discovery/consul"
+	"github.com/zoninnik89/messenger/common/discovery/backend"
+	"github.com/zoninnik89/messenger/common/telemetry"
 	zap "go.uber.org/zap"
 	"os"
 	"os/signal"
@@ -22,9 +23,22 @@ func main() {
 
 	logger.Info("starting chat-client service")
 
-	registry, err := consul.NewRegistry(cfg.GRPC.Address, cfg.Consul.Port)
+	shutdownTracing, err := telemetry.Init(context.Background(), cfg.GRPC.Name, cfg.Tracing)
 	if err != nil {
-		logger.Panic("failed to connect to Consul", zap.Error(err))
+		logger.Panic("failed to set up tracing", zap.Error(err))
+		panic(err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Warn("failed to flush spans", zap.Error(err))
+		}
+	}()
+
+	registry, err := backend.New(cfg.Discovery, cfg.GRPC.Address, cfg.Consul.Port)
+	if err != nil {
+		logger.Panic("failed to create service registry", zap.Error(err))
 		panic(err)
 	}
 
@@ -51,7 +65,7 @@ func main() {
 		}
 	}()
 
-	defer func(registry *consul.Registry, ctx context.Context, instanceID string) {
+	defer func(registry discovery.Registry, ctx context.Context, instanceID string) {
 		err := registry.Deregister(ctx, instanceID)
 		if err != nil {
 			logger.Fatal("failed to deregister service", zap.Error(err))
@@ -64,9 +78,9 @@ func main() {
 	if err != nil {
 		logger.Panic("failed to connect to Kafka", zap.Error(err))
 	}
-	defer queue.Producer.Flush(10)
+	defer queue.Close()
 
-	application := app.NewApp(cfg.GRPC.Port, registry, queue)
+	application := app.NewApp(cfg.GRPC.Port, registry, queue, cfg.Chats.ParticipantsTTL)
 	go application.GRPCsrv.MustRun()
 
 	stop := make(chan os.Signal, 1)
@@ -78,6 +92,9 @@ func main() {
 
 	//cancel()
 	application.GRPCsrv.Stop()
+	if err := application.Close(); err != nil {
+		logger.Warn("failed to close connections to services", zap.Error(err))
+	}
 
 	logger.Info("shut down gracefully")
 }
@@ -2,17 +2,20 @@ package app
 
 import (
 	grpcapp "github.com/zoninnik89/messenger/chat-client/internal/app/grpc"
-	producer "github.com/zoninnik89/messenger/chat-client/internal/producer"
 	"github.com/zoninnik89/messenger/chat-client/internal/service"
+	"github.com/zoninnik89/messenger/common/bus"
 	"github.com/zoninnik89/messenger/common/discovery"
+	"time"
 )
 
 type App struct {
 	GRPCsrv *grpcapp.App
+
+	service *service.ChatClient
 }
 
-func NewApp(grpcPort int, r discovery.Registry, queue *producer.MessageProducer) *App {
-	chatClientService, err := service.NewChatClient(r, queue)
+func NewApp(grpcPort int, r discovery.Registry, queue bus.Producer, participantsTTL time.Duration) *App {
+	chatClientService, err := service.NewChatClient(r, queue, participantsTTL)
 	if err != nil {
 		return nil
 	}
@@ -21,5 +24,11 @@ func NewApp(grpcPort int, r discovery.Registry, queue *producer.MessageProducer)
 
 	return &App{
 		GRPCsrv: grpcApp,
+		service: chatClientService,
 	}
 }
+
+// Close closes connections to the services.
+func (a *App) Close() error {
+	return a.service.Close()
+}
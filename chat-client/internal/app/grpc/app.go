@@ -5,6 +5,7 @@ import (
 	chatclientgrpc "github.com/zoninnik89/messenger/chat-client/internal/grpc"
 	"github.com/zoninnik89/messenger/chat-client/internal/logging"
 	"github.com/zoninnik89/messenger/chat-client/internal/types"
+	"github.com/zoninnik89/messenger/common/telemetry"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"net"
@@ -19,7 +20,7 @@ type App struct {
 
 func NewApp(chatClientService types.ChatClientInterface, port int) *App {
 	l := logging.GetLogger().Sugar()
-	grpcServer := grpc.NewServer()
+	grpcServer := grpc.NewServer(telemetry.ServerOption())
 	chatclientgrpc.Register(grpcServer, chatClientService)
 
 	return &App{
@@ -45,12 +46,21 @@ func (a *App) Run() error {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	addr := l.Addr().String()
+	if err := a.Serve(l); err != nil {
+		a.logger.Fatalw("failed to serve", "op", op, "err", err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
 
-	a.logger.Infow("grpc server is listening", "op", op, "addr", addr)
+// Serve serves gRPC requests accepted by the listener until the server is stopped.
+func (a *App) Serve(l net.Listener) error {
+	const op = "grpcapp.Serve"
+
+	a.logger.Infow("grpc server is listening", "op", op, "addr", l.Addr().String())
 
 	if err := a.grpcServer.Serve(l); err != nil {
-		a.logger.Fatalw("failed to serve", "op", op, "err", err)
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
@@ -4,15 +4,20 @@ import (
 	"flag"
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/zoninnik89/messenger/common"
+	"github.com/zoninnik89/messenger/common/discovery/backend"
+	"github.com/zoninnik89/messenger/common/telemetry"
 	"os"
 	"time"
 )
 
 type Config struct {
-	Env    string       `yaml:"env" env-default:"local"`
-	GRPC   GRPCConfig   `yaml:"grpc"`
-	Kafka  KafkaConfig  `yaml:"kafka"`
-	Consul ConsulConfig `yaml:"consul"`
+	Env       string           `yaml:"env" env-default:"local"`
+	GRPC      GRPCConfig       `yaml:"grpc"`
+	Kafka     KafkaConfig      `yaml:"kafka"`
+	Consul    ConsulConfig     `yaml:"consul"`
+	Discovery backend.Config   `yaml:"discovery"`
+	Chats     ChatsConfig      `yaml:"chats"`
+	Tracing   telemetry.Config `yaml:"tracing"`
 }
 
 type GRPCConfig struct {
@@ -30,6 +35,11 @@ type ConsulConfig struct {
 	Port int `yaml:"port"`
 }
 
+type ChatsConfig struct {
+	// ParticipantsTTL is how long participants of a group are cached
+	ParticipantsTTL time.Duration `yaml:"participants_ttl" env-default:"5s"`
+}
+
 func MustLoad() *Config {
 	path := fetchConfigPath()
 	if path == "" {
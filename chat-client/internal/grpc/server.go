@@ -2,7 +2,9 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	"github.com/zoninnik89/messenger/chat-client/internal/logging"
+	"github.com/zoninnik89/messenger/chat-client/internal/service"
 	"github.com/zoninnik89/messenger/chat-client/internal/types"
 	pb "github.com/zoninnik89/messenger/common/api"
 	"go.uber.org/zap"
@@ -35,7 +37,8 @@ func (s *serverAPI) GetMessagesStream(req *pb.GetMessagesStreamRequest, stream p
 		return err
 	}
 
-	err := s.service.SubscribeForMessages(context.Background(), userID, stream)
+	// the subscription to pub-sub ends together with the stream of the client
+	err := s.service.SubscribeForMessages(stream.Context(), userID, stream)
 	if err != nil {
 		s.logger.Errorw("internal server error", "op", op, "req", req)
 		return status.Error(codes.Internal, "internal server error")
@@ -55,6 +58,7 @@ func (s *serverAPI) SendMessage(ctx context.Context, req *pb.SendMessageRequest)
 	}
 
 	err := s.service.SendMessage(
+		ctx,
 		req.Message.GetMessageId(),
 		req.Message.GetChatId(),
 		req.Message.GetSenderId(),
@@ -63,6 +67,14 @@ func (s *serverAPI) SendMessage(ctx context.Context, req *pb.SendMessageRequest)
 	)
 
 	if err != nil {
+		if errors.Is(err, service.ErrBlockedByRecipient) {
+			return nil, status.Error(codes.PermissionDenied, "recipient has blocked the sender")
+		}
+
+		if errors.Is(err, service.ErrNotChatParticipant) {
+			return nil, status.Error(codes.PermissionDenied, "sender is not a participant of the chat")
+		}
+
 		return nil, status.Error(codes.Internal, "internal server error")
 	}
 
@@ -2,34 +2,67 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"github.com/confluentinc/confluent-kafka-go/kafka"
 	"github.com/zoninnik89/messenger/chat-client/internal/logging"
-	"github.com/zoninnik89/messenger/chat-client/internal/producer"
+	"github.com/zoninnik89/messenger/common"
 	pb "github.com/zoninnik89/messenger/common/api"
+	"github.com/zoninnik89/messenger/common/bus"
 	"github.com/zoninnik89/messenger/common/discovery"
+	"github.com/zoninnik89/messenger/common/telemetry"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 	"io"
+	"slices"
+	"sync"
+	"time"
 )
 
 type ChatClient struct {
 	logger   *zap.SugaredLogger
-	queue    *producer.MessageProducer
+	queue    bus.Producer
 	registry discovery.Registry
+	pool     *discovery.Pool
+
+	// participants of groups are cached for participantsTTL, so messages to groups don't hit chat-history
+	// every time. Users added to or removed from a group may be refused or still send messages to it until
+	// the cached participants expire.
+	participantsTTL time.Duration
+	participants    sync.Map
+}
+
+type cachedParticipants struct {
+	participantIDs []string
+	expiresAt      time.Time
 }
 
-func NewChatClient(r discovery.Registry, q *producer.MessageProducer) (*ChatClient, error) {
+var (
+	ErrBlockedByRecipient = errors.New("sender is blocked by recipient")
+	ErrNotChatParticipant = errors.New("sender is not a participant of the chat")
+)
+
+// NewChatClient returns chat client of services discovered in the registry. Connections used to check
+// messages are shared by all of them, Close closes them.
+func NewChatClient(r discovery.Registry, q bus.Producer, participantsTTL time.Duration) (*ChatClient, error) {
 	const op = "service.NewChatClient"
 	logger := logging.GetLogger().Sugar()
 
 	return &ChatClient{
-		logger:   logger,
-		queue:    q,
-		registry: r,
+		logger:          logger,
+		queue:           q,
+		registry:        r,
+		pool:            discovery.NewPool(r),
+		participantsTTL: participantsTTL,
 	}, nil
 }
 
+// Close closes connections to the services.
+func (c *ChatClient) Close() error {
+	return c.pool.Close()
+}
+
 func (c *ChatClient) SubscribeForMessages(
 	ctx context.Context,
 	userID string,
@@ -43,9 +76,10 @@ func (c *ChatClient) SubscribeForMessages(
 	conn, err := discovery.ServiceConnection(context.Background(), "pub-sub", c.registry)
 
 	if err != nil {
-		c.logger.Fatalw("failed to dial pub-sub", "op", op, "err", err)
-		return err
+		c.logger.Errorw("failed to dial pub-sub", "op", op, "err", err)
+		return fmt.Errorf("%s: %w", op, err)
 	}
+	defer conn.Close()
 
 	client := pb.NewPubSubServiceClient(conn)
 
@@ -59,7 +93,9 @@ func (c *ChatClient) SubscribeForMessages(
 
 	streamFromPubSub, err := client.Subscribe(ctx, subscribeRequest)
 	if err != nil {
-		c.logger.Fatalw("failed to subscribe to pub-sub", "op", op, "user ID", userID, "err", err)
+		// the client may disconnect before the subscription is made, it mustn't take the service down
+		c.logger.Errorw("failed to subscribe to pub-sub", "op", op, "user ID", userID, "err", err)
+		return fmt.Errorf("%s: %w", op, err)
 	}
 
 	c.logger.Infow("subscribed to pub-sub", "op", op, "user", userID)
@@ -116,6 +152,7 @@ func (c *ChatClient) SubscribeForMessages(
 }
 
 func (c *ChatClient) SendMessage(
+	ctx context.Context,
 	messageID string,
 	chatID string,
 	senderID string,
@@ -124,6 +161,45 @@ func (c *ChatClient) SendMessage(
 ) error {
 	const op = "service.SendMessage"
 
+	// direct messages are refused if the recipient has blocked the sender
+	if userID, otherUserID, ok := common.ParseDirectChatID(chatID); ok {
+		var recipientID string
+		switch senderID {
+		case userID:
+			recipientID = otherUserID
+		case otherUserID:
+			recipientID = userID
+		default:
+			c.logger.Warnw("sender is not a participant of the direct chat", "op", op, "chatID", chatID, "senderID", senderID)
+			return fmt.Errorf("%s: %w", op, ErrNotChatParticipant)
+		}
+
+		blocked, err := c.isBlocked(ctx, recipientID, senderID)
+		if err != nil {
+			c.logger.Errorw("failed to check if sender is blocked", "op", op, "messageID", messageID, "err", err)
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		if blocked {
+			c.logger.Infow("message refused, sender is blocked by recipient", "op", op, "messageID", messageID, "senderID", senderID)
+			return fmt.Errorf("%s: %w", op, ErrBlockedByRecipient)
+		}
+	}
+
+	// messages to groups are refused unless the sender participates in the group
+	if common.IsGroupChatID(chatID) {
+		participates, err := c.isGroupParticipant(ctx, chatID, senderID)
+		if err != nil {
+			c.logger.Errorw("failed to check if sender participates in the group", "op", op, "messageID", messageID, "err", err)
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		if !participates {
+			c.logger.Warnw("sender is not a participant of the group", "op", op, "chatID", chatID, "senderID", senderID)
+			return fmt.Errorf("%s: %w", op, ErrNotChatParticipant)
+		}
+	}
+
 	// publish message to the chat
 	message := &pb.Message{
 		MessageId:   messageID,
@@ -139,25 +215,66 @@ func (c *ChatClient) SendMessage(
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	deliveryChan := make(chan kafka.Event)
-	defer close(deliveryChan)
+	// messages of a chat share the partition, so they are consumed in the order they were sent
+	err = c.queue.Publish(ctx, "messages", []byte(chatID), serializedMessage)
+	if err != nil {
+		c.logger.Errorw("failed to publish message", "op", op, "messageID", messageID, "err", err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	c.logger.Infow("message successfully published", "messageID", messageID, "request_id", telemetry.RequestID(ctx), "trace_id", telemetry.TraceID(ctx))
+
+	return nil
+}
 
-	err = c.queue.Publish(serializedMessage, "messages", nil, deliveryChan)
+// isBlocked asks sso service whether userID has blocked targetID.
+func (c *ChatClient) isBlocked(ctx context.Context, userID string, targetID string) (bool, error) {
+	const op = "service.isBlocked"
 
+	conn, err := c.pool.Conn("sso-service")
 	if err != nil {
-		c.logger.Errorw("failed to publish message in Kafka", "op", op, "messageID", messageID, "err", err)
-		return fmt.Errorf("%s: %w", op, err)
+		return false, fmt.Errorf("%s: %w", op, err)
 	}
 
-	e := <-deliveryChan
-	msg := e.(*kafka.Message)
+	client := pb.NewRelationsServiceClient(conn)
 
-	if msg.TopicPartition.Error != nil {
-		c.logger.Errorw("message was not published", "messageID", messageID, "error", msg.TopicPartition.Error)
-		return fmt.Errorf("%s: %w", op, msg.TopicPartition.Error)
-	} else {
-		c.logger.Infow("message successfully published", "messageID", messageID, "topic", msg.TopicPartition, "time", msg.Timestamp.String())
+	res, err := client.IsBlocked(ctx, &pb.IsBlockedRequest{UserId: userID, TargetId: targetID})
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return nil
+	return res.GetBlocked(), nil
+}
+
+// isGroupParticipant asks chat-history service whether userID participates in the group, groups which don't exist
+// have no participants.
+func (c *ChatClient) isGroupParticipant(ctx context.Context, chatID string, userID string) (bool, error) {
+	const op = "service.isGroupParticipant"
+
+	if cached, ok := c.participants.Load(chatID); ok && time.Now().Before(cached.(cachedParticipants).expiresAt) {
+		return slices.Contains(cached.(cachedParticipants).participantIDs, userID), nil
+	}
+
+	conn, err := c.pool.Conn("chat-history")
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	client := pb.NewChatHistoryServiceClient(conn)
+
+	res, err := client.GetChatParticipants(ctx, &pb.GetChatParticipantsRequest{ChatId: chatID})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	c.participants.Store(chatID, cachedParticipants{
+		participantIDs: res.GetParticipantIds(),
+		expiresAt:      time.Now().Add(c.participantsTTL),
+	})
+
+	return slices.Contains(res.GetParticipantIds(), userID), nil
 }
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zoninnik89/messenger/common"
+	pb "github.com/zoninnik89/messenger/common/api"
+	"github.com/zoninnik89/messenger/common/bus/memory"
+	"github.com/zoninnik89/messenger/common/discovery"
+	"github.com/zoninnik89/messenger/common/discovery/static"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// messagesStream is the stream of the user who requested messages
+type messagesStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *messagesStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *messagesStream) Send(*pb.Message) error {
+	return nil
+}
+
+func TestSubscribeForMessages_PubSubUnavailable(t *testing.T) {
+	// nothing listens on the address once the listener is closed
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	closedAddress := l.Addr().String()
+	_ = l.Close()
+
+	tests := []struct {
+		name     string
+		services map[string][]string
+		check    func(t *testing.T, err error)
+	}{
+		{
+			name: "no instances",
+			check: func(t *testing.T, err error) {
+				if !errors.Is(err, discovery.ErrNoInstances) {
+					t.Errorf("expected %v, got %v", discovery.ErrNoInstances, err)
+				}
+			},
+		},
+		{
+			name:     "instance down",
+			services: map[string][]string{"pub-sub": {closedAddress}},
+			check: func(t *testing.T, err error) {
+				if status.Code(err) != codes.Unavailable {
+					t.Errorf("expected unavailable, got %v", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewChatClient(static.NewRegistry(tt.services), memory.NewBroker(1).NewProducer(), time.Second)
+			if err != nil {
+				t.Fatalf("failed to create chat client: %v", err)
+			}
+			t.Cleanup(func() { _ = c.Close() })
+
+			ctx := context.Background()
+			err = c.SubscribeForMessages(ctx, "user", &messagesStream{ctx: ctx})
+			tt.check(t, err)
+		})
+	}
+}
+
+// chatHistory knows participants of every group and counts how many times it was asked for them
+type chatHistory struct {
+	pb.UnimplementedChatHistoryServiceServer
+
+	participantIDs []string
+	calls          atomic.Int32
+}
+
+func (h *chatHistory) GetChatParticipants(context.Context, *pb.GetChatParticipantsRequest) (*pb.GetChatParticipantsResponse, error) {
+	h.calls.Add(1)
+	return &pb.GetChatParticipantsResponse{ParticipantIds: h.participantIDs}, nil
+}
+
+func TestSendMessage_GroupParticipantsCached(t *testing.T) {
+	history := &chatHistory{participantIDs: []string{"user1", "user2"}}
+	historyServer := grpc.NewServer()
+	pb.RegisterChatHistoryServiceServer(historyServer, history)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	go func() { _ = historyServer.Serve(l) }()
+	t.Cleanup(historyServer.Stop)
+
+	registry := static.NewRegistry(map[string][]string{"chat-history": {l.Addr().String()}})
+	c, err := NewChatClient(registry, memory.NewBroker(1).NewProducer(), time.Minute)
+	if err != nil {
+		t.Fatalf("failed to create chat client: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+
+	ctx := context.Background()
+	chatID := common.GroupChatID("group")
+
+	tests := []struct {
+		name     string
+		senderID string
+		wantErr  error
+	}{
+		{name: "participant", senderID: "user1"},
+		{name: "other participant", senderID: "user2"},
+		{name: "not a participant", senderID: "user3", wantErr: ErrNotChatParticipant},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := c.SendMessage(ctx, "message", chatID, tt.senderID, "text", "0")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	if calls := history.calls.Load(); calls != 1 {
+		t.Errorf("expected participants to be asked once, asked %d times", calls)
+	}
+}
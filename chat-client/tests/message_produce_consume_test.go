@@ -6,7 +6,11 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	suite "github.com/zoninnik89/messenger/chat-client/tests/suite"
+	"github.com/zoninnik89/messenger/common"
 	pb "github.com/zoninnik89/messenger/common/api"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 	"testing"
 	"time"
 )
@@ -43,23 +47,30 @@ func TestMessageSendReceive_HappyPath(t *testing.T) {
 	var receivedMessages []*pb.Message
 	var receivedMessages2 []*pb.Message
 
+	timeout := time.After(5 * time.Second)
+
+loop:
 	for {
 		select {
 		case msg, ok := <-receivedMessagesChan:
-			if ok {
-				receivedMessages = append(receivedMessages, msg)
+			if !ok {
+				receivedMessagesChan = nil
+				continue
 			}
+			receivedMessages = append(receivedMessages, msg)
 		case msg, ok := <-receivedMessagesChan2:
-			if ok {
-				receivedMessages2 = append(receivedMessages2, msg)
+			if !ok {
+				receivedMessagesChan2 = nil
+				continue
 			}
-		case <-time.After(5 * time.Second):
+			receivedMessages2 = append(receivedMessages2, msg)
+		case <-timeout:
 			cancel()
 			cancel2()
-			break
+			break loop
 		}
 
-		// Break the loop when both channels are closed
+		// Break the loop when both subscribers received the message
 		if len(receivedMessages) == 1 && len(receivedMessages2) == 1 {
 			cancel()
 			cancel2()
@@ -108,21 +119,28 @@ func TestMessageProduceConsume_OneChatParticipant(t *testing.T) {
 	var receivedMessages []*pb.Message
 	var receivedMessages2 []*pb.Message
 
+	timeout := time.After(5 * time.Second)
+
+loop:
 	for {
 		select {
 		case msg, ok := <-receivedMessagesChan:
-			if ok {
-				receivedMessages = append(receivedMessages, msg)
+			if !ok {
+				receivedMessagesChan = nil
+				continue
 			}
+			receivedMessages = append(receivedMessages, msg)
 		case msg, ok := <-receivedMessagesChan2:
-			if ok {
-				receivedMessages2 = append(receivedMessages2, msg)
+			if !ok {
+				receivedMessagesChan2 = nil
+				continue
 			}
-		case <-time.After(5 * time.Second):
+			receivedMessages2 = append(receivedMessages2, msg)
+		case <-timeout:
 			cancel()
 			receivedMessagesChan = nil
 			receivedMessagesChan2 = nil
-			break
+			break loop
 		}
 
 		if receivedMessagesChan == nil && receivedMessagesChan2 == nil {
@@ -134,3 +152,55 @@ func TestMessageProduceConsume_OneChatParticipant(t *testing.T) {
 	assert.Equal(t, len(receivedMessages), 1)
 	assert.Equal(t, len(receivedMessagesChan2), 0)
 }
+
+func TestSendMessage_PublishedByChat(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	chatID := "chat1"
+	messageIDs := []string{gofakeit.UUID(), gofakeit.UUID(), gofakeit.UUID()}
+
+	for _, messageID := range messageIDs {
+		require.NoError(t, st.SendMessage(ctx, messageID, chatID, "user1", gofakeit.Word()))
+	}
+
+	published := st.Bus.Messages("messages")
+	require.Len(t, published, len(messageIDs))
+
+	// messages of a chat are keyed by chat ID, so they share the partition and keep their order
+	for i, msg := range published {
+		var message pb.Message
+		require.NoError(t, proto.Unmarshal(msg.Value, &message))
+
+		assert.Equal(t, chatID, string(msg.Key))
+		assert.Equal(t, published[0].Partition, msg.Partition)
+		assert.Equal(t, messageIDs[i], message.GetMessageId())
+	}
+}
+
+func TestSendMessage_GroupRequiresParticipant(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	chatID := common.GroupChatID(gofakeit.UUID())
+	st.SeedGroup(chatID, "user1", "user2")
+
+	require.NoError(t, st.SendMessage(ctx, gofakeit.UUID(), chatID, "user1", gofakeit.Word()))
+
+	tests := []struct {
+		name     string
+		chatID   string
+		senderID string
+	}{
+		{name: "not a participant", chatID: chatID, senderID: "user3"},
+		{name: "unknown group", chatID: common.GroupChatID(gofakeit.UUID()), senderID: "user1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := st.SendMessage(ctx, gofakeit.UUID(), tt.chatID, tt.senderID, gofakeit.Word())
+			assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		})
+	}
+
+	// only the message of the participant is published
+	assert.Len(t, st.Bus.Messages("messages"), 1)
+}
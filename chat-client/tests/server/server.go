@@ -0,0 +1,87 @@
+// Package server runs chat-client service in the process of a test, publishing messages to an in-memory bus.
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+	"runtime"
+	"strconv"
+
+	grpcapp "github.com/zoninnik89/messenger/chat-client/internal/app/grpc"
+	"github.com/zoninnik89/messenger/chat-client/internal/config"
+	"github.com/zoninnik89/messenger/chat-client/internal/service"
+	"github.com/zoninnik89/messenger/common/bus/memory"
+	"github.com/zoninnik89/messenger/common/discovery"
+)
+
+const host = "127.0.0.1"
+
+// Server is chat-client service listening on an ephemeral port.
+type Server struct {
+	// Addr is the address of the gRPC server
+	Addr string
+	Cfg  *config.Config
+
+	app        *grpcapp.App
+	service    *service.ChatClient
+	registry   discovery.Registry
+	instanceID string
+}
+
+// Start starts the service with the local config and registers it in the registry. Messages are streamed
+// from pub-sub service found in the registry.
+func Start(registry discovery.Registry, broker *memory.Broker) (*Server, error) {
+	const op = "server.Start"
+
+	cfg := config.MustLoadByPath(filepath.Join(moduleDir(), "config", "local.yaml"))
+
+	chatClientService, err := service.NewChatClient(registry, broker.NewProducer(), cfg.Chats.ParticipantsTTL)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	application := grpcapp.NewApp(chatClientService, 0)
+
+	l, err := net.Listen("tcp", net.JoinHostPort(host, "0"))
+	if err != nil {
+		_ = chatClientService.Close()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	go func() {
+		_ = application.Serve(l)
+	}()
+
+	port := l.Addr().(*net.TCPAddr).Port
+	s := &Server{
+		Addr:       net.JoinHostPort(host, strconv.Itoa(port)),
+		Cfg:        cfg,
+		app:        application,
+		service:    chatClientService,
+		registry:   registry,
+		instanceID: discovery.GenerateInstanceID(cfg.GRPC.Name),
+	}
+
+	if err := registry.Register(context.Background(), s.instanceID, host, port, cfg.GRPC.Name); err != nil {
+		s.Stop()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return s, nil
+}
+
+// Stop deregisters the service and stops the gRPC server gracefully, so streams must be ended by their
+// clients first.
+func (s *Server) Stop() {
+	_ = s.registry.Deregister(context.Background(), s.instanceID)
+	s.app.Stop()
+	_ = s.service.Close()
+}
+
+// moduleDir returns the root directory of chat-client module, so the server starts the same way from any test package
+func moduleDir() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..")
+}
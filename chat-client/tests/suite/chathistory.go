@@ -0,0 +1,42 @@
+package suite
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	pb "github.com/zoninnik89/messenger/common/api"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// chatHistory stands in for chat-history service, it knows participants of groups seeded by tests
+type chatHistory struct {
+	pb.UnimplementedChatHistoryServiceServer
+
+	mu     sync.Mutex
+	groups map[string][]string
+}
+
+func newChatHistory() *chatHistory {
+	return &chatHistory{groups: make(map[string][]string)}
+}
+
+func (h *chatHistory) GetChatParticipants(_ context.Context, req *pb.GetChatParticipantsRequest) (*pb.GetChatParticipantsResponse, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	participantIDs, ok := h.groups[req.GetChatId()]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "chat not found")
+	}
+
+	return &pb.GetChatParticipantsResponse{ParticipantIds: slices.Clone(participantIDs)}, nil
+}
+
+func (h *chatHistory) seedGroup(chatID string, participantIDs []string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.groups[chatID] = slices.Clone(participantIDs)
+}
@@ -0,0 +1,74 @@
+package suite
+
+import (
+	"context"
+	"sync"
+
+	pb "github.com/zoninnik89/messenger/common/api"
+	"github.com/zoninnik89/messenger/common/bus"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// pubSub stands in for pub-sub service, it sends every message published to the bus to every subscriber
+type pubSub struct {
+	pb.UnimplementedPubSubServiceServer
+
+	mu          sync.Mutex
+	subscribers map[string]chan *pb.Message
+}
+
+func newPubSub() *pubSub {
+	return &pubSub{subscribers: make(map[string]chan *pb.Message)}
+}
+
+func (p *pubSub) Subscribe(req *pb.SubscribeRequest, stream grpc.ServerStreamingServer[pb.Message]) error {
+	messages := make(chan *pb.Message, 10)
+
+	p.mu.Lock()
+	p.subscribers[req.GetUserId()] = messages
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		if p.subscribers[req.GetUserId()] == messages {
+			delete(p.subscribers, req.GetUserId())
+		}
+		p.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case msg := <-messages:
+			if err := stream.Send(msg); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+// consume sends out messages read from the bus until ctx is done
+func (p *pubSub) consume(ctx context.Context, consumer bus.Consumer) {
+	for {
+		msg, err := consumer.ReadMessage(ctx)
+		if err != nil {
+			return
+		}
+
+		var message pb.Message
+		if err := proto.Unmarshal(msg.Value, &message); err != nil {
+			continue
+		}
+
+		p.mu.Lock()
+		for _, messages := range p.subscribers {
+			select {
+			case messages <- &message:
+			default:
+			}
+		}
+		p.mu.Unlock()
+	}
+}
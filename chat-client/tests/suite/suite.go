@@ -3,27 +3,49 @@ package suite
 import (
 	"context"
 	"github.com/zoninnik89/messenger/chat-client/internal/config"
+	"github.com/zoninnik89/messenger/chat-client/tests/server"
 	pb "github.com/zoninnik89/messenger/common/api"
+	"github.com/zoninnik89/messenger/common/bus/memory"
+	"github.com/zoninnik89/messenger/common/discovery/static"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"io"
 	"log"
 	"net"
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 )
 
 const (
-	grpcHost = "localhost"
+	grpcHost = "127.0.0.1"
+	// partitions of topics of the in-memory bus
+	partitions = 3
 )
 
 type Suite struct {
 	*testing.T
 	Cfg                     *config.Config
 	ChatClientServiceClient pb.ChatClientServiceClient
+	// Bus is the in-memory bus chat-client publishes messages to
+	Bus *memory.Broker
+
+	history *chatHistory
+}
+
+// instance is chat-client service running in the test process, all suites of a test share it
+type instance struct {
+	address string
+	bus     *memory.Broker
+	history *chatHistory
 }
 
+var (
+	mu        sync.Mutex
+	instances = make(map[string]*instance)
+)
+
 func New(t *testing.T) (context.Context, *Suite) {
 	t.Helper()
 	//t.Parallel()
@@ -36,21 +58,105 @@ func New(t *testing.T) (context.Context, *Suite) {
 		cancelCtx()
 	})
 
-	cc, err := grpc.NewClient(grpcAddress(cfg),
+	chatClient := start(t)
+
+	cc, err := grpc.NewClient(chatClient.address,
 		grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err != nil {
 		return nil, nil
 	}
 
+	// closing the connection ends streams, so the service can stop gracefully
+	t.Cleanup(func() {
+		_ = cc.Close()
+	})
+
 	return ctx, &Suite{
 		T:                       t,
 		Cfg:                     cfg,
 		ChatClientServiceClient: pb.NewChatClientServiceClient(cc),
+		Bus:                     chatClient.bus,
+		history:                 chatClient.history,
+	}
+}
+
+// start runs chat-client service for the test on an ephemeral port. It publishes messages to an in-memory bus,
+// and streams messages from a stand-in of pub-sub service, which sends out everything published to the bus.
+// Participants of groups are asked from a stand-in of chat-history service, see SeedGroup.
+func start(t *testing.T) *instance {
+	t.Helper()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if chatClient, ok := instances[t.Name()]; ok {
+		return chatClient
+	}
+
+	broker := memory.NewBroker(partitions)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	consumer := broker.NewConsumer("pub-sub-group", memory.Latest)
+	if err := consumer.SubscribeTopics([]string{"messages"}); err != nil {
+		t.Fatalf("failed to subscribe to messages: %v", err)
+	}
+
+	pubSub := newPubSub()
+	pubSubServer := grpc.NewServer()
+	pb.RegisterPubSubServiceServer(pubSubServer, pubSub)
+	pubSubAddress := serve(t, func(l net.Listener) { _ = pubSubServer.Serve(l) })
+	go pubSub.consume(ctx, consumer)
+
+	history := newChatHistory()
+	historyServer := grpc.NewServer()
+	pb.RegisterChatHistoryServiceServer(historyServer, history)
+	historyAddress := serve(t, func(l net.Listener) { _ = historyServer.Serve(l) })
+
+	registry := static.NewRegistry(map[string][]string{
+		"pub-sub":      {pubSubAddress},
+		"chat-history": {historyAddress},
+	})
+
+	srv, err := server.Start(registry, broker)
+	if err != nil {
+		t.Fatalf("failed to start chat-client: %v", err)
 	}
+
+	chatClient := &instance{address: srv.Addr, bus: broker, history: history}
+	instances[t.Name()] = chatClient
+
+	t.Cleanup(func() {
+		cancel()
+		srv.Stop()
+		pubSubServer.Stop()
+		historyServer.Stop()
+		_ = consumer.Close()
+
+		mu.Lock()
+		delete(instances, t.Name())
+		mu.Unlock()
+	})
+
+	return chatClient
+}
+
+// serve starts serving on an ephemeral port and returns its address
+func serve(t *testing.T, serve func(l net.Listener)) string {
+	t.Helper()
+
+	l, err := net.Listen("tcp", net.JoinHostPort(grpcHost, "0"))
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	go serve(l)
+
+	return l.Addr().String()
 }
 
-func grpcAddress(cfg *config.Config) string {
-	return net.JoinHostPort(grpcHost, strconv.Itoa(cfg.GRPC.Port))
+// SeedGroup makes the group known to chat-history, so its participants may send messages to it.
+func (s *Suite) SeedGroup(chatID string, participantIDs ...string) {
+	s.history.seedGroup(chatID, participantIDs)
 }
 
 func (s *Suite) SubscribeToChat(ctx context.Context, userID string, messages chan<- *pb.Message) {
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/zoninnik89/messenger/chat-history/types"
+	pb "github.com/zoninnik89/messenger/common/api"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const chatsCollectionName = "chats"
+
+type chat struct {
+	ChatID         string   `bson:"chat_id"`
+	Kind           int32    `bson:"kind"`
+	Name           string   `bson:"name,omitempty"`
+	AvatarURL      string   `bson:"avatar_url,omitempty"`
+	OwnerID        string   `bson:"owner_id,omitempty"`
+	ParticipantIDs []string `bson:"participant_ids"`
+	CreatedAt      int64    `bson:"created_at"`
+}
+
+func (s *Store) SaveChat(ctx context.Context, c *pb.Chat) error {
+	const op = "store.SaveChat"
+
+	_, err := s.chats.InsertOne(ctx, chat{
+		ChatID:         c.GetChatId(),
+		Kind:           int32(c.GetKind()),
+		Name:           c.GetName(),
+		AvatarURL:      c.GetAvatarUrl(),
+		OwnerID:        c.GetOwnerId(),
+		ParticipantIDs: c.GetParticipantIds(),
+		CreatedAt:      c.GetCreatedAt(),
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Store) EnsureDirectChat(ctx context.Context, chatID string, participantIDs []string, createdAt int64) error {
+	const op = "store.EnsureDirectChat"
+
+	_, err := s.chats.UpdateOne(ctx,
+		bson.M{"chat_id": chatID},
+		bson.M{"$setOnInsert": chat{
+			ChatID:         chatID,
+			Kind:           int32(pb.ChatKind_CHAT_KIND_DIRECT),
+			ParticipantIDs: participantIDs,
+			CreatedAt:      createdAt,
+		}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Store) Chat(ctx context.Context, chatID string) (*pb.Chat, error) {
+	const op = "store.Chat"
+
+	var doc chat
+	err := s.chats.FindOne(ctx, bson.M{"chat_id": chatID}).Decode(&doc)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, fmt.Errorf("%s: %w", op, types.ErrChatNotFound)
+		}
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return doc.toProto(), nil
+}
+
+func (s *Store) ChatsOf(ctx context.Context, userID string) ([]*pb.Chat, error) {
+	const op = "store.ChatsOf"
+
+	cursor, err := s.chats.Find(ctx, bson.M{"participant_ids": userID})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer cursor.Close(ctx)
+
+	var docs []chat
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	chats := make([]*pb.Chat, 0, len(docs))
+	for _, doc := range docs {
+		chats = append(chats, doc.toProto())
+	}
+
+	return chats, nil
+}
+
+func (s *Store) SetChatName(ctx context.Context, chatID, name string) error {
+	const op = "store.SetChatName"
+
+	if err := s.updateChat(ctx, chatID, bson.M{"$set": bson.M{"name": name}}); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Store) SetChatAvatar(ctx context.Context, chatID, avatarURL string) error {
+	const op = "store.SetChatAvatar"
+
+	if err := s.updateChat(ctx, chatID, bson.M{"$set": bson.M{"avatar_url": avatarURL}}); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Store) SetChatOwner(ctx context.Context, chatID, ownerID string) error {
+	const op = "store.SetChatOwner"
+
+	if err := s.updateChat(ctx, chatID, bson.M{"$set": bson.M{"owner_id": ownerID}}); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// AddParticipants appends users to participants of the chat, users who already participate keep their place.
+func (s *Store) AddParticipants(ctx context.Context, chatID string, participantIDs []string) error {
+	const op = "store.AddParticipants"
+
+	err := s.updateChat(ctx, chatID, bson.M{"$addToSet": bson.M{"participant_ids": bson.M{"$each": participantIDs}}})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Store) RemoveParticipant(ctx context.Context, chatID, userID string) error {
+	const op = "store.RemoveParticipant"
+
+	if err := s.updateChat(ctx, chatID, bson.M{"$pull": bson.M{"participant_ids": userID}}); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Store) DeleteChat(ctx context.Context, chatID string) error {
+	const op = "store.DeleteChat"
+
+	res, err := s.chats.DeleteOne(ctx, bson.M{"chat_id": chatID})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if res.DeletedCount == 0 {
+		return fmt.Errorf("%s: %w", op, types.ErrChatNotFound)
+	}
+
+	return nil
+}
+
+func (s *Store) updateChat(ctx context.Context, chatID string, update bson.M) error {
+	res, err := s.chats.UpdateOne(ctx, bson.M{"chat_id": chatID}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return types.ErrChatNotFound
+	}
+
+	return nil
+}
+
+func (c chat) toProto() *pb.Chat {
+	return &pb.Chat{
+		ChatId:         c.ChatID,
+		Kind:           pb.ChatKind(c.Kind),
+		Name:           c.Name,
+		AvatarUrl:      c.AvatarURL,
+		OwnerId:        c.OwnerID,
+		ParticipantIds: c.ParticipantIDs,
+		CreatedAt:      c.CreatedAt,
+	}
+}
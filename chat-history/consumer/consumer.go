@@ -2,13 +2,14 @@ package consumer
 
 import (
 	"github.com/confluentinc/confluent-kafka-go/kafka"
+	kafkabus "github.com/zoninnik89/messenger/common/bus/kafka"
 )
 
 func NewKafkaConsumer(
 	kafkaServerAddress string,
 	kafkaConsumerID string,
 	kafkaGroupID string,
-) (*kafka.Consumer, error) {
+) (*kafkabus.Consumer, error) {
 
 	configMap := &kafka.ConfigMap{
 		"bootstrap.servers": kafkaServerAddress,
@@ -16,10 +17,5 @@ func NewKafkaConsumer(
 		"group.id":          kafkaGroupID,
 	}
 
-	c, err := kafka.NewConsumer(configMap)
-
-	if err != nil {
-		return nil, err
-	}
-	return c, nil
+	return kafkabus.NewConsumer(configMap)
 }
@@ -2,11 +2,23 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
 	"github.com/zoninnik89/messenger/chat-history/logging"
 	"github.com/zoninnik89/messenger/chat-history/types"
 	pb "github.com/zoninnik89/messenger/common/api"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	maxChatNameLength  = 128
+	maxAvatarURLLength = 2048
 )
 
 type GrpcHandler struct {
@@ -22,11 +34,214 @@ func NewGrpcHandler(grpcServer *grpc.Server, s types.ChatHistoryServiceInterface
 }
 
 func (h *GrpcHandler) GetMessages(ctx context.Context, req *pb.GetMessagesRequest) (*pb.GetMessagesResponse, error) {
+	if req.GetChatId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "chat id is required")
+	}
+
 	res, err := h.service.GetMessages(ctx, req)
 	if err != nil {
 		h.logger.Errorw("error getting messages", "chatID", req.ChatId, "fromTS", req.FromTs, "toTS", req.ToTs, "error", err)
+		return nil, chatError(err)
 	}
 
 	h.logger.Infow("Successfully retrieved messages", "chatID", req.ChatId, "fromTS", req.FromTs, "toTS", req.ToTs)
 	return res, nil
 }
+
+func (h *GrpcHandler) GetUserMessages(ctx context.Context, req *pb.GetUserMessagesRequest) (*pb.GetUserMessagesResponse, error) {
+	res, err := h.service.GetUserMessages(ctx, req)
+	if err != nil {
+		h.logger.Errorw("error getting user messages", "userID", req.UserId, "error", err)
+		return nil, err
+	}
+
+	h.logger.Infow("Successfully retrieved user messages", "userID", req.UserId)
+	return res, nil
+}
+
+func (h *GrpcHandler) ListChats(ctx context.Context, req *pb.ListChatsRequest) (*pb.ListChatsResponse, error) {
+	if err := validateUser(req.GetUserId()); err != nil {
+		return nil, err
+	}
+
+	chats, err := h.service.ListChats(ctx, req.GetUserId())
+	if err != nil {
+		h.logger.Errorw("error listing chats", "userID", req.GetUserId(), "error", err)
+		return nil, chatError(err)
+	}
+
+	return &pb.ListChatsResponse{Chats: chats}, nil
+}
+
+func (h *GrpcHandler) CreateGroup(ctx context.Context, req *pb.CreateGroupRequest) (*pb.CreateGroupResponse, error) {
+	if err := validateUser(req.GetUserId()); err != nil {
+		return nil, err
+	}
+	if err := validateChatName(req.GetName()); err != nil {
+		return nil, err
+	}
+	if err := validateAvatarURL(req.GetAvatarUrl()); err != nil {
+		return nil, err
+	}
+
+	chat, err := h.service.CreateGroup(ctx, req.GetUserId(), strings.TrimSpace(req.GetName()), req.GetAvatarUrl(), req.GetParticipantIds())
+	if err != nil {
+		h.logger.Errorw("error creating group", "userID", req.GetUserId(), "error", err)
+		return nil, chatError(err)
+	}
+
+	h.logger.Infow("Successfully created group", "userID", req.GetUserId(), "chatID", chat.GetChatId())
+	return &pb.CreateGroupResponse{Chat: chat}, nil
+}
+
+func (h *GrpcHandler) RenameChat(ctx context.Context, req *pb.RenameChatRequest) (*pb.RenameChatResponse, error) {
+	if err := validateChat(req.GetUserId(), req.GetChatId()); err != nil {
+		return nil, err
+	}
+	if err := validateChatName(req.GetName()); err != nil {
+		return nil, err
+	}
+
+	if err := h.service.RenameChat(ctx, req.GetUserId(), req.GetChatId(), strings.TrimSpace(req.GetName())); err != nil {
+		h.logger.Errorw("error renaming chat", "userID", req.GetUserId(), "chatID", req.GetChatId(), "error", err)
+		return nil, chatError(err)
+	}
+
+	return &pb.RenameChatResponse{Status: "ok"}, nil
+}
+
+func (h *GrpcHandler) SetChatAvatar(ctx context.Context, req *pb.SetChatAvatarRequest) (*pb.SetChatAvatarResponse, error) {
+	if err := validateChat(req.GetUserId(), req.GetChatId()); err != nil {
+		return nil, err
+	}
+	if err := validateAvatarURL(req.GetAvatarUrl()); err != nil {
+		return nil, err
+	}
+
+	if err := h.service.SetChatAvatar(ctx, req.GetUserId(), req.GetChatId(), req.GetAvatarUrl()); err != nil {
+		h.logger.Errorw("error setting chat avatar", "userID", req.GetUserId(), "chatID", req.GetChatId(), "error", err)
+		return nil, chatError(err)
+	}
+
+	return &pb.SetChatAvatarResponse{Status: "ok"}, nil
+}
+
+func (h *GrpcHandler) AddParticipants(ctx context.Context, req *pb.AddParticipantsRequest) (*pb.AddParticipantsResponse, error) {
+	if err := validateChat(req.GetUserId(), req.GetChatId()); err != nil {
+		return nil, err
+	}
+	if len(req.GetParticipantIds()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "participant ids are required")
+	}
+
+	if err := h.service.AddParticipants(ctx, req.GetUserId(), req.GetChatId(), req.GetParticipantIds()); err != nil {
+		h.logger.Errorw("error adding participants", "userID", req.GetUserId(), "chatID", req.GetChatId(), "error", err)
+		return nil, chatError(err)
+	}
+
+	return &pb.AddParticipantsResponse{Status: "ok"}, nil
+}
+
+func (h *GrpcHandler) RemoveParticipant(ctx context.Context, req *pb.RemoveParticipantRequest) (*pb.RemoveParticipantResponse, error) {
+	if err := validateChat(req.GetUserId(), req.GetChatId()); err != nil {
+		return nil, err
+	}
+	if req.GetParticipantId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "participant id is required")
+	}
+
+	if err := h.service.RemoveParticipant(ctx, req.GetUserId(), req.GetChatId(), req.GetParticipantId()); err != nil {
+		h.logger.Errorw("error removing participant", "userID", req.GetUserId(), "chatID", req.GetChatId(), "error", err)
+		return nil, chatError(err)
+	}
+
+	return &pb.RemoveParticipantResponse{Status: "ok"}, nil
+}
+
+func (h *GrpcHandler) LeaveChat(ctx context.Context, req *pb.LeaveChatRequest) (*pb.LeaveChatResponse, error) {
+	if err := validateChat(req.GetUserId(), req.GetChatId()); err != nil {
+		return nil, err
+	}
+
+	if err := h.service.LeaveChat(ctx, req.GetUserId(), req.GetChatId()); err != nil {
+		h.logger.Errorw("error leaving chat", "userID", req.GetUserId(), "chatID", req.GetChatId(), "error", err)
+		return nil, chatError(err)
+	}
+
+	return &pb.LeaveChatResponse{Status: "ok"}, nil
+}
+
+func (h *GrpcHandler) GetChatParticipants(ctx context.Context, req *pb.GetChatParticipantsRequest) (*pb.GetChatParticipantsResponse, error) {
+	if req.GetChatId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "chat id is required")
+	}
+
+	participantIDs, err := h.service.ChatParticipants(ctx, req.GetChatId())
+	if err != nil {
+		h.logger.Errorw("error getting chat participants", "chatID", req.GetChatId(), "error", err)
+		return nil, chatError(err)
+	}
+
+	return &pb.GetChatParticipantsResponse{ParticipantIds: participantIDs}, nil
+}
+
+func chatError(err error) error {
+	switch {
+	case errors.Is(err, types.ErrChatNotFound):
+		return status.Error(codes.NotFound, "chat not found")
+	case errors.Is(err, types.ErrNotChatParticipant):
+		return status.Error(codes.PermissionDenied, types.ErrNotChatParticipant.Error())
+	case errors.Is(err, types.ErrNotChatOwner):
+		return status.Error(codes.PermissionDenied, types.ErrNotChatOwner.Error())
+	case errors.Is(err, types.ErrDirectChat):
+		return status.Error(codes.FailedPrecondition, types.ErrDirectChat.Error())
+	case errors.Is(err, types.ErrTooManyParticipants):
+		return status.Error(codes.InvalidArgument, types.ErrTooManyParticipants.Error())
+	}
+
+	return status.Error(codes.Internal, "internal server error")
+}
+
+func validateUser(userID string) error {
+	if userID == "" {
+		return status.Error(codes.InvalidArgument, "user id is required")
+	}
+	return nil
+}
+
+func validateChat(userID string, chatID string) error {
+	if err := validateUser(userID); err != nil {
+		return err
+	}
+	if chatID == "" {
+		return status.Error(codes.InvalidArgument, "chat id is required")
+	}
+	return nil
+}
+
+func validateChatName(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return status.Error(codes.InvalidArgument, "chat name is required")
+	}
+	if utf8.RuneCountInString(name) > maxChatNameLength {
+		return status.Errorf(codes.InvalidArgument, "chat name is longer than %d characters", maxChatNameLength)
+	}
+	return nil
+}
+
+// validateAvatarURL accepts empty URL, which removes the avatar
+func validateAvatarURL(avatarURL string) error {
+	if avatarURL == "" {
+		return nil
+	}
+	if len(avatarURL) > maxAvatarURLLength {
+		return status.Error(codes.InvalidArgument, "avatar url is too long")
+	}
+
+	u, err := url.Parse(avatarURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return status.Error(codes.InvalidArgument, "avatar url must be an absolute http(s) url")
+	}
+	return nil
+}
@@ -9,7 +9,8 @@ import (
 	s "github.com/zoninnik89/messenger/chat-history/service"
 	common "github.com/zoninnik89/messenger/common"
 	"github.com/zoninnik89/messenger/common/discovery"
-	"github.com/zoninnik89/messenger/common/discovery/consul"
+	"github.com/zoninnik89/messenger/common/discovery/backend"
+	"github.com/zoninnik89/messenger/common/telemetry"
 	"go.mongodb.org/mongo-driver/mongo"
 	_ "go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -20,53 +21,106 @@ import (
 	"google.golang.org/grpc"
 	_ "google.golang.org/grpc"
 	"net"
-	_ "strconv"
+	"strconv"
 	"time"
 )
 
 var (
-	serviceName   = "chat-history"
-	grpcAddress   = common.EnvString("GRPC_ADDR", ":2001")
-	consulAddress = common.EnvString("CONSUL_ADDR", ":8500")
-	mongoUser     = common.EnvString("MONGO_DB_USER", "root")
-	mongoPass     = common.EnvString("MONGO_DB_PASS", "rootpassword")
-	mongoAddr     = common.EnvString("MONGO_DB_HOST", "localhost:27017")
+	serviceName = "chat-history"
+	// chat-client listens on :2001 locally, so chat-history takes :2002 which the static discovery configs
+	// of the other services point to
+	grpcAddress   = common.EnvString("GRPC_ADDR", ":2002")
+	consulAddress = common.EnvString("CONSUL_ADDR", "localhost:8500")
+	// "consul", "static" or "dns", chat-history doesn't call other services, so it only matters for registration
+	discoveryBackend   = common.EnvString("DISCOVERY_BACKEND", backend.Consul)
+	discoveryDNSDomain = common.EnvString("DISCOVERY_DNS_DOMAIN", "service.consul")
+	discoveryDNSServer = common.EnvString("DISCOVERY_DNS_SERVER", "")
+	mongoUser          = common.EnvString("MONGO_DB_USER", "root")
+	mongoPass          = common.EnvString("MONGO_DB_PASS", "rootpassword")
+	mongoAddr          = common.EnvString("MONGO_DB_HOST", "localhost:27017")
+	kafkaAddress       = common.EnvString("KAFKA_SERVER_ADDRESS", "localhost:9092")
+	// "none", "stdout" or "otlp", see telemetry.Config
+	tracingExporter    = common.EnvString("TRACING_EXPORTER", telemetry.None)
+	tracingEndpoint    = common.EnvString("TRACING_ENDPOINT", "localhost:4317")
+	tracingSampleRatio = common.EnvString("TRACING_SAMPLE_RATIO", "1")
 )
 
 func main() {
 	logger := logging.InitLogger()
 	defer logging.Sync()
 
-	registry, err := consul.NewRegistry(consulAddress, serviceName)
+	sampleRatio, err := strconv.ParseFloat(tracingSampleRatio, 64)
 	if err != nil {
-		logger.Panic("Failed to connect to Consul", zap.Error(err))
+		logger.Panic("Invalid tracing sample ratio", zap.Error(err))
+		panic(err)
+	}
+
+	shutdownTracing, err := telemetry.Init(
+		context.Background(),
+		serviceName,
+		telemetry.Config{Exporter: tracingExporter, Endpoint: tracingEndpoint, SampleRatio: sampleRatio},
+	)
+	if err != nil {
+		logger.Panic("Failed to set up tracing", zap.Error(err))
+		panic(err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Warn("Failed to flush spans", zap.Error(err))
+		}
+	}()
+
+	consulHost, consulPort, err := splitHostPort(consulAddress)
+	if err != nil {
+		logger.Panic("Invalid Consul address", zap.Error(err))
+		panic(err)
+	}
+
+	registry, err := backend.New(
+		backend.Config{
+			Backend: discoveryBackend,
+			DNS:     backend.DNSConfig{Domain: discoveryDNSDomain, Server: discoveryDNSServer},
+		},
+		consulHost,
+		consulPort,
+	)
+	if err != nil {
+		logger.Panic("Failed to create service registry", zap.Error(err))
+		panic(err)
+	}
+
+	grpcHost, grpcPort, err := splitHostPort(grpcAddress)
+	if err != nil {
+		logger.Panic("Invalid GRPC address", zap.Error(err))
 		panic(err)
 	}
 
 	ctx := context.Background()
 	instanceID := discovery.GenerateInstanceID(serviceName)
-	if err := registry.Register(ctx, instanceID, serviceName, grpcAddress); err != nil {
+	if err := registry.Register(ctx, instanceID, grpcHost, grpcPort, serviceName); err != nil {
 		logger.Panic("Failed to register service", zap.Error(err))
 		panic(err)
 	}
 
 	go func() {
 		for {
-			if err := registry.HealthCheck(instanceID, serviceName); err != nil {
+			if err := registry.HealthCheck(instanceID); err != nil {
 				logger.Warn("Failed to health check", zap.Error(err))
 			}
 			time.Sleep(time.Second * 1)
 		}
 	}()
 
-	defer func(registry *consul.Registry, ctx context.Context, instanceID string, serviceName string) {
-		err := registry.Deregister(ctx, instanceID, serviceName)
+	defer func(registry discovery.Registry, ctx context.Context, instanceID string) {
+		err := registry.Deregister(ctx, instanceID)
 		if err != nil {
 			logger.Fatal("Failed to deregister service", zap.Error(err))
 		}
-	}(registry, ctx, instanceID, serviceName)
+	}(registry, ctx, instanceID)
 
-	grpcServer := grpc.NewServer()
+	grpcServer := grpc.NewServer(telemetry.ServerOption())
 
 	l, err := net.Listen("tcp", grpcAddress)
 	if err != nil {
@@ -93,28 +147,49 @@ func main() {
 	logger.Info("Starting GRPC server", zap.String("port", grpcAddress))
 
 	logger.Info("Starting Kafka Consumer")
-	consumer, err := c.NewKafkaConsumer()
+	consumer, err := c.NewKafkaConsumer(kafkaAddress, "chat-history-consumer", "chat-history-messages-group")
 	if err != nil {
 		logger.Panic("Failed to create kafka consumer", zap.Error(err))
 		panic(err)
 	}
 
 	topics := []string{"messages", "read_events"}
-	err = consumer.SubscribeTopics(topics, nil)
+	err = consumer.SubscribeTopics(topics)
 
 	if err != nil {
 		panic(err)
 	}
 
 	go func() {
-		m, err := service.ConsumeMessage(ctx, consumer)
-		if err != nil {
-			logger.Fatal("Error consuming a message", zap.Error(err))
-		} else {
-			logger.Info("Message was consumed with status", zap.String("message", m.Status))
+		for {
+			m, err := service.ConsumeMessage(ctx, consumer)
+			if err != nil {
+				logger.Warn("Error consuming a message", zap.Error(err))
+			} else {
+				logger.Info("Message was consumed", zap.String("messageID", m.GetMessageId()))
+			}
 		}
+	}()
+
+	accountEventsConsumer, err := c.NewKafkaConsumer(kafkaAddress, "chat-history-account-events-consumer", "chat-history-group")
+	if err != nil {
+		logger.Panic("Failed to create account events consumer", zap.Error(err))
+		panic(err)
+	}
 
-		time.Sleep(time.Second * 1)
+	if err := accountEventsConsumer.SubscribeTopics([]string{common.AccountEventsTopic}); err != nil {
+		panic(err)
+	}
+
+	go func() {
+		for {
+			userID, err := service.ConsumeAccountEvent(ctx, accountEventsConsumer)
+			if err != nil {
+				logger.Warn("Error consuming an account event", zap.Error(err))
+			} else {
+				logger.Info("Account event was consumed", zap.String("userID", userID))
+			}
+		}
 	}()
 
 	if err := grpcServer.Serve(l); err != nil {
@@ -133,3 +208,17 @@ func connectToMongoDB(uri string) (*mongo.Client, error) {
 	err = client.Ping(ctx, readpref.Primary())
 	return client, err
 }
+
+func splitHostPort(address string) (string, int, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", 0, err
+	}
+
+	p, err := strconv.Atoi(port)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid port of %s: %w", address, err)
+	}
+
+	return host, p, nil
+}
@@ -0,0 +1,322 @@
+// Package memory stores chat history in memory of the process, for tests which run without MongoDB.
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/zoninnik89/messenger/chat-history/types"
+	pb "github.com/zoninnik89/messenger/common/api"
+	"google.golang.org/protobuf/proto"
+)
+
+type readEvent struct {
+	userID string
+	readAt string
+}
+
+type message struct {
+	chatID      string
+	senderID    string
+	messageID   string
+	messageText string
+	sentTS      string
+	readBy      []readEvent
+}
+
+// Store behaves like the MongoDB store of the service. It is safe for concurrent use.
+type Store struct {
+	mu       sync.RWMutex
+	messages []*message
+	// chats are kept in the order they were saved in, like documents of a collection
+	chats []*pb.Chat
+}
+
+var _ types.StoreInterface = (*Store)(nil)
+
+func NewStore() *Store {
+	return &Store{}
+}
+
+func (s *Store) Add(ctx context.Context, chatID, senderID, messageID, messageText, sentTime string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.messages = append(s.messages, &message{
+		chatID:      chatID,
+		senderID:    senderID,
+		messageID:   messageID,
+		messageText: messageText,
+		sentTS:      sentTime,
+	})
+
+	return nil
+}
+
+func (s *Store) GetAll(ctx context.Context, chatID, fromTS, toTS string) ([]*pb.Message, error) {
+	return s.find(func(m *message) bool {
+		return m.chatID == chatID && (fromTS == "" || m.sentTS >= fromTS) && (toTS == "" || m.sentTS <= toTS)
+	}), nil
+}
+
+func (s *Store) AddReadEvent(ctx context.Context, chatID, messageID, readByUserID, readAt string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, m := range s.messages {
+		if m.chatID == chatID && m.messageID == messageID {
+			m.readBy = append(m.readBy, readEvent{userID: readByUserID, readAt: readAt})
+			break
+		}
+	}
+
+	return nil
+}
+
+// LastMessages returns the latest message of every given chat which has messages, keyed by chat ID.
+func (s *Store) LastMessages(ctx context.Context, chatIDs []string) (map[string]*pb.Message, error) {
+	messages := s.find(func(m *message) bool { return slices.Contains(chatIDs, m.chatID) })
+
+	last := make(map[string]*pb.Message)
+	for _, msg := range messages {
+		// messages are sorted by the time they were sent, so the latest one is stored last
+		last[msg.GetChatId()] = msg
+	}
+
+	return last, nil
+}
+
+// UnreadCounts returns the number of messages of other participants the user hasn't read, for every given chat
+// which has them, keyed by chat ID.
+func (s *Store) UnreadCounts(ctx context.Context, chatIDs []string, userID string) (map[string]int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	counts := make(map[string]int64)
+	for _, m := range s.messages {
+		if slices.Contains(chatIDs, m.chatID) && m.senderID != userID && !m.readByUser(userID) {
+			counts[m.chatID]++
+		}
+	}
+
+	return counts, nil
+}
+
+func (s *Store) GetBySender(ctx context.Context, senderID string) ([]*pb.Message, error) {
+	return s.find(func(m *message) bool { return m.senderID == senderID }), nil
+}
+
+// AnonymizeSender replaces the sender of all messages of the user with types.DeletedUserID and forgets which
+// messages the user has read.
+func (s *Store) AnonymizeSender(ctx context.Context, senderID string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var anonymized int64
+	for _, m := range s.messages {
+		if m.senderID == senderID {
+			m.senderID = types.DeletedUserID
+			anonymized++
+		}
+
+		m.readBy = slices.DeleteFunc(m.readBy, func(e readEvent) bool { return e.userID == senderID })
+	}
+
+	return anonymized, nil
+}
+
+func (s *Store) SaveChat(ctx context.Context, c *pb.Chat) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.chats = append(s.chats, proto.Clone(c).(*pb.Chat))
+
+	return nil
+}
+
+func (s *Store) EnsureDirectChat(ctx context.Context, chatID string, participantIDs []string, createdAt int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.chat(chatID) != nil {
+		return nil
+	}
+
+	s.chats = append(s.chats, &pb.Chat{
+		ChatId:         chatID,
+		Kind:           pb.ChatKind_CHAT_KIND_DIRECT,
+		ParticipantIds: slices.Clone(participantIDs),
+		CreatedAt:      createdAt,
+	})
+
+	return nil
+}
+
+func (s *Store) Chat(ctx context.Context, chatID string) (*pb.Chat, error) {
+	const op = "memory.Store.Chat"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	c := s.chat(chatID)
+	if c == nil {
+		return nil, fmt.Errorf("%s: %w", op, types.ErrChatNotFound)
+	}
+
+	return proto.Clone(c).(*pb.Chat), nil
+}
+
+func (s *Store) ChatsOf(ctx context.Context, userID string) ([]*pb.Chat, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	chats := make([]*pb.Chat, 0)
+	for _, c := range s.chats {
+		if slices.Contains(c.GetParticipantIds(), userID) {
+			chats = append(chats, proto.Clone(c).(*pb.Chat))
+		}
+	}
+
+	return chats, nil
+}
+
+func (s *Store) SetChatName(ctx context.Context, chatID, name string) error {
+	const op = "memory.Store.SetChatName"
+
+	if err := s.updateChat(chatID, func(c *pb.Chat) { c.Name = name }); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Store) SetChatAvatar(ctx context.Context, chatID, avatarURL string) error {
+	const op = "memory.Store.SetChatAvatar"
+
+	if err := s.updateChat(chatID, func(c *pb.Chat) { c.AvatarUrl = avatarURL }); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Store) SetChatOwner(ctx context.Context, chatID, ownerID string) error {
+	const op = "memory.Store.SetChatOwner"
+
+	if err := s.updateChat(chatID, func(c *pb.Chat) { c.OwnerId = ownerID }); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// AddParticipants appends users to participants of the chat, users who already participate keep their place.
+func (s *Store) AddParticipants(ctx context.Context, chatID string, participantIDs []string) error {
+	const op = "memory.Store.AddParticipants"
+
+	err := s.updateChat(chatID, func(c *pb.Chat) {
+		for _, id := range participantIDs {
+			if !slices.Contains(c.ParticipantIds, id) {
+				c.ParticipantIds = append(c.ParticipantIds, id)
+			}
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Store) RemoveParticipant(ctx context.Context, chatID, userID string) error {
+	const op = "memory.Store.RemoveParticipant"
+
+	err := s.updateChat(chatID, func(c *pb.Chat) {
+		c.ParticipantIds = slices.DeleteFunc(c.ParticipantIds, func(id string) bool { return id == userID })
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Store) DeleteChat(ctx context.Context, chatID string) error {
+	const op = "memory.Store.DeleteChat"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := slices.IndexFunc(s.chats, func(c *pb.Chat) bool { return c.GetChatId() == chatID })
+	if i < 0 {
+		return fmt.Errorf("%s: %w", op, types.ErrChatNotFound)
+	}
+
+	s.chats = slices.Delete(s.chats, i, i+1)
+
+	return nil
+}
+
+// find returns messages matching the filter, sorted by the time they were sent
+func (s *Store) find(filter func(m *message) bool) []*pb.Message {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var found []*message
+	for _, m := range s.messages {
+		if filter(m) {
+			found = append(found, m)
+		}
+	}
+
+	slices.SortStableFunc(found, func(a, b *message) int { return strings.Compare(a.sentTS, b.sentTS) })
+
+	messages := make([]*pb.Message, 0, len(found))
+	for _, m := range found {
+		messages = append(messages, m.toProto())
+	}
+
+	return messages
+}
+
+// chat returns the chat or nil if it doesn't exist. Must be called with s.mu held.
+func (s *Store) chat(chatID string) *pb.Chat {
+	for _, c := range s.chats {
+		if c.GetChatId() == chatID {
+			return c
+		}
+	}
+
+	return nil
+}
+
+func (s *Store) updateChat(chatID string, update func(c *pb.Chat)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.chat(chatID)
+	if c == nil {
+		return types.ErrChatNotFound
+	}
+
+	update(c)
+
+	return nil
+}
+
+func (m *message) readByUser(userID string) bool {
+	return slices.ContainsFunc(m.readBy, func(e readEvent) bool { return e.userID == userID })
+}
+
+func (m *message) toProto() *pb.Message {
+	return &pb.Message{
+		ChatId:      m.chatID,
+		SenderId:    m.senderID,
+		MessageId:   m.messageID,
+		MessageText: m.messageText,
+		SentTs:      m.sentTS,
+	}
+}
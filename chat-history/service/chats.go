@@ -0,0 +1,309 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/zoninnik89/messenger/chat-history/types"
+	"github.com/zoninnik89/messenger/common"
+	pb "github.com/zoninnik89/messenger/common/api"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaxGroupParticipants limits the size of groups, every message is fanned out to all participants
+const MaxGroupParticipants = 200
+
+// ListChats returns chats of the user with the last message and the number of unread messages,
+// most recently active chats first.
+func (s *ChatHistoryService) ListChats(ctx context.Context, userID string) ([]*pb.Chat, error) {
+	const op = "service.ListChats"
+
+	chats, err := s.store.ChatsOf(ctx, userID)
+	if err != nil {
+		s.logger.Errorw("failed to get chats", "op", op, "userID", userID, "err", err)
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	chatIDs := make([]string, 0, len(chats))
+	for _, chat := range chats {
+		chatIDs = append(chatIDs, chat.GetChatId())
+	}
+
+	// messages of all chats are fetched at once, so the number of queries doesn't grow with the number of chats
+	lastMessages, err := s.store.LastMessages(ctx, chatIDs)
+	if err != nil {
+		s.logger.Errorw("failed to get last messages", "op", op, "userID", userID, "err", err)
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	unreadCounts, err := s.store.UnreadCounts(ctx, chatIDs, userID)
+	if err != nil {
+		s.logger.Errorw("failed to count unread messages", "op", op, "userID", userID, "err", err)
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for _, chat := range chats {
+		chat.LastMessage = lastMessages[chat.GetChatId()]
+		chat.UnreadCount = unreadCounts[chat.GetChatId()]
+	}
+
+	sort.SliceStable(chats, func(i, j int) bool {
+		return lastActivity(chats[i]) > lastActivity(chats[j])
+	})
+
+	return chats, nil
+}
+
+// CreateGroup creates a group chat owned by ownerID.
+func (s *ChatHistoryService) CreateGroup(
+	ctx context.Context,
+	ownerID string,
+	name string,
+	avatarURL string,
+	participantIDs []string,
+) (*pb.Chat, error) {
+	const op = "service.CreateGroup"
+
+	participants := []string{ownerID}
+	for _, id := range participantIDs {
+		if id != "" && !slices.Contains(participants, id) {
+			participants = append(participants, id)
+		}
+	}
+
+	if len(participants) > MaxGroupParticipants {
+		return nil, fmt.Errorf("%s: %w", op, types.ErrTooManyParticipants)
+	}
+
+	chat := &pb.Chat{
+		ChatId:         common.GroupChatID(primitive.NewObjectID().Hex()),
+		Kind:           pb.ChatKind_CHAT_KIND_GROUP,
+		Name:           name,
+		AvatarUrl:      avatarURL,
+		OwnerId:        ownerID,
+		ParticipantIds: participants,
+		CreatedAt:      time.Now().Unix(),
+	}
+
+	if err := s.store.SaveChat(ctx, chat); err != nil {
+		s.logger.Errorw("failed to save group", "op", op, "ownerID", ownerID, "err", err)
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.logger.Infow("group created", "op", op, "chatID", chat.GetChatId(), "ownerID", ownerID, "participants", len(participants))
+
+	return chat, nil
+}
+
+// RenameChat renames the group, any participant may rename it.
+func (s *ChatHistoryService) RenameChat(ctx context.Context, userID string, chatID string, name string) error {
+	const op = "service.RenameChat"
+
+	if _, err := s.participatingGroup(ctx, userID, chatID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.store.SetChatName(ctx, chatID, name); err != nil {
+		s.logger.Errorw("failed to rename chat", "op", op, "chatID", chatID, "err", err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// SetChatAvatar sets avatar of the group, any participant may change it.
+func (s *ChatHistoryService) SetChatAvatar(ctx context.Context, userID string, chatID string, avatarURL string) error {
+	const op = "service.SetChatAvatar"
+
+	if _, err := s.participatingGroup(ctx, userID, chatID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.store.SetChatAvatar(ctx, chatID, avatarURL); err != nil {
+		s.logger.Errorw("failed to set chat avatar", "op", op, "chatID", chatID, "err", err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// AddParticipants adds users to the group, any participant may add users.
+func (s *ChatHistoryService) AddParticipants(ctx context.Context, userID string, chatID string, participantIDs []string) error {
+	const op = "service.AddParticipants"
+
+	chat, err := s.participatingGroup(ctx, userID, chatID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	participants := chat.GetParticipantIds()
+	var added []string
+	for _, id := range participantIDs {
+		if id != "" && !slices.Contains(participants, id) && !slices.Contains(added, id) {
+			added = append(added, id)
+		}
+	}
+
+	if len(added) == 0 {
+		return nil
+	}
+
+	if len(participants)+len(added) > MaxGroupParticipants {
+		return fmt.Errorf("%s: %w", op, types.ErrTooManyParticipants)
+	}
+
+	if err := s.store.AddParticipants(ctx, chatID, added); err != nil {
+		s.logger.Errorw("failed to add participants", "op", op, "chatID", chatID, "err", err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.logger.Infow("participants added", "op", op, "chatID", chatID, "userID", userID, "added", len(added))
+
+	return nil
+}
+
+// RemoveParticipant removes another participant from the group, only the owner may do it.
+func (s *ChatHistoryService) RemoveParticipant(ctx context.Context, userID string, chatID string, participantID string) error {
+	const op = "service.RemoveParticipant"
+
+	if participantID == userID {
+		return s.LeaveChat(ctx, userID, chatID)
+	}
+
+	chat, err := s.participatingGroup(ctx, userID, chatID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if chat.GetOwnerId() != userID {
+		return fmt.Errorf("%s: %w", op, types.ErrNotChatOwner)
+	}
+
+	if !slices.Contains(chat.GetParticipantIds(), participantID) {
+		return fmt.Errorf("%s: %w", op, types.ErrNotChatParticipant)
+	}
+
+	if err := s.store.RemoveParticipant(ctx, chatID, participantID); err != nil {
+		s.logger.Errorw("failed to remove participant", "op", op, "chatID", chatID, "err", err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.logger.Infow("participant removed", "op", op, "chatID", chatID, "participantID", participantID)
+
+	return nil
+}
+
+// LeaveChat removes the user from the group. Ownership of the group passes to the participant who joined first,
+// the group is deleted when the last participant leaves.
+func (s *ChatHistoryService) LeaveChat(ctx context.Context, userID string, chatID string) error {
+	const op = "service.LeaveChat"
+
+	chat, err := s.participatingGroup(ctx, userID, chatID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.leave(ctx, chat, userID); err != nil {
+		s.logger.Errorw("failed to leave chat", "op", op, "chatID", chatID, "userID", userID, "err", err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.logger.Infow("user left chat", "op", op, "chatID", chatID, "userID", userID)
+
+	return nil
+}
+
+// ChatParticipants returns participants of the chat. Participants of direct chats are encoded in the chat ID,
+// so they are returned even before the first message is stored.
+func (s *ChatHistoryService) ChatParticipants(ctx context.Context, chatID string) ([]string, error) {
+	const op = "service.ChatParticipants"
+
+	if userID, otherUserID, ok := common.ParseDirectChatID(chatID); ok {
+		return []string{userID, otherUserID}, nil
+	}
+
+	chat, err := s.store.Chat(ctx, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return chat.GetParticipantIds(), nil
+}
+
+func (s *ChatHistoryService) leave(ctx context.Context, chat *pb.Chat, userID string) error {
+	remaining := slices.DeleteFunc(slices.Clone(chat.GetParticipantIds()), func(id string) bool {
+		return id == userID
+	})
+
+	if len(remaining) == 0 {
+		return s.store.DeleteChat(ctx, chat.GetChatId())
+	}
+
+	if err := s.store.RemoveParticipant(ctx, chat.GetChatId(), userID); err != nil {
+		return err
+	}
+
+	if chat.GetOwnerId() == userID {
+		return s.store.SetChatOwner(ctx, chat.GetChatId(), remaining[0])
+	}
+
+	return nil
+}
+
+// participatingGroup returns the group chat if the user participates in it.
+func (s *ChatHistoryService) participatingGroup(ctx context.Context, userID string, chatID string) (*pb.Chat, error) {
+	if _, _, ok := common.ParseDirectChatID(chatID); ok {
+		return nil, types.ErrDirectChat
+	}
+
+	chat, err := s.store.Chat(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	if chat.GetKind() != pb.ChatKind_CHAT_KIND_GROUP {
+		return nil, types.ErrDirectChat
+	}
+
+	if !slices.Contains(chat.GetParticipantIds(), userID) {
+		return nil, types.ErrNotChatParticipant
+	}
+
+	return chat, nil
+}
+
+// canRead reports whether the user may read messages of the chat
+func (s *ChatHistoryService) canRead(ctx context.Context, userID string, chatID string) error {
+	if first, second, ok := common.ParseDirectChatID(chatID); ok {
+		if userID != first && userID != second {
+			return types.ErrNotChatParticipant
+		}
+
+		return nil
+	}
+
+	chat, err := s.store.Chat(ctx, chatID)
+	if err != nil {
+		return err
+	}
+
+	if !slices.Contains(chat.GetParticipantIds(), userID) {
+		return types.ErrNotChatParticipant
+	}
+
+	return nil
+}
+
+func lastActivity(chat *pb.Chat) int64 {
+	if chat.GetLastMessage() != nil {
+		if sentTS, err := strconv.ParseInt(chat.GetLastMessage().GetSentTs(), 10, 64); err == nil {
+			return sentTS
+		}
+	}
+
+	return chat.GetCreatedAt()
+}
@@ -0,0 +1,300 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/zoninnik89/messenger/chat-history/memory"
+	"github.com/zoninnik89/messenger/chat-history/types"
+	"github.com/zoninnik89/messenger/common"
+	pb "github.com/zoninnik89/messenger/common/api"
+)
+
+func TestCreateGroup(t *testing.T) {
+	ctx := context.Background()
+	s := NewChatHistoryService(memory.NewStore())
+
+	chat, err := s.CreateGroup(ctx, "owner", "group", "https://example.com/avatar.png", []string{"alice", "", "owner", "bob", "alice"})
+	if err != nil {
+		t.Fatalf("failed to create group: %v", err)
+	}
+
+	if !common.IsGroupChatID(chat.GetChatId()) {
+		t.Errorf("expected group chat ID, got %s", chat.GetChatId())
+	}
+	if chat.GetKind() != pb.ChatKind_CHAT_KIND_GROUP || chat.GetOwnerId() != "owner" {
+		t.Errorf("expected group owned by owner, got %v", chat)
+	}
+	// the owner comes first, duplicates and empty IDs are skipped
+	if want := []string{"owner", "alice", "bob"}; !reflect.DeepEqual(chat.GetParticipantIds(), want) {
+		t.Errorf("expected participants %v, got %v", want, chat.GetParticipantIds())
+	}
+
+	participants, err := s.ChatParticipants(ctx, chat.GetChatId())
+	if err != nil {
+		t.Fatalf("failed to get participants: %v", err)
+	}
+	if !reflect.DeepEqual(participants, chat.GetParticipantIds()) {
+		t.Errorf("expected stored participants %v, got %v", chat.GetParticipantIds(), participants)
+	}
+}
+
+func TestCreateGroup_TooManyParticipants(t *testing.T) {
+	s := NewChatHistoryService(memory.NewStore())
+
+	// the owner makes one more
+	_, err := s.CreateGroup(context.Background(), "owner", "group", "", userIDs(MaxGroupParticipants))
+	if !errors.Is(err, types.ErrTooManyParticipants) {
+		t.Errorf("expected %v, got %v", types.ErrTooManyParticipants, err)
+	}
+}
+
+func TestListChats(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	s := NewChatHistoryService(store)
+
+	quiet := &pb.Chat{ChatId: "group:quiet", Kind: pb.ChatKind_CHAT_KIND_GROUP, ParticipantIds: []string{"user", "alice"}, CreatedAt: 100}
+	active := &pb.Chat{ChatId: "group:active", Kind: pb.ChatKind_CHAT_KIND_GROUP, ParticipantIds: []string{"user", "bob"}, CreatedAt: 50}
+	other := &pb.Chat{ChatId: "group:other", Kind: pb.ChatKind_CHAT_KIND_GROUP, ParticipantIds: []string{"alice"}, CreatedAt: 300}
+	for _, chat := range []*pb.Chat{quiet, active, other} {
+		if err := store.SaveChat(ctx, chat); err != nil {
+			t.Fatalf("failed to save chat: %v", err)
+		}
+	}
+
+	for _, msg := range []*pb.Message{
+		{ChatId: "group:active", SenderId: "bob", MessageId: "1", MessageText: "hi", SentTs: "200"},
+		{ChatId: "group:active", SenderId: "bob", MessageId: "2", MessageText: "there", SentTs: "201"},
+		{ChatId: "group:active", SenderId: "user", MessageId: "3", MessageText: "hello", SentTs: "202"},
+	} {
+		if err := store.Add(ctx, msg.GetChatId(), msg.GetSenderId(), msg.GetMessageId(), msg.GetMessageText(), msg.GetSentTs()); err != nil {
+			t.Fatalf("failed to add message: %v", err)
+		}
+	}
+	if err := store.AddReadEvent(ctx, "group:active", "1", "user", "203"); err != nil {
+		t.Fatalf("failed to add read event: %v", err)
+	}
+
+	chats, err := s.ListChats(ctx, "user")
+	if err != nil {
+		t.Fatalf("failed to list chats: %v", err)
+	}
+
+	if len(chats) != 2 {
+		t.Fatalf("expected 2 chats, got %d", len(chats))
+	}
+
+	// the chat with the latest message comes before the chat created later without messages
+	if chats[0].GetChatId() != active.GetChatId() || chats[1].GetChatId() != quiet.GetChatId() {
+		t.Fatalf("expected chats %s, %s, got %s, %s", active.GetChatId(), quiet.GetChatId(), chats[0].GetChatId(), chats[1].GetChatId())
+	}
+
+	if got := chats[0].GetLastMessage().GetMessageId(); got != "3" {
+		t.Errorf("expected last message 3, got %s", got)
+	}
+	// own messages and read messages aren't counted
+	if got := chats[0].GetUnreadCount(); got != 1 {
+		t.Errorf("expected 1 unread message, got %d", got)
+	}
+
+	if chats[1].GetLastMessage() != nil || chats[1].GetUnreadCount() != 0 {
+		t.Errorf("expected chat without messages, got %v", chats[1])
+	}
+}
+
+func TestGroupChanges_RequireParticipant(t *testing.T) {
+	ctx := context.Background()
+	s := NewChatHistoryService(memory.NewStore())
+
+	chat, err := s.CreateGroup(ctx, "owner", "group", "", []string{"alice"})
+	if err != nil {
+		t.Fatalf("failed to create group: %v", err)
+	}
+	directChatID := common.DirectChatID("owner", "alice")
+
+	tests := []struct {
+		name   string
+		change func(userID string, chatID string) error
+	}{
+		{
+			name:   "rename",
+			change: func(userID string, chatID string) error { return s.RenameChat(ctx, userID, chatID, "renamed") },
+		},
+		{
+			name: "set avatar",
+			change: func(userID string, chatID string) error {
+				return s.SetChatAvatar(ctx, userID, chatID, "https://example.com/avatar.png")
+			},
+		},
+		{
+			name: "add participants",
+			change: func(userID string, chatID string) error {
+				return s.AddParticipants(ctx, userID, chatID, []string{"bob"})
+			},
+		},
+		{
+			name:   "leave",
+			change: func(userID string, chatID string) error { return s.LeaveChat(ctx, userID, chatID) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.change("stranger", chat.GetChatId()); !errors.Is(err, types.ErrNotChatParticipant) {
+				t.Errorf("expected %v for a stranger, got %v", types.ErrNotChatParticipant, err)
+			}
+			if err := tt.change("owner", directChatID); !errors.Is(err, types.ErrDirectChat) {
+				t.Errorf("expected %v for a direct chat, got %v", types.ErrDirectChat, err)
+			}
+			if err := tt.change("owner", "group:missing"); !errors.Is(err, types.ErrChatNotFound) {
+				t.Errorf("expected %v for a missing chat, got %v", types.ErrChatNotFound, err)
+			}
+		})
+	}
+}
+
+func TestAddParticipants(t *testing.T) {
+	ctx := context.Background()
+	s := NewChatHistoryService(memory.NewStore())
+
+	chat, err := s.CreateGroup(ctx, "owner", "group", "", []string{"alice"})
+	if err != nil {
+		t.Fatalf("failed to create group: %v", err)
+	}
+
+	// any participant may add users, users already in the group keep their place
+	if err := s.AddParticipants(ctx, "alice", chat.GetChatId(), []string{"bob", "owner", "bob"}); err != nil {
+		t.Fatalf("failed to add participants: %v", err)
+	}
+
+	assertParticipants(t, s, chat.GetChatId(), []string{"owner", "alice", "bob"})
+
+	err = s.AddParticipants(ctx, "owner", chat.GetChatId(), userIDs(MaxGroupParticipants))
+	if !errors.Is(err, types.ErrTooManyParticipants) {
+		t.Errorf("expected %v, got %v", types.ErrTooManyParticipants, err)
+	}
+}
+
+func TestRemoveParticipant(t *testing.T) {
+	ctx := context.Background()
+	s := NewChatHistoryService(memory.NewStore())
+
+	chat, err := s.CreateGroup(ctx, "owner", "group", "", []string{"alice", "bob"})
+	if err != nil {
+		t.Fatalf("failed to create group: %v", err)
+	}
+
+	if err := s.RemoveParticipant(ctx, "alice", chat.GetChatId(), "bob"); !errors.Is(err, types.ErrNotChatOwner) {
+		t.Errorf("expected %v, got %v", types.ErrNotChatOwner, err)
+	}
+	if err := s.RemoveParticipant(ctx, "owner", chat.GetChatId(), "stranger"); !errors.Is(err, types.ErrNotChatParticipant) {
+		t.Errorf("expected %v, got %v", types.ErrNotChatParticipant, err)
+	}
+
+	if err := s.RemoveParticipant(ctx, "owner", chat.GetChatId(), "bob"); err != nil {
+		t.Fatalf("failed to remove participant: %v", err)
+	}
+	assertParticipants(t, s, chat.GetChatId(), []string{"owner", "alice"})
+
+	// removing yourself is leaving
+	if err := s.RemoveParticipant(ctx, "alice", chat.GetChatId(), "alice"); err != nil {
+		t.Fatalf("failed to leave: %v", err)
+	}
+	assertParticipants(t, s, chat.GetChatId(), []string{"owner"})
+}
+
+func TestLeaveChat(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	s := NewChatHistoryService(store)
+
+	chat, err := s.CreateGroup(ctx, "owner", "group", "", []string{"alice", "bob"})
+	if err != nil {
+		t.Fatalf("failed to create group: %v", err)
+	}
+
+	// ownership passes to the participant who joined first
+	if err := s.LeaveChat(ctx, "owner", chat.GetChatId()); err != nil {
+		t.Fatalf("failed to leave: %v", err)
+	}
+
+	stored, err := store.Chat(ctx, chat.GetChatId())
+	if err != nil {
+		t.Fatalf("failed to get chat: %v", err)
+	}
+	if stored.GetOwnerId() != "alice" {
+		t.Errorf("expected alice to own the group, got %s", stored.GetOwnerId())
+	}
+	assertParticipants(t, s, chat.GetChatId(), []string{"alice", "bob"})
+
+	// the group is deleted when the last participant leaves
+	for _, userID := range []string{"alice", "bob"} {
+		if err := s.LeaveChat(ctx, userID, chat.GetChatId()); err != nil {
+			t.Fatalf("failed to leave: %v", err)
+		}
+	}
+
+	if _, err := s.ChatParticipants(ctx, chat.GetChatId()); !errors.Is(err, types.ErrChatNotFound) {
+		t.Errorf("expected %v, got %v", types.ErrChatNotFound, err)
+	}
+}
+
+func TestChatParticipants(t *testing.T) {
+	s := NewChatHistoryService(memory.NewStore())
+
+	tests := []struct {
+		name    string
+		chatID  string
+		want    []string
+		wantErr error
+	}{
+		{
+			name: "direct chat without messages",
+			// participants are encoded in the ID, sorted
+			chatID: common.DirectChatID("bob", "alice"),
+			want:   []string{"alice", "bob"},
+		},
+		{
+			name:    "missing group",
+			chatID:  "group:missing",
+			wantErr: types.ErrChatNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.ChatParticipants(context.Background(), tt.chatID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected participants %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func assertParticipants(t *testing.T, s *ChatHistoryService, chatID string, want []string) {
+	t.Helper()
+
+	participants, err := s.ChatParticipants(context.Background(), chatID)
+	if err != nil {
+		t.Fatalf("failed to get participants: %v", err)
+	}
+	if !reflect.DeepEqual(participants, want) {
+		t.Errorf("expected participants %v, got %v", want, participants)
+	}
+}
+
+func userIDs(n int) []string {
+	ids := make([]string, 0, n)
+	for i := range n {
+		ids = append(ids, fmt.Sprintf("user-%d", i))
+	}
+
+	return ids
+}
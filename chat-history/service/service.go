@@ -2,12 +2,15 @@ package service
 
 import (
 	"context"
-	"github.com/confluentinc/confluent-kafka-go/kafka"
 	"github.com/zoninnik89/messenger/chat-history/logging"
 	"github.com/zoninnik89/messenger/chat-history/types"
+	"github.com/zoninnik89/messenger/common"
 	pb "github.com/zoninnik89/messenger/common/api"
+	"github.com/zoninnik89/messenger/common/bus"
+	"github.com/zoninnik89/messenger/common/telemetry"
 	"go.uber.org/zap"
-	"strings"
+	"google.golang.org/protobuf/proto"
+	"strconv"
 )
 
 type ChatHistoryService struct {
@@ -20,20 +23,48 @@ func NewChatHistoryService(s types.StoreInterface) *ChatHistoryService {
 	return &ChatHistoryService{store: s, logger: l}
 }
 
-func (s *ChatHistoryService) ConsumeMessage(ctx context.Context, queue *kafka.Consumer) (*pb.Message, error) {
-	msg, err := queue.ReadMessage(-1)
+func (s *ChatHistoryService) ConsumeMessage(ctx context.Context, queue bus.Consumer) (*pb.Message, error) {
+	msg, err := queue.ReadMessage(ctx)
 	if err != nil {
-		s.logger.Fatalw("Failed to read message", "err", err)
+		s.logger.Errorw("Failed to read message", "err", err)
 		return nil, err
 	}
-	msgSlice := strings.Split(string(msg.Value), ",")
-	chatID, senderID, messageID, messageText, sentTime := msgSlice[0], msgSlice[1], msgSlice[2], msgSlice[3], msgSlice[4]
 
-	err = s.store.Add(ctx, chatID, senderID, messageID, messageText, sentTime)
+	ctx, span := telemetry.StartProcess(ctx, msg.Topic, msg.Headers)
+	saved, err := s.saveMessage(ctx, msg)
+	telemetry.End(span, err)
+
+	return saved, err
+}
+
+// saveMessage stores the consumed message, continuing the trace of ctx.
+func (s *ChatHistoryService) saveMessage(ctx context.Context, msg *bus.Message) (*pb.Message, error) {
+	// messages are published by chat-client serialized as protobuf
+	var message pb.Message
+	if err := proto.Unmarshal(msg.Value, &message); err != nil {
+		s.logger.Errorw("Failed to unmarshal message", "err", err)
+		return nil, err
+	}
+
+	chatID, senderID, messageID := message.GetChatId(), message.GetSenderId(), message.GetMessageId()
+	messageText, sentTime := message.GetMessageText(), message.GetSentTs()
+
+	s.logger.Infow("Saving message", "messageID", messageID, "request_id", telemetry.RequestID(ctx), "trace_id", telemetry.TraceID(ctx))
+
+	err := s.store.Add(ctx, chatID, senderID, messageID, messageText, sentTime)
 	if err != nil {
 		return nil, err
 	}
 
+	// direct chats are not created explicitly, they appear in chat lists with the first message
+	if userID, otherUserID, ok := common.ParseDirectChatID(chatID); ok {
+		createdAt, _ := strconv.ParseInt(sentTime, 10, 64)
+		if err := s.store.EnsureDirectChat(ctx, chatID, []string{userID, otherUserID}, createdAt); err != nil {
+			s.logger.Errorw("Failed to save direct chat", "chatID", chatID, "err", err)
+			return nil, err
+		}
+	}
+
 	return &pb.Message{ChatId: chatID,
 		MessageId:   messageID,
 		MessageText: messageText,
@@ -43,6 +74,12 @@ func (s *ChatHistoryService) ConsumeMessage(ctx context.Context, queue *kafka.Co
 }
 
 func (s *ChatHistoryService) GetMessages(ctx context.Context, req *pb.GetMessagesRequest) (*pb.GetMessagesResponse, error) {
+	if req.GetUserId() != "" {
+		if err := s.canRead(ctx, req.GetUserId(), req.GetChatId()); err != nil {
+			return nil, err
+		}
+	}
+
 	messages, err := s.store.GetAll(ctx, req.ChatId, req.FromTs, req.ToTs)
 	if err != nil {
 		s.logger.Error(err)
@@ -59,3 +96,59 @@ func (s *ChatHistoryService) ConsumeMessageReadEvent(ctx context.Context, req *p
 	}
 	return nil
 }
+
+// GetUserMessages returns all messages sent by the user, used for data export
+func (s *ChatHistoryService) GetUserMessages(ctx context.Context, req *pb.GetUserMessagesRequest) (*pb.GetUserMessagesResponse, error) {
+	messages, err := s.store.GetBySender(ctx, req.GetUserId())
+	if err != nil {
+		s.logger.Error(err)
+		return nil, err
+	}
+	return &pb.GetUserMessagesResponse{Message: messages}, nil
+}
+
+// ConsumeAccountEvent anonymizes messages of deleted users
+func (s *ChatHistoryService) ConsumeAccountEvent(ctx context.Context, queue bus.Consumer) (string, error) {
+	msg, err := queue.ReadMessage(ctx)
+	if err != nil {
+		s.logger.Errorw("Failed to read account event", "err", err)
+		return "", err
+	}
+
+	var event pb.AccountEvent
+	if err := proto.Unmarshal(msg.Value, &event); err != nil {
+		s.logger.Errorw("Failed to unmarshal account event", "err", err)
+		return "", err
+	}
+
+	if event.GetType() != pb.AccountEventType_ACCOUNT_EVENT_TYPE_DELETED || event.GetUserId() == "" {
+		return event.GetUserId(), nil
+	}
+
+	anonymized, err := s.store.AnonymizeSender(ctx, event.GetUserId())
+	if err != nil {
+		s.logger.Errorw("Failed to anonymize messages", "userID", event.GetUserId(), "err", err)
+		return "", err
+	}
+
+	s.logger.Infow("Messages of deleted user anonymized", "userID", event.GetUserId(), "messages", anonymized)
+
+	chats, err := s.store.ChatsOf(ctx, event.GetUserId())
+	if err != nil {
+		s.logger.Errorw("Failed to get chats of deleted user", "userID", event.GetUserId(), "err", err)
+		return "", err
+	}
+
+	for _, chat := range chats {
+		if chat.GetKind() != pb.ChatKind_CHAT_KIND_GROUP {
+			continue
+		}
+
+		if err := s.leave(ctx, chat, event.GetUserId()); err != nil {
+			s.logger.Errorw("Failed to remove deleted user from group", "userID", event.GetUserId(), "chatID", chat.GetChatId(), "err", err)
+			return "", err
+		}
+	}
+
+	return event.GetUserId(), nil
+}
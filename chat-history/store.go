@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zoninnik89/messenger/chat-history/types"
+	pb "github.com/zoninnik89/messenger/common/api"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	dbName         = "chat_history"
+	collectionName = "messages"
+)
+
+type readEvent struct {
+	UserID string `bson:"user_id"`
+	ReadAt string `bson:"read_at"`
+}
+
+type message struct {
+	ChatID      string      `bson:"chat_id"`
+	SenderID    string      `bson:"sender_id"`
+	MessageID   string      `bson:"message_id"`
+	MessageText string      `bson:"message_text"`
+	SentTS      string      `bson:"sent_ts"`
+	ReadBy      []readEvent `bson:"read_by,omitempty"`
+}
+
+type Store struct {
+	messages *mongo.Collection
+	chats    *mongo.Collection
+}
+
+func NewStore(client *mongo.Client) *Store {
+	return &Store{
+		messages: client.Database(dbName).Collection(collectionName),
+		chats:    client.Database(dbName).Collection(chatsCollectionName),
+	}
+}
+
+func (s *Store) Add(ctx context.Context, chatID, senderID, messageID, messageText, sentTime string) error {
+	const op = "store.Add"
+
+	_, err := s.messages.InsertOne(ctx, message{
+		ChatID:      chatID,
+		SenderID:    senderID,
+		MessageID:   messageID,
+		MessageText: messageText,
+		SentTS:      sentTime,
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Store) GetAll(ctx context.Context, chatID, fromTS, toTS string) ([]*pb.Message, error) {
+	const op = "store.GetAll"
+
+	sentTS := bson.M{}
+	if fromTS != "" {
+		sentTS["$gte"] = fromTS
+	}
+	if toTS != "" {
+		sentTS["$lte"] = toTS
+	}
+
+	filter := bson.M{"chat_id": chatID}
+	if len(sentTS) > 0 {
+		filter["sent_ts"] = sentTS
+	}
+
+	messages, err := s.find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return messages, nil
+}
+
+func (s *Store) AddReadEvent(ctx context.Context, chatID, messageID, readByUserID, readAt string) error {
+	const op = "store.AddReadEvent"
+
+	_, err := s.messages.UpdateOne(ctx,
+		bson.M{"chat_id": chatID, "message_id": messageID},
+		bson.M{"$push": bson.M{"read_by": readEvent{UserID: readByUserID, ReadAt: readAt}}},
+	)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// LastMessages returns the latest message of every given chat which has messages, keyed by chat ID.
+func (s *Store) LastMessages(ctx context.Context, chatIDs []string) (map[string]*pb.Message, error) {
+	const op = "store.LastMessages"
+
+	cursor, err := s.messages.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"chat_id": bson.M{"$in": chatIDs}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "sent_ts", Value: -1}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$chat_id", "message": bson.M{"$first": "$$ROOT"}}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer cursor.Close(ctx)
+
+	var docs []struct {
+		Message message `bson:"message"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	messages := make(map[string]*pb.Message, len(docs))
+	for _, doc := range docs {
+		messages[doc.Message.ChatID] = doc.Message.toProto()
+	}
+
+	return messages, nil
+}
+
+// UnreadCounts returns the number of messages of other participants the user hasn't read, for every given chat
+// which has them, keyed by chat ID.
+func (s *Store) UnreadCounts(ctx context.Context, chatIDs []string, userID string) (map[string]int64, error) {
+	const op = "store.UnreadCounts"
+
+	cursor, err := s.messages.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"chat_id":         bson.M{"$in": chatIDs},
+			"sender_id":       bson.M{"$ne": userID},
+			"read_by.user_id": bson.M{"$ne": userID},
+		}}},
+		{{Key: "$group", Value: bson.M{"_id": "$chat_id", "count": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer cursor.Close(ctx)
+
+	var docs []struct {
+		ChatID string `bson:"_id"`
+		Count  int64  `bson:"count"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	counts := make(map[string]int64, len(docs))
+	for _, doc := range docs {
+		counts[doc.ChatID] = doc.Count
+	}
+
+	return counts, nil
+}
+
+func (s *Store) GetBySender(ctx context.Context, senderID string) ([]*pb.Message, error) {
+	const op = "store.GetBySender"
+
+	messages, err := s.find(ctx, bson.M{"sender_id": senderID})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return messages, nil
+}
+
+// AnonymizeSender replaces the sender of all messages of the user with types.DeletedUserID and forgets which
+// messages the user has read. Message IDs, chats and times are kept, so threads stay intact.
+func (s *Store) AnonymizeSender(ctx context.Context, senderID string) (int64, error) {
+	const op = "store.AnonymizeSender"
+
+	res, err := s.messages.UpdateMany(ctx,
+		bson.M{"sender_id": senderID},
+		bson.M{"$set": bson.M{"sender_id": types.DeletedUserID}},
+	)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = s.messages.UpdateMany(ctx,
+		bson.M{"read_by.user_id": senderID},
+		bson.M{"$pull": bson.M{"read_by": bson.M{"user_id": senderID}}},
+	)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return res.ModifiedCount, nil
+}
+
+func (s *Store) find(ctx context.Context, filter bson.M) ([]*pb.Message, error) {
+	cursor, err := s.messages.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "sent_ts", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []message
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	messages := make([]*pb.Message, 0, len(docs))
+	for _, doc := range docs {
+		messages = append(messages, doc.toProto())
+	}
+
+	return messages, nil
+}
+
+func (m message) toProto() *pb.Message {
+	return &pb.Message{
+		ChatId:      m.ChatID,
+		SenderId:    m.SenderID,
+		MessageId:   m.MessageID,
+		MessageText: m.MessageText,
+		SentTs:      m.SentTS,
+	}
+}
@@ -0,0 +1,111 @@
+// Package server runs chat-history service in the process of a test, with an in-memory store and bus.
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	h "github.com/zoninnik89/messenger/chat-history/handlers"
+	"github.com/zoninnik89/messenger/chat-history/memory"
+	s "github.com/zoninnik89/messenger/chat-history/service"
+	"github.com/zoninnik89/messenger/common"
+	"github.com/zoninnik89/messenger/common/bus"
+	busmemory "github.com/zoninnik89/messenger/common/bus/memory"
+	"github.com/zoninnik89/messenger/common/discovery"
+	"github.com/zoninnik89/messenger/common/telemetry"
+	"google.golang.org/grpc"
+)
+
+const (
+	serviceName = "chat-history"
+	host        = "127.0.0.1"
+)
+
+// Server is chat-history service listening on an ephemeral port.
+type Server struct {
+	// Addr is the address of the gRPC server
+	Addr string
+	// Store keeps messages and chats of the service, tests may seed it directly
+	Store *memory.Store
+
+	grpcServer *grpc.Server
+	consumers  []bus.Consumer
+	cancel     context.CancelFunc
+	registry   discovery.Registry
+	instanceID string
+}
+
+// Start starts the service consuming messages and account events from the broker from the earliest ones,
+// and registers it in the registry.
+func Start(registry discovery.Registry, broker *busmemory.Broker) (*Server, error) {
+	const op = "server.Start"
+
+	store := memory.NewStore()
+	service := s.NewChatHistoryService(store)
+
+	grpcServer := grpc.NewServer(telemetry.ServerOption())
+	h.NewGrpcHandler(grpcServer, service)
+
+	messages := broker.NewConsumer("chat-history-messages-group", busmemory.Earliest)
+	if err := messages.SubscribeTopics([]string{"messages", "read_events"}); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	accountEvents := broker.NewConsumer("chat-history-group", busmemory.Earliest)
+	if err := accountEvents.SubscribeTopics([]string{common.AccountEventsTopic}); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	l, err := net.Listen("tcp", net.JoinHostPort(host, "0"))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		_ = grpcServer.Serve(l)
+	}()
+	go func() {
+		for ctx.Err() == nil {
+			_, _ = service.ConsumeMessage(ctx, messages)
+		}
+	}()
+	go func() {
+		for ctx.Err() == nil {
+			_, _ = service.ConsumeAccountEvent(ctx, accountEvents)
+		}
+	}()
+
+	port := l.Addr().(*net.TCPAddr).Port
+	srv := &Server{
+		Addr:       net.JoinHostPort(host, strconv.Itoa(port)),
+		Store:      store,
+		grpcServer: grpcServer,
+		consumers:  []bus.Consumer{messages, accountEvents},
+		cancel:     cancel,
+		registry:   registry,
+		instanceID: discovery.GenerateInstanceID(serviceName),
+	}
+
+	if err := registry.Register(context.Background(), srv.instanceID, host, port, serviceName); err != nil {
+		srv.Stop()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return srv, nil
+}
+
+// Stop deregisters the service, stops consuming and stops the gRPC server gracefully.
+func (srv *Server) Stop() {
+	_ = srv.registry.Deregister(context.Background(), srv.instanceID)
+
+	srv.cancel()
+	for _, consumer := range srv.consumers {
+		_ = consumer.Close()
+	}
+
+	srv.grpcServer.GracefulStop()
+}
@@ -2,18 +2,65 @@ package types
 
 import (
 	"context"
-	"github.com/confluentinc/confluent-kafka-go/kafka"
+	"errors"
+
 	pb "github.com/zoninnik89/messenger/common/api"
+	"github.com/zoninnik89/messenger/common/bus"
+)
+
+// DeletedUserID replaces the sender of messages of deleted users, texts are kept so that replies
+// of other participants still make sense
+const DeletedUserID = "deleted-user"
+
+var (
+	ErrChatNotFound        = errors.New("chat not found")
+	ErrNotChatParticipant  = errors.New("user is not a participant of the chat")
+	ErrNotChatOwner        = errors.New("only the owner of the group can do this")
+	ErrDirectChat          = errors.New("direct chats can't be changed")
+	ErrTooManyParticipants = errors.New("too many participants")
 )
 
 type ChatHistoryServiceInterface interface {
-	ConsumeMessage(ctx context.Context, queue *kafka.Consumer) error
+	ConsumeMessage(ctx context.Context, queue bus.Consumer) (*pb.Message, error)
 	GetMessages(ctx context.Context, request *pb.GetMessagesRequest) (*pb.GetMessagesResponse, error)
 	ConsumeMessageReadEvent(ctx context.Context, request *pb.SendMessageReadEventRequest) error
+	GetUserMessages(ctx context.Context, request *pb.GetUserMessagesRequest) (*pb.GetUserMessagesResponse, error)
+	ConsumeAccountEvent(ctx context.Context, queue bus.Consumer) (string, error)
+	ListChats(ctx context.Context, userID string) ([]*pb.Chat, error)
+	CreateGroup(ctx context.Context, ownerID string, name string, avatarURL string, participantIDs []string) (*pb.Chat, error)
+	RenameChat(ctx context.Context, userID string, chatID string, name string) error
+	SetChatAvatar(ctx context.Context, userID string, chatID string, avatarURL string) error
+	AddParticipants(ctx context.Context, userID string, chatID string, participantIDs []string) error
+	RemoveParticipant(ctx context.Context, userID string, chatID string, participantID string) error
+	LeaveChat(ctx context.Context, userID string, chatID string) error
+	ChatParticipants(ctx context.Context, chatID string) ([]string, error)
 }
 
 type StoreInterface interface {
 	Add(ctx context.Context, chatID, senderID, messageID, messageText, sentTime string) error
 	GetAll(ctx context.Context, chatID, fromTS, toTS string) ([]*pb.Message, error)
 	AddReadEvent(ctx context.Context, chatId, messageId, readByUserId, readAt string) error
+	GetBySender(ctx context.Context, senderID string) ([]*pb.Message, error)
+	AnonymizeSender(ctx context.Context, senderID string) (int64, error)
+	// LastMessages returns the latest message of every given chat which has messages, keyed by chat ID
+	LastMessages(ctx context.Context, chatIDs []string) (map[string]*pb.Message, error)
+	// UnreadCounts returns the number of unread messages of every given chat which has them, keyed by chat ID
+	UnreadCounts(ctx context.Context, chatIDs []string, userID string) (map[string]int64, error)
+	ChatStoreInterface
+}
+
+// ChatStoreInterface stores chats and their participants, messages are stored separately.
+type ChatStoreInterface interface {
+	SaveChat(ctx context.Context, chat *pb.Chat) error
+	// EnsureDirectChat saves the direct chat unless it is already saved
+	EnsureDirectChat(ctx context.Context, chatID string, participantIDs []string, createdAt int64) error
+	// Chat returns ErrChatNotFound if the chat doesn't exist
+	Chat(ctx context.Context, chatID string) (*pb.Chat, error)
+	ChatsOf(ctx context.Context, userID string) ([]*pb.Chat, error)
+	SetChatName(ctx context.Context, chatID, name string) error
+	SetChatAvatar(ctx context.Context, chatID, avatarURL string) error
+	SetChatOwner(ctx context.Context, chatID, ownerID string) error
+	AddParticipants(ctx context.Context, chatID string, participantIDs []string) error
+	RemoveParticipant(ctx context.Context, chatID, userID string) error
+	DeleteChat(ctx context.Context, chatID string) error
 }
@@ -0,0 +1,39 @@
+package bus
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrClosed is returned by consumers and producers after they have been closed.
+var ErrClosed = errors.New("bus client is closed")
+
+// Message is a record of a topic partition.
+type Message struct {
+	Topic     string
+	Partition int32
+	Offset    int64
+	Key       []byte
+	Value     []byte
+	Timestamp time.Time
+	// Headers carry the trace context of the publisher, see telemetry.StartProcess
+	Headers map[string]string
+}
+
+// Producer publishes messages to topics. Messages with the same key go to the same partition,
+// so they are consumed in the order they were published.
+type Producer interface {
+	// Publish returns once the message is stored by the bus. The message carries the trace context of ctx.
+	Publish(ctx context.Context, topic string, key []byte, value []byte) error
+	Close()
+}
+
+// Consumer reads messages of subscribed topics as a member of a consumer group. Partitions of the topics
+// are split between members of the group, and offsets of read messages are committed for the group.
+type Consumer interface {
+	SubscribeTopics(topics []string) error
+	// ReadMessage blocks until a message is available or ctx is done.
+	ReadMessage(ctx context.Context) (*Message, error)
+	Close() error
+}
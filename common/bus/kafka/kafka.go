@@ -0,0 +1,141 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	confluent "github.com/confluentinc/confluent-kafka-go/kafka"
+	"github.com/zoninnik89/messenger/common/bus"
+	"github.com/zoninnik89/messenger/common/telemetry"
+)
+
+const (
+	// pollTimeoutMs bounds a single poll, so reads notice cancellation of their context
+	pollTimeoutMs = 100
+	// flushTimeoutMs is how long closing producer waits for delivery of queued messages
+	flushTimeoutMs = 10000
+)
+
+// Producer publishes messages to Kafka.
+type Producer struct {
+	producer *confluent.Producer
+}
+
+func NewProducer(configMap *confluent.ConfigMap) (*Producer, error) {
+	const op = "kafka.NewProducer"
+
+	p, err := confluent.NewProducer(configMap)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &Producer{producer: p}, nil
+}
+
+// Publish waits for the delivery report of the message.
+func (p *Producer) Publish(ctx context.Context, topic string, key []byte, value []byte) error {
+	ctx, span := telemetry.StartPublish(ctx, topic)
+	err := p.publish(ctx, topic, key, value)
+	telemetry.End(span, err)
+
+	return err
+}
+
+func (p *Producer) publish(ctx context.Context, topic string, key []byte, value []byte) error {
+	const op = "kafka.Producer.Publish"
+
+	var headers []confluent.Header
+	for name, value := range telemetry.Headers(ctx) {
+		headers = append(headers, confluent.Header{Key: name, Value: []byte(value)})
+	}
+
+	deliveryChan := make(chan confluent.Event, 1)
+
+	err := p.producer.Produce(&confluent.Message{
+		TopicPartition: confluent.TopicPartition{Topic: &topic, Partition: confluent.PartitionAny},
+		Key:            key,
+		Value:          value,
+		Headers:        headers,
+	}, deliveryChan)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("%s: %w", op, ctx.Err())
+	case e := <-deliveryChan:
+		msg, ok := e.(*confluent.Message)
+		if !ok {
+			return fmt.Errorf("%s: unexpected delivery event %v", op, e)
+		}
+		if msg.TopicPartition.Error != nil {
+			return fmt.Errorf("%s: %w", op, msg.TopicPartition.Error)
+		}
+		return nil
+	}
+}
+
+func (p *Producer) Close() {
+	p.producer.Flush(flushTimeoutMs)
+	p.producer.Close()
+}
+
+// Consumer reads messages from Kafka, offsets are committed automatically.
+type Consumer struct {
+	consumer *confluent.Consumer
+}
+
+func NewConsumer(configMap *confluent.ConfigMap) (*Consumer, error) {
+	const op = "kafka.NewConsumer"
+
+	c, err := confluent.NewConsumer(configMap)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &Consumer{consumer: c}, nil
+}
+
+func (c *Consumer) SubscribeTopics(topics []string) error {
+	return c.consumer.SubscribeTopics(topics, nil)
+}
+
+func (c *Consumer) ReadMessage(ctx context.Context) (*bus.Message, error) {
+	const op = "kafka.Consumer.ReadMessage"
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		msg, err := c.consumer.ReadMessage(pollTimeoutMs)
+		if err != nil {
+			var kafkaErr confluent.Error
+			if errors.As(err, &kafkaErr) && kafkaErr.Code() == confluent.ErrTimedOut {
+				continue
+			}
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		headers := make(map[string]string, len(msg.Headers))
+		for _, header := range msg.Headers {
+			headers[header.Key] = string(header.Value)
+		}
+
+		return &bus.Message{
+			Topic:     *msg.TopicPartition.Topic,
+			Partition: msg.TopicPartition.Partition,
+			Offset:    int64(msg.TopicPartition.Offset),
+			Key:       msg.Key,
+			Value:     msg.Value,
+			Timestamp: msg.Timestamp,
+			Headers:   headers,
+		}, nil
+	}
+}
+
+func (c *Consumer) Close() error {
+	return c.consumer.Close()
+}
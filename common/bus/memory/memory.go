@@ -0,0 +1,310 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/zoninnik89/messenger/common/bus"
+	"github.com/zoninnik89/messenger/common/telemetry"
+)
+
+const (
+	// Earliest makes a group without committed offsets start from the first message of a partition.
+	Earliest = "earliest"
+	// Latest makes a group without committed offsets start from messages published after it got
+	// the partition, like Kafka does by default.
+	Latest = "latest"
+)
+
+// Broker is an in-process stand-in for Kafka, for local runs and tests. It keeps all messages in memory.
+type Broker struct {
+	partitions int
+
+	mu     sync.Mutex
+	topics map[string]*topic
+	groups map[string]*group
+	// changed is closed and replaced whenever messages are published or partitions reassigned
+	changed chan struct{}
+}
+
+type topic struct {
+	partitions [][]*bus.Message
+	// next is the partition for the next message without key
+	next int
+}
+
+type partition struct {
+	topic string
+	index int
+}
+
+type group struct {
+	members []*Consumer
+	offsets map[partition]int64
+}
+
+// NewBroker returns broker which creates topics with the given number of partitions on first use.
+func NewBroker(partitions int) *Broker {
+	return &Broker{
+		partitions: max(partitions, 1),
+		topics:     make(map[string]*topic),
+		groups:     make(map[string]*group),
+		changed:    make(chan struct{}),
+	}
+}
+
+// Messages returns all messages published to the topic, ordered by partition and offset.
+func (b *Broker) Messages(topicName string) []*bus.Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var messages []*bus.Message
+	for _, log := range b.topic(topicName).partitions {
+		messages = append(messages, log...)
+	}
+
+	return messages
+}
+
+// topic returns the topic, creating it if needed. Must be called with b.mu held.
+func (b *Broker) topic(name string) *topic {
+	t, ok := b.topics[name]
+	if !ok {
+		t = &topic{partitions: make([][]*bus.Message, b.partitions)}
+		b.topics[name] = t
+	}
+
+	return t
+}
+
+// notify wakes up consumers waiting for messages. Must be called with b.mu held.
+func (b *Broker) notify() {
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
+
+// rebalance splits partitions of the topics subscribed by members of the group between them.
+// Must be called with b.mu held.
+func (b *Broker) rebalance(g *group) {
+	for _, member := range g.members {
+		member.assigned = nil
+	}
+
+	var topics []string
+	for _, member := range g.members {
+		for _, name := range member.topics {
+			if !slices.Contains(topics, name) {
+				topics = append(topics, name)
+			}
+		}
+	}
+	slices.Sort(topics)
+
+	for _, name := range topics {
+		var subscribers []*Consumer
+		for _, member := range g.members {
+			if slices.Contains(member.topics, name) {
+				subscribers = append(subscribers, member)
+			}
+		}
+
+		for i := range b.topic(name).partitions {
+			member := subscribers[i%len(subscribers)]
+			p := partition{topic: name, index: i}
+			member.assigned = append(member.assigned, p)
+
+			if _, ok := g.offsets[p]; !ok && member.offsetReset == Latest {
+				g.offsets[p] = int64(len(b.topics[name].partitions[i]))
+			}
+		}
+	}
+
+	b.notify()
+}
+
+// Producer publishes messages to topics of the broker.
+type Producer struct {
+	broker *Broker
+}
+
+// NewProducer returns producer of the broker.
+func (b *Broker) NewProducer() *Producer {
+	return &Producer{broker: b}
+}
+
+func (p *Producer) Publish(ctx context.Context, topicName string, key []byte, value []byte) error {
+	ctx, span := telemetry.StartPublish(ctx, topicName)
+	err := p.publish(ctx, topicName, key, value)
+	telemetry.End(span, err)
+
+	return err
+}
+
+func (p *Producer) publish(ctx context.Context, topicName string, key []byte, value []byte) error {
+	const op = "memory.Producer.Publish"
+
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	b := p.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	t := b.topic(topicName)
+
+	index := t.next
+	if key != nil {
+		h := fnv.New32a()
+		_, _ = h.Write(key)
+		index = int(h.Sum32() % uint32(len(t.partitions)))
+	} else {
+		t.next = (t.next + 1) % len(t.partitions)
+	}
+
+	t.partitions[index] = append(t.partitions[index], &bus.Message{
+		Topic:     topicName,
+		Partition: int32(index),
+		Offset:    int64(len(t.partitions[index])),
+		Key:       slices.Clone(key),
+		Value:     slices.Clone(value),
+		Timestamp: time.Now(),
+		Headers:   telemetry.Headers(ctx),
+	})
+
+	b.notify()
+
+	return nil
+}
+
+func (p *Producer) Close() {}
+
+// Consumer reads messages of the broker as a member of a consumer group.
+type Consumer struct {
+	broker      *Broker
+	groupID     string
+	offsetReset string
+
+	// fields below are guarded by broker.mu
+	topics   []string
+	assigned []partition
+	// next is the index of the assigned partition to read first, so partitions are read in turns
+	next   int
+	closed bool
+}
+
+// NewConsumer returns consumer joining the group. offsetReset is Earliest or Latest, it decides where
+// the group starts reading partitions it has no committed offsets of.
+func (b *Broker) NewConsumer(groupID string, offsetReset string) *Consumer {
+	return &Consumer{
+		broker:      b,
+		groupID:     groupID,
+		offsetReset: offsetReset,
+	}
+}
+
+func (c *Consumer) SubscribeTopics(topics []string) error {
+	const op = "memory.Consumer.SubscribeTopics"
+
+	b := c.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if c.closed {
+		return fmt.Errorf("%s: %w", op, bus.ErrClosed)
+	}
+
+	g, ok := b.groups[c.groupID]
+	if !ok {
+		g = &group{offsets: make(map[partition]int64)}
+		b.groups[c.groupID] = g
+	}
+
+	if !slices.Contains(g.members, c) {
+		g.members = append(g.members, c)
+	}
+
+	c.topics = slices.Clone(topics)
+	b.rebalance(g)
+
+	return nil
+}
+
+func (c *Consumer) ReadMessage(ctx context.Context) (*bus.Message, error) {
+	const op = "memory.Consumer.ReadMessage"
+
+	b := c.broker
+	for {
+		b.mu.Lock()
+
+		if c.closed {
+			b.mu.Unlock()
+			return nil, fmt.Errorf("%s: %w", op, bus.ErrClosed)
+		}
+
+		if msg := c.poll(); msg != nil {
+			b.mu.Unlock()
+			return msg, nil
+		}
+
+		changed := b.changed
+		b.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%s: %w", op, ctx.Err())
+		case <-changed:
+		}
+	}
+}
+
+// poll returns the next message of assigned partitions and commits its offset, or nil if there are none.
+// Must be called with broker.mu held.
+func (c *Consumer) poll() *bus.Message {
+	g := c.broker.groups[c.groupID]
+
+	for i := range c.assigned {
+		p := c.assigned[(c.next+i)%len(c.assigned)]
+		log := c.broker.topics[p.topic].partitions[p.index]
+
+		offset := g.offsets[p]
+		if offset < int64(len(log)) {
+			g.offsets[p] = offset + 1
+			c.next = (c.next + i + 1) % len(c.assigned)
+
+			// every group gets its own copy, like it would from Kafka
+			msg := *log[offset]
+			return &msg
+		}
+	}
+
+	return nil
+}
+
+// Close leaves the group, partitions of the consumer are reassigned to other members.
+func (c *Consumer) Close() error {
+	b := c.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+
+	g, ok := b.groups[c.groupID]
+	if !ok || !slices.Contains(g.members, c) {
+		// wakes up reads of the consumer, rebalancing does it otherwise
+		b.notify()
+		return nil
+	}
+
+	g.members = slices.DeleteFunc(g.members, func(member *Consumer) bool { return member == c })
+	b.rebalance(g)
+
+	return nil
+}
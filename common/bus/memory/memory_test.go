@@ -0,0 +1,204 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/zoninnik89/messenger/common/bus"
+)
+
+func TestBroker_KeyedMessagesKeepOrder(t *testing.T) {
+	broker := NewBroker(4)
+	producer := broker.NewProducer()
+
+	for i := 0; i < 10; i++ {
+		publish(t, producer, "messages", fmt.Sprintf("chat%d", i%3), fmt.Sprint(i))
+	}
+
+	consumer := broker.NewConsumer("group", Earliest)
+	subscribe(t, consumer, "messages")
+
+	byKey := make(map[string][]string)
+	partitions := make(map[string]int32)
+	for i := 0; i < 10; i++ {
+		msg := read(t, consumer)
+		key := string(msg.Key)
+
+		if p, ok := partitions[key]; ok && p != msg.Partition {
+			t.Errorf("messages with key %s are in partitions %d and %d", key, p, msg.Partition)
+		}
+		partitions[key] = msg.Partition
+		byKey[key] = append(byKey[key], string(msg.Value))
+	}
+
+	want := map[string][]string{
+		"chat0": {"0", "3", "6", "9"},
+		"chat1": {"1", "4", "7"},
+		"chat2": {"2", "5", "8"},
+	}
+	for key, values := range want {
+		if !slices.Equal(byKey[key], values) {
+			t.Errorf("messages with key %s are %v, want %v", key, byKey[key], values)
+		}
+	}
+}
+
+func TestBroker_GroupsSplitPartitions(t *testing.T) {
+	broker := NewBroker(2)
+	producer := broker.NewProducer()
+
+	first := broker.NewConsumer("pub-sub", Earliest)
+	second := broker.NewConsumer("pub-sub", Earliest)
+	other := broker.NewConsumer("chat-history", Earliest)
+	subscribe(t, first, "messages")
+	subscribe(t, second, "messages")
+	subscribe(t, other, "messages")
+
+	for i := 0; i < 4; i++ {
+		publish(t, producer, "messages", "", fmt.Sprint(i))
+	}
+
+	// messages without key are spread over both partitions, every member of the group gets one of them
+	got := []string{
+		string(read(t, first).Value), string(read(t, first).Value),
+		string(read(t, second).Value), string(read(t, second).Value),
+	}
+	slices.Sort(got)
+	if !slices.Equal(got, []string{"0", "1", "2", "3"}) {
+		t.Errorf("group read %v, want every message once", got)
+	}
+	expectNone(t, first)
+	expectNone(t, second)
+
+	// other groups read all messages independently
+	for i := 0; i < 4; i++ {
+		read(t, other)
+	}
+	expectNone(t, other)
+}
+
+func TestBroker_OffsetReset(t *testing.T) {
+	broker := NewBroker(1)
+	producer := broker.NewProducer()
+
+	publish(t, producer, "events", "", "old")
+
+	earliest := broker.NewConsumer("earliest", Earliest)
+	latest := broker.NewConsumer("latest", Latest)
+	subscribe(t, earliest, "events")
+	subscribe(t, latest, "events")
+
+	publish(t, producer, "events", "", "new")
+
+	if msg := read(t, earliest); string(msg.Value) != "old" || msg.Offset != 0 {
+		t.Errorf("earliest consumer read %s at %d, want old at 0", msg.Value, msg.Offset)
+	}
+	if msg := read(t, latest); string(msg.Value) != "new" || msg.Offset != 1 {
+		t.Errorf("latest consumer read %s at %d, want new at 1", msg.Value, msg.Offset)
+	}
+}
+
+func TestBroker_CommittedOffsetsSurviveRebalance(t *testing.T) {
+	broker := NewBroker(1)
+	producer := broker.NewProducer()
+
+	first := broker.NewConsumer("group", Earliest)
+	subscribe(t, first, "messages")
+
+	publish(t, producer, "messages", "", "0")
+	publish(t, producer, "messages", "", "1")
+
+	read(t, first)
+	if err := first.Close(); err != nil {
+		t.Fatalf("failed to close consumer: %v", err)
+	}
+
+	// the partition of the closed member goes to the new one, which continues after the committed offset
+	second := broker.NewConsumer("group", Earliest)
+	subscribe(t, second, "messages")
+
+	if msg := read(t, second); string(msg.Value) != "1" {
+		t.Errorf("read %s after rebalance, want 1", msg.Value)
+	}
+}
+
+func TestConsumer_ReadMessageWaits(t *testing.T) {
+	broker := NewBroker(1)
+	consumer := broker.NewConsumer("group", Latest)
+	subscribe(t, consumer, "messages")
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		publish(t, broker.NewProducer(), "messages", "", "late")
+	}()
+
+	if msg := read(t, consumer); string(msg.Value) != "late" {
+		t.Errorf("read %s, want late", msg.Value)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := consumer.ReadMessage(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = consumer.Close()
+	}()
+
+	if _, err := consumer.ReadMessage(context.Background()); !errors.Is(err, bus.ErrClosed) {
+		t.Errorf("expected closed consumer error, got %v", err)
+	}
+}
+
+func publish(t *testing.T, producer *Producer, topic string, key string, value string) {
+	t.Helper()
+
+	var k []byte
+	if key != "" {
+		k = []byte(key)
+	}
+
+	if err := producer.Publish(context.Background(), topic, k, []byte(value)); err != nil {
+		t.Errorf("failed to publish: %v", err)
+	}
+}
+
+func subscribe(t *testing.T, consumer *Consumer, topics ...string) {
+	t.Helper()
+
+	if err := consumer.SubscribeTopics(topics); err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+}
+
+func read(t *testing.T, consumer *Consumer) *bus.Message {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	msg, err := consumer.ReadMessage(ctx)
+	if err != nil {
+		t.Fatalf("failed to read message: %v", err)
+	}
+
+	return msg
+}
+
+func expectNone(t *testing.T, consumer *Consumer) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if msg, err := consumer.ReadMessage(ctx); err == nil {
+		t.Errorf("unexpected message %s", msg.Value)
+	}
+}
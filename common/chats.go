@@ -0,0 +1,44 @@
+package common
+
+import (
+	"sort"
+	"strings"
+)
+
+const (
+	directChatPrefix = "dm:"
+	groupChatPrefix  = "group:"
+)
+
+// DirectChatID returns the chat ID of a direct (one-to-one) chat between two users.
+// The ID does not depend on the order of the arguments.
+func DirectChatID(userID, otherUserID string) string {
+	ids := []string{userID, otherUserID}
+	sort.Strings(ids)
+
+	return directChatPrefix + ids[0] + ":" + ids[1]
+}
+
+// ParseDirectChatID returns participants of a direct chat, ok is false if chatID is not a direct chat ID.
+func ParseDirectChatID(chatID string) (userID, otherUserID string, ok bool) {
+	if !strings.HasPrefix(chatID, directChatPrefix) {
+		return "", "", false
+	}
+
+	userID, otherUserID, ok = strings.Cut(strings.TrimPrefix(chatID, directChatPrefix), ":")
+	if !ok || userID == "" || otherUserID == "" {
+		return "", "", false
+	}
+
+	return userID, otherUserID, true
+}
+
+// GroupChatID returns the chat ID of a group chat with the given unique ID.
+func GroupChatID(id string) string {
+	return groupChatPrefix + id
+}
+
+// IsGroupChatID reports whether chatID is a group chat ID. Participants of groups are kept by chat-history service.
+func IsGroupChatID(chatID string) bool {
+	return strings.HasPrefix(chatID, groupChatPrefix)
+}
@@ -0,0 +1,44 @@
+package common
+
+import (
+	"context"
+
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// UserAgentMetadataKey is the gRPC metadata key carrying user agent of the end user's client.
+	UserAgentMetadataKey = "x-client-user-agent"
+	// DeviceNameMetadataKey is the gRPC metadata key carrying device name chosen by the end user's client.
+	DeviceNameMetadataKey = "x-client-device-name"
+)
+
+// WithClientDevice returns a context which passes user agent and device name of the client
+// to outgoing gRPC requests, empty values are omitted.
+func WithClientDevice(ctx context.Context, userAgent string, deviceName string) context.Context {
+	if userAgent != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, UserAgentMetadataKey, userAgent)
+	}
+	if deviceName != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, DeviceNameMetadataKey, deviceName)
+	}
+
+	return ctx
+}
+
+// ClientDevice returns user agent and device name of the client of an incoming gRPC request.
+func ClientDevice(ctx context.Context) (userAgent string, deviceName string) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", ""
+	}
+
+	if values := md.Get(UserAgentMetadataKey); len(values) > 0 {
+		userAgent = values[0]
+	}
+	if values := md.Get(DeviceNameMetadataKey); len(values) > 0 {
+		deviceName = values[0]
+	}
+
+	return userAgent, deviceName
+}
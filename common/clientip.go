@@ -0,0 +1,45 @@
+package common
+
+import (
+	"context"
+	"net"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// ClientIPMetadataKey is the gRPC metadata key carrying IP of the end user on whose behalf the request is made.
+//
+// It is set by the facade service, which is the only entry point for end users, so internal services trust it.
+const ClientIPMetadataKey = "x-client-ip"
+
+// WithClientIP returns a context which passes the given client IP to outgoing gRPC requests.
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	if ip == "" {
+		return ctx
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, ClientIPMetadataKey, ip)
+}
+
+// ClientIP returns client IP of an incoming gRPC request.
+//
+// The IP is taken from request metadata, if it isn't there, address of the gRPC peer is used.
+func ClientIP(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(ClientIPMetadataKey); len(values) > 0 && values[0] != "" {
+			return values[0]
+		}
+	}
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			return p.Addr.String()
+		}
+
+		return host
+	}
+
+	return ""
+}
@@ -0,0 +1,52 @@
+package backend
+
+import (
+	"fmt"
+
+	"github.com/zoninnik89/messenger/common/discovery"
+	"github.com/zoninnik89/messenger/common/discovery/consul"
+	"github.com/zoninnik89/messenger/common/discovery/dns"
+	"github.com/zoninnik89/messenger/common/discovery/static"
+)
+
+const (
+	Consul = "consul"
+	Static = "static"
+	DNS    = "dns"
+)
+
+// Config selects the registry of services, it is meant to be embedded into configs of the services.
+type Config struct {
+	// Backend is one of "consul", "static" and "dns"
+	Backend string `yaml:"backend" env-default:"consul"`
+	// Services are addresses of instances keyed by service name, used by the static backend
+	Services map[string][]string `yaml:"services"`
+	DNS      DNSConfig           `yaml:"dns"`
+}
+
+type DNSConfig struct {
+	// Domain is appended to service names to look up their SRV records
+	Domain string `yaml:"domain" env-default:"service.consul"`
+	// Server is "host:port" of the DNS server, system ones are used if empty
+	Server string `yaml:"server"`
+}
+
+// New returns registry of the configured backend. The Consul agent is expected at consulHost:consulPort.
+func New(cfg Config, consulHost string, consulPort int) (discovery.Registry, error) {
+	const op = "backend.New"
+
+	switch cfg.Backend {
+	case Consul, "":
+		registry, err := consul.NewRegistry(consulHost, consulPort)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		return registry, nil
+	case Static:
+		return static.NewRegistry(cfg.Services), nil
+	case DNS:
+		return dns.NewRegistry(cfg.DNS.Domain, cfg.DNS.Server), nil
+	default:
+		return nil, fmt.Errorf("%s: unknown discovery backend %q", op, cfg.Backend)
+	}
+}
@@ -6,8 +6,12 @@ import (
 	consul "github.com/hashicorp/consul/api"
 	"log"
 	"strconv"
+	"time"
 )
 
+// watchWaitTime is how long a blocking query waits for changes of service instances.
+const watchWaitTime = time.Minute
+
 type Registry struct {
 	client *consul.Client
 }
@@ -53,7 +57,7 @@ func (registry *Registry) Deregister(
 ) error {
 
 	log.Printf("Deregistering service %s", instanceID)
-	return registry.client.Agent().CheckDeregister(instanceID)
+	return registry.client.Agent().ServiceDeregister(instanceID)
 }
 
 func (registry *Registry) HealthCheck(instanceID string) error {
@@ -66,10 +70,42 @@ func (registry *Registry) Discover(ctx context.Context, serviceName string) ([]s
 		return nil, err
 	}
 
-	var instances []string
+	return addresses(entries), nil
+}
+
+// Watch calls update with addresses of healthy instances of the service whenever they change,
+// using blocking queries.
+func (registry *Registry) Watch(ctx context.Context, serviceName string, update func(addrs []string)) error {
+	var index uint64
+	for {
+		opts := (&consul.QueryOptions{WaitIndex: index, WaitTime: watchWaitTime}).WithContext(ctx)
+
+		entries, meta, err := registry.client.Health().Service(serviceName, "", true, opts)
+		if err != nil {
+			return err
+		}
+
+		// the wait timed out without changes
+		if index != 0 && meta.LastIndex == index {
+			continue
+		}
+
+		// the index may go backwards after Consul restarts, then the next query waits for changes after
+		// the new one, but it must never be 0, which doesn't wait at all
+		index = meta.LastIndex
+		if index < 1 {
+			index = 1
+		}
+
+		update(addresses(entries))
+	}
+}
+
+func addresses(entries []*consul.ServiceEntry) []string {
+	instances := make([]string, 0, len(entries))
 	for _, entry := range entries {
 		instances = append(instances, fmt.Sprintf("%s:%d", entry.Service.Address, entry.Service.Port))
 	}
 
-	return instances, nil
+	return instances
 }
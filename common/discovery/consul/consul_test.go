@@ -0,0 +1,139 @@
+package consul
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	consul "github.com/hashicorp/consul/api"
+)
+
+// waitTimeout is how long the test waits for updates of the watch
+const waitTimeout = 5 * time.Second
+
+// healthAPI serves healthy instances of services like Consul, blocking queries return when instances
+// change or after waitTime, like when the wait of Consul times out
+type healthAPI struct {
+	waitTime time.Duration
+
+	mu      sync.Mutex
+	index   uint64
+	entries []*consul.ServiceEntry
+	// changed is closed and replaced on every change of instances
+	changed chan struct{}
+}
+
+func newHealthAPI(waitTime time.Duration) *healthAPI {
+	return &healthAPI{waitTime: waitTime, index: 1, changed: make(chan struct{})}
+}
+
+func (h *healthAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	index, _ := strconv.ParseUint(r.URL.Query().Get("index"), 10, 64)
+
+	h.mu.Lock()
+	current, changed := h.index, h.changed
+	h.mu.Unlock()
+
+	if index == current {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-changed:
+		case <-time.After(h.waitTime):
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	w.Header().Set("X-Consul-Index", strconv.FormatUint(h.index, 10))
+	_ = json.NewEncoder(w).Encode(h.entries)
+}
+
+func (h *healthAPI) setInstances(addrs ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.entries = nil
+	for _, addr := range addrs {
+		host, port, _ := net.SplitHostPort(addr)
+		p, _ := strconv.Atoi(port)
+		h.entries = append(h.entries, &consul.ServiceEntry{Service: &consul.AgentService{Address: host, Port: p}})
+	}
+
+	h.index++
+	close(h.changed)
+	h.changed = make(chan struct{})
+}
+
+func TestRegistry_Watch(t *testing.T) {
+	api := newHealthAPI(10 * time.Millisecond)
+	api.setInstances("10.0.0.1:2000")
+
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+
+	host, port, _ := net.SplitHostPort(srv.Listener.Addr().String())
+	p, _ := strconv.Atoi(port)
+
+	registry, err := NewRegistry(host, p)
+	if err != nil {
+		t.Fatalf("failed to create registry: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	updates := make(chan []string, 10)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- registry.Watch(ctx, "sso-service", func(addrs []string) { updates <- addrs })
+	}()
+
+	assertUpdate(t, updates, []string{"10.0.0.1:2000"})
+
+	// waits which time out without changes don't update
+	time.Sleep(100 * time.Millisecond)
+	select {
+	case addrs := <-updates:
+		t.Fatalf("expected no update without changes, got %v", addrs)
+	default:
+	}
+
+	api.setInstances("10.0.0.1:2000", "10.0.0.2:2000")
+	assertUpdate(t, updates, []string{"10.0.0.1:2000", "10.0.0.2:2000"})
+
+	api.setInstances("10.0.0.2:2000")
+	assertUpdate(t, updates, []string{"10.0.0.2:2000"})
+
+	cancel()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("expected watch to fail once the context is done")
+		}
+	case <-time.After(waitTimeout):
+		t.Fatal("watch didn't stop once the context is done")
+	}
+}
+
+// assertUpdate asserts that the next update of the watch has the addresses
+func assertUpdate(t *testing.T, updates <-chan []string, want []string) {
+	t.Helper()
+
+	select {
+	case got := <-updates:
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("expected addresses %v, got %v", want, got)
+		}
+	case <-time.After(waitTimeout):
+		t.Fatalf("watch didn't update addresses to %v", want)
+	}
+}
@@ -0,0 +1,88 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Registry discovers services by SRV records named "<service>.<domain>", as served by Consul DNS
+// ("service.consul" domain) or Kubernetes headless services. Instances are registered by whoever serves
+// the records, so Register, Deregister and HealthCheck do nothing.
+type Registry struct {
+	domain   string
+	resolver resolver
+}
+
+// resolver looks up SRV records, it is implemented by net.Resolver
+type resolver interface {
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+}
+
+// NewRegistry returns registry which looks up SRV records in the domain. If server isn't empty, it is
+// "host:port" of the DNS server to query instead of the system ones.
+func NewRegistry(domain string, server string) *Registry {
+	resolver := net.DefaultResolver
+	if server != "" {
+		resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, server)
+			},
+		}
+	}
+
+	return &Registry{
+		domain:   strings.Trim(domain, "."),
+		resolver: resolver,
+	}
+}
+
+func (registry *Registry) Register(
+	ctx context.Context,
+	instanceID string,
+	host string,
+	hostPort int,
+	serviceName string,
+) error {
+
+	return nil
+}
+
+func (registry *Registry) Deregister(ctx context.Context, instanceID string) error {
+	return nil
+}
+
+func (registry *Registry) HealthCheck(instanceID string) error {
+	return nil
+}
+
+func (registry *Registry) Discover(ctx context.Context, serviceName string) ([]string, error) {
+	const op = "dns.Registry.Discover"
+
+	name := serviceName
+	if registry.domain != "" {
+		name += "." + registry.domain
+	}
+
+	_, records, err := registry.resolver.LookupSRV(ctx, "", "", name)
+	if err != nil {
+		// no records means no instances rather than a failure
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	instances := make([]string, 0, len(records))
+	for _, record := range records {
+		instances = append(instances, net.JoinHostPort(strings.TrimSuffix(record.Target, "."), strconv.Itoa(int(record.Port))))
+	}
+
+	return instances, nil
+}
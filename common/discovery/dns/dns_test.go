@@ -0,0 +1,101 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+)
+
+// stubResolver answers with the records of the looked up name
+type stubResolver struct {
+	records map[string][]*net.SRV
+	err     error
+	// looked up is the name of the last lookup
+	lookedUp string
+}
+
+func (r *stubResolver) LookupSRV(_ context.Context, _, _, name string) (string, []*net.SRV, error) {
+	r.lookedUp = name
+	if r.err != nil {
+		return "", nil, r.err
+	}
+
+	records, ok := r.records[name]
+	if !ok {
+		return "", nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+
+	return name, records, nil
+}
+
+func TestRegistry_Discover(t *testing.T) {
+	records := map[string][]*net.SRV{
+		"chat-history.service.consul": {
+			{Target: "10.0.0.1.", Port: 2002},
+			{Target: "chat-history-2.node.consul.", Port: 2003},
+		},
+		"sso-service": {{Target: "sso.", Port: 44044}},
+	}
+
+	tests := []struct {
+		name        string
+		domain      string
+		serviceName string
+		wantName    string
+		want        []string
+	}{
+		{
+			name:        "service in domain",
+			domain:      "service.consul.",
+			serviceName: "chat-history",
+			wantName:    "chat-history.service.consul",
+			want:        []string{"10.0.0.1:2002", "chat-history-2.node.consul:2003"},
+		},
+		{
+			name:        "without domain",
+			serviceName: "sso-service",
+			wantName:    "sso-service",
+			want:        []string{"sso:44044"},
+		},
+		{
+			name:        "no records",
+			domain:      "service.consul",
+			serviceName: "pub-sub",
+			wantName:    "pub-sub.service.consul",
+			want:        nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &stubResolver{records: records}
+			registry := NewRegistry(tt.domain, "")
+			registry.resolver = stub
+
+			got, err := registry.Discover(context.Background(), tt.serviceName)
+			if err != nil {
+				t.Fatalf("failed to discover: %v", err)
+			}
+
+			if stub.lookedUp != tt.wantName {
+				t.Errorf("expected lookup of %s, got %s", tt.wantName, stub.lookedUp)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected instances %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRegistry_DiscoverFails(t *testing.T) {
+	lookupErr := &net.DNSError{Err: "server misbehaving", Name: "sso-service", IsTemporary: true}
+
+	registry := NewRegistry("", "")
+	registry.resolver = &stubResolver{err: lookupErr}
+
+	if _, err := registry.Discover(context.Background(), "sso-service"); !errors.Is(err, lookupErr) {
+		t.Errorf("expected %v, got %v", lookupErr, err)
+	}
+}
@@ -2,13 +2,20 @@ package discovery
 
 import (
 	"context"
+	"errors"
 	"log"
 	"math/rand"
 
+	"github.com/zoninnik89/messenger/common/telemetry"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
+// ErrNoInstances is returned by ServiceConnection when no healthy instance of the service is registered.
+var ErrNoInstances = errors.New("no instances of the service")
+
+// ServiceConnection creates a connection to a random instance of the service. Unlike connections of a Pool,
+// it is neither reused nor balanced, so it is meant for one-off calls and must be closed by the caller.
 func ServiceConnection(ctx context.Context, serviceName string, registry Registry) (*grpc.ClientConn, error) {
 	addrs, err := registry.Discover(ctx, serviceName)
 	if err != nil {
@@ -17,9 +24,14 @@ func ServiceConnection(ctx context.Context, serviceName string, registry Registr
 
 	log.Printf("Discovered %d instances of %s", len(addrs), serviceName)
 
+	if len(addrs) == 0 {
+		return nil, ErrNoInstances
+	}
+
 	// Randomly select an instance
 	return grpc.NewClient(
 		addrs[rand.Intn(len(addrs))],
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		telemetry.DialOption(),
 	)
 }
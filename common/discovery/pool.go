@@ -0,0 +1,90 @@
+package discovery
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/zoninnik89/messenger/common/telemetry"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// roundRobinConfig makes client connections spread RPCs over all instances of the service.
+const roundRobinConfig = `{"loadBalancingConfig": [{"round_robin": {}}]}`
+
+// ErrPoolClosed is returned by Pool.Conn after the pool has been closed.
+var ErrPoolClosed = errors.New("connection pool is closed")
+
+// Pool caches a client connection per service. Connections resolve instances through the registry,
+// follow them as they register and deregister, and balance RPCs over them round-robin. RPCs propagate
+// traces of their contexts.
+type Pool struct {
+	builder *resolverBuilder
+	options []grpc.DialOption
+
+	mu     sync.Mutex
+	conns  map[string]*grpc.ClientConn
+	closed bool
+}
+
+// NewPool returns pool of connections to services discovered in the registry. Options are applied
+// to every connection after the pool's own ones.
+func NewPool(registry Registry, options ...grpc.DialOption) *Pool {
+	return &Pool{
+		builder: &resolverBuilder{registry: registry},
+		options: options,
+		conns:   make(map[string]*grpc.ClientConn),
+	}
+}
+
+// Conn returns connection to the service, creating it on the first call. Connections are shared
+// and must not be closed by callers.
+func (p *Pool) Conn(serviceName string) (*grpc.ClientConn, error) {
+	const op = "discovery.Pool.Conn"
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, fmt.Errorf("%s: %w", op, ErrPoolClosed)
+	}
+
+	if conn, ok := p.conns[serviceName]; ok {
+		return conn, nil
+	}
+
+	options := append([]grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithResolvers(p.builder),
+		grpc.WithDefaultServiceConfig(roundRobinConfig),
+		telemetry.DialOption(),
+	}, p.options...)
+
+	conn, err := grpc.NewClient(Scheme+":///"+serviceName, options...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	p.conns[serviceName] = conn
+
+	return conn, nil
+}
+
+// Close closes all connections of the pool.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.closed = true
+
+	var errs []error
+	for serviceName, conn := range p.conns {
+		if err := conn.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close connection to %s: %w", serviceName, err))
+		}
+		delete(p.conns, serviceName)
+	}
+
+	return errors.Join(errs...)
+}
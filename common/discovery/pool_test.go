@@ -0,0 +1,218 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/stats"
+)
+
+// countingServer is an instance of a service, it counts RPCs it served and connections open to it
+type countingServer struct {
+	healthpb.UnimplementedHealthServer
+
+	host  string
+	port  int
+	calls atomic.Int32
+	conns atomic.Int32
+}
+
+// startCountingServer starts serving on an ephemeral port until the test ends
+func startCountingServer(t *testing.T) *countingServer {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	s := &countingServer{host: "127.0.0.1", port: l.Addr().(*net.TCPAddr).Port}
+
+	srv := grpc.NewServer(grpc.StatsHandler(s))
+	healthpb.RegisterHealthServer(srv, s)
+	go func() { _ = srv.Serve(l) }()
+	t.Cleanup(srv.Stop)
+
+	return s
+}
+
+func (s *countingServer) Check(context.Context, *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	s.calls.Add(1)
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+func (s *countingServer) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (s *countingServer) HandleRPC(context.Context, stats.RPCStats) {}
+
+func (s *countingServer) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (s *countingServer) HandleConn(_ context.Context, connStats stats.ConnStats) {
+	switch connStats.(type) {
+	case *stats.ConnBegin:
+		s.conns.Add(1)
+	case *stats.ConnEnd:
+		s.conns.Add(-1)
+	}
+}
+
+// waitFor waits until the condition holds
+func waitFor(t *testing.T, condition func() bool, msg string) {
+	t.Helper()
+
+	deadline := time.Now().Add(waitTimeout)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal(msg)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// check makes an RPC to the service, waiting for an instance to be ready
+func check(t *testing.T, conn *grpc.ClientConn) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), waitTimeout)
+	defer cancel()
+
+	if _, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{}, grpc.WaitForReady(true)); err != nil {
+		t.Fatalf("failed to check: %v", err)
+	}
+}
+
+func TestPool_Conn(t *testing.T) {
+	pool := NewPool(newWatchedRegistry())
+
+	conn, err := pool.Conn("sso-service")
+	if err != nil {
+		t.Fatalf("failed to get connection: %v", err)
+	}
+
+	again, err := pool.Conn("sso-service")
+	if err != nil {
+		t.Fatalf("failed to get connection: %v", err)
+	}
+	if again != conn {
+		t.Error("expected the connection to the service to be shared")
+	}
+
+	other, err := pool.Conn("chat-history")
+	if err != nil {
+		t.Fatalf("failed to get connection: %v", err)
+	}
+	if other == conn {
+		t.Error("expected services to have their own connections")
+	}
+
+	if err := pool.Close(); err != nil {
+		t.Fatalf("failed to close pool: %v", err)
+	}
+
+	if _, err := pool.Conn("sso-service"); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("expected %v, got %v", ErrPoolClosed, err)
+	}
+}
+
+func TestPool_RoundRobin(t *testing.T) {
+	ctx := context.Background()
+	registry := newWatchedRegistry()
+
+	servers := []*countingServer{startCountingServer(t), startCountingServer(t)}
+	for i, s := range servers {
+		if err := registry.Register(ctx, "sso-service-"+strconv.Itoa(i), s.host, s.port, "sso-service"); err != nil {
+			t.Fatalf("failed to register: %v", err)
+		}
+	}
+
+	pool := NewPool(registry)
+	t.Cleanup(func() { _ = pool.Close() })
+
+	conn, err := pool.Conn("sso-service")
+	if err != nil {
+		t.Fatalf("failed to get connection: %v", err)
+	}
+
+	// RPCs go to the instances which are ready, so both must be before counting
+	waitFor(t, func() bool {
+		check(t, conn)
+		return servers[0].calls.Load() > 0 && servers[1].calls.Load() > 0
+	}, "RPCs didn't reach both instances")
+
+	for _, s := range servers {
+		s.calls.Store(0)
+	}
+
+	const calls = 10
+	for i := 0; i < calls; i++ {
+		check(t, conn)
+	}
+
+	// round-robin alternates the instances, so they serve the same number of RPCs
+	want := int32(calls / len(servers))
+	for i, s := range servers {
+		if got := s.calls.Load(); got != want {
+			t.Errorf("expected instance %d to serve %d RPCs, served %d", i, want, got)
+		}
+	}
+}
+
+func TestPool_Deregistered(t *testing.T) {
+	ctx := context.Background()
+	registry := newWatchedRegistry()
+
+	deregistered, remaining := startCountingServer(t), startCountingServer(t)
+	if err := registry.Register(ctx, "sso-service-1", deregistered.host, deregistered.port, "sso-service"); err != nil {
+		t.Fatalf("failed to register: %v", err)
+	}
+	if err := registry.Register(ctx, "sso-service-2", remaining.host, remaining.port, "sso-service"); err != nil {
+		t.Fatalf("failed to register: %v", err)
+	}
+
+	pool := NewPool(registry)
+	t.Cleanup(func() { _ = pool.Close() })
+
+	conn, err := pool.Conn("sso-service")
+	if err != nil {
+		t.Fatalf("failed to get connection: %v", err)
+	}
+
+	waitFor(t, func() bool {
+		check(t, conn)
+		return deregistered.calls.Load() > 0 && remaining.calls.Load() > 0
+	}, "RPCs didn't reach both instances")
+
+	if err := registry.Deregister(ctx, "sso-service-1"); err != nil {
+		t.Fatalf("failed to deregister: %v", err)
+	}
+
+	waitFor(t, func() bool {
+		return deregistered.conns.Load() == 0
+	}, "connection to the deregistered instance wasn't closed")
+
+	deregistered.calls.Store(0)
+	remaining.calls.Store(0)
+
+	const calls = 5
+	for i := 0; i < calls; i++ {
+		check(t, conn)
+	}
+
+	if got := deregistered.calls.Load(); got != 0 {
+		t.Errorf("expected the deregistered instance to serve no RPCs, served %d", got)
+	}
+	if got := remaining.calls.Load(); got != calls {
+		t.Errorf("expected the remaining instance to serve %d RPCs, served %d", calls, got)
+	}
+}
@@ -0,0 +1,153 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/resolver"
+)
+
+// Scheme is the scheme of gRPC targets resolved through a Registry, e.g. "registry:///sso-service".
+const Scheme = "registry"
+
+// pollInterval is how often instances are rediscovered when the registry can't watch them,
+// and how long to wait before watching again after a failure.
+const pollInterval = 5 * time.Second
+
+// Watcher is implemented by registries which can notify about changes of service instances.
+type Watcher interface {
+	// Watch calls update with addresses of healthy instances of the service whenever they change,
+	// until ctx is done or watching fails.
+	Watch(ctx context.Context, serviceName string, update func(addrs []string)) error
+}
+
+// resolverBuilder builds resolvers of targets with Scheme to instances of the service discovered in the
+// registry. Registries implementing Watcher are watched, others are polled.
+type resolverBuilder struct {
+	registry Registry
+}
+
+func (b *resolverBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	r := &registryResolver{
+		serviceName: target.Endpoint(),
+		registry:    b.registry,
+		cc:          cc,
+		cancel:      cancel,
+		resolveNow:  make(chan struct{}, 1),
+	}
+
+	r.wg.Add(1)
+	go r.run(ctx)
+
+	return r, nil
+}
+
+func (b *resolverBuilder) Scheme() string {
+	return Scheme
+}
+
+type registryResolver struct {
+	serviceName string
+	registry    Registry
+	cc          resolver.ClientConn
+
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+	resolveNow chan struct{}
+
+	// addrs are addresses last passed to the client connection, nil until the first update
+	addrs []string
+}
+
+func (r *registryResolver) ResolveNow(resolver.ResolveNowOptions) {
+	select {
+	case r.resolveNow <- struct{}{}:
+	default:
+	}
+}
+
+func (r *registryResolver) Close() {
+	r.cancel()
+	r.wg.Wait()
+}
+
+func (r *registryResolver) run(ctx context.Context) {
+	defer r.wg.Done()
+
+	if watcher, ok := r.registry.(Watcher); ok {
+		r.watch(ctx, watcher)
+		return
+	}
+
+	r.poll(ctx)
+}
+
+func (r *registryResolver) watch(ctx context.Context, watcher Watcher) {
+	for {
+		err := watcher.Watch(ctx, r.serviceName, r.update)
+		if ctx.Err() != nil {
+			return
+		}
+
+		r.cc.ReportError(fmt.Errorf("failed to watch instances of %s: %w", r.serviceName, err))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+func (r *registryResolver) poll(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		discoverCtx, cancel := context.WithTimeout(ctx, pollInterval)
+		addrs, err := r.registry.Discover(discoverCtx, r.serviceName)
+		cancel()
+
+		switch {
+		case ctx.Err() != nil:
+			return
+		case err != nil:
+			r.cc.ReportError(fmt.Errorf("failed to discover instances of %s: %w", r.serviceName, err))
+		default:
+			r.update(addrs)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-r.resolveNow:
+		}
+	}
+}
+
+// update passes the addresses to the client connection if they changed. The balancer closes connections
+// to addresses which are gone, and fails RPCs while there are none.
+func (r *registryResolver) update(addrs []string) {
+	addrs = append([]string{}, addrs...)
+	slices.Sort(addrs)
+	addrs = slices.Compact(addrs)
+
+	if r.addrs != nil && slices.Equal(r.addrs, addrs) {
+		return
+	}
+	r.addrs = addrs
+
+	state := resolver.State{Addresses: make([]resolver.Address, 0, len(addrs))}
+	for _, addr := range addrs {
+		state.Addresses = append(state.Addresses, resolver.Address{Addr: addr})
+	}
+
+	// the error only asks to resolve again, which the next poll or watch update does anyway
+	_ = r.cc.UpdateState(state)
+}
@@ -0,0 +1,189 @@
+package discovery
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/resolver"
+)
+
+// waitTimeout is how long tests wait for resolvers and connections to catch up with the registry
+const waitTimeout = 5 * time.Second
+
+type instance struct {
+	serviceName string
+	addr        string
+}
+
+// watchedRegistry keeps instances in memory and notifies watchers whenever they change
+type watchedRegistry struct {
+	mu        sync.Mutex
+	instances map[string]instance
+	// changed is closed and replaced on every change of instances
+	changed chan struct{}
+}
+
+func newWatchedRegistry() *watchedRegistry {
+	return &watchedRegistry{
+		instances: make(map[string]instance),
+		changed:   make(chan struct{}),
+	}
+}
+
+func (r *watchedRegistry) Register(_ context.Context, instanceID, host string, hostPort int, serviceName string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.instances[instanceID] = instance{serviceName: serviceName, addr: net.JoinHostPort(host, strconv.Itoa(hostPort))}
+	close(r.changed)
+	r.changed = make(chan struct{})
+
+	return nil
+}
+
+func (r *watchedRegistry) Deregister(_ context.Context, instanceID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.instances, instanceID)
+	close(r.changed)
+	r.changed = make(chan struct{})
+
+	return nil
+}
+
+func (r *watchedRegistry) Discover(_ context.Context, serviceName string) ([]string, error) {
+	addrs, _ := r.addrs(serviceName)
+	if len(addrs) == 0 {
+		return nil, ErrNoInstances
+	}
+
+	return addrs, nil
+}
+
+func (r *watchedRegistry) HealthCheck(string) error {
+	return nil
+}
+
+func (r *watchedRegistry) Watch(ctx context.Context, serviceName string, update func(addrs []string)) error {
+	for {
+		addrs, changed := r.addrs(serviceName)
+		update(addrs)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// addrs returns addresses of instances of the service and the channel closed on their next change
+func (r *watchedRegistry) addrs(serviceName string) ([]string, <-chan struct{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var addrs []string
+	for _, instance := range r.instances {
+		if instance.serviceName == serviceName {
+			addrs = append(addrs, instance.addr)
+		}
+	}
+	slices.Sort(addrs)
+
+	return addrs, r.changed
+}
+
+// polledRegistry hides Watch of the registry, so resolvers poll it
+type polledRegistry struct {
+	Registry
+}
+
+// recordingClientConn records states passed by the resolver
+type recordingClientConn struct {
+	resolver.ClientConn
+
+	states chan resolver.State
+}
+
+func (cc *recordingClientConn) UpdateState(state resolver.State) error {
+	cc.states <- state
+	return nil
+}
+
+func (cc *recordingClientConn) ReportError(error) {}
+
+func TestResolver_FollowsRegistry(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		registry func(r *watchedRegistry) Registry
+	}{
+		{
+			name:     "watched",
+			registry: func(r *watchedRegistry) Registry { return r },
+		},
+		{
+			name:     "polled",
+			registry: func(r *watchedRegistry) Registry { return polledRegistry{r} },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := newWatchedRegistry()
+			if err := registry.Register(ctx, "pub-sub-1", "127.0.0.1", 2000, "pub-sub"); err != nil {
+				t.Fatalf("failed to register: %v", err)
+			}
+			if err := registry.Register(ctx, "pub-sub-2", "127.0.0.1", 2100, "pub-sub"); err != nil {
+				t.Fatalf("failed to register: %v", err)
+			}
+
+			cc := &recordingClientConn{states: make(chan resolver.State, 10)}
+			builder := &resolverBuilder{registry: tt.registry(registry)}
+
+			r, err := builder.Build(resolver.Target{URL: url.URL{Scheme: Scheme, Path: "/pub-sub"}}, cc, resolver.BuildOptions{})
+			if err != nil {
+				t.Fatalf("failed to build resolver: %v", err)
+			}
+			defer r.Close()
+
+			assertAddrs(t, cc.states, []string{"127.0.0.1:2000", "127.0.0.1:2100"})
+
+			if err := registry.Deregister(ctx, "pub-sub-1"); err != nil {
+				t.Fatalf("failed to deregister: %v", err)
+			}
+			// polled registries are discovered again on the next poll, gRPC asks for it when connections fail
+			r.ResolveNow(resolver.ResolveNowOptions{})
+
+			assertAddrs(t, cc.states, []string{"127.0.0.1:2100"})
+		})
+	}
+}
+
+// assertAddrs asserts that the next state passed by the resolver has the addresses
+func assertAddrs(t *testing.T, states <-chan resolver.State, want []string) {
+	t.Helper()
+
+	select {
+	case state := <-states:
+		var got []string
+		for _, addr := range state.Addresses {
+			got = append(got, addr.Addr)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("expected addresses %v, got %v", want, got)
+		}
+	case <-time.After(waitTimeout):
+		t.Fatalf("resolver didn't pass addresses %v", want)
+	}
+}
@@ -0,0 +1,99 @@
+package static
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+var ErrInstanceNotFound = errors.New("instance is not registered")
+
+type instance struct {
+	serviceName string
+	address     string
+}
+
+// Registry discovers services at fixed addresses, e.g. from a config file, and instances registered in
+// the same process, so services can find each other without Consul in tests and single-box setups.
+type Registry struct {
+	services map[string][]string
+
+	mu        sync.RWMutex
+	instances map[string]instance
+}
+
+// NewRegistry returns registry of services at the given addresses, keyed by service name.
+func NewRegistry(services map[string][]string) *Registry {
+	registry := &Registry{
+		services:  make(map[string][]string, len(services)),
+		instances: make(map[string]instance),
+	}
+
+	for serviceName, addrs := range services {
+		registry.services[serviceName] = slices.Clone(addrs)
+	}
+
+	return registry
+}
+
+func (registry *Registry) Register(
+	ctx context.Context,
+	instanceID string,
+	host string,
+	hostPort int,
+	serviceName string,
+) error {
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	registry.instances[instanceID] = instance{
+		serviceName: serviceName,
+		address:     net.JoinHostPort(host, strconv.Itoa(hostPort)),
+	}
+
+	return nil
+}
+
+func (registry *Registry) Deregister(ctx context.Context, instanceID string) error {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if _, ok := registry.instances[instanceID]; !ok {
+		return fmt.Errorf("%s: %w", instanceID, ErrInstanceNotFound)
+	}
+
+	delete(registry.instances, instanceID)
+
+	return nil
+}
+
+// HealthCheck only checks that the instance is registered, instances of the registry are always healthy.
+func (registry *Registry) HealthCheck(instanceID string) error {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	if _, ok := registry.instances[instanceID]; !ok {
+		return fmt.Errorf("%s: %w", instanceID, ErrInstanceNotFound)
+	}
+
+	return nil
+}
+
+func (registry *Registry) Discover(ctx context.Context, serviceName string) ([]string, error) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	instances := slices.Clone(registry.services[serviceName])
+	for _, inst := range registry.instances {
+		if inst.serviceName == serviceName && !slices.Contains(instances, inst.address) {
+			instances = append(instances, inst.address)
+		}
+	}
+
+	return instances, nil
+}
@@ -0,0 +1,93 @@
+package static
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRegistry_Discover(t *testing.T) {
+	ctx := context.Background()
+
+	registry := NewRegistry(map[string][]string{
+		"sso-service": {"localhost:44044"},
+		"pub-sub":     {"localhost:2000", "localhost:2100"},
+	})
+
+	if err := registry.Register(ctx, "pub-sub-1", "127.0.0.1", 3000, "pub-sub"); err != nil {
+		t.Fatalf("failed to register: %v", err)
+	}
+	// the configured address isn't listed twice
+	if err := registry.Register(ctx, "pub-sub-2", "localhost", 2000, "pub-sub"); err != nil {
+		t.Fatalf("failed to register: %v", err)
+	}
+	if err := registry.Register(ctx, "chat-history-1", "127.0.0.1", 2002, "chat-history"); err != nil {
+		t.Fatalf("failed to register: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		serviceName string
+		want        []string
+	}{
+		{
+			name:        "configured",
+			serviceName: "sso-service",
+			want:        []string{"localhost:44044"},
+		},
+		{
+			name:        "configured and registered",
+			serviceName: "pub-sub",
+			want:        []string{"localhost:2000", "localhost:2100", "127.0.0.1:3000"},
+		},
+		{
+			name:        "registered",
+			serviceName: "chat-history",
+			want:        []string{"127.0.0.1:2002"},
+		},
+		{
+			name:        "unknown",
+			serviceName: "chat-client",
+			want:        nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := registry.Discover(ctx, tt.serviceName)
+			if err != nil {
+				t.Fatalf("failed to discover: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected instances %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRegistry_Deregister(t *testing.T) {
+	ctx := context.Background()
+	registry := NewRegistry(nil)
+
+	if err := registry.Register(ctx, "pub-sub-1", "127.0.0.1", 3000, "pub-sub"); err != nil {
+		t.Fatalf("failed to register: %v", err)
+	}
+	if err := registry.HealthCheck("pub-sub-1"); err != nil {
+		t.Errorf("expected registered instance to be healthy, got %v", err)
+	}
+
+	if err := registry.Deregister(ctx, "pub-sub-1"); err != nil {
+		t.Fatalf("failed to deregister: %v", err)
+	}
+
+	if got, _ := registry.Discover(ctx, "pub-sub"); len(got) != 0 {
+		t.Errorf("expected no instances, got %v", got)
+	}
+	if err := registry.HealthCheck("pub-sub-1"); !errors.Is(err, ErrInstanceNotFound) {
+		t.Errorf("expected %v, got %v", ErrInstanceNotFound, err)
+	}
+	if err := registry.Deregister(ctx, "pub-sub-1"); !errors.Is(err, ErrInstanceNotFound) {
+		t.Errorf("expected %v, got %v", ErrInstanceNotFound, err)
+	}
+}
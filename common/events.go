@@ -0,0 +1,8 @@
+package common
+
+const (
+	// AccountEventsTopic is the Kafka topic of api.AccountEvent messages published by SSO service.
+	AccountEventsTopic = "account_events"
+	// AuditEventsTopic is the Kafka topic of api.AuditEvent messages published by SSO and facade services.
+	AuditEventsTopic = "audit_events"
+)
@@ -0,0 +1,189 @@
+// Package telemetry traces requests across services with OpenTelemetry. The trace context and the request ID
+// of the facade are propagated over gRPC metadata and headers of bus messages, so a message can be followed
+// from the client request which sent it to its delivery and persistence.
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+)
+
+const (
+	None   = "none"
+	Stdout = "stdout"
+	OTLP   = "otlp"
+)
+
+// requestIDKey is the baggage member with the request ID
+const requestIDKey = "request_id"
+
+// instrumentationName names the tracer of spans started by this package
+const instrumentationName = "github.com/zoninnik89/messenger/common/telemetry"
+
+// propagator carries the trace context and baggage with the request ID. It doesn't depend on configuration,
+// so traces are propagated by services, and their tests, which don't call Init.
+var propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// Config selects where spans of the service are exported, it is meant to be embedded into configs of the services.
+type Config struct {
+	// Exporter is one of "none", "stdout" and "otlp". Traces are propagated with any exporter,
+	// so a service which doesn't export spans doesn't break traces of others
+	Exporter string `yaml:"exporter" env:"TRACING_EXPORTER" env-default:"none"`
+	// Endpoint is "host:port" of the OTLP collector, spans are sent to it over gRPC without TLS
+	Endpoint string `yaml:"endpoint" env:"TRACING_ENDPOINT" env-default:"localhost:4317"`
+	// SampleRatio is the share of traces started by the service which are recorded, zero records none.
+	// Traces started by callers are recorded if the callers record them
+	SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO" env-default:"1"`
+}
+
+// Init sets up exporting of spans of the service, unless the exporter is "none", and makes the propagator
+// of the package the global one, which other instrumentations use. The returned function flushes spans which are not exported yet, it must be called before the service exits.
+func Init(ctx context.Context, serviceName string, cfg Config) (func(context.Context) error, error) {
+	const op = "telemetry.Init"
+
+	otel.SetTextMapPropagator(propagator)
+
+	var (
+		exporter sdktrace.SpanExporter
+		err      error
+	)
+
+	switch cfg.Exporter {
+	case None, "":
+		return func(context.Context) error { return nil }, nil
+	case Stdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case OTLP:
+		exporter, err = otlptracegrpc.New(ctx, otlptracegrpc.WithEndpoint(cfg.Endpoint), otlptracegrpc.WithInsecure())
+	default:
+		return nil, fmt.Errorf("%s: unknown tracing exporter %q", op, cfg.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	res, err := resource.Merge(
+		resource.Default(),
+		resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// Tracer returns the tracer of the instrumentation, e.g. a package of the service.
+func Tracer(name string) trace.Tracer {
+	return otel.Tracer(name)
+}
+
+// ServerOption makes the gRPC server trace RPCs it serves, continuing traces of their callers.
+func ServerOption() grpc.ServerOption {
+	return grpc.StatsHandler(otelgrpc.NewServerHandler(otelgrpc.WithPropagators(propagator)))
+}
+
+// DialOption makes the gRPC client trace RPCs it calls and propagate their traces to the servers.
+func DialOption() grpc.DialOption {
+	return grpc.WithStatsHandler(otelgrpc.NewClientHandler(otelgrpc.WithPropagators(propagator)))
+}
+
+// WithRequestID returns ctx with the request ID, which is propagated to services called with ctx along with the trace.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	if requestID == "" {
+		return ctx
+	}
+
+	member, err := baggage.NewMemberRaw(requestIDKey, requestID)
+	if err != nil {
+		return ctx
+	}
+
+	bag, err := baggage.FromContext(ctx).SetMember(member)
+	if err != nil {
+		return ctx
+	}
+
+	return baggage.ContextWithBaggage(ctx, bag)
+}
+
+// RequestID returns the request ID of ctx, if the request was made through the facade.
+func RequestID(ctx context.Context) string {
+	return baggage.FromContext(ctx).Member(requestIDKey).Value()
+}
+
+// TraceID returns ID of the trace of ctx, it is empty if ctx isn't traced.
+func TraceID(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.HasTraceID() {
+		return ""
+	}
+
+	return spanContext.TraceID().String()
+}
+
+// FromHTTP returns ctx with the trace context which the client sent in headers of the HTTP request, if any.
+func FromHTTP(ctx context.Context, header http.Header) context.Context {
+	return propagator.Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// Headers returns headers of a bus message which carry the trace context and the request ID of ctx.
+func Headers(ctx context.Context) map[string]string {
+	headers := make(map[string]string)
+	propagator.Inject(ctx, propagation.MapCarrier(headers))
+
+	return headers
+}
+
+// StartPublish starts the span of publishing a message to the topic, headers of the message are taken
+// from the returned context.
+func StartPublish(ctx context.Context, topic string) (context.Context, trace.Span) {
+	return Tracer(instrumentationName).Start(ctx, "publish "+topic,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(semconv.MessagingDestinationName(topic)),
+	)
+}
+
+// StartProcess starts the span of processing a message consumed from the topic, continuing the trace
+// its headers carry.
+func StartProcess(ctx context.Context, topic string, headers map[string]string) (context.Context, trace.Span) {
+	ctx = propagator.Extract(ctx, propagation.MapCarrier(headers))
+
+	return Tracer(instrumentationName).Start(ctx, "process "+topic,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(semconv.MessagingDestinationName(topic)),
+	)
+}
+
+// End ends the span, marking it failed if err is not nil. Cancelled contexts aren't failures.
+func End(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, context.Canceled) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
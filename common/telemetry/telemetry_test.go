@@ -0,0 +1,84 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func record(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	return recorder
+}
+
+func TestHeaders_ContinueTraceOfPublisher(t *testing.T) {
+	recorder := record(t)
+
+	ctx, request := Tracer("test").Start(WithRequestID(context.Background(), "host/request-1"), "request")
+
+	publishCtx, publish := StartPublish(ctx, "messages")
+	headers := Headers(publishCtx)
+	End(publish, nil)
+	request.End()
+
+	processCtx, process := StartProcess(context.Background(), "messages", headers)
+	End(process, nil)
+
+	if got := RequestID(processCtx); got != "host/request-1" {
+		t.Errorf("request ID of consumer is %q, want %q", got, "host/request-1")
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("got %d spans, want 3", len(spans))
+	}
+
+	byName := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range spans {
+		byName[span.Name()] = span
+	}
+
+	traceID := byName["request"].SpanContext().TraceID()
+	for _, name := range []string{"publish messages", "process messages"} {
+		if got := byName[name].SpanContext().TraceID(); got != traceID {
+			t.Errorf("span %q is in trace %s, want %s", name, got, traceID)
+		}
+	}
+
+	if got, want := byName["process messages"].Parent().SpanID(), byName["publish messages"].SpanContext().SpanID(); got != want {
+		t.Errorf("parent of process span is %s, want publish span %s", got, want)
+	}
+	if got := byName["process messages"].SpanKind(); got != trace.SpanKindConsumer {
+		t.Errorf("process span is of kind %s, want consumer", got)
+	}
+}
+
+func TestStartProcess_WithoutHeadersStartsTrace(t *testing.T) {
+	record(t)
+
+	ctx, span := StartProcess(context.Background(), "messages", nil)
+	defer span.End()
+
+	if TraceID(ctx) == "" {
+		t.Error("message without headers isn't traced")
+	}
+	if got := RequestID(ctx); got != "" {
+		t.Errorf("request ID is %q, want none", got)
+	}
+}
+
+func TestInit_UnknownExporter(t *testing.T) {
+	if _, err := Init(context.Background(), "test", Config{Exporter: "jaeger"}); err == nil {
+		t.Error("unknown exporter is accepted")
+	}
+}
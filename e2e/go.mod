@@ -0,0 +1,16 @@
+// End-to-end tests of the services, which are resolved from the workspace (see go.work in the repository root).
+module github.com/zoninnik89/messenger/e2e
+
+go 1.23.1
+
+require (
+	connectrpc.com/connect v1.18.1
+	github.com/brianvoe/gofakeit v3.18.0+incompatible
+	github.com/gorilla/websocket v1.5.3
+	github.com/stretchr/testify v1.9.0
+	github.com/zoninnik89/messenger/common v0.0.0-20241014203952-140dd62a4950
+	go.opentelemetry.io/otel v1.28.0
+	go.opentelemetry.io/otel/sdk v1.28.0
+	go.opentelemetry.io/otel/trace v1.28.0
+	google.golang.org/protobuf v1.34.2
+)
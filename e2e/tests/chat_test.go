@@ -0,0 +1,177 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zoninnik89/messenger/common"
+	"github.com/zoninnik89/messenger/e2e/tests/suite"
+)
+
+const (
+	// silence is how long a connection is watched to make sure it doesn't get a message
+	silence = 500 * time.Millisecond
+	// historyWait bounds waiting for chat-history to store a delivered message
+	historyWait = 5 * time.Second
+)
+
+func TestGroupChat_MessageDeliveredAndStored(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	alice, bob, carol, dave := st.NewUser(ctx), st.NewUser(ctx), st.NewUser(ctx), st.NewUser(ctx)
+	chatID := st.CreateGroup(ctx, alice, bob, carol)
+
+	aliceConn := st.Connect(ctx, alice)
+	bobConn := st.Connect(ctx, bob)
+	carolConn := st.Connect(ctx, carol)
+	daveConn := st.Connect(ctx, dave)
+
+	text := gofakeit.Sentence(5)
+	require.NoError(t, aliceConn.Send(chatID, text))
+
+	var messageID string
+	for _, conn := range []*suite.Client{aliceConn, bobConn, carolConn} {
+		msg, err := conn.Receive(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, chatID, msg.ChatID)
+		assert.Equal(t, alice.ID, msg.SenderID)
+		assert.Equal(t, text, msg.MessageText)
+		assert.NotEmpty(t, msg.MessageID)
+
+		if messageID == "" {
+			messageID = msg.MessageID
+		}
+		assert.Equal(t, messageID, msg.MessageID, "participants got different messages")
+	}
+
+	expectNothing(t, daveConn)
+
+	for _, user := range []*suite.User{alice, bob, carol} {
+		require.Eventually(t, func() bool {
+			return containsMessage(st.ChatHistory(ctx, user, chatID), messageID, text)
+		}, historyWait, 50*time.Millisecond, "history of %s misses the message", user.Login)
+	}
+}
+
+func TestDirectChat_OnlyParticipantsReceive(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	alice, bob, carol := st.NewUser(ctx), st.NewUser(ctx), st.NewUser(ctx)
+	chatID := common.DirectChatID(alice.ID, bob.ID)
+
+	aliceConn := st.Connect(ctx, alice)
+	bobConn := st.Connect(ctx, bob)
+	carolConn := st.Connect(ctx, carol)
+
+	text := gofakeit.Sentence(5)
+	require.NoError(t, aliceConn.Send(chatID, text))
+
+	msg, err := bobConn.Receive(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, chatID, msg.ChatID)
+	assert.Equal(t, alice.ID, msg.SenderID)
+	assert.Equal(t, text, msg.MessageText)
+
+	expectNothing(t, carolConn)
+
+	// direct chats appear in chat-history with the first message
+	require.Eventually(t, func() bool {
+		return containsMessage(st.ChatHistory(ctx, bob, chatID), msg.MessageID, text)
+	}, historyWait, 50*time.Millisecond)
+
+	status, err := st.Request(ctx, carol, http.MethodGet, "/chats/"+chatID+"/messages", nil, nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusForbidden, status, "history of a direct chat is readable by others")
+}
+
+func TestGroupChat_BlockedSenderIsNotDelivered(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	alice, bob, carol := st.NewUser(ctx), st.NewUser(ctx), st.NewUser(ctx)
+	chatID := st.CreateGroup(ctx, alice, bob, carol)
+
+	st.Do(ctx, bob, http.MethodPut, "/relations/blocks/"+alice.ID, nil, nil)
+
+	aliceConn := st.Connect(ctx, alice)
+	bobConn := st.Connect(ctx, bob)
+	carolConn := st.Connect(ctx, carol)
+
+	text := gofakeit.Sentence(5)
+	require.NoError(t, aliceConn.Send(chatID, text))
+
+	msg, err := carolConn.Receive(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, text, msg.MessageText)
+
+	expectNothing(t, bobConn)
+}
+
+func TestDirectChat_MutedSenderIsNotDeliveredButStored(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	alice, bob := st.NewUser(ctx), st.NewUser(ctx)
+	chatID := common.DirectChatID(alice.ID, bob.ID)
+
+	st.Do(ctx, bob, http.MethodPut, "/relations/mutes/"+alice.ID, nil, nil)
+
+	aliceConn := st.Connect(ctx, alice)
+	bobConn := st.Connect(ctx, bob)
+
+	text := gofakeit.Sentence(5)
+	require.NoError(t, aliceConn.Send(chatID, text))
+
+	// the sender gets their own message, the user who muted them doesn't
+	msg, err := aliceConn.Receive(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, text, msg.MessageText)
+
+	expectNothing(t, bobConn)
+
+	require.Eventually(t, func() bool {
+		return containsMessage(st.ChatHistory(ctx, bob, chatID), msg.MessageID, text)
+	}, historyWait, 50*time.Millisecond)
+}
+
+func TestGroupChat_LeftParticipantCanNotSend(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	alice, bob := st.NewUser(ctx), st.NewUser(ctx)
+	chatID := st.CreateGroup(ctx, alice, bob)
+
+	st.Do(ctx, bob, http.MethodPost, "/chats/"+chatID+"/leave", nil, nil)
+
+	status, err := st.Request(ctx, bob, http.MethodPost, "/chats/"+chatID+"/messages", map[string]string{
+		"message_text": gofakeit.Sentence(5),
+	}, nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusForbidden, status)
+}
+
+func containsMessage(messages []suite.Message, messageID string, text string) bool {
+	for _, msg := range messages {
+		if msg.MessageID == messageID && msg.MessageText == text {
+			return true
+		}
+	}
+
+	return false
+}
+
+func expectNothing(t *testing.T, conn *suite.Client) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), silence)
+	defer cancel()
+
+	msg, err := conn.Receive(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected no message, got %+v, err %v", msg, err)
+	}
+}
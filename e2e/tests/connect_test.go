@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/brianvoe/gofakeit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zoninnik89/messenger/common"
+	pb "github.com/zoninnik89/messenger/common/api"
+	"github.com/zoninnik89/messenger/e2e/tests/suite"
+)
+
+func TestConnect_MessageDeliveredToStream(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	alice, bob := st.NewUser(ctx), st.NewUser(ctx)
+	chatID := common.DirectChatID(alice.ID, bob.ID)
+
+	// bob streams over gRPC-Web like the browser does, alice sends over Connect
+	stream, err := connect.NewClient[pb.GetMessagesStreamRequest, pb.Message](
+		http.DefaultClient, st.URL+pb.ChatClientService_GetMessagesStream_FullMethodName, connect.WithGRPCWeb(),
+	).CallServerStream(ctx, authorized(&pb.GetMessagesStreamRequest{}, bob))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = stream.Close() })
+	require.NoError(t, st.PubSub.WaitSubscribed(ctx, bob.ID))
+
+	text := gofakeit.Sentence(5)
+	res, err := connect.NewClient[pb.SendMessageRequest, pb.SendMessageResponse](
+		http.DefaultClient, st.URL+pb.ChatClientService_SendMessage_FullMethodName,
+	).CallUnary(ctx, authorized(&pb.SendMessageRequest{
+		// the sender is the authenticated user, whoever the request claims
+		Message: &pb.Message{ChatId: chatID, SenderId: bob.ID, MessageText: text},
+	}, alice))
+	require.NoError(t, err)
+	messageID := res.Header().Get("Message-Id")
+	assert.NotEmpty(t, messageID)
+
+	require.True(t, stream.Receive(), "stream ended: %v", stream.Err())
+	msg := stream.Msg()
+	assert.Equal(t, chatID, msg.GetChatId())
+	assert.Equal(t, alice.ID, msg.GetSenderId())
+	assert.Equal(t, text, msg.GetMessageText())
+	assert.Equal(t, messageID, msg.GetMessageId())
+}
+
+func TestConnect_ManagesChats(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	alice, bob := st.NewUser(ctx), st.NewUser(ctx)
+
+	name := gofakeit.Word()
+	created, err := connect.NewClient[pb.CreateGroupRequest, pb.CreateGroupResponse](
+		http.DefaultClient, st.URL+pb.ChatHistoryService_CreateGroup_FullMethodName, connect.WithProtoJSON(),
+	).CallUnary(ctx, authorized(&pb.CreateGroupRequest{Name: name, ParticipantIds: []string{bob.ID}}, alice))
+	require.NoError(t, err)
+	assert.Equal(t, alice.ID, created.Msg.GetChat().GetOwnerId())
+
+	listChats := connect.NewClient[pb.ListChatsRequest, pb.ListChatsResponse](
+		http.DefaultClient, st.URL+pb.ChatHistoryService_ListChats_FullMethodName, connect.WithGRPCWeb(),
+	)
+	// the user ID of the request is ignored, so alice can't list chats of others
+	listed, err := listChats.CallUnary(ctx, authorized(&pb.ListChatsRequest{UserId: "someone-else"}, bob))
+	require.NoError(t, err)
+
+	var found bool
+	for _, chat := range listed.Msg.GetChats() {
+		if chat.GetChatId() == created.Msg.GetChat().GetChatId() {
+			found = true
+			assert.Equal(t, name, chat.GetName())
+		}
+	}
+	assert.True(t, found, "the group isn't listed for the participant")
+
+	_, err = listChats.CallUnary(ctx, connect.NewRequest(&pb.ListChatsRequest{}))
+	assert.Equal(t, connect.CodeUnauthenticated, connect.CodeOf(err))
+}
+
+// authorized returns the request with the auth token of the user.
+func authorized[T any](msg *T, user *suite.User) *connect.Request[T] {
+	req := connect.NewRequest(msg)
+	req.Header().Set("Authorization", "Bearer "+user.Token)
+
+	return req
+}
@@ -0,0 +1,266 @@
+package suite
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit"
+	chatclient "github.com/zoninnik89/messenger/chat-client/tests/server"
+	chathistory "github.com/zoninnik89/messenger/chat-history/tests/server"
+	"github.com/zoninnik89/messenger/common/bus/memory"
+	"github.com/zoninnik89/messenger/common/discovery/static"
+	facade "github.com/zoninnik89/messenger/facade-service/tests/server"
+	pubsub "github.com/zoninnik89/messenger/pub-sub/tests/server"
+	sso "github.com/zoninnik89/messenger/sso/tests/server"
+)
+
+const (
+	// partitions of topics of the in-memory bus
+	partitions = 3
+	// timeout bounds a whole test
+	timeout = 30 * time.Second
+	// password satisfies the password policy of the local sso config
+	password = "Passw0rd"
+	// statusError is the status of facade responses to failed requests
+	statusError = "Error"
+)
+
+// Suite runs sso, chat-history, pub-sub, chat-client and facade services in the test process. They find
+// each other in an in-process registry and exchange messages through an in-memory bus, sso stores users
+// in a SQLite database in a directory of the test. Every test gets its own services, spans of all services
+// are recorded, see Spans.
+type Suite struct {
+	*testing.T
+	// URL is the base URL of the facade HTTP API
+	URL string
+	Bus *memory.Broker
+	// History is chat-history service, tests may seed chats in its store
+	History *chathistory.Server
+	PubSub  *pubsub.Server
+
+	client *http.Client
+}
+
+// User is a registered user logged in through the facade.
+type User struct {
+	ID    string
+	Login string
+	Token string
+}
+
+// Message is a chat message as the facade sends it over websocket connections and returns it in chat history.
+type Message struct {
+	ChatID      string `json:"chat_id"`
+	MessageID   string `json:"message_id"`
+	SenderID    string `json:"sender_id"`
+	MessageText string `json:"message_text"`
+	SentTS      string `json:"sent_ts"`
+}
+
+func New(t *testing.T) (context.Context, *Suite) {
+	t.Helper()
+	t.Parallel()
+
+	ctx, cancelCtx := context.WithTimeout(context.Background(), timeout)
+	t.Cleanup(cancelCtx)
+
+	recordSpans()
+
+	registry := static.NewRegistry(nil)
+	broker := memory.NewBroker(partitions)
+
+	// services are started after those they call, and stopped in reverse order by cleanups of the test
+	ssoServer, err := sso.Start(registry, t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to start sso: %v", err)
+	}
+	t.Cleanup(ssoServer.Stop)
+
+	historyServer, err := chathistory.Start(registry, broker)
+	if err != nil {
+		t.Fatalf("failed to start chat-history: %v", err)
+	}
+	t.Cleanup(historyServer.Stop)
+
+	pubSubServer, err := pubsub.Start(registry, broker)
+	if err != nil {
+		t.Fatalf("failed to start pub-sub: %v", err)
+	}
+	t.Cleanup(pubSubServer.Stop)
+
+	chatClientServer, err := chatclient.Start(registry, broker)
+	if err != nil {
+		t.Fatalf("failed to start chat-client: %v", err)
+	}
+	t.Cleanup(chatClientServer.Stop)
+
+	facadeServer, err := facade.Start(registry)
+	if err != nil {
+		t.Fatalf("failed to start facade: %v", err)
+	}
+	t.Cleanup(facadeServer.Stop)
+
+	return ctx, &Suite{
+		T:       t,
+		URL:     facadeServer.URL,
+		Bus:     broker,
+		History: historyServer,
+		PubSub:  pubSubServer,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// NewUser registers a user with a random login and logs them in.
+func (s *Suite) NewUser(ctx context.Context) *User {
+	s.Helper()
+
+	login := gofakeit.Email()
+
+	var registered struct {
+		UserID string `json:"user_id"`
+	}
+	s.Do(ctx, nil, http.MethodPost, "/register", map[string]string{"login": login, "password": password}, &registered)
+
+	var loggedIn struct {
+		Token string `json:"auth_token"`
+	}
+	s.Do(ctx, nil, http.MethodPost, "/login", map[string]string{"login": login, "password": password}, &loggedIn)
+
+	return &User{ID: registered.UserID, Login: login, Token: loggedIn.Token}
+}
+
+// Connect opens a websocket connection of the user with a ticket, like the web client does, and waits
+// until messages of their chats are routed to it.
+func (s *Suite) Connect(ctx context.Context, user *User) *Client {
+	s.Helper()
+
+	client, err := Dial(ctx, s.WebsocketURL()+"?ticket="+url.QueryEscape(s.Ticket(ctx, user)), nil)
+	if err != nil {
+		s.Fatalf("failed to connect %s: %v", user.Login, err)
+	}
+	s.Cleanup(func() { _ = client.Close() })
+
+	if err := s.PubSub.WaitSubscribed(ctx, user.ID); err != nil {
+		s.Fatalf("%s isn't subscribed: %v", user.Login, err)
+	}
+
+	return client
+}
+
+// Ticket returns a new ticket for opening a websocket connection of the user.
+func (s *Suite) Ticket(ctx context.Context, user *User) string {
+	s.Helper()
+
+	var ticket struct {
+		Ticket string `json:"ticket"`
+	}
+	s.Do(ctx, user, http.MethodPost, "/ws/ticket", nil, &ticket)
+
+	return ticket.Ticket
+}
+
+// WebsocketURL returns the URL websocket connections are opened at.
+func (s *Suite) WebsocketURL() string {
+	return "ws" + strings.TrimPrefix(s.URL, "http") + "/ws"
+}
+
+// CreateGroup creates a group owned by the owner through the facade and returns its chat ID.
+func (s *Suite) CreateGroup(ctx context.Context, owner *User, participants ...*User) string {
+	s.Helper()
+
+	participantIDs := make([]string, 0, len(participants))
+	for _, participant := range participants {
+		participantIDs = append(participantIDs, participant.ID)
+	}
+
+	var group struct {
+		ChatID string `json:"chat_id"`
+	}
+	s.Do(ctx, owner, http.MethodPost, "/chats", map[string]any{
+		"name":            gofakeit.Word(),
+		"participant_ids": participantIDs,
+	}, &group)
+
+	return group.ChatID
+}
+
+// ChatHistory returns history of the chat as the user sees it.
+func (s *Suite) ChatHistory(ctx context.Context, user *User, chatID string) []Message {
+	s.Helper()
+
+	var history struct {
+		Messages []Message `json:"messages"`
+	}
+	s.Do(ctx, user, http.MethodGet, "/chats/"+chatID+"/messages", nil, &history)
+
+	return history.Messages
+}
+
+// Do sends a request to the facade on behalf of the user, or anonymously if user is nil, and decodes
+// the response into resp. The test fails unless the request succeeds.
+func (s *Suite) Do(ctx context.Context, user *User, method string, path string, body any, resp any) {
+	s.Helper()
+
+	status, err := s.Request(ctx, user, method, path, body, resp)
+	if err != nil {
+		s.Fatalf("%s %s failed: %v", method, path, err)
+	}
+	if status != http.StatusOK {
+		s.Fatalf("%s %s returned %d", method, path, status)
+	}
+}
+
+// Request is like Do, but returns the status of the response instead of failing the test. The error is
+// also set if the facade reports an error in the response body.
+func (s *Suite) Request(ctx context.Context, user *User, method string, path string, body any, resp any) (int, error) {
+	var reqBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reqBody).Encode(body); err != nil {
+			return 0, err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.URL+path, &reqBody)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if user != nil {
+		req.Header.Set("Authorization", "Bearer "+user.Token)
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return res.StatusCode, err
+	}
+
+	var status struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+	}
+	if err := json.Unmarshal(resBody, &status); err == nil && status.Status == statusError {
+		return res.StatusCode, fmt.Errorf("facade responded with error: %s", status.Error)
+	}
+
+	if resp != nil && res.StatusCode == http.StatusOK {
+		if err := json.Unmarshal(resBody, resp); err != nil {
+			return res.StatusCode, fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+
+	return res.StatusCode, nil
+}
@@ -0,0 +1,30 @@
+package suite
+
+import (
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+var (
+	recordOnce sync.Once
+	recorder   *tracetest.SpanRecorder
+)
+
+// recordSpans makes services of all tests record their spans in memory. Services share the tracer provider
+// of the process, so tests tell their spans apart by traces.
+func recordSpans() {
+	recordOnce.Do(func() {
+		recorder = tracetest.NewSpanRecorder()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	})
+}
+
+// Spans returns spans ended by services of all tests so far.
+func Spans() []sdktrace.ReadOnlySpan {
+	recordSpans()
+
+	return recorder.Ended()
+}
@@ -0,0 +1,108 @@
+package suite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// Client is a websocket connection to the facade, like the web client keeps. Pings of the facade are
+// answered by the connection while it reads, so the connection stays open as long as the test needs it.
+type Client struct {
+	conn *websocket.Conn
+	// writeMu serializes writes, the connection supports one concurrent writer
+	writeMu sync.Mutex
+
+	messages chan *Message
+	// err is the reason reading stopped, it is set before messages is closed
+	err error
+}
+
+// frame is a message of the facade, its type is empty for chat messages
+type frame struct {
+	Type string `json:"type"`
+	Message
+}
+
+// Dial opens a connection with the headers, which may carry the auth token.
+func Dial(ctx context.Context, url string, header http.Header) (*Client, error) {
+	conn, res, err := websocket.DefaultDialer.DialContext(ctx, url, header)
+	if err != nil {
+		if res != nil {
+			return nil, fmt.Errorf("%w: status %d", err, res.StatusCode)
+		}
+		return nil, err
+	}
+
+	c := &Client{
+		conn:     conn,
+		messages: make(chan *Message, 100),
+	}
+	go c.readLoop()
+
+	return c, nil
+}
+
+// Send sends the message to the chat.
+func (c *Client) Send(chatID string, text string) error {
+	return c.write(map[string]string{"type": "message", "chat_id": chatID, "message_text": text})
+}
+
+// Receive returns the next chat message sent to the connection.
+func (c *Client) Receive(ctx context.Context) (*Message, error) {
+	select {
+	case msg, ok := <-c.messages:
+		if !ok {
+			return nil, c.err
+		}
+		return msg, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Subprotocol returns the subprotocol selected by the facade.
+func (c *Client) Subprotocol() string {
+	return c.conn.Subprotocol()
+}
+
+// Close closes the connection without waiting for the facade to acknowledge it.
+func (c *Client) Close() error {
+	c.writeMu.Lock()
+	_ = c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	c.writeMu.Unlock()
+
+	return c.conn.Close()
+}
+
+func (c *Client) readLoop() {
+	defer close(c.messages)
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			c.err = err
+			return
+		}
+
+		// errors are sent as JSON strings rather than objects
+		var f frame
+		if err := json.Unmarshal(data, &f); err != nil {
+			c.err = fmt.Errorf("unexpected message %s", data)
+			return
+		}
+
+		c.messages <- &f.Message
+	}
+}
+
+func (c *Client) write(v any) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	return c.conn.WriteJSON(v)
+}
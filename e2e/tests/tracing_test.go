@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zoninnik89/messenger/common"
+	"github.com/zoninnik89/messenger/e2e/tests/suite"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing_MessageTracedFromWebsocketToDeliveryAndPersistence(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	alice, bob := st.NewUser(ctx), st.NewUser(ctx)
+	chatID := common.DirectChatID(alice.ID, bob.ID)
+
+	aliceConn := st.Connect(ctx, alice)
+	bobConn := st.Connect(ctx, bob)
+
+	text := gofakeit.Sentence(5)
+	require.NoError(t, aliceConn.Send(chatID, text))
+
+	msg, err := bobConn.Receive(ctx)
+	require.NoError(t, err)
+
+	// chat-history and pub-sub both process the message, each in its own span
+	var names []string
+	require.Eventually(t, func() bool {
+		names = spansOfMessage(suite.Spans(), msg.MessageID)
+		return count(names, "process messages") == 2
+	}, historyWait, 50*time.Millisecond, "message isn't processed by both consumers in its trace, spans: %v", names)
+
+	assert.Contains(t, names, "api.ChatClientService/SendMessage", "chat-client call isn't in the trace")
+	assert.Contains(t, names, "publish messages", "publishing isn't in the trace")
+}
+
+func TestTracing_MessageTracedFromRESTToDeliveryAndPersistence(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	alice, bob := st.NewUser(ctx), st.NewUser(ctx)
+	chatID := common.DirectChatID(alice.ID, bob.ID)
+	path := "/chats/" + chatID + "/messages"
+
+	bobConn := st.Connect(ctx, bob)
+
+	var sent struct {
+		MessageID string `json:"message_id"`
+	}
+	status, err := st.Request(ctx, alice, http.MethodPost, path, map[string]string{
+		"message_text": gofakeit.Sentence(5),
+	}, &sent)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, status)
+
+	msg, err := bobConn.Receive(ctx)
+	require.NoError(t, err)
+	require.Equal(t, sent.MessageID, msg.MessageID)
+
+	// the request, the chat-client call and both consumers are in one trace
+	var names []string
+	require.Eventually(t, func() bool {
+		names = spansOfTrace(suite.Spans(), func(span sdktrace.ReadOnlySpan) bool {
+			return span.SpanKind() == trace.SpanKindServer && hasAttribute(span, semconv.URLPath(path))
+		})
+		return count(names, "process messages") == 2
+	}, historyWait, 50*time.Millisecond, "message isn't processed by both consumers in the trace of the request, spans: %v", names)
+
+	assert.Contains(t, names, "POST /chats/{chatID}/messages", "request isn't in the trace")
+	assert.Contains(t, names, "api.ChatClientService/SendMessage", "chat-client call isn't in the trace")
+	assert.Contains(t, names, "publish messages", "publishing isn't in the trace")
+}
+
+// spansOfMessage returns names of spans in the trace of the message sent over websocket.
+func spansOfMessage(spans []sdktrace.ReadOnlySpan, messageID string) []string {
+	return spansOfTrace(spans, func(span sdktrace.ReadOnlySpan) bool {
+		return span.Name() == "websocket message" && hasAttribute(span, semconv.MessagingMessageID(messageID))
+	})
+}
+
+// spansOfTrace returns names of spans in the trace of the span matching the filter.
+func spansOfTrace(spans []sdktrace.ReadOnlySpan, match func(span sdktrace.ReadOnlySpan) bool) []string {
+	var traceID trace.TraceID
+	for _, span := range spans {
+		if match(span) {
+			traceID = span.SpanContext().TraceID()
+		}
+	}
+	if !traceID.IsValid() {
+		return nil
+	}
+
+	var names []string
+	for _, span := range spans {
+		if span.SpanContext().TraceID() == traceID {
+			names = append(names, span.Name())
+		}
+	}
+
+	return names
+}
+
+func count(names []string, name string) int {
+	n := 0
+	for _, got := range names {
+		if got == name {
+			n++
+		}
+	}
+
+	return n
+}
+
+func hasAttribute(span sdktrace.ReadOnlySpan, attr attribute.KeyValue) bool {
+	for _, got := range span.Attributes() {
+		if got == attr {
+			return true
+		}
+	}
+
+	return false
+}
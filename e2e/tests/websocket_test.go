@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/brianvoe/gofakeit"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zoninnik89/messenger/common"
+	pb "github.com/zoninnik89/messenger/common/api"
+	"github.com/zoninnik89/messenger/e2e/tests/suite"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestWebsocket_TicketIsUsedOnce(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	alice := st.NewUser(ctx)
+	wsURL := st.WebsocketURL() + "?ticket=" + url.QueryEscape(st.Ticket(ctx, alice))
+
+	conn, err := suite.Dial(ctx, wsURL, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	_, err = suite.Dial(ctx, wsURL, nil)
+	assert.Error(t, err, "ticket was accepted twice")
+}
+
+func TestWebsocket_TokenTransports(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	alice := st.NewUser(ctx)
+
+	tests := []struct {
+		name   string
+		header http.Header
+		// subprotocol is the one the facade should select
+		subprotocol string
+	}{
+		{
+			name:   "authorization header",
+			header: http.Header{"Authorization": {"Bearer " + alice.Token}},
+		},
+		{
+			name:        "bearer subprotocol",
+			header:      http.Header{"Sec-Websocket-Protocol": {"bearer, " + alice.Token}},
+			subprotocol: "bearer",
+		},
+		{
+			name:   "cookie",
+			header: http.Header{"Cookie": {"auth_token=" + alice.Token}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := suite.Dial(ctx, st.WebsocketURL(), tt.header)
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = conn.Close() })
+
+			assert.Equal(t, tt.subprotocol, conn.Subprotocol())
+		})
+	}
+
+	_, err := suite.Dial(ctx, st.WebsocketURL(), nil)
+	assert.Error(t, err, "connection without credentials was accepted")
+}
+
+func TestWebsocket_ProtobufSubprotocol(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	alice, bob := st.NewUser(ctx), st.NewUser(ctx)
+	chatID := common.DirectChatID(alice.ID, bob.ID)
+
+	aliceConn := st.Connect(ctx, alice)
+
+	dialer := websocket.Dialer{EnableCompression: true}
+	bobConn, res, err := dialer.DialContext(
+		ctx,
+		st.WebsocketURL()+"?ticket="+url.QueryEscape(st.Ticket(ctx, bob)),
+		http.Header{"Sec-Websocket-Protocol": {"messenger.protobuf"}},
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = bobConn.Close() })
+
+	assert.Equal(t, "messenger.protobuf", bobConn.Subprotocol())
+	assert.Contains(t, res.Header.Get("Sec-Websocket-Extensions"), "permessage-deflate")
+	require.NoError(t, st.PubSub.WaitSubscribed(ctx, bob.ID))
+
+	// the JSON client and the protobuf client talk to each other
+	text := gofakeit.Sentence(5)
+	require.NoError(t, aliceConn.Send(chatID, text))
+
+	// reads of the connection aren't bound to the context otherwise
+	deadline, _ := ctx.Deadline()
+	require.NoError(t, bobConn.SetReadDeadline(deadline))
+	messageType, data, err := bobConn.ReadMessage()
+	require.NoError(t, err)
+	require.Equal(t, websocket.BinaryMessage, messageType)
+
+	var msg pb.Message
+	require.NoError(t, proto.Unmarshal(data, &msg))
+	assert.Equal(t, chatID, msg.GetChatId())
+	assert.Equal(t, alice.ID, msg.GetSenderId())
+	assert.Equal(t, text, msg.GetMessageText())
+
+	reply := gofakeit.Sentence(5)
+	data, err = proto.Marshal(&pb.Message{ChatId: chatID, MessageText: reply})
+	require.NoError(t, err)
+	require.NoError(t, bobConn.WriteMessage(websocket.BinaryMessage, data))
+
+	// alice gets her own message back before the reply
+	for {
+		received, err := aliceConn.Receive(ctx)
+		require.NoError(t, err)
+
+		if received.SenderID == bob.ID {
+			assert.Equal(t, reply, received.MessageText)
+			break
+		}
+	}
+}
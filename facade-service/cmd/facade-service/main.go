@@ -2,22 +2,32 @@ package main
 
 import (
 	"context"
-	"fmt"
+	"crypto/tls"
+	"errors"
+	_ "expvar"
 	"net/http"
-	"time"
-
+	"os"
+	"os/signal"
 	"strconv"
+	"syscall"
+	"time"
 
-	"github.com/go-chi/chi"
-	"github.com/go-chi/chi/middleware"
-	"github.com/go-chi/cors"
+	"github.com/zoninnik89/messenger/common"
 	"github.com/zoninnik89/messenger/common/discovery"
-	"github.com/zoninnik89/messenger/common/discovery/consul"
+	"github.com/zoninnik89/messenger/common/discovery/backend"
+	"github.com/zoninnik89/messenger/common/telemetry"
+	"github.com/zoninnik89/messenger/facade-service/internal/audit"
 	"github.com/zoninnik89/messenger/facade-service/internal/config"
 	grpcgateway "github.com/zoninnik89/messenger/facade-service/internal/gateway"
-	"github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/auth/login"
-	"github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/auth/register"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/clientip"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/origin"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/router"
+	"github.com/zoninnik89/messenger/facade-service/internal/lib/certs"
+	"github.com/zoninnik89/messenger/facade-service/internal/lib/token"
 	"github.com/zoninnik89/messenger/facade-service/internal/logging"
+	"github.com/zoninnik89/messenger/facade-service/internal/ratelimit"
+	"github.com/zoninnik89/messenger/facade-service/internal/sessions"
+	"github.com/zoninnik89/messenger/facade-service/internal/tickets"
 	websocketserver "github.com/zoninnik89/messenger/facade-service/internal/websocket-server"
 	"go.uber.org/zap"
 )
@@ -31,9 +41,22 @@ func main() {
 
 	logger.Info("starting facade service", zap.String("port", strPort))
 
-	registry, err := consul.NewRegistry(cfg.HTTPServer.Address, cfg.Consul.Port)
+	shutdownTracing, err := telemetry.Init(context.Background(), cfg.HTTPServer.Name, cfg.Tracing)
+	if err != nil {
+		logger.Panic("failed to set up tracing", zap.Error(err))
+		panic(err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Warn("failed to flush spans", zap.Error(err))
+		}
+	}()
+
+	registry, err := backend.New(cfg.Discovery, cfg.HTTPServer.Address, cfg.Consul.Port)
 	if err != nil {
-		logger.Panic("failed to connect to Consul", zap.Error(err))
+		logger.Panic("failed to create service registry", zap.Error(err))
 		panic(err)
 	}
 
@@ -60,7 +83,7 @@ func main() {
 		}
 	}()
 
-	defer func(registry *consul.Registry, ctx context.Context, instanceID string) {
+	defer func(registry discovery.Registry, ctx context.Context, instanceID string) {
 		err := registry.Deregister(ctx, instanceID)
 		if err != nil {
 			logger.Fatal("failed to deregister service", zap.Error(err))
@@ -68,35 +91,142 @@ func main() {
 	}(registry, ctx, instanceID)
 
 	gateway := grpcgateway.NewGRPCGateway(registry)
+	defer func() {
+		if err := gateway.Close(); err != nil {
+			logger.Warn("failed to close gateway connections", zap.Error(err))
+		}
+	}()
+	tokens := token.NewParser(cfg.Auth.AppID, cfg.Auth.AppSecret)
+
+	auditRecorder, err := audit.New(cfg.Audit.Publisher, cfg.Audit.KafkaAddress, cfg.HTTPServer.Name)
+	if err != nil {
+		logger.Panic("failed to create audit recorder", zap.Error(err))
+		panic(err)
+	}
+
+	tracker := sessions.NewTracker(gateway, cfg.Sessions.CheckInterval)
 
-	router := chi.NewRouter()
-	router.Use(middleware.RequestID)
-	router.Use(middleware.RealIP)
-	router.Use(middleware.Logger)
-	router.Use(middleware.Recoverer)
-	router.Use(middleware.URLFormat)
-
-	// Basic CORS configuration
-	router.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"http://localhost:3001"},                   // Allow all origins
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}, // Allow specific methods
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "auth_token"},
-		ExposedHeaders:   []string{"Link"},
-		AllowCredentials: true, // Allow cookies to be sent
-		MaxAge:           300,  // Maximum value for the preflight request cache
-	}))
-
-	router.Post("/login", login.New(gateway))
-	router.Post("/register", register.New(gateway))
-
-	wsServer := websocketserver.NewWebsocketServer(gateway)
-
-	router.Get("/ws", wsServer.ServeHTTP)
-
-	// Start the server using http.Serve with the custom listener
-	logger.Info("http server is listening", zap.String("port", "3002"))
-	if err := http.ListenAndServe(fmt.Sprintf(":%s", "3002"), router); err != nil {
-		logger.Fatal("Failed to start server", zap.Error(err))
+	switch cfg.Sessions.Consumer {
+	case "kafka":
+		// every instance closes its own connections, so it consumes all revocations in its own group
+		consumer, err := sessions.NewConsumer(cfg.Sessions.KafkaAddress, instanceID)
+		if err != nil {
+			logger.Panic("failed to create account events consumer", zap.Error(err))
+			panic(err)
+		}
+
+		if err := consumer.SubscribeTopics([]string{common.AccountEventsTopic}, nil); err != nil {
+			logger.Panic("failed to subscribe to account events", zap.Error(err))
+			panic(err)
+		}
+
+		go tracker.ConsumeRevocations(ctx, consumer)
+	case "none":
+	default:
+		logger.Panic("unknown sessions consumer", zap.String("consumer", cfg.Sessions.Consumer))
+	}
+
+	limiter, err := ratelimit.New(cfg.RateLimit, cfg.Redis)
+	if err != nil {
+		logger.Panic("failed to create rate limiter", zap.Error(err))
+		panic(err)
+	}
+	defer func() {
+		if err := limiter.Close(); err != nil {
+			logger.Warn("failed to close rate limit store", zap.Error(err))
+		}
+	}()
+
+	wsTickets, err := tickets.New(cfg.Websocket, cfg.Redis)
+	if err != nil {
+		logger.Panic("failed to create websocket tickets", zap.Error(err))
+		panic(err)
+	}
+	defer func() {
+		if err := wsTickets.Close(); err != nil {
+			logger.Warn("failed to close websocket ticket store", zap.Error(err))
+		}
+	}()
+
+	wsServer := websocketserver.NewWebsocketServer(
+		gateway,
+		tokens,
+		tracker,
+		limiter,
+		origin.Allowlist(cfg.CORS.AllowedOrigins),
+		wsTickets,
+		cfg.Websocket,
+	)
+
+	trustedProxies, err := clientip.ParseProxies(cfg.HTTPServer.TrustedProxies)
+	if err != nil {
+		logger.Panic("invalid trusted proxies", zap.Error(err))
+		panic(err)
+	}
+
+	mux := router.New(cfg, gateway, tokens, tracker, auditRecorder, wsServer, limiter, wsTickets, trustedProxies)
+
+	// responses have no write timeout, websocket connections and message streams stay open for long
+	server := &http.Server{
+		Addr:              ":" + strPort,
+		Handler:           mux,
+		ReadHeaderTimeout: cfg.HTTPServer.Timeout,
+		ReadTimeout:       cfg.HTTPServer.Timeout,
+		IdleTimeout:       cfg.HTTPServer.IdleTimeout,
+	}
+
+	serve := server.ListenAndServe
+	if cfg.HTTPServer.TLS.Enabled() {
+		reloader, err := certs.NewReloader(cfg.HTTPServer.TLS.CertFile, cfg.HTTPServer.TLS.KeyFile)
+		if err != nil {
+			logger.Panic("failed to load TLS certificate", zap.Error(err))
+			panic(err)
+		}
+		go reloader.ReloadOnSIGHUP(ctx)
+
+		server.TLSConfig = &tls.Config{
+			GetCertificate: reloader.GetCertificate,
+			MinVersion:     tls.VersionTLS12,
+		}
+
+		// the certificate is taken from TLSConfig
+		serve = func() error { return server.ListenAndServeTLS("", "") }
+	}
+
+	if cfg.HTTPServer.DebugAddress != "" {
+		// expvar registers /debug/vars on the default mux, which serves nothing else
+		go func() {
+			logger.Info("debug server is listening", zap.String("address", cfg.HTTPServer.DebugAddress))
+			if err := http.ListenAndServe(cfg.HTTPServer.DebugAddress, http.DefaultServeMux); err != nil {
+				logger.Error("debug server failed", zap.Error(err))
+			}
+		}()
+	}
+
+	go func() {
+		logger.Info("server is listening", zap.String("port", strPort), zap.Bool("tls", cfg.HTTPServer.TLS.Enabled()))
+		if err := serve(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Fatal("Failed to start server", zap.Error(err))
+		}
+	}()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGTERM, syscall.SIGINT)
+
+	sig := <-stop
+
+	logger.Info("shutting down gracefully", zap.Any("signal", sig))
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, cfg.HTTPServer.ShutdownTimeout)
+	defer cancel()
+
+	// hijacked websocket connections aren't tracked by the http server, they are closed first
+	if err := wsServer.Shutdown(shutdownCtx); err != nil {
+		logger.Warn("websocket connections weren't closed in time", zap.Error(err))
+	}
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Warn("http requests weren't finished in time", zap.Error(err))
 	}
 
+	logger.Info("shut down gracefully")
 }
@@ -0,0 +1,122 @@
+// Package audit publishes security-relevant events of the facade to the audit log kept by sso service.
+package audit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+	"github.com/google/uuid"
+	"github.com/zoninnik89/messenger/common"
+	pb "github.com/zoninnik89/messenger/common/api"
+	"github.com/zoninnik89/messenger/facade-service/internal/logging"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+)
+
+// publishTimeout bounds publishing of a single event, which happens after the request is answered.
+const publishTimeout = 10 * time.Second
+
+type publisher interface {
+	publish(ctx context.Context, event *pb.AuditEvent) error
+}
+
+// Recorder publishes audit events in background, so that recording doesn't slow down or fail requests.
+type Recorder struct {
+	logger    *zap.SugaredLogger
+	publisher publisher
+	source    string
+}
+
+// New returns recorder which marks events with the given source and publishes them with
+// the given publisher, either "log" or "kafka".
+func New(publisherName string, kafkaAddress string, source string) (*Recorder, error) {
+	const op = "audit.New"
+
+	logger := logging.GetLogger().Sugar()
+
+	r := &Recorder{logger: logger, source: source}
+
+	switch publisherName {
+	case "log":
+		r.publisher = &logPublisher{logger: logger}
+	case "kafka":
+		producer, err := kafka.NewProducer(&kafka.ConfigMap{
+			"bootstrap.servers": kafkaAddress,
+			"acks":              "all",
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		r.publisher = &kafkaPublisher{producer: producer}
+	default:
+		return nil, fmt.Errorf("%s: unknown audit publisher: %s", op, publisherName)
+	}
+
+	return r, nil
+}
+
+// Record sets ID, source and time of the event and publishes it.
+func (r *Recorder) Record(ctx context.Context, event *pb.AuditEvent) {
+	const op = "audit.Record"
+
+	event.EventId = uuid.New().String()
+	event.Source = r.source
+	event.OccurredAt = time.Now().UnixMilli()
+
+	// publishing must not be cancelled together with the request which caused the event
+	go func(ctx context.Context) {
+		ctx, cancel := context.WithTimeout(ctx, publishTimeout)
+		defer cancel()
+
+		if err := r.publisher.publish(ctx, event); err != nil {
+			r.logger.Errorw("failed to publish audit event", "op", op, "eventID", event.GetEventId(), "error", err)
+		}
+	}(context.WithoutCancel(ctx))
+}
+
+// logPublisher only writes events to the log. Intended for local development.
+type logPublisher struct {
+	logger *zap.SugaredLogger
+}
+
+func (p *logPublisher) publish(_ context.Context, event *pb.AuditEvent) error {
+	p.logger.Infow("audit event", "event", event)
+	return nil
+}
+
+type kafkaPublisher struct {
+	producer *kafka.Producer
+}
+
+func (p *kafkaPublisher) publish(ctx context.Context, event *pb.AuditEvent) error {
+	value, err := proto.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	topic := common.AuditEventsTopic
+	delivery := make(chan kafka.Event, 1)
+
+	err = p.producer.Produce(&kafka.Message{
+		TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: kafka.PartitionAny},
+		Key:            []byte(event.GetUserId()),
+		Value:          value,
+	}, delivery)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case e := <-delivery:
+		if msg, ok := e.(*kafka.Message); ok && msg.TopicPartition.Error != nil {
+			return msg.TopicPartition.Error
+		}
+
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
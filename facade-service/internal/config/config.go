@@ -8,20 +8,134 @@ import (
 
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/zoninnik89/messenger/common"
+	"github.com/zoninnik89/messenger/common/discovery/backend"
+	"github.com/zoninnik89/messenger/common/telemetry"
 )
 
 type Config struct {
 	Env        string `yaml:"env" env:"ENV" env-default:"local"`
 	HTTPServer `yaml:"http_server"`
-	Consul     ConsulConfig `yaml:"consul"`
+	CORS       CORSConfig       `yaml:"cors"`
+	Consul     ConsulConfig     `yaml:"consul"`
+	Discovery  backend.Config   `yaml:"discovery"`
+	Auth       AuthConfig       `yaml:"auth"`
+	Audit      AuditConfig      `yaml:"audit"`
+	OIDC       OIDCConfig       `yaml:"oidc"`
+	Sessions   SessionsConfig   `yaml:"sessions"`
+	RateLimit  RateLimitConfig  `yaml:"rate_limit"`
+	Websocket  WebsocketConfig  `yaml:"websocket"`
+	Redis      RedisConfig      `yaml:"redis"`
+	Tracing    telemetry.Config `yaml:"tracing"`
+}
+
+// AuthConfig identifies the app registered in sso service which users log in to through the facade.
+type AuthConfig struct {
+	AppID     int    `yaml:"app_id" env:"APP_ID" env-default:"1"`
+	AppSecret string `yaml:"app_secret" env:"APP_SECRET" env-required:"true"`
+}
+
+// AuditConfig configures publishing of audit events, they are stored by sso service.
+type AuditConfig struct {
+	// Publisher is either "log" or "kafka"
+	Publisher    string `yaml:"publisher" env:"AUDIT_PUBLISHER" env-default:"log"`
+	KafkaAddress string `yaml:"kafka_address" env:"KAFKA_SERVER_ADDRESS" env-default:"localhost:9092"`
+}
+
+// OIDCConfig configures login via external identity providers, the providers are registered in sso service.
+type OIDCConfig struct {
+	// AfterLoginURL is the page of the web client users are redirected to once the provider redirects back
+	AfterLoginURL string `yaml:"after_login_url" env:"OIDC_AFTER_LOGIN_URL" env-default:"/"`
+}
+
+// SessionsConfig configures checking whether sso sessions of authenticated clients were revoked.
+type SessionsConfig struct {
+	// CheckInterval limits how often a session is checked in sso service, so it bounds time a revoked
+	// session stays usable on instances which didn't receive the revocation event
+	CheckInterval time.Duration `yaml:"check_interval" env:"SESSIONS_CHECK_INTERVAL" env-default:"30s"`
+	// Consumer of revocation events is either "none" or "kafka"
+	Consumer     string `yaml:"consumer" env:"SESSIONS_CONSUMER" env-default:"none"`
+	KafkaAddress string `yaml:"kafka_address" env:"KAFKA_SERVER_ADDRESS" env-default:"localhost:9092"`
+}
+
+// RateLimitConfig configures token buckets limiting how often clients may send messages and make requests.
+type RateLimitConfig struct {
+	// Store of the buckets is either "memory", which limits every instance on its own, or "redis",
+	// which shares the buckets between instances
+	Store string `yaml:"store" env:"RATE_LIMIT_STORE" env-default:"memory"`
+	// UserMessages and ChatMessages limit messages sent by a user and to a chat, over websocket and HTTP
+	UserMessages LimitConfig `yaml:"user_messages"`
+	ChatMessages LimitConfig `yaml:"chat_messages"`
+	// IPRequests limits HTTP requests and websocket messages of a client IP
+	IPRequests LimitConfig `yaml:"ip_requests"`
+	// IPLogins limits logins, registrations and password resets of a client IP
+	IPLogins LimitConfig `yaml:"ip_logins"`
+}
+
+// WebsocketConfig configures authentication of websocket connections.
+type WebsocketConfig struct {
+	// TicketTTL is how long a ticket for opening a connection may be used
+	TicketTTL time.Duration `yaml:"ticket_ttl" env:"WEBSOCKET_TICKET_TTL" env-default:"30s"`
+	// TicketStore is either "memory", so a ticket is only accepted by the instance which issued it,
+	// or "redis", which shares tickets between instances
+	TicketStore string `yaml:"ticket_store" env:"WEBSOCKET_TICKET_STORE" env-default:"memory"`
+	// PingInterval is how often clients are sent ping control frames
+	PingInterval time.Duration `yaml:"ping_interval" env:"WEBSOCKET_PING_INTERVAL" env-default:"15s"`
+	// PongWait is how long a connection stays open without receiving anything from the client, it should
+	// be longer than the ping interval, so a client answering pings isn't closed
+	PongWait time.Duration `yaml:"pong_wait" env:"WEBSOCKET_PONG_WAIT" env-default:"30s"`
+	// Compression enables permessage-deflate for clients which negotiate it
+	Compression bool `yaml:"compression" env:"WEBSOCKET_COMPRESSION" env-default:"true"`
+}
+
+// RedisConfig addresses Redis or a server compatible with it, which keeps state shared by instances.
+type RedisConfig struct {
+	Address  string `yaml:"address" env:"REDIS_ADDRESS" env-default:"localhost:6379"`
+	Password string `yaml:"password" env:"REDIS_PASSWORD"`
+	DB       int    `yaml:"db" env:"REDIS_DB" env-default:"0"`
+}
+
+// LimitConfig is a token bucket which holds up to Burst tokens and is refilled with Rate tokens every Period,
+// every message or request takes a token. Zero rate disables the limit.
+type LimitConfig struct {
+	Rate   int           `yaml:"rate"`
+	Period time.Duration `yaml:"period" env-default:"1s"`
+	Burst  int           `yaml:"burst"`
+}
+
+// CORSConfig configures which web clients may call the API and open websocket connections.
+type CORSConfig struct {
+	// AllowedOrigins are origins of the web clients, e.g. "https://messenger.example.com". "*" allows any
+	// origin, including with the auth cookie, so it is only meant for local development
+	AllowedOrigins []string `yaml:"allowed_origins" env:"CORS_ALLOWED_ORIGINS" env-separator:"," env-default:"http://localhost:3001"`
 }
 
 type HTTPServer struct {
-	Port        int           `yaml:"port" env:"PORT" env-default:"8080"`
-	Address     string        `yaml:"address" env:"ADDRESS" env-default:"localhost"`
+	Port    int    `yaml:"port" env:"PORT" env-default:"8080"`
+	Address string `yaml:"address" env:"ADDRESS" env-default:"localhost"`
+	// Timeout bounds reading a request, responses aren't bounded as messages are streamed over them
 	Timeout     time.Duration `yaml:"timeout" env:"TIMEOUT" env-default:"4s"`
-	IdleTimeout time.Duration `yaml:"idleTimeout" env-default:"10s"`
-	Name        string        `yaml:"name" env:"NAME" env-default:"facade"`
+	IdleTimeout time.Duration `yaml:"idle_timeout" env:"IDLE_TIMEOUT" env-default:"10s"`
+	// ShutdownTimeout bounds closing websocket connections and finishing requests on SIGTERM
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" env-default:"10s"`
+	// DebugAddress serves expvar metrics at /debug/vars, it must not be reachable publicly. Empty disables it.
+	DebugAddress string    `yaml:"debug_address" env:"DEBUG_ADDRESS"`
+	Name         string    `yaml:"name" env:"NAME" env-default:"facade"`
+	TLS          TLSConfig `yaml:"tls"`
+	// TrustedProxies are IPs or CIDR networks of proxies in front of the facade, client IPs are only taken
+	// from X-Forwarded-For and X-Real-IP headers of requests they forward. Empty trusts no proxy.
+	TrustedProxies []string `yaml:"trusted_proxies" env:"TRUSTED_PROXIES" env-separator:","`
+}
+
+// TLSConfig enables TLS termination by the facade, it is enabled when both files are set.
+// The files are read again on SIGHUP, so renewed certificates are used without a restart.
+type TLSConfig struct {
+	CertFile string `yaml:"cert_file" env:"TLS_CERT_FILE"`
+	KeyFile  string `yaml:"key_file" env:"TLS_KEY_FILE"`
+}
+
+// Enabled reports whether the facade serves TLS.
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != "" && c.KeyFile != ""
 }
 
 type ConsulConfig struct {
@@ -34,13 +148,17 @@ func MustLoad() *Config {
 		log.Fatal("CONFIG_PATH environment variable not set")
 	}
 
-	if _, err := os.Stat(config); os.IsNotExist(err) {
-		log.Fatal("CONFIG_PATH does not exist")
+	return MustLoadByPath(config)
+}
+
+func MustLoadByPath(configPath string) *Config {
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		log.Fatalf("config file does not exist: %s", configPath)
 	}
 
 	var cfg Config
 
-	if err := cleanenv.ReadConfig(config, &cfg); err != nil {
+	if err := cleanenv.ReadConfig(configPath, &cfg); err != nil {
 		log.Fatalf("can't read config file: %s", err)
 	}
 
@@ -0,0 +1,138 @@
+package connectserver
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/go-chi/chi/middleware"
+	"github.com/google/uuid"
+	pb "github.com/zoninnik89/messenger/common/api"
+	grpcgateway "github.com/zoninnik89/messenger/facade-service/internal/gateway"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/auth"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/clientip"
+	"github.com/zoninnik89/messenger/facade-service/internal/ratelimit"
+)
+
+var errNotValidMessage = errors.New("not valid message request")
+
+// SendMessage sends the message to its chat on behalf of the authenticated user. IDs of the message and
+// its sender, and the time it is sent, are set by the server.
+func (s *Server) SendMessage(
+	ctx context.Context,
+	req *connect.Request[pb.SendMessageRequest],
+) (*connect.Response[pb.SendMessageResponse], error) {
+	const op = "connectserver.SendMessage"
+	requestID := middleware.GetReqID(ctx)
+	senderID := auth.UserID(ctx)
+	chatID := req.Msg.GetMessage().GetChatId()
+
+	if chatID == "" {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("chat id is required"))
+	}
+
+	limit := s.limiter.Allow(
+		ctx,
+		ratelimit.Key{Scope: ratelimit.ScopeUser, ID: senderID},
+		ratelimit.Key{Scope: ratelimit.ScopeChat, ID: chatID},
+		ratelimit.Key{Scope: ratelimit.ScopeIP, ID: clientip.FromAddr(req.Peer().Addr)},
+	)
+	if !limit.Allowed {
+		s.logger.Infow("message rate limited", "op", op, "request_id", requestID, "userID", senderID, "chatID", chatID, "scope", limit.Scope)
+		return nil, rateLimitedError(limit)
+	}
+
+	message := &pb.Message{
+		MessageId:   uuid.New().String(),
+		ChatId:      chatID,
+		SenderId:    senderID,
+		MessageText: req.Msg.GetMessage().GetMessageText(),
+		SentTs:      strconv.FormatInt(time.Now().Unix(), 10),
+	}
+
+	sent, err := s.gw.SendMessage(ctx, &pb.SendMessageRequest{Message: message})
+	if err != nil {
+		s.logger.Infow("failed to send message", "op", op, "request_id", requestID, "messageID", message.GetMessageId(), "error", err)
+
+		switch {
+		case errors.Is(err, grpcgateway.ErrMessageRejected), errors.Is(err, grpcgateway.ErrInternalServerError):
+			return nil, connectError(err)
+		default:
+			return nil, connect.NewError(connect.CodeInvalidArgument, errNotValidMessage)
+		}
+	}
+
+	s.logger.Infow("message sent", "op", op, "request_id", requestID, "messageID", message.GetMessageId())
+
+	res := connect.NewResponse(sent)
+	// the response of chat-client has no ID, clients match the message they receive by this header
+	res.Header().Set("Message-Id", message.GetMessageId())
+
+	return res, nil
+}
+
+// GetMessagesStream streams messages of chats of the authenticated user until the client cancels the call
+// or the session is revoked.
+func (s *Server) GetMessagesStream(
+	ctx context.Context,
+	_ *connect.Request[pb.GetMessagesStreamRequest],
+	stream *connect.ServerStream[pb.Message],
+) error {
+	const op = "connectserver.GetMessagesStream"
+	requestID := middleware.GetReqID(ctx)
+	userID := auth.UserID(ctx)
+	sessionID := auth.SessionID(ctx)
+
+	// headers are sent with the first message otherwise, clients wait for them before the call returns
+	if err := stream.Send(nil); err != nil {
+		s.logger.Infow("failed to open messages stream", "op", op, "request_id", requestID, "error", err)
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	messages := make(chan *pb.Message)
+	streamErr := make(chan error, 1)
+
+	go func() {
+		streamErr <- s.gw.GetMessagesStream(ctx, &pb.GetMessagesStreamRequest{UserId: userID}, messages)
+	}()
+
+	// set to nil once the gateway closes it, the stream error follows
+	incoming := (<-chan *pb.Message)(messages)
+
+	ticker := time.NewTicker(sessionCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Infow("client closed messages stream", "op", op, "request_id", requestID)
+			return nil
+		case err := <-streamErr:
+			if err != nil {
+				s.logger.Errorw("failed to get message stream", "op", op, "request_id", requestID, "error", err)
+				return connectError(err)
+			}
+			return nil
+		case msg, ok := <-incoming:
+			if !ok {
+				incoming = nil
+				continue
+			}
+
+			if err := stream.Send(msg); err != nil {
+				s.logger.Infow("failed to send message to stream", "op", op, "request_id", requestID, "error", err)
+				return nil
+			}
+		case <-ticker.C:
+			if err := s.tracker.Check(ctx, sessionID); err != nil {
+				s.logger.Infow("session revoked, closing messages stream", "op", op, "request_id", requestID)
+				return connect.NewError(connect.CodeUnauthenticated, err)
+			}
+		}
+	}
+}
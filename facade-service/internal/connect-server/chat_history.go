@@ -0,0 +1,109 @@
+package connectserver
+
+import (
+	"context"
+
+	"connectrpc.com/connect"
+	"github.com/go-chi/chi/middleware"
+	pb "github.com/zoninnik89/messenger/common/api"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/auth"
+)
+
+// GetMessages returns history of the chat, if the authenticated user participates in it.
+func (s *Server) GetMessages(
+	ctx context.Context,
+	req *connect.Request[pb.GetMessagesRequest],
+) (*connect.Response[pb.GetMessagesResponse], error) {
+	req.Msg.UserId = auth.UserID(ctx)
+
+	return unary(ctx, "connectserver.GetMessages", req.Msg, s.gw.GetMessages, s)
+}
+
+// ListChats returns chats of the authenticated user.
+func (s *Server) ListChats(
+	ctx context.Context,
+	req *connect.Request[pb.ListChatsRequest],
+) (*connect.Response[pb.ListChatsResponse], error) {
+	req.Msg.UserId = auth.UserID(ctx)
+
+	return unary(ctx, "connectserver.ListChats", req.Msg, s.gw.ListChats, s)
+}
+
+// CreateGroup creates the group owned by the authenticated user.
+func (s *Server) CreateGroup(
+	ctx context.Context,
+	req *connect.Request[pb.CreateGroupRequest],
+) (*connect.Response[pb.CreateGroupResponse], error) {
+	req.Msg.UserId = auth.UserID(ctx)
+
+	return unary(ctx, "connectserver.CreateGroup", req.Msg, s.gw.CreateGroup, s)
+}
+
+// RenameChat renames the group on behalf of the authenticated user.
+func (s *Server) RenameChat(
+	ctx context.Context,
+	req *connect.Request[pb.RenameChatRequest],
+) (*connect.Response[pb.RenameChatResponse], error) {
+	req.Msg.UserId = auth.UserID(ctx)
+
+	return unary(ctx, "connectserver.RenameChat", req.Msg, s.gw.RenameChat, s)
+}
+
+// SetChatAvatar sets or removes the avatar of the group on behalf of the authenticated user.
+func (s *Server) SetChatAvatar(
+	ctx context.Context,
+	req *connect.Request[pb.SetChatAvatarRequest],
+) (*connect.Response[pb.SetChatAvatarResponse], error) {
+	req.Msg.UserId = auth.UserID(ctx)
+
+	return unary(ctx, "connectserver.SetChatAvatar", req.Msg, s.gw.SetChatAvatar, s)
+}
+
+// AddParticipants adds users to the group on behalf of the authenticated user.
+func (s *Server) AddParticipants(
+	ctx context.Context,
+	req *connect.Request[pb.AddParticipantsRequest],
+) (*connect.Response[pb.AddParticipantsResponse], error) {
+	req.Msg.UserId = auth.UserID(ctx)
+
+	return unary(ctx, "connectserver.AddParticipants", req.Msg, s.gw.AddParticipants, s)
+}
+
+// RemoveParticipant removes the participant from the group on behalf of the authenticated user.
+func (s *Server) RemoveParticipant(
+	ctx context.Context,
+	req *connect.Request[pb.RemoveParticipantRequest],
+) (*connect.Response[pb.RemoveParticipantResponse], error) {
+	req.Msg.UserId = auth.UserID(ctx)
+
+	return unary(ctx, "connectserver.RemoveParticipant", req.Msg, s.gw.RemoveParticipant, s)
+}
+
+// LeaveChat removes the authenticated user from the group.
+func (s *Server) LeaveChat(
+	ctx context.Context,
+	req *connect.Request[pb.LeaveChatRequest],
+) (*connect.Response[pb.LeaveChatResponse], error) {
+	req.Msg.UserId = auth.UserID(ctx)
+
+	return unary(ctx, "connectserver.LeaveChat", req.Msg, s.gw.LeaveChat, s)
+}
+
+// unary calls the gateway method of chat-history with the request and wraps its response.
+func unary[Req, Res any](
+	ctx context.Context,
+	op string,
+	req *Req,
+	call func(context.Context, *Req, string) (*Res, error),
+	s *Server,
+) (*connect.Response[Res], error) {
+	requestID := middleware.GetReqID(ctx)
+
+	res, err := call(ctx, req, requestID)
+	if err != nil {
+		s.logger.Infow("chat request failed", "op", op, "request_id", requestID, "error", err)
+		return nil, connectError(err)
+	}
+
+	return connect.NewResponse(res), nil
+}
@@ -0,0 +1,123 @@
+// Package connectserver serves chat services of messenger.proto to browser clients over Connect, gRPC-Web
+// and gRPC protocols, so the web client can use clients generated from the proto rather than hand-written
+// JSON. Procedures are authenticated by the auth middleware of the router and are called on behalf of the
+// authenticated user, whatever user IDs requests carry.
+package connectserver
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"connectrpc.com/connect"
+	pb "github.com/zoninnik89/messenger/common/api"
+	grpcgateway "github.com/zoninnik89/messenger/facade-service/internal/gateway"
+	"github.com/zoninnik89/messenger/facade-service/internal/lib/token"
+	"github.com/zoninnik89/messenger/facade-service/internal/logging"
+	"github.com/zoninnik89/messenger/facade-service/internal/ratelimit"
+	"github.com/zoninnik89/messenger/facade-service/internal/sessions"
+	"go.uber.org/zap"
+)
+
+// sessionCheckInterval is how often the session of a message stream is checked
+const sessionCheckInterval = 15 * time.Second
+
+var errInternal = errors.New("internal server error")
+
+// readProcedures only read chats and messages, other procedures send messages or change chats
+var readProcedures = map[string]bool{
+	pb.ChatClientService_GetMessagesStream_FullMethodName: true,
+	pb.ChatHistoryService_GetMessages_FullMethodName:      true,
+	pb.ChatHistoryService_ListChats_FullMethodName:        true,
+}
+
+// Server implements procedures of ChatClientService and ChatHistoryService with the gateway.
+type Server struct {
+	logger  *zap.SugaredLogger
+	gw      *grpcgateway.Gateway
+	tracker *sessions.Tracker
+	limiter *ratelimit.Limiter
+}
+
+// New returns the server. Messages sent are limited per user and chat by the limiter, message streams
+// end once their session is revoked in the tracker.
+func New(g *grpcgateway.Gateway, tracker *sessions.Tracker, limiter *ratelimit.Limiter) *Server {
+	return &Server{
+		logger:  logging.GetLogger().Sugar(),
+		gw:      g,
+		tracker: tracker,
+		limiter: limiter,
+	}
+}
+
+// Scope returns the token scope the procedure requires.
+func Scope(procedure string) string {
+	if readProcedures[procedure] {
+		return token.ScopeChatRead
+	}
+
+	return token.ScopeChatWrite
+}
+
+// Handlers returns handlers of the procedures by their paths, every procedure is called with POST.
+// The gRPC protocol needs HTTP/2, which is only served with TLS, Connect and gRPC-Web work over HTTP/1.1.
+func (s *Server) Handlers() map[string]http.Handler {
+	return map[string]http.Handler{
+		pb.ChatClientService_SendMessage_FullMethodName: connect.NewUnaryHandler(
+			pb.ChatClientService_SendMessage_FullMethodName, s.SendMessage,
+		),
+		pb.ChatClientService_GetMessagesStream_FullMethodName: connect.NewServerStreamHandler(
+			pb.ChatClientService_GetMessagesStream_FullMethodName, s.GetMessagesStream,
+		),
+		pb.ChatHistoryService_GetMessages_FullMethodName: connect.NewUnaryHandler(
+			pb.ChatHistoryService_GetMessages_FullMethodName, s.GetMessages,
+		),
+		pb.ChatHistoryService_ListChats_FullMethodName: connect.NewUnaryHandler(
+			pb.ChatHistoryService_ListChats_FullMethodName, s.ListChats,
+		),
+		pb.ChatHistoryService_CreateGroup_FullMethodName: connect.NewUnaryHandler(
+			pb.ChatHistoryService_CreateGroup_FullMethodName, s.CreateGroup,
+		),
+		pb.ChatHistoryService_RenameChat_FullMethodName: connect.NewUnaryHandler(
+			pb.ChatHistoryService_RenameChat_FullMethodName, s.RenameChat,
+		),
+		pb.ChatHistoryService_SetChatAvatar_FullMethodName: connect.NewUnaryHandler(
+			pb.ChatHistoryService_SetChatAvatar_FullMethodName, s.SetChatAvatar,
+		),
+		pb.ChatHistoryService_AddParticipants_FullMethodName: connect.NewUnaryHandler(
+			pb.ChatHistoryService_AddParticipants_FullMethodName, s.AddParticipants,
+		),
+		pb.ChatHistoryService_RemoveParticipant_FullMethodName: connect.NewUnaryHandler(
+			pb.ChatHistoryService_RemoveParticipant_FullMethodName, s.RemoveParticipant,
+		),
+		pb.ChatHistoryService_LeaveChat_FullMethodName: connect.NewUnaryHandler(
+			pb.ChatHistoryService_LeaveChat_FullMethodName, s.LeaveChat,
+		),
+	}
+}
+
+// connectError returns the error of the gateway with the code clients of the procedure get. Internal errors
+// are hidden from clients.
+func connectError(err error) *connect.Error {
+	switch {
+	case errors.Is(err, grpcgateway.ErrChatNotFound):
+		return connect.NewError(connect.CodeNotFound, err)
+	case errors.Is(err, grpcgateway.ErrChatForbidden), errors.Is(err, grpcgateway.ErrMessageRejected):
+		return connect.NewError(connect.CodePermissionDenied, err)
+	case errors.Is(err, grpcgateway.ErrInvalidChatRequest), errors.Is(err, grpcgateway.ErrUserIDIsMissing):
+		return connect.NewError(connect.CodeInvalidArgument, err)
+	default:
+		return connect.NewError(connect.CodeInternal, errInternal)
+	}
+}
+
+// rateLimitedError returns the error of a message rejected by the limiter, clients learn when to retry
+// from "Retry-After" metadata in seconds, as with HTTP responses.
+func rateLimitedError(res ratelimit.Result) *connect.Error {
+	err := connect.NewError(connect.CodeResourceExhausted, errors.New("rate limited"))
+	seconds := int64((res.RetryAfter + time.Second - 1) / time.Second)
+	err.Meta().Set("Retry-After", strconv.FormatInt(seconds, 10))
+
+	return err
+}
@@ -0,0 +1,98 @@
+package connectserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	pb "github.com/zoninnik89/messenger/common/api"
+	"github.com/zoninnik89/messenger/common/discovery/static"
+	grpcgateway "github.com/zoninnik89/messenger/facade-service/internal/gateway"
+	"github.com/zoninnik89/messenger/facade-service/internal/ratelimit"
+)
+
+// remoteAddrHeader sets remote address of requests to the test server
+const remoteAddrHeader = "X-Test-Remote-Addr"
+
+func TestConnectError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want connect.Code
+	}{
+		{err: grpcgateway.ErrChatNotFound, want: connect.CodeNotFound},
+		{err: fmt.Errorf("%w: not an owner", grpcgateway.ErrChatForbidden), want: connect.CodePermissionDenied},
+		{err: grpcgateway.ErrMessageRejected, want: connect.CodePermissionDenied},
+		{err: fmt.Errorf("%w: empty name", grpcgateway.ErrInvalidChatRequest), want: connect.CodeInvalidArgument},
+		{err: grpcgateway.ErrInternalServerError, want: connect.CodeInternal},
+		{err: errors.New("no chat-history instances"), want: connect.CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			got := connectError(tt.err)
+			if got.Code() != tt.want {
+				t.Errorf("code = %v, want %v", got.Code(), tt.want)
+			}
+			if tt.want == connect.CodeInternal && got.Message() != errInternal.Error() {
+				t.Errorf("internal error %q is exposed", got.Message())
+			}
+		})
+	}
+}
+
+func TestRateLimitedError(t *testing.T) {
+	err := rateLimitedError(ratelimit.Result{RetryAfter: 1500 * time.Millisecond, Scope: ratelimit.ScopeUser})
+
+	if err.Code() != connect.CodeResourceExhausted {
+		t.Errorf("code = %v, want %v", err.Code(), connect.CodeResourceExhausted)
+	}
+	if got := err.Meta().Get("Retry-After"); got != "2" {
+		t.Errorf("Retry-After = %q, want rounded up to 2", got)
+	}
+}
+
+func TestSendMessage_RateLimitedByIP(t *testing.T) {
+	g := grpcgateway.NewGRPCGateway(static.NewRegistry(nil))
+	// messages let through the limit fail to be sent, which doesn't matter to the limit
+	_ = g.Close()
+
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), map[ratelimit.Scope]ratelimit.Limit{
+		ratelimit.ScopeIP: {Rate: 1, Period: time.Minute, Burst: 1},
+	})
+	handler := New(g, nil, limiter).Handlers()[pb.ChatClientService_SendMessage_FullMethodName]
+
+	// the client IP is taken from the remote address, which the test sets by a header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.RemoteAddr = r.Header.Get(remoteAddrHeader)
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	client := connect.NewClient[pb.SendMessageRequest, pb.SendMessageResponse](
+		srv.Client(), srv.URL+pb.ChatClientService_SendMessage_FullMethodName,
+	)
+
+	send := func(addr string, chatID string) error {
+		req := connect.NewRequest(&pb.SendMessageRequest{Message: &pb.Message{ChatId: chatID, MessageText: "hello"}})
+		req.Header().Set(remoteAddrHeader, addr)
+
+		_, err := client.CallUnary(context.Background(), req)
+		return err
+	}
+
+	if err := send("203.0.113.7:51000", "chat"); connect.CodeOf(err) == connect.CodeResourceExhausted {
+		t.Fatalf("first message was rate limited")
+	}
+	// the bucket of the IP is shared by all chats and connections
+	if err := send("203.0.113.7:51001", "other"); connect.CodeOf(err) != connect.CodeResourceExhausted {
+		t.Errorf("expected message over the IP limit to be rate limited, got %v", err)
+	}
+	if err := send("198.51.100.1:51000", "chat"); connect.CodeOf(err) == connect.CodeResourceExhausted {
+		t.Errorf("message of another IP was rate limited")
+	}
+}
@@ -6,40 +6,79 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"strconv"
+	"time"
 
 	pb "github.com/zoninnik89/messenger/common/api"
 	"github.com/zoninnik89/messenger/common/discovery"
 	"github.com/zoninnik89/messenger/facade-service/internal/logging"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
 type Gateway struct {
-	registry discovery.Registry
-	logger   *zap.SugaredLogger
+	pool   *discovery.Pool
+	logger *zap.SugaredLogger
 }
 
+// NewGRPCGateway returns gateway to services discovered in the registry. Connections to the services are
+// created on first use and shared by all requests, Close closes them.
 func NewGRPCGateway(r discovery.Registry) *Gateway {
 	logger := logging.GetLogger().Sugar()
 	return &Gateway{
-		registry: r,
-		logger:   logger,
+		pool:   discovery.NewPool(r),
+		logger: logger,
 	}
 }
 
+// Close closes connections to the services.
+func (g *Gateway) Close() error {
+	return g.pool.Close()
+}
+
 var (
 	ErrInternalServerError = errors.New("internal server error")
 	// ErrOneOfFieldsMissing     = errors.New("one of the fields are missing")
 	ErrUserIDIsMissing        = errors.New("user ID is missing")
 	ErrInvalidLoginOrPassword = errors.New("invalid login or password")
+	ErrMessageRejected        = errors.New("message rejected: recipient has blocked the sender")
+	ErrInvalidRelation        = errors.New("invalid relation request")
+	ErrRelationNotFound       = errors.New("relation not found")
+	ErrEmailNotVerified       = errors.New("email is not verified")
+	ErrInvalidAccountRequest  = errors.New("invalid account request")
+	ErrTooManyAttempts        = errors.New("too many login attempts")
+	ErrInvalidMFARequest      = errors.New("invalid two-factor authentication request")
+	ErrInvalidOIDCRequest     = errors.New("invalid identity provider login request")
+	ErrOIDCLoginFailed        = errors.New("identity provider login failed")
+	ErrInvalidSessionRequest  = errors.New("invalid session request")
+	ErrSessionNotFound        = errors.New("session not found")
+	ErrInvalidChatRequest     = errors.New("invalid chat request")
+	ErrChatNotFound           = errors.New("chat not found")
+	ErrChatForbidden          = errors.New("chat action is not allowed")
 )
 
+// TooManyAttemptsError is returned by Login when SSO service throttles login attempts.
+type TooManyAttemptsError struct {
+	// RetryAfter is zero if SSO service didn't tell when to retry
+	RetryAfter time.Duration
+}
+
+func (e *TooManyAttemptsError) Error() string {
+	return ErrTooManyAttempts.Error()
+}
+
+func (e *TooManyAttemptsError) Unwrap() error {
+	return ErrTooManyAttempts
+}
+
 // SendMessage method establishes GRPC connection with Chat-client service and makes a request to send a message.
 func (g *Gateway) SendMessage(ctx context.Context, req *pb.SendMessageRequest) (*pb.SendMessageResponse, error) {
 	const op = "grpcgateway.SendMessage"
 	g.logger.Infow("starting connection with chat-client service")
-	conn, err := discovery.ServiceConnection(ctx, "chat-client", g.registry)
+	conn, err := g.pool.Conn("chat-client")
 	if err != nil {
 		g.logger.Errorw("error while connecting to chat-client", "op", op, "req", req, "error", err)
 		return nil, err
@@ -48,7 +87,7 @@ func (g *Gateway) SendMessage(ctx context.Context, req *pb.SendMessageRequest) (
 	g.logger.Infow("connected to chat-client")
 
 	client := pb.NewChatClientServiceClient(conn)
-	res, err := client.SendMessage(context.Background(), req)
+	res, err := client.SendMessage(ctx, req)
 
 	if err != nil {
 		st, ok := status.FromError(err)
@@ -57,6 +96,10 @@ func (g *Gateway) SendMessage(ctx context.Context, req *pb.SendMessageRequest) (
 				g.logger.Errorw("error while sending message", "op", op, "req", req, "error", err)
 				return nil, fmt.Errorf("%s: %s", op, st.Message())
 			}
+			if st.Code() == codes.PermissionDenied {
+				g.logger.Infow("message rejected", "op", op, "req", req, "error", err)
+				return nil, ErrMessageRejected
+			}
 		}
 		return nil, ErrInternalServerError
 	}
@@ -65,12 +108,15 @@ func (g *Gateway) SendMessage(ctx context.Context, req *pb.SendMessageRequest) (
 }
 
 // GetMessagesStream method establishes persistent GRPC connection with Chat-client service and gets a stream of messages
-// for all chats, where the given user is on participants.
+// for all chats, where the given user is on participants. The messages channel is closed once it returns, whether
+// the stream ended or couldn't be opened, so consumers may range over it.
 func (g *Gateway) GetMessagesStream(ctx context.Context, req *pb.GetMessagesStreamRequest, messages chan<- *pb.Message) error {
 	const op = "grpcgateway.GetMessagesStream"
+	defer close(messages)
+
 	g.logger.Infow("starting connection with chat-client service", "op", op)
 
-	conn, err := discovery.ServiceConnection(ctx, "chat-client", g.registry)
+	conn, err := g.pool.Conn("chat-client")
 	if err != nil {
 		g.logger.Errorw("error while connecting to chat-client", "op", op, "req", req, "error", err)
 		return err
@@ -94,12 +140,12 @@ func (g *Gateway) GetMessagesStream(ctx context.Context, req *pb.GetMessagesStre
 	defer func() {
 		log.Println("closing connection")
 		stream.CloseSend()
-		close(messages)
 	}()
 
 	// Create a channel to receive stream messages or errors
 	recvChan := make(chan *pb.Message)
-	errChan := make(chan error)
+	// buffered, as nobody receives the error once the context is cancelled
+	errChan := make(chan error, 1)
 
 	// Start a goroutine to receive messages from the stream
 	go func() {
@@ -113,7 +159,11 @@ func (g *Gateway) GetMessagesStream(ctx context.Context, req *pb.GetMessagesStre
 				errChan <- err
 				return
 			}
-			recvChan <- msg
+			select {
+			case recvChan <- msg:
+			case <-ctx.Done():
+				return
+			}
 		}
 	}()
 
@@ -128,7 +178,11 @@ func (g *Gateway) GetMessagesStream(ctx context.Context, req *pb.GetMessagesStre
 				return nil
 			}
 			// Send the received message to the messages channel
-			messages <- msg
+			select {
+			case messages <- msg:
+			case <-ctx.Done():
+				return nil
+			}
 		case err := <-errChan:
 			// Handle any error from stream.Recv()
 			g.logger.Errorw("error while receiving message", "op", op, "userID", req.GetUserId(), "error", err)
@@ -143,7 +197,7 @@ func (g *Gateway) Login(ctx context.Context, req *pb.LoginRequest, requestID str
 	const op = "grpcgateway.Login"
 	g.logger.Infow("starting connection with sso service")
 
-	conn, err := discovery.ServiceConnection(ctx, "sso-service", g.registry)
+	conn, err := g.pool.Conn("sso-service")
 	if err != nil {
 		g.logger.Errorw("error while connecting to sso service", "op", op, "requestID", requestID, "req", req, "error", err)
 		return nil, err
@@ -152,15 +206,25 @@ func (g *Gateway) Login(ctx context.Context, req *pb.LoginRequest, requestID str
 	g.logger.Infow("connected to sso service")
 
 	client := pb.NewAuthServiceClient(conn)
-	res, err := client.Login(context.Background(), req)
+
+	var header metadata.MD
+	res, err := client.Login(ctx, req, grpc.Header(&header))
 
 	if err != nil {
 		st, ok := status.FromError(err)
 		if ok {
+			if st.Code() == codes.ResourceExhausted {
+				g.logger.Infow("login attempt throttled", "op", op, "requestID", requestID, "error", err)
+				return nil, &TooManyAttemptsError{RetryAfter: retryAfter(header)}
+			}
 			if st.Code() == codes.InvalidArgument {
 				g.logger.Errorw("error while logging in", "op", op, "requestID", requestID, "req", req, "error", err)
 				return nil, ErrInvalidLoginOrPassword
 			}
+			if st.Code() == codes.FailedPrecondition {
+				g.logger.Infow("login of unverified user", "op", op, "requestID", requestID, "error", err)
+				return nil, ErrEmailNotVerified
+			}
 		}
 		return nil, ErrInvalidLoginOrPassword
 	}
@@ -173,7 +237,7 @@ func (g *Gateway) Register(ctx context.Context, req *pb.RegisterRequest, request
 	const op = "grpcgateway.Register"
 	g.logger.Infow("starting connection with sso service", "op", op, "requestID", requestID)
 
-	conn, err := discovery.ServiceConnection(ctx, "sso-service", g.registry)
+	conn, err := g.pool.Conn("sso-service")
 	if err != nil {
 		g.logger.Errorw("error while connecting to sso service", "op", op, "requestID", requestID, "error", err)
 		return nil, err
@@ -182,14 +246,14 @@ func (g *Gateway) Register(ctx context.Context, req *pb.RegisterRequest, request
 	g.logger.Infow("connected to sso service", "op", op, "requestID", requestID)
 
 	client := pb.NewAuthServiceClient(conn)
-	res, err := client.Register(context.Background(), req)
+	res, err := client.Register(ctx, req)
 
 	if err != nil {
 		st, ok := status.FromError(err)
 		if ok {
 			if st.Code() == codes.InvalidArgument {
 				g.logger.Errorw("error while registering", "op", op, "requestID", requestID, "req", req, "error", err)
-				return nil, ErrInvalidLoginOrPassword
+				return nil, fmt.Errorf("%w: %s", ErrInvalidLoginOrPassword, st.Message())
 			}
 		}
 		return nil, ErrInternalServerError
@@ -197,3 +261,753 @@ func (g *Gateway) Register(ctx context.Context, req *pb.RegisterRequest, request
 
 	return res, err
 }
+
+// VerifyEmail method establishes GRPC connection with SSO service and makes a request to verify user's email.
+func (g *Gateway) VerifyEmail(ctx context.Context, req *pb.VerifyEmailRequest, requestID string) (*pb.VerifyEmailResponse, error) {
+	const op = "grpcgateway.VerifyEmail"
+	g.logger.Infow("starting connection with sso service", "op", op, "requestID", requestID)
+
+	conn, err := g.pool.Conn("sso-service")
+	if err != nil {
+		g.logger.Errorw("error while connecting to sso service", "op", op, "requestID", requestID, "error", err)
+		return nil, err
+	}
+
+	client := pb.NewAuthServiceClient(conn)
+	res, err := client.VerifyEmail(ctx, req)
+
+	if err != nil {
+		g.logger.Errorw("error while verifying email", "op", op, "requestID", requestID, "error", err)
+		return nil, accountError(err)
+	}
+
+	return res, nil
+}
+
+// ResendVerificationEmail method establishes GRPC connection with SSO service and makes a request to send
+// a new verification email.
+func (g *Gateway) ResendVerificationEmail(
+	ctx context.Context,
+	req *pb.ResendVerificationEmailRequest,
+	requestID string,
+) (*pb.ResendVerificationEmailResponse, error) {
+	const op = "grpcgateway.ResendVerificationEmail"
+	g.logger.Infow("starting connection with sso service", "op", op, "requestID", requestID)
+
+	conn, err := g.pool.Conn("sso-service")
+	if err != nil {
+		g.logger.Errorw("error while connecting to sso service", "op", op, "requestID", requestID, "error", err)
+		return nil, err
+	}
+
+	client := pb.NewAuthServiceClient(conn)
+	res, err := client.ResendVerificationEmail(ctx, req)
+
+	if err != nil {
+		g.logger.Errorw("error while resending verification email", "op", op, "requestID", requestID, "error", err)
+		return nil, accountError(err)
+	}
+
+	return res, nil
+}
+
+// RequestPasswordReset method establishes GRPC connection with SSO service and makes a request to send
+// a password reset email.
+func (g *Gateway) RequestPasswordReset(
+	ctx context.Context,
+	req *pb.RequestPasswordResetRequest,
+	requestID string,
+) (*pb.RequestPasswordResetResponse, error) {
+	const op = "grpcgateway.RequestPasswordReset"
+	g.logger.Infow("starting connection with sso service", "op", op, "requestID", requestID)
+
+	conn, err := g.pool.Conn("sso-service")
+	if err != nil {
+		g.logger.Errorw("error while connecting to sso service", "op", op, "requestID", requestID, "error", err)
+		return nil, err
+	}
+
+	client := pb.NewAuthServiceClient(conn)
+	res, err := client.RequestPasswordReset(ctx, req)
+
+	if err != nil {
+		g.logger.Errorw("error while requesting password reset", "op", op, "requestID", requestID, "error", err)
+		return nil, accountError(err)
+	}
+
+	return res, nil
+}
+
+// ResetPassword method establishes GRPC connection with SSO service and makes a request to set a new password
+// using password reset token.
+func (g *Gateway) ResetPassword(ctx context.Context, req *pb.ResetPasswordRequest, requestID string) (*pb.ResetPasswordResponse, error) {
+	const op = "grpcgateway.ResetPassword"
+	g.logger.Infow("starting connection with sso service", "op", op, "requestID", requestID)
+
+	conn, err := g.pool.Conn("sso-service")
+	if err != nil {
+		g.logger.Errorw("error while connecting to sso service", "op", op, "requestID", requestID, "error", err)
+		return nil, err
+	}
+
+	client := pb.NewAuthServiceClient(conn)
+	res, err := client.ResetPassword(ctx, req)
+
+	if err != nil {
+		g.logger.Errorw("error while resetting password", "op", op, "requestID", requestID, "error", err)
+		return nil, accountError(err)
+	}
+
+	return res, nil
+}
+
+// VerifyMFA method establishes GRPC connection with SSO service and makes a request to complete login with the second factor.
+func (g *Gateway) VerifyMFA(ctx context.Context, req *pb.VerifyMFARequest, requestID string) (*pb.VerifyMFAResponse, error) {
+	const op = "grpcgateway.VerifyMFA"
+	g.logger.Infow("starting connection with sso service", "op", op, "requestID", requestID)
+
+	conn, err := g.pool.Conn("sso-service")
+	if err != nil {
+		g.logger.Errorw("error while connecting to sso service", "op", op, "requestID", requestID, "error", err)
+		return nil, err
+	}
+
+	client := pb.NewAuthServiceClient(conn)
+
+	var header metadata.MD
+	res, err := client.VerifyMFA(ctx, req, grpc.Header(&header))
+
+	if err != nil {
+		g.logger.Errorw("error while verifying second factor", "op", op, "requestID", requestID, "error", err)
+		return nil, mfaError(err, header)
+	}
+
+	return res, nil
+}
+
+// EnrollTOTP method establishes GRPC connection with SSO service and makes a request to generate TOTP secret for a user.
+func (g *Gateway) EnrollTOTP(ctx context.Context, req *pb.EnrollTOTPRequest, requestID string) (*pb.EnrollTOTPResponse, error) {
+	const op = "grpcgateway.EnrollTOTP"
+	g.logger.Infow("starting connection with sso service", "op", op, "requestID", requestID)
+
+	conn, err := g.pool.Conn("sso-service")
+	if err != nil {
+		g.logger.Errorw("error while connecting to sso service", "op", op, "requestID", requestID, "error", err)
+		return nil, err
+	}
+
+	client := pb.NewAuthServiceClient(conn)
+
+	var header metadata.MD
+	res, err := client.EnrollTOTP(ctx, req, grpc.Header(&header))
+
+	if err != nil {
+		g.logger.Errorw("error while enrolling totp", "op", op, "requestID", requestID, "error", err)
+		return nil, mfaError(err, header)
+	}
+
+	return res, nil
+}
+
+// ConfirmTOTP method establishes GRPC connection with SSO service and makes a request to enable two-factor authentication of a user.
+func (g *Gateway) ConfirmTOTP(ctx context.Context, req *pb.ConfirmTOTPRequest, requestID string) (*pb.ConfirmTOTPResponse, error) {
+	const op = "grpcgateway.ConfirmTOTP"
+	g.logger.Infow("starting connection with sso service", "op", op, "requestID", requestID)
+
+	conn, err := g.pool.Conn("sso-service")
+	if err != nil {
+		g.logger.Errorw("error while connecting to sso service", "op", op, "requestID", requestID, "error", err)
+		return nil, err
+	}
+
+	client := pb.NewAuthServiceClient(conn)
+
+	var header metadata.MD
+	res, err := client.ConfirmTOTP(ctx, req, grpc.Header(&header))
+
+	if err != nil {
+		g.logger.Errorw("error while confirming totp", "op", op, "requestID", requestID, "error", err)
+		return nil, mfaError(err, header)
+	}
+
+	return res, nil
+}
+
+// DisableTOTP method establishes GRPC connection with SSO service and makes a request to disable two-factor authentication of a user.
+func (g *Gateway) DisableTOTP(ctx context.Context, req *pb.DisableTOTPRequest, requestID string) (*pb.DisableTOTPResponse, error) {
+	const op = "grpcgateway.DisableTOTP"
+	g.logger.Infow("starting connection with sso service", "op", op, "requestID", requestID)
+
+	conn, err := g.pool.Conn("sso-service")
+	if err != nil {
+		g.logger.Errorw("error while connecting to sso service", "op", op, "requestID", requestID, "error", err)
+		return nil, err
+	}
+
+	client := pb.NewAuthServiceClient(conn)
+
+	var header metadata.MD
+	res, err := client.DisableTOTP(ctx, req, grpc.Header(&header))
+
+	if err != nil {
+		g.logger.Errorw("error while disabling totp", "op", op, "requestID", requestID, "error", err)
+		return nil, mfaError(err, header)
+	}
+
+	return res, nil
+}
+
+// AddRelation method establishes GRPC connection with SSO service and makes a request to block or mute a user.
+func (g *Gateway) AddRelation(ctx context.Context, req *pb.AddRelationRequest, requestID string) (*pb.AddRelationResponse, error) {
+	const op = "grpcgateway.AddRelation"
+	g.logger.Infow("starting connection with sso service", "op", op, "requestID", requestID)
+
+	conn, err := g.pool.Conn("sso-service")
+	if err != nil {
+		g.logger.Errorw("error while connecting to sso service", "op", op, "requestID", requestID, "error", err)
+		return nil, err
+	}
+
+	client := pb.NewRelationsServiceClient(conn)
+	res, err := client.AddRelation(ctx, req)
+
+	if err != nil {
+		g.logger.Errorw("error while adding relation", "op", op, "requestID", requestID, "req", req, "error", err)
+		return nil, relationError(err)
+	}
+
+	return res, nil
+}
+
+// RemoveRelation method establishes GRPC connection with SSO service and makes a request to unblock or unmute a user.
+func (g *Gateway) RemoveRelation(ctx context.Context, req *pb.RemoveRelationRequest, requestID string) (*pb.RemoveRelationResponse, error) {
+	const op = "grpcgateway.RemoveRelation"
+	g.logger.Infow("starting connection with sso service", "op", op, "requestID", requestID)
+
+	conn, err := g.pool.Conn("sso-service")
+	if err != nil {
+		g.logger.Errorw("error while connecting to sso service", "op", op, "requestID", requestID, "error", err)
+		return nil, err
+	}
+
+	client := pb.NewRelationsServiceClient(conn)
+	res, err := client.RemoveRelation(ctx, req)
+
+	if err != nil {
+		g.logger.Errorw("error while removing relation", "op", op, "requestID", requestID, "req", req, "error", err)
+		return nil, relationError(err)
+	}
+
+	return res, nil
+}
+
+// ListRelations method establishes GRPC connection with SSO service and requests users blocked or muted by a user.
+func (g *Gateway) ListRelations(ctx context.Context, req *pb.ListRelationsRequest, requestID string) (*pb.ListRelationsResponse, error) {
+	const op = "grpcgateway.ListRelations"
+	g.logger.Infow("starting connection with sso service", "op", op, "requestID", requestID)
+
+	conn, err := g.pool.Conn("sso-service")
+	if err != nil {
+		g.logger.Errorw("error while connecting to sso service", "op", op, "requestID", requestID, "error", err)
+		return nil, err
+	}
+
+	client := pb.NewRelationsServiceClient(conn)
+	res, err := client.ListRelations(ctx, req)
+
+	if err != nil {
+		g.logger.Errorw("error while listing relations", "op", op, "requestID", requestID, "req", req, "error", err)
+		return nil, relationError(err)
+	}
+
+	return res, nil
+}
+
+// DeleteAccount method establishes GRPC connection with SSO service and makes a request to delete a user
+// after checking their password.
+func (g *Gateway) DeleteAccount(ctx context.Context, req *pb.DeleteAccountRequest, requestID string) (*pb.DeleteAccountResponse, error) {
+	const op = "grpcgateway.DeleteAccount"
+	g.logger.Infow("starting connection with sso service", "op", op, "requestID", requestID)
+
+	conn, err := g.pool.Conn("sso-service")
+	if err != nil {
+		g.logger.Errorw("error while connecting to sso service", "op", op, "requestID", requestID, "error", err)
+		return nil, err
+	}
+
+	client := pb.NewAuthServiceClient(conn)
+
+	var header metadata.MD
+	res, err := client.DeleteAccount(ctx, req, grpc.Header(&header))
+
+	if err != nil {
+		g.logger.Errorw("error while deleting account", "op", op, "requestID", requestID, "error", err)
+		if status.Code(err) == codes.ResourceExhausted {
+			return nil, &TooManyAttemptsError{RetryAfter: retryAfter(header)}
+		}
+		return nil, accountError(err)
+	}
+
+	return res, nil
+}
+
+// ExportAccountData method establishes GRPC connection with SSO service and requests profile of a user.
+func (g *Gateway) ExportAccountData(
+	ctx context.Context,
+	req *pb.ExportAccountDataRequest,
+	requestID string,
+) (*pb.ExportAccountDataResponse, error) {
+	const op = "grpcgateway.ExportAccountData"
+	g.logger.Infow("starting connection with sso service", "op", op, "requestID", requestID)
+
+	conn, err := g.pool.Conn("sso-service")
+	if err != nil {
+		g.logger.Errorw("error while connecting to sso service", "op", op, "requestID", requestID, "error", err)
+		return nil, err
+	}
+
+	client := pb.NewAuthServiceClient(conn)
+	res, err := client.ExportAccountData(ctx, req)
+
+	if err != nil {
+		g.logger.Errorw("error while exporting account data", "op", op, "requestID", requestID, "error", err)
+		return nil, accountError(err)
+	}
+
+	return res, nil
+}
+
+// StartOIDCLogin method establishes GRPC connection with SSO service and requests URL of the external
+// identity provider the user is redirected to.
+func (g *Gateway) StartOIDCLogin(
+	ctx context.Context,
+	req *pb.StartOIDCLoginRequest,
+	requestID string,
+) (*pb.StartOIDCLoginResponse, error) {
+	const op = "grpcgateway.StartOIDCLogin"
+	g.logger.Infow("starting connection with sso service", "op", op, "requestID", requestID)
+
+	conn, err := g.pool.Conn("sso-service")
+	if err != nil {
+		g.logger.Errorw("error while connecting to sso service", "op", op, "requestID", requestID, "error", err)
+		return nil, err
+	}
+
+	client := pb.NewAuthServiceClient(conn)
+	res, err := client.StartOIDCLogin(ctx, req)
+
+	if err != nil {
+		g.logger.Errorw("error while starting identity provider login", "op", op, "requestID", requestID, "error", err)
+		return nil, oidcError(err)
+	}
+
+	return res, nil
+}
+
+// CompleteOIDCLogin method establishes GRPC connection with SSO service and logs the user in
+// with the authorization code the identity provider redirected back with.
+func (g *Gateway) CompleteOIDCLogin(
+	ctx context.Context,
+	req *pb.CompleteOIDCLoginRequest,
+	requestID string,
+) (*pb.CompleteOIDCLoginResponse, error) {
+	const op = "grpcgateway.CompleteOIDCLogin"
+	g.logger.Infow("starting connection with sso service", "op", op, "requestID", requestID)
+
+	conn, err := g.pool.Conn("sso-service")
+	if err != nil {
+		g.logger.Errorw("error while connecting to sso service", "op", op, "requestID", requestID, "error", err)
+		return nil, err
+	}
+
+	client := pb.NewAuthServiceClient(conn)
+	res, err := client.CompleteOIDCLogin(ctx, req)
+
+	if err != nil {
+		g.logger.Errorw("error while completing identity provider login", "op", op, "requestID", requestID, "error", err)
+		return nil, oidcError(err)
+	}
+
+	return res, nil
+}
+
+// GetUserMessages method establishes GRPC connection with Chat-history service and requests all messages sent by a user.
+func (g *Gateway) ListSessions(ctx context.Context, req *pb.ListSessionsRequest, requestID string) (*pb.ListSessionsResponse, error) {
+	const op = "grpcgateway.ListSessions"
+	g.logger.Infow("starting connection with sso service", "op", op, "requestID", requestID)
+
+	conn, err := g.pool.Conn("sso-service")
+	if err != nil {
+		g.logger.Errorw("error while connecting to sso service", "op", op, "requestID", requestID, "error", err)
+		return nil, err
+	}
+
+	client := pb.NewAuthServiceClient(conn)
+	res, err := client.ListSessions(ctx, req)
+
+	if err != nil {
+		g.logger.Errorw("error while listing sessions", "op", op, "requestID", requestID, "error", err)
+		return nil, sessionError(err)
+	}
+
+	return res, nil
+}
+
+func (g *Gateway) RevokeSession(ctx context.Context, req *pb.RevokeSessionRequest, requestID string) (*pb.RevokeSessionResponse, error) {
+	const op = "grpcgateway.RevokeSession"
+	g.logger.Infow("starting connection with sso service", "op", op, "requestID", requestID)
+
+	conn, err := g.pool.Conn("sso-service")
+	if err != nil {
+		g.logger.Errorw("error while connecting to sso service", "op", op, "requestID", requestID, "error", err)
+		return nil, err
+	}
+
+	client := pb.NewAuthServiceClient(conn)
+	res, err := client.RevokeSession(ctx, req)
+
+	if err != nil {
+		g.logger.Errorw("error while revoking session", "op", op, "requestID", requestID, "error", err)
+		return nil, sessionError(err)
+	}
+
+	return res, nil
+}
+
+func (g *Gateway) TouchSession(ctx context.Context, req *pb.TouchSessionRequest, requestID string) (*pb.TouchSessionResponse, error) {
+	const op = "grpcgateway.TouchSession"
+	g.logger.Infow("starting connection with sso service", "op", op, "requestID", requestID)
+
+	conn, err := g.pool.Conn("sso-service")
+	if err != nil {
+		g.logger.Errorw("error while connecting to sso service", "op", op, "requestID", requestID, "error", err)
+		return nil, err
+	}
+
+	client := pb.NewAuthServiceClient(conn)
+	res, err := client.TouchSession(ctx, req)
+
+	if err != nil {
+		g.logger.Infow("error while touching session", "op", op, "requestID", requestID, "error", err)
+		return nil, sessionError(err)
+	}
+
+	return res, nil
+}
+
+func (g *Gateway) GetUserMessages(ctx context.Context, req *pb.GetUserMessagesRequest, requestID string) (*pb.GetUserMessagesResponse, error) {
+	const op = "grpcgateway.GetUserMessages"
+	g.logger.Infow("starting connection with chat-history service", "op", op, "requestID", requestID)
+
+	conn, err := g.pool.Conn("chat-history")
+	if err != nil {
+		g.logger.Errorw("error while connecting to chat-history service", "op", op, "requestID", requestID, "error", err)
+		return nil, err
+	}
+
+	client := pb.NewChatHistoryServiceClient(conn)
+	res, err := client.GetUserMessages(ctx, req)
+
+	if err != nil {
+		g.logger.Errorw("error while getting user messages", "op", op, "requestID", requestID, "error", err)
+		return nil, ErrInternalServerError
+	}
+
+	return res, nil
+}
+
+func (g *Gateway) GetMessages(
+	ctx context.Context,
+	req *pb.GetMessagesRequest,
+	requestID string,
+) (*pb.GetMessagesResponse, error) {
+	const op = "grpcgateway.GetMessages"
+	g.logger.Infow("starting connection with chat-history service", "op", op, "requestID", requestID)
+
+	conn, err := g.pool.Conn("chat-history")
+	if err != nil {
+		g.logger.Errorw("error while connecting to chat-history service", "op", op, "requestID", requestID, "error", err)
+		return nil, err
+	}
+
+	client := pb.NewChatHistoryServiceClient(conn)
+	res, err := client.GetMessages(ctx, req)
+
+	if err != nil {
+		g.logger.Errorw("error while getting messages", "op", op, "requestID", requestID, "req", req, "error", err)
+		return nil, chatError(err)
+	}
+
+	return res, nil
+}
+
+func (g *Gateway) ListChats(
+	ctx context.Context,
+	req *pb.ListChatsRequest,
+	requestID string,
+) (*pb.ListChatsResponse, error) {
+	const op = "grpcgateway.ListChats"
+	g.logger.Infow("starting connection with chat-history service", "op", op, "requestID", requestID)
+
+	conn, err := g.pool.Conn("chat-history")
+	if err != nil {
+		g.logger.Errorw("error while connecting to chat-history service", "op", op, "requestID", requestID, "error", err)
+		return nil, err
+	}
+
+	client := pb.NewChatHistoryServiceClient(conn)
+	res, err := client.ListChats(ctx, req)
+
+	if err != nil {
+		g.logger.Errorw("error while listing chats", "op", op, "requestID", requestID, "req", req, "error", err)
+		return nil, chatError(err)
+	}
+
+	return res, nil
+}
+
+func (g *Gateway) CreateGroup(
+	ctx context.Context,
+	req *pb.CreateGroupRequest,
+	requestID string,
+) (*pb.CreateGroupResponse, error) {
+	const op = "grpcgateway.CreateGroup"
+	g.logger.Infow("starting connection with chat-history service", "op", op, "requestID", requestID)
+
+	conn, err := g.pool.Conn("chat-history")
+	if err != nil {
+		g.logger.Errorw("error while connecting to chat-history service", "op", op, "requestID", requestID, "error", err)
+		return nil, err
+	}
+
+	client := pb.NewChatHistoryServiceClient(conn)
+	res, err := client.CreateGroup(ctx, req)
+
+	if err != nil {
+		g.logger.Errorw("error while creating group", "op", op, "requestID", requestID, "req", req, "error", err)
+		return nil, chatError(err)
+	}
+
+	return res, nil
+}
+
+func (g *Gateway) RenameChat(
+	ctx context.Context,
+	req *pb.RenameChatRequest,
+	requestID string,
+) (*pb.RenameChatResponse, error) {
+	const op = "grpcgateway.RenameChat"
+	g.logger.Infow("starting connection with chat-history service", "op", op, "requestID", requestID)
+
+	conn, err := g.pool.Conn("chat-history")
+	if err != nil {
+		g.logger.Errorw("error while connecting to chat-history service", "op", op, "requestID", requestID, "error", err)
+		return nil, err
+	}
+
+	client := pb.NewChatHistoryServiceClient(conn)
+	res, err := client.RenameChat(ctx, req)
+
+	if err != nil {
+		g.logger.Errorw("error while renaming chat", "op", op, "requestID", requestID, "req", req, "error", err)
+		return nil, chatError(err)
+	}
+
+	return res, nil
+}
+
+func (g *Gateway) SetChatAvatar(
+	ctx context.Context,
+	req *pb.SetChatAvatarRequest,
+	requestID string,
+) (*pb.SetChatAvatarResponse, error) {
+	const op = "grpcgateway.SetChatAvatar"
+	g.logger.Infow("starting connection with chat-history service", "op", op, "requestID", requestID)
+
+	conn, err := g.pool.Conn("chat-history")
+	if err != nil {
+		g.logger.Errorw("error while connecting to chat-history service", "op", op, "requestID", requestID, "error", err)
+		return nil, err
+	}
+
+	client := pb.NewChatHistoryServiceClient(conn)
+	res, err := client.SetChatAvatar(ctx, req)
+
+	if err != nil {
+		g.logger.Errorw("error while setting chat avatar", "op", op, "requestID", requestID, "req", req, "error", err)
+		return nil, chatError(err)
+	}
+
+	return res, nil
+}
+
+func (g *Gateway) AddParticipants(
+	ctx context.Context,
+	req *pb.AddParticipantsRequest,
+	requestID string,
+) (*pb.AddParticipantsResponse, error) {
+	const op = "grpcgateway.AddParticipants"
+	g.logger.Infow("starting connection with chat-history service", "op", op, "requestID", requestID)
+
+	conn, err := g.pool.Conn("chat-history")
+	if err != nil {
+		g.logger.Errorw("error while connecting to chat-history service", "op", op, "requestID", requestID, "error", err)
+		return nil, err
+	}
+
+	client := pb.NewChatHistoryServiceClient(conn)
+	res, err := client.AddParticipants(ctx, req)
+
+	if err != nil {
+		g.logger.Errorw("error while adding participants", "op", op, "requestID", requestID, "req", req, "error", err)
+		return nil, chatError(err)
+	}
+
+	return res, nil
+}
+
+func (g *Gateway) RemoveParticipant(
+	ctx context.Context,
+	req *pb.RemoveParticipantRequest,
+	requestID string,
+) (*pb.RemoveParticipantResponse, error) {
+	const op = "grpcgateway.RemoveParticipant"
+	g.logger.Infow("starting connection with chat-history service", "op", op, "requestID", requestID)
+
+	conn, err := g.pool.Conn("chat-history")
+	if err != nil {
+		g.logger.Errorw("error while connecting to chat-history service", "op", op, "requestID", requestID, "error", err)
+		return nil, err
+	}
+
+	client := pb.NewChatHistoryServiceClient(conn)
+	res, err := client.RemoveParticipant(ctx, req)
+
+	if err != nil {
+		g.logger.Errorw("error while removing participant", "op", op, "requestID", requestID, "req", req, "error", err)
+		return nil, chatError(err)
+	}
+
+	return res, nil
+}
+
+func (g *Gateway) LeaveChat(
+	ctx context.Context,
+	req *pb.LeaveChatRequest,
+	requestID string,
+) (*pb.LeaveChatResponse, error) {
+	const op = "grpcgateway.LeaveChat"
+	g.logger.Infow("starting connection with chat-history service", "op", op, "requestID", requestID)
+
+	conn, err := g.pool.Conn("chat-history")
+	if err != nil {
+		g.logger.Errorw("error while connecting to chat-history service", "op", op, "requestID", requestID, "error", err)
+		return nil, err
+	}
+
+	client := pb.NewChatHistoryServiceClient(conn)
+	res, err := client.LeaveChat(ctx, req)
+
+	if err != nil {
+		g.logger.Errorw("error while leaving chat", "op", op, "requestID", requestID, "req", req, "error", err)
+		return nil, chatError(err)
+	}
+
+	return res, nil
+}
+
+// retryAfter returns delay from "retry-after" response header of SSO service.
+func retryAfter(header metadata.MD) time.Duration {
+	values := header.Get("retry-after")
+	if len(values) == 0 {
+		return 0
+	}
+
+	seconds, err := strconv.Atoi(values[0])
+	if err != nil || seconds < 0 {
+		return 0
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+func mfaError(err error, header metadata.MD) error {
+	st, ok := status.FromError(err)
+	if ok {
+		switch st.Code() {
+		case codes.ResourceExhausted:
+			return &TooManyAttemptsError{RetryAfter: retryAfter(header)}
+		case codes.InvalidArgument, codes.FailedPrecondition, codes.NotFound:
+			return fmt.Errorf("%w: %s", ErrInvalidMFARequest, st.Message())
+		}
+	}
+
+	return ErrInternalServerError
+}
+
+func relationError(err error) error {
+	st, ok := status.FromError(err)
+	if ok {
+		switch st.Code() {
+		case codes.InvalidArgument:
+			return fmt.Errorf("%w: %s", ErrInvalidRelation, st.Message())
+		case codes.NotFound:
+			return ErrRelationNotFound
+		}
+	}
+
+	return ErrInternalServerError
+}
+
+func accountError(err error) error {
+	st, ok := status.FromError(err)
+	if ok && st.Code() == codes.InvalidArgument {
+		return fmt.Errorf("%w: %s", ErrInvalidAccountRequest, st.Message())
+	}
+
+	return ErrInternalServerError
+}
+
+func oidcError(err error) error {
+	st, ok := status.FromError(err)
+	if ok {
+		switch st.Code() {
+		case codes.InvalidArgument, codes.NotFound, codes.AlreadyExists, codes.FailedPrecondition:
+			return fmt.Errorf("%w: %s", ErrInvalidOIDCRequest, st.Message())
+		case codes.Unauthenticated, codes.Unavailable:
+			return fmt.Errorf("%w: %s", ErrOIDCLoginFailed, st.Message())
+		}
+	}
+
+	return ErrInternalServerError
+}
+
+func sessionError(err error) error {
+	st, ok := status.FromError(err)
+	if ok {
+		switch st.Code() {
+		case codes.InvalidArgument:
+			return fmt.Errorf("%w: %s", ErrInvalidSessionRequest, st.Message())
+		case codes.NotFound:
+			return ErrSessionNotFound
+		}
+	}
+
+	return ErrInternalServerError
+}
+
+func chatError(err error) error {
+	st, ok := status.FromError(err)
+	if ok {
+		switch st.Code() {
+		case codes.InvalidArgument, codes.FailedPrecondition:
+			return fmt.Errorf("%w: %s", ErrInvalidChatRequest, st.Message())
+		case codes.NotFound:
+			return ErrChatNotFound
+		case codes.PermissionDenied:
+			return fmt.Errorf("%w: %s", ErrChatForbidden, st.Message())
+		}
+	}
+
+	return ErrInternalServerError
+}
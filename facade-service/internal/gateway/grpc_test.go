@@ -0,0 +1,174 @@
+package grpcgateway
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	pb "github.com/zoninnik89/messenger/common/api"
+	"github.com/zoninnik89/messenger/common/discovery/static"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// chatClient streams the given messages, then fails with err, returns or waits for the end of the call
+type chatClient struct {
+	pb.UnimplementedChatClientServiceServer
+
+	messages []*pb.Message
+	err      error
+	wait     bool
+}
+
+func (c *chatClient) GetMessagesStream(_ *pb.GetMessagesStreamRequest, stream grpc.ServerStreamingServer[pb.Message]) error {
+	for _, msg := range c.messages {
+		if err := stream.Send(msg); err != nil {
+			return err
+		}
+	}
+
+	if c.wait {
+		<-stream.Context().Done()
+	}
+
+	return c.err
+}
+
+func TestGateway_GetMessagesStreamClosesMessages(t *testing.T) {
+	msg := &pb.Message{MessageId: "message", ChatId: "chat", SenderId: "sender", MessageText: "hello"}
+
+	tests := []struct {
+		name    string
+		gateway func(t *testing.T) *Gateway
+		// cancel cancels the call once the messages are received
+		cancel  bool
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "stream ends",
+			gateway: func(t *testing.T) *Gateway {
+				return startGateway(t, &chatClient{messages: []*pb.Message{msg}})
+			},
+			want: 1,
+		},
+		{
+			name: "stream fails",
+			gateway: func(t *testing.T) *Gateway {
+				return startGateway(t, &chatClient{messages: []*pb.Message{msg}, err: status.Error(codes.Internal, "failed")})
+			},
+			want:    1,
+			wantErr: true,
+		},
+		{
+			name: "call cancelled",
+			gateway: func(t *testing.T) *Gateway {
+				return startGateway(t, &chatClient{messages: []*pb.Message{msg}, wait: true})
+			},
+			cancel: true,
+			want:   1,
+		},
+		{
+			name: "chat-client unreachable",
+			gateway: func(t *testing.T) *Gateway {
+				l, err := net.Listen("tcp", "127.0.0.1:0")
+				if err != nil {
+					t.Fatalf("failed to listen: %v", err)
+				}
+				// nothing listens on the address once the listener is closed
+				_ = l.Close()
+
+				g := NewGRPCGateway(static.NewRegistry(map[string][]string{"chat-client": {l.Addr().String()}}))
+				t.Cleanup(func() { _ = g.Close() })
+
+				return g
+			},
+			wantErr: true,
+		},
+		{
+			name: "connection fails",
+			gateway: func(t *testing.T) *Gateway {
+				g := NewGRPCGateway(static.NewRegistry(nil))
+				// connections of a closed gateway fail before the stream is opened
+				_ = g.Close()
+
+				return g
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			messages := make(chan *pb.Message)
+			streamErr := make(chan error, 1)
+
+			go func() {
+				streamErr <- tt.gateway(t).GetMessagesStream(ctx, &pb.GetMessagesStreamRequest{UserId: "user"}, messages)
+			}()
+
+			received := 0
+			timeout := time.After(5 * time.Second)
+
+		loop:
+			for {
+				select {
+				case _, ok := <-messages:
+					if !ok {
+						break loop
+					}
+
+					received++
+					if tt.cancel && received == tt.want {
+						cancel()
+					}
+				case <-timeout:
+					t.Fatalf("messages channel wasn't closed")
+				}
+			}
+
+			if received != tt.want {
+				t.Errorf("expected %d messages, got %d", tt.want, received)
+			}
+
+			select {
+			case err := <-streamErr:
+				if (err != nil) != tt.wantErr {
+					t.Errorf("expected error %v, got %v", tt.wantErr, err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("stream didn't return")
+			}
+		})
+	}
+}
+
+// startGateway returns gateway to chat-client service served in the test process
+func startGateway(t *testing.T, srv pb.ChatClientServiceServer) *Gateway {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	gRPCServer := grpc.NewServer()
+	pb.RegisterChatClientServiceServer(gRPCServer, srv)
+	go func() {
+		_ = gRPCServer.Serve(l)
+	}()
+
+	g := NewGRPCGateway(static.NewRegistry(map[string][]string{"chat-client": {l.Addr().String()}}))
+
+	t.Cleanup(func() {
+		_ = g.Close()
+		gRPCServer.Stop()
+	})
+
+	return g
+}
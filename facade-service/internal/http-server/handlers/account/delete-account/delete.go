@@ -0,0 +1,92 @@
+package delete_account
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	pb "github.com/zoninnik89/messenger/common/api"
+	grpcgateway "github.com/zoninnik89/messenger/facade-service/internal/gateway"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/auth"
+	"github.com/zoninnik89/messenger/facade-service/internal/lib/response"
+	"github.com/zoninnik89/messenger/facade-service/internal/logging"
+)
+
+type Request struct {
+	Password string `json:"password" validate:"required"`
+}
+
+// New returns handler which deletes the account of the authenticated user after checking their password.
+//
+// Chat memberships and messages of the user are cleaned up by other services asynchronously.
+func New(g *grpcgateway.Gateway) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.account.delete-account.New"
+		logger := logging.GetLogger().Sugar()
+
+		var req Request
+		requestID := middleware.GetReqID(r.Context())
+
+		logger.Infow("received delete account request", "op", op, "request_id", requestID)
+
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			logger.Errorw("error while decoding request body", "op", op, "request_id", requestID, "err", err)
+
+			render.JSON(w, r, response.Error("failed to decode request body"))
+
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			render.JSON(w, r, response.ValidationError(validateErr))
+
+			return
+		}
+
+		// request context carries client IP used by SSO service to throttle password guessing
+		_, err := g.DeleteAccount(
+			r.Context(),
+			&pb.DeleteAccountRequest{UserId: auth.UserID(r.Context()), Password: req.Password},
+			requestID,
+		)
+		if err != nil {
+			if throttledErr := new(grpcgateway.TooManyAttemptsError); errors.As(err, &throttledErr) {
+				if throttledErr.RetryAfter > 0 {
+					w.Header().Set("Retry-After", strconv.Itoa(int(throttledErr.RetryAfter.Seconds())))
+				}
+
+				render.Status(r, http.StatusTooManyRequests)
+				render.JSON(w, r, response.Error("too many attempts, try again later"))
+				return
+			}
+			if errors.Is(err, grpcgateway.ErrInvalidAccountRequest) {
+				render.JSON(w, r, response.Error(err.Error()))
+				return
+			}
+			render.JSON(w, r, response.Error("internal server error"))
+			return
+		}
+
+		logger.Infow("account deleted", "op", op, "request_id", requestID)
+
+		clearAuthCookie(w)
+
+		render.JSON(w, r, response.OK())
+	}
+}
+
+func clearAuthCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     "auth_token",
+		Value:    "",
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   -1,
+	})
+}
@@ -0,0 +1,119 @@
+package export_data
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	pb "github.com/zoninnik89/messenger/common/api"
+	"github.com/zoninnik89/messenger/facade-service/internal/audit"
+	grpcgateway "github.com/zoninnik89/messenger/facade-service/internal/gateway"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/auth"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/clientip"
+	"github.com/zoninnik89/messenger/facade-service/internal/lib/export"
+	"github.com/zoninnik89/messenger/facade-service/internal/lib/response"
+	"github.com/zoninnik89/messenger/facade-service/internal/logging"
+)
+
+// New returns handler which responds with a zip archive of the profile, relations and messages
+// of the authenticated user.
+func New(g *grpcgateway.Gateway, recorder *audit.Recorder) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.account.export-data.New"
+		logger := logging.GetLogger().Sugar()
+
+		requestID := middleware.GetReqID(r.Context())
+		userID := auth.UserID(r.Context())
+
+		logger.Infow("received export data request", "op", op, "request_id", requestID)
+
+		archive, err := collect(r.Context(), g, userID, requestID)
+		if err != nil {
+			logger.Errorw("error while collecting account data", "op", op, "request_id", requestID, "err", err)
+
+			if errors.Is(err, grpcgateway.ErrInvalidAccountRequest) {
+				render.JSON(w, r, response.Error(err.Error()))
+				return
+			}
+			render.JSON(w, r, response.Error("internal server error"))
+			return
+		}
+
+		now := time.Now().UTC()
+
+		// the archive is built in memory, so that a failure doesn't leave the client with a truncated file
+		var buf bytes.Buffer
+		if err := export.WriteZip(&buf, archive, now); err != nil {
+			logger.Errorw("error while writing archive", "op", op, "request_id", requestID, "err", err)
+
+			render.JSON(w, r, response.Error("internal server error"))
+			return
+		}
+
+		logger.Infow("account data exported", "op", op, "request_id", requestID, "messages", len(archive.Messages))
+
+		recorder.Record(r.Context(), &pb.AuditEvent{
+			Type:     pb.AuditEventType_AUDIT_EVENT_TYPE_ACCOUNT_DATA_EXPORTED,
+			UserId:   userID,
+			Login:    archive.Profile.Login,
+			ClientIp: clientip.FromRequest(r),
+		})
+
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="account-%s.zip"`, now.Format("20060102")))
+		_, _ = w.Write(buf.Bytes())
+	}
+}
+
+func collect(ctx context.Context, g *grpcgateway.Gateway, userID string, requestID string) (export.Archive, error) {
+	profile, err := g.ExportAccountData(ctx, &pb.ExportAccountDataRequest{UserId: userID}, requestID)
+	if err != nil {
+		return export.Archive{}, err
+	}
+
+	relations, err := g.ListRelations(ctx, &pb.ListRelationsRequest{UserId: userID}, requestID)
+	if err != nil {
+		return export.Archive{}, err
+	}
+
+	messages, err := g.GetUserMessages(ctx, &pb.GetUserMessagesRequest{UserId: userID}, requestID)
+	if err != nil {
+		return export.Archive{}, err
+	}
+
+	archive := export.Archive{
+		Profile: export.Profile{
+			UserID:        profile.GetUserId(),
+			Login:         profile.GetLogin(),
+			EmailVerified: profile.GetEmailVerified(),
+			MFAEnabled:    profile.GetMfaEnabled(),
+			Relations:     []export.Relation{},
+		},
+		Messages: []export.Message{},
+	}
+
+	for _, relation := range relations.GetRelations() {
+		archive.Profile.Relations = append(archive.Profile.Relations, export.Relation{
+			UserID:    relation.GetTargetId(),
+			Kind:      strings.ToLower(strings.TrimPrefix(relation.GetKind().String(), "RELATION_KIND_")),
+			CreatedAt: relation.GetCreatedAt(),
+		})
+	}
+
+	for _, message := range messages.GetMessage() {
+		archive.Messages = append(archive.Messages, export.Message{
+			ChatID:      message.GetChatId(),
+			MessageID:   message.GetMessageId(),
+			MessageText: message.GetMessageText(),
+			SentTS:      message.GetSentTs(),
+		})
+	}
+
+	return archive, nil
+}
@@ -0,0 +1,65 @@
+package forgot_password
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	pb "github.com/zoninnik89/messenger/common/api"
+	grpcgateway "github.com/zoninnik89/messenger/facade-service/internal/gateway"
+	"github.com/zoninnik89/messenger/facade-service/internal/lib/response"
+	"github.com/zoninnik89/messenger/facade-service/internal/logging"
+)
+
+type Request struct {
+	Login string `json:"login" validate:"required"`
+}
+
+// New returns handler which sends a password reset email to the given user.
+//
+// The response does not reveal whether the user exists.
+func New(g *grpcgateway.Gateway) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.auth.forgot-password.New"
+		logger := logging.GetLogger().Sugar()
+
+		var req Request
+		requestID := middleware.GetReqID(r.Context())
+
+		logger.Infow("received forgot password request", "op", op, "request_id", requestID)
+
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			logger.Errorw("error while decoding request body", "op", op, "request_id", requestID, "err", err)
+
+			render.JSON(w, r, response.Error("failed to decode request body"))
+
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			render.JSON(w, r, response.ValidationError(validateErr))
+
+			return
+		}
+
+		_, err := g.RequestPasswordReset(
+			r.Context(),
+			&pb.RequestPasswordResetRequest{Login: req.Login},
+			requestID,
+		)
+		if err != nil {
+			if errors.Is(err, grpcgateway.ErrInvalidAccountRequest) {
+				render.JSON(w, r, response.Error(err.Error()))
+				return
+			}
+			render.JSON(w, r, response.Error("internal server error"))
+			return
+		}
+
+		render.JSON(w, r, response.OK())
+	}
+}
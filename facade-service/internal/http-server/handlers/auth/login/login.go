@@ -1,7 +1,6 @@
 package login
 
 import (
-	"context"
 	"errors"
 	"github.com/go-chi/chi/middleware"
 	"github.com/go-chi/render"
@@ -11,6 +10,7 @@ import (
 	"github.com/zoninnik89/messenger/facade-service/internal/lib/response"
 	"github.com/zoninnik89/messenger/facade-service/internal/logging"
 	"net/http"
+	"strconv"
 	"time"
 )
 
@@ -21,10 +21,14 @@ type Request struct {
 
 type Response struct {
 	response.Response
-	Token string `json:"auth_token"`
+	Token string `json:"auth_token,omitempty"`
+	// MFARequired is set instead of Token when the user must pass the second factor at /login/mfa
+	MFARequired bool   `json:"mfa_required,omitempty"`
+	MFAToken    string `json:"mfa_token,omitempty"`
 }
 
-func New(g *grpcgateway.Gateway) http.HandlerFunc {
+// New returns handler which logs the user in to the app with the given ID.
+func New(g *grpcgateway.Gateway, appID int) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.chat.login.New"
 		logger := logging.GetLogger().Sugar()
@@ -61,32 +65,45 @@ func New(g *grpcgateway.Gateway) http.HandlerFunc {
 		loginReq := &pb.LoginRequest{
 			Login:    req.Login,
 			Password: req.Password,
-			AppId:    1,
+			AppId:    int32(appID),
 		}
 
 		// Add call to GRPC handler
-		res, err := g.Login(context.Background(), loginReq, requestID)
+		// request context carries client IP used by SSO service to throttle login attempts
+		res, err := g.Login(r.Context(), loginReq, requestID)
 
 		if err != nil {
+			if throttledErr := new(grpcgateway.TooManyAttemptsError); errors.As(err, &throttledErr) {
+				tooManyAttempts(w, r, throttledErr)
+				return
+			}
 			if errors.Is(err, grpcgateway.ErrInvalidLoginOrPassword) {
 				render.JSON(w, r, response.Error("invalid login or password"))
 				return
 			}
+			if errors.Is(err, grpcgateway.ErrEmailNotVerified) {
+				render.JSON(w, r, response.Error("email is not verified"))
+				return
+			}
 			render.JSON(w, r, response.Error("internal server error"))
 			return
 		}
 
+		if res.GetMfaRequired() {
+			logger.Infow("second factor required", "op", op, "request_id", requestID)
+
+			render.JSON(w, r, Response{
+				Response:    response.OK(),
+				MFARequired: true,
+				MFAToken:    res.GetMfaToken(),
+			})
+
+			return
+		}
+
 		logger.Infow("successful login", "op", op, "request_id", requestID)
 
-		http.SetCookie(w, &http.Cookie{
-			Name:     "auth_token",
-			Value:    res.GetToken(),
-			Path:     "/",
-			HttpOnly: true,                           // Prevent JavaScript access
-			Secure:   false,                          // Ensure it's sent only over HTTPS
-			SameSite: http.SameSiteLaxMode,           // Prevent CSRF attacks
-			Expires:  time.Now().Add(24 * time.Hour), // Set expiration time
-		})
+		setAuthCookie(w, res.GetToken())
 
 		render.JSON(w, r, Response{
 			Response: response.OK(),
@@ -94,3 +111,24 @@ func New(g *grpcgateway.Gateway) http.HandlerFunc {
 		})
 	}
 }
+
+func setAuthCookie(w http.ResponseWriter, token string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     "auth_token",
+		Value:    token,
+		Path:     "/",
+		HttpOnly: true,                           // Prevent JavaScript access
+		Secure:   false,                          // Ensure it's sent only over HTTPS
+		SameSite: http.SameSiteLaxMode,           // Prevent CSRF attacks
+		Expires:  time.Now().Add(24 * time.Hour), // Set expiration time
+	})
+}
+
+func tooManyAttempts(w http.ResponseWriter, r *http.Request, err *grpcgateway.TooManyAttemptsError) {
+	if err.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(err.RetryAfter.Seconds())))
+	}
+
+	render.Status(r, http.StatusTooManyRequests)
+	render.JSON(w, r, response.Error("too many login attempts, try again later"))
+}
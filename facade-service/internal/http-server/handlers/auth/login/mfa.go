@@ -0,0 +1,73 @@
+package login
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	pb "github.com/zoninnik89/messenger/common/api"
+	grpcgateway "github.com/zoninnik89/messenger/facade-service/internal/gateway"
+	"github.com/zoninnik89/messenger/facade-service/internal/lib/response"
+	"github.com/zoninnik89/messenger/facade-service/internal/logging"
+)
+
+type MFARequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+// NewMFA returns handler which completes login of a user with two-factor authentication
+// by the challenge token returned on login and a TOTP or recovery code.
+func NewMFA(g *grpcgateway.Gateway) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.auth.login.NewMFA"
+		logger := logging.GetLogger().Sugar()
+
+		var req MFARequest
+		requestID := middleware.GetReqID(r.Context())
+
+		logger.Infow("received mfa login request", "op", op, "request_id", requestID)
+
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			logger.Errorw("error while decoding request body", "op", op, "request_id", requestID, "err", err)
+
+			render.JSON(w, r, response.Error("failed to decode request body"))
+
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			render.JSON(w, r, response.ValidationError(validateErr))
+
+			return
+		}
+
+		// request context carries client IP used by SSO service to throttle attempts
+		res, err := g.VerifyMFA(r.Context(), &pb.VerifyMFARequest{MfaToken: req.MFAToken, Code: req.Code}, requestID)
+		if err != nil {
+			if throttledErr := new(grpcgateway.TooManyAttemptsError); errors.As(err, &throttledErr) {
+				tooManyAttempts(w, r, throttledErr)
+				return
+			}
+			if errors.Is(err, grpcgateway.ErrInvalidMFARequest) {
+				render.JSON(w, r, response.Error(err.Error()))
+				return
+			}
+			render.JSON(w, r, response.Error("internal server error"))
+			return
+		}
+
+		logger.Infow("successful mfa login", "op", op, "request_id", requestID)
+
+		setAuthCookie(w, res.GetToken())
+
+		render.JSON(w, r, Response{
+			Response: response.OK(),
+			Token:    res.GetToken(),
+		})
+	}
+}
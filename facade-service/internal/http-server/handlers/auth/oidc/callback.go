@@ -0,0 +1,104 @@
+package oidc
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	pb "github.com/zoninnik89/messenger/common/api"
+	grpcgateway "github.com/zoninnik89/messenger/facade-service/internal/gateway"
+	"github.com/zoninnik89/messenger/facade-service/internal/logging"
+)
+
+// NewCallback returns handler the external identity provider redirects the user back to.
+//
+// The user is redirected to afterLoginURL in any case: with auth cookie set on success,
+// with "mfa_token" in the URL fragment if the second factor is required, to be passed to /login/mfa,
+// or with "oidc_error" query parameter if the login failed.
+func NewCallback(g *grpcgateway.Gateway, afterLoginURL string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.auth.oidc.NewCallback"
+		logger := logging.GetLogger().Sugar()
+
+		requestID := middleware.GetReqID(r.Context())
+		provider := chi.URLParam(r, "provider")
+		query := r.URL.Query()
+
+		logger.Infow("received identity provider callback", "op", op, "request_id", requestID, "provider", provider)
+
+		clearStateCookie(w)
+
+		if providerErr := query.Get("error"); providerErr != "" {
+			logger.Infow("identity provider returned error", "op", op, "request_id", requestID, "error", providerErr)
+
+			redirectWithError(w, r, afterLoginURL, "identity provider login failed: "+providerErr)
+
+			return
+		}
+
+		state := query.Get("state")
+		cookie, err := r.Cookie(stateCookie)
+		if err != nil || state == "" || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) != 1 {
+			logger.Infow("state doesn't match the cookie", "op", op, "request_id", requestID)
+
+			redirectWithError(w, r, afterLoginURL, "invalid or expired state")
+
+			return
+		}
+
+		// request context carries client IP recorded by SSO service in the audit log
+		res, err := g.CompleteOIDCLogin(
+			r.Context(),
+			&pb.CompleteOIDCLoginRequest{Provider: provider, State: state, Code: query.Get("code")},
+			requestID,
+		)
+		if err != nil {
+			redirectWithError(w, r, afterLoginURL, err.Error())
+			return
+		}
+
+		if res.GetMfaRequired() {
+			logger.Infow("second factor required", "op", op, "request_id", requestID)
+
+			// fragment isn't sent to servers, so the challenge doesn't end up in access logs
+			http.Redirect(w, r, afterLoginURL+"#"+url.Values{"mfa_token": {res.GetMfaToken()}}.Encode(), http.StatusFound)
+
+			return
+		}
+
+		logger.Infow("successful identity provider login", "op", op, "request_id", requestID, "linked", res.GetLinked())
+
+		setAuthCookie(w, res.GetToken())
+
+		http.Redirect(w, r, afterLoginURL, http.StatusFound)
+	}
+}
+
+func redirectWithError(w http.ResponseWriter, r *http.Request, afterLoginURL string, msg string) {
+	target, err := url.Parse(afterLoginURL)
+	if err != nil {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	query := target.Query()
+	query.Set("oidc_error", msg)
+	target.RawQuery = query.Encode()
+
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
+
+func setAuthCookie(w http.ResponseWriter, token string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     "auth_token",
+		Value:    token,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   false,
+		SameSite: http.SameSiteLaxMode,
+		Expires:  time.Now().Add(24 * time.Hour),
+	})
+}
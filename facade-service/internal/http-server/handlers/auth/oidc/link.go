@@ -0,0 +1,65 @@
+package oidc
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	pb "github.com/zoninnik89/messenger/common/api"
+	grpcgateway "github.com/zoninnik89/messenger/facade-service/internal/gateway"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/auth"
+	"github.com/zoninnik89/messenger/facade-service/internal/lib/response"
+	"github.com/zoninnik89/messenger/facade-service/internal/logging"
+)
+
+type LinkResponse struct {
+	response.Response
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+// NewLink returns handler which starts linking an account of the external identity provider to the
+// authenticated user. The client navigates to the returned URL, the provider redirects back to the
+// callback handler.
+//
+// Unlike login it is a POST answered with JSON, so that a cross-site page can't make a logged in user
+// link an account of the attacker.
+func NewLink(g *grpcgateway.Gateway, appID int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.auth.oidc.NewLink"
+		logger := logging.GetLogger().Sugar()
+
+		requestID := middleware.GetReqID(r.Context())
+		provider := chi.URLParam(r, "provider")
+
+		logger.Infow("received identity provider link request", "op", op, "request_id", requestID, "provider", provider)
+
+		res, err := g.StartOIDCLogin(
+			r.Context(),
+			&pb.StartOIDCLoginRequest{
+				Provider:   provider,
+				AppId:      int32(appID),
+				LinkUserId: auth.UserID(r.Context()),
+			},
+			requestID,
+		)
+		if err != nil {
+			startError(w, r, err)
+			return
+		}
+
+		if err := setStateCookie(w, res.GetAuthorizationUrl()); err != nil {
+			logger.Errorw("invalid authorization url", "op", op, "request_id", requestID, "error", err)
+
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal server error"))
+
+			return
+		}
+
+		render.JSON(w, r, LinkResponse{
+			Response:         response.OK(),
+			AuthorizationURL: res.GetAuthorizationUrl(),
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package oidc
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	pb "github.com/zoninnik89/messenger/common/api"
+	grpcgateway "github.com/zoninnik89/messenger/facade-service/internal/gateway"
+	"github.com/zoninnik89/messenger/facade-service/internal/lib/response"
+	"github.com/zoninnik89/messenger/facade-service/internal/logging"
+)
+
+const (
+	// stateCookie binds the login to the browser which started it, so that a victim can't be
+	// logged in to the attacker's account by following the attacker's callback URL
+	stateCookie       = "oidc_state"
+	stateCookiePath   = "/oauth"
+	stateCookieMaxAge = 600
+)
+
+// New returns handler which redirects the user to the external identity provider to log in to the app
+// with the given ID, the provider redirects back to the callback handler.
+func New(g *grpcgateway.Gateway, appID int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.auth.oidc.New"
+		logger := logging.GetLogger().Sugar()
+
+		requestID := middleware.GetReqID(r.Context())
+		provider := chi.URLParam(r, "provider")
+
+		logger.Infow("received identity provider login request", "op", op, "request_id", requestID, "provider", provider)
+
+		res, err := g.StartOIDCLogin(
+			r.Context(),
+			&pb.StartOIDCLoginRequest{Provider: provider, AppId: int32(appID)},
+			requestID,
+		)
+		if err != nil {
+			startError(w, r, err)
+			return
+		}
+
+		if err := setStateCookie(w, res.GetAuthorizationUrl()); err != nil {
+			logger.Errorw("invalid authorization url", "op", op, "request_id", requestID, "error", err)
+
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal server error"))
+
+			return
+		}
+
+		http.Redirect(w, r, res.GetAuthorizationUrl(), http.StatusFound)
+	}
+}
+
+func startError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, grpcgateway.ErrInvalidOIDCRequest) {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, response.Error(err.Error()))
+		return
+	}
+	if errors.Is(err, grpcgateway.ErrOIDCLoginFailed) {
+		render.Status(r, http.StatusBadGateway)
+		render.JSON(w, r, response.Error(err.Error()))
+		return
+	}
+
+	render.Status(r, http.StatusInternalServerError)
+	render.JSON(w, r, response.Error("internal server error"))
+}
+
+// setStateCookie remembers the state parameter of the authorization URL in the browser.
+func setStateCookie(w http.ResponseWriter, authorizationURL string) error {
+	authURL, err := url.Parse(authorizationURL)
+	if err != nil {
+		return err
+	}
+
+	state := authURL.Query().Get("state")
+	if state == "" {
+		return errors.New("authorization url has no state")
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     stateCookie,
+		Value:    state,
+		Path:     stateCookiePath,
+		HttpOnly: true,
+		// the cookie must be sent on the top-level redirect from the provider
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   stateCookieMaxAge,
+	})
+
+	return nil
+}
+
+func clearStateCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     stateCookie,
+		Value:    "",
+		Path:     stateCookiePath,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   -1,
+	})
+}
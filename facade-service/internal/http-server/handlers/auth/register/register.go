@@ -1,7 +1,6 @@
 package register
 
 import (
-	"context"
 	"errors"
 	"github.com/go-chi/chi/middleware"
 	"github.com/go-chi/render"
@@ -62,7 +61,7 @@ func New(g *grpcgateway.Gateway) http.HandlerFunc {
 			Password: req.Password,
 		}
 
-		res, err := g.Register(context.Background(), registerReq, requestID)
+		res, err := g.Register(r.Context(), registerReq, requestID)
 
 		if err != nil {
 			logger.Errorw("failed registering user",
@@ -71,11 +70,11 @@ func New(g *grpcgateway.Gateway) http.HandlerFunc {
 				"error", err.Error(),
 			)
 
-			if errors.Is(err, grpcgateway.ErrInternalServerError) {
-				render.JSON(w, r, response.Error("internal server error"))
+			if errors.Is(err, grpcgateway.ErrInvalidLoginOrPassword) {
+				render.JSON(w, r, response.Error(err.Error()))
 				return
 			}
-			render.JSON(w, r, response.Error("not valid register request"))
+			render.JSON(w, r, response.Error("internal server error"))
 			return
 		}
 
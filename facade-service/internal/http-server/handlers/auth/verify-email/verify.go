@@ -0,0 +1,69 @@
+package verify_email
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	pb "github.com/zoninnik89/messenger/common/api"
+	grpcgateway "github.com/zoninnik89/messenger/facade-service/internal/gateway"
+	"github.com/zoninnik89/messenger/facade-service/internal/lib/response"
+	"github.com/zoninnik89/messenger/facade-service/internal/logging"
+)
+
+type Request struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type Response struct {
+	response.Response
+	UserID string `json:"user_id"`
+}
+
+// New returns handler which verifies user's email with the token sent to the user by email.
+func New(g *grpcgateway.Gateway) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.auth.verify-email.New"
+		logger := logging.GetLogger().Sugar()
+
+		var req Request
+		requestID := middleware.GetReqID(r.Context())
+
+		logger.Infow("received verify email request", "op", op, "request_id", requestID)
+
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			logger.Errorw("error while decoding request body", "op", op, "request_id", requestID, "err", err)
+
+			render.JSON(w, r, response.Error("failed to decode request body"))
+
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			render.JSON(w, r, response.ValidationError(validateErr))
+
+			return
+		}
+
+		res, err := g.VerifyEmail(r.Context(), &pb.VerifyEmailRequest{Token: req.Token}, requestID)
+		if err != nil {
+			if errors.Is(err, grpcgateway.ErrInvalidAccountRequest) {
+				render.JSON(w, r, response.Error("invalid or expired token"))
+				return
+			}
+			render.JSON(w, r, response.Error("internal server error"))
+			return
+		}
+
+		logger.Infow("email verified", "op", op, "request_id", requestID, "user_id", res.GetUserId())
+
+		render.JSON(w, r, Response{
+			Response: response.OK(),
+			UserID:   res.GetUserId(),
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package chat_error
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/render"
+	grpcgateway "github.com/zoninnik89/messenger/facade-service/internal/gateway"
+	"github.com/zoninnik89/messenger/facade-service/internal/lib/response"
+)
+
+// Render writes the error returned by chat methods of the gateway, missing chats are reported
+// with 404 and actions the user isn't allowed to do with 403.
+func Render(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, grpcgateway.ErrChatNotFound):
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, response.Error("chat not found"))
+	case errors.Is(err, grpcgateway.ErrChatForbidden):
+		render.Status(r, http.StatusForbidden)
+		render.JSON(w, r, response.Error(err.Error()))
+	case errors.Is(err, grpcgateway.ErrInvalidChatRequest):
+		render.JSON(w, r, response.Error(err.Error()))
+	default:
+		render.JSON(w, r, response.Error("internal server error"))
+	}
+}
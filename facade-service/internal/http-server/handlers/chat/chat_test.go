@@ -0,0 +1,427 @@
+package chat_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/golang-jwt/jwt/v5"
+	pb "github.com/zoninnik89/messenger/common/api"
+	"github.com/zoninnik89/messenger/common/discovery/static"
+	"github.com/zoninnik89/messenger/facade-service/internal/audit"
+	grpcgateway "github.com/zoninnik89/messenger/facade-service/internal/gateway"
+	addparticipants "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/chat/add-participants"
+	creategroup "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/chat/create-group"
+	getmessages "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/chat/get-messages"
+	leavechat "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/chat/leave-chat"
+	listchats "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/chat/list-chats"
+	removeparticipant "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/chat/remove-participant"
+	renamechat "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/chat/rename-chat"
+	sendmessage "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/chat/send-message"
+	setchatavatar "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/chat/set-chat-avatar"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/auth"
+	"github.com/zoninnik89/messenger/facade-service/internal/lib/token"
+	"github.com/zoninnik89/messenger/facade-service/internal/sessions"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	secret = "test-secret"
+	userID = "user"
+	// requests to these chats fail in the stand-ins of the services
+	forbiddenChatID = "group:forbidden"
+	missingChatID   = "group:missing"
+)
+
+// recorder remembers the last request received by a stand-in of a service
+type recorder struct {
+	mu   sync.Mutex
+	last proto.Message
+}
+
+func (r *recorder) record(req proto.Message, chatID string) error {
+	r.mu.Lock()
+	r.last = req
+	r.mu.Unlock()
+
+	switch chatID {
+	case forbiddenChatID:
+		return status.Error(codes.PermissionDenied, "user is not a participant of the chat")
+	case missingChatID:
+		return status.Error(codes.NotFound, "chat not found")
+	}
+
+	return nil
+}
+
+func (r *recorder) lastRequest() proto.Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.last
+}
+
+type chatHistory struct {
+	pb.UnimplementedChatHistoryServiceServer
+	*recorder
+}
+
+func (h *chatHistory) ListChats(_ context.Context, req *pb.ListChatsRequest) (*pb.ListChatsResponse, error) {
+	_ = h.record(req, "")
+
+	return &pb.ListChatsResponse{Chats: []*pb.Chat{
+		{
+			ChatId:         "group:1",
+			Kind:           pb.ChatKind_CHAT_KIND_GROUP,
+			Name:           "group",
+			OwnerId:        userID,
+			ParticipantIds: []string{userID, "alice"},
+			CreatedAt:      100,
+			LastMessage:    &pb.Message{ChatId: "group:1", MessageId: "message", SenderId: "alice", MessageText: "hi", SentTs: "200"},
+			UnreadCount:    2,
+		},
+		{
+			ChatId:         "dm:alice:user",
+			Kind:           pb.ChatKind_CHAT_KIND_DIRECT,
+			ParticipantIds: []string{"alice", userID},
+			CreatedAt:      50,
+		},
+	}}, nil
+}
+
+func (h *chatHistory) GetMessages(_ context.Context, req *pb.GetMessagesRequest) (*pb.GetMessagesResponse, error) {
+	if err := h.record(req, req.GetChatId()); err != nil {
+		return nil, err
+	}
+
+	return &pb.GetMessagesResponse{Message: []*pb.Message{
+		{ChatId: req.GetChatId(), MessageId: "message", SenderId: "alice", MessageText: "hi", SentTs: "200"},
+	}}, nil
+}
+
+func (h *chatHistory) CreateGroup(_ context.Context, req *pb.CreateGroupRequest) (*pb.CreateGroupResponse, error) {
+	_ = h.record(req, "")
+
+	return &pb.CreateGroupResponse{Chat: &pb.Chat{ChatId: "group:new"}}, nil
+}
+
+func (h *chatHistory) RenameChat(_ context.Context, req *pb.RenameChatRequest) (*pb.RenameChatResponse, error) {
+	return &pb.RenameChatResponse{Status: "ok"}, h.record(req, req.GetChatId())
+}
+
+func (h *chatHistory) SetChatAvatar(_ context.Context, req *pb.SetChatAvatarRequest) (*pb.SetChatAvatarResponse, error) {
+	return &pb.SetChatAvatarResponse{Status: "ok"}, h.record(req, req.GetChatId())
+}
+
+func (h *chatHistory) AddParticipants(_ context.Context, req *pb.AddParticipantsRequest) (*pb.AddParticipantsResponse, error) {
+	return &pb.AddParticipantsResponse{Status: "ok"}, h.record(req, req.GetChatId())
+}
+
+func (h *chatHistory) RemoveParticipant(_ context.Context, req *pb.RemoveParticipantRequest) (*pb.RemoveParticipantResponse, error) {
+	return &pb.RemoveParticipantResponse{Status: "ok"}, h.record(req, req.GetChatId())
+}
+
+func (h *chatHistory) LeaveChat(_ context.Context, req *pb.LeaveChatRequest) (*pb.LeaveChatResponse, error) {
+	return &pb.LeaveChatResponse{Status: "ok"}, h.record(req, req.GetChatId())
+}
+
+type chatClient struct {
+	pb.UnimplementedChatClientServiceServer
+	*recorder
+}
+
+func (c *chatClient) SendMessage(_ context.Context, req *pb.SendMessageRequest) (*pb.SendMessageResponse, error) {
+	if err := c.record(req, req.GetMessage().GetChatId()); err != nil {
+		return nil, err
+	}
+
+	return &pb.SendMessageResponse{Status: "ok"}, nil
+}
+
+func TestListChats(t *testing.T) {
+	router, services := newTestRouter(t)
+
+	var resp listchats.Response
+	if code := do(t, router, http.MethodGet, "/chats", nil, &resp); code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, code)
+	}
+
+	want := []listchats.Chat{
+		{
+			ChatID:         "group:1",
+			Kind:           "group",
+			Name:           "group",
+			OwnerID:        userID,
+			ParticipantIDs: []string{userID, "alice"},
+			CreatedAt:      100,
+			LastMessage:    &listchats.Message{MessageID: "message", SenderID: "alice", MessageText: "hi", SentTS: "200"},
+			UnreadCount:    2,
+		},
+		{
+			ChatID:         "dm:alice:user",
+			Kind:           "direct",
+			ParticipantIDs: []string{"alice", userID},
+			CreatedAt:      50,
+		},
+	}
+	if !reflect.DeepEqual(resp.Chats, want) {
+		t.Errorf("expected chats %+v, got %+v", want, resp.Chats)
+	}
+
+	if got := services.lastRequest().(*pb.ListChatsRequest).GetUserId(); got != userID {
+		t.Errorf("expected chats of %s, got %s", userID, got)
+	}
+}
+
+func TestGetMessages(t *testing.T) {
+	router, services := newTestRouter(t)
+
+	var resp getmessages.Response
+	code := do(t, router, http.MethodGet, "/chats/group:1/messages?from_ts=100&to_ts=300", nil, &resp)
+	if code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, code)
+	}
+
+	want := []getmessages.Message{{MessageID: "message", SenderID: "alice", MessageText: "hi", SentTS: "200"}}
+	if !reflect.DeepEqual(resp.Messages, want) {
+		t.Errorf("expected messages %+v, got %+v", want, resp.Messages)
+	}
+
+	wantReq := &pb.GetMessagesRequest{UserId: userID, ChatId: "group:1", FromTs: "100", ToTs: "300"}
+	if got := services.lastRequest(); !proto.Equal(got, wantReq) {
+		t.Errorf("expected request %v, got %v", wantReq, got)
+	}
+}
+
+func TestCreateGroup(t *testing.T) {
+	router, services := newTestRouter(t)
+
+	var resp creategroup.Response
+	code := do(t, router, http.MethodPost, "/chats", creategroup.Request{Name: "group", ParticipantIDs: []string{"alice"}}, &resp)
+	if code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, code)
+	}
+
+	if resp.ChatID != "group:new" {
+		t.Errorf("expected chat group:new, got %s", resp.ChatID)
+	}
+
+	// the authenticated user owns the group
+	wantReq := &pb.CreateGroupRequest{UserId: userID, Name: "group", ParticipantIds: []string{"alice"}}
+	if got := services.lastRequest(); !proto.Equal(got, wantReq) {
+		t.Errorf("expected request %v, got %v", wantReq, got)
+	}
+}
+
+func TestChatHandlers_Errors(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	forbidden := grpcgateway.ErrChatForbidden.Error() + ": user is not a participant of the chat"
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       any
+		wantStatus int
+		wantError  string
+	}{
+		{
+			name:       "create group without name",
+			method:     http.MethodPost,
+			path:       "/chats",
+			body:       creategroup.Request{},
+			wantStatus: http.StatusOK,
+			wantError:  "Name, field Name is required",
+		},
+		{
+			name:       "messages of a chat of others",
+			method:     http.MethodGet,
+			path:       "/chats/" + forbiddenChatID + "/messages",
+			wantStatus: http.StatusForbidden,
+			wantError:  forbidden,
+		},
+		{
+			name:       "messages of a missing chat",
+			method:     http.MethodGet,
+			path:       "/chats/" + missingChatID + "/messages",
+			wantStatus: http.StatusNotFound,
+			wantError:  "chat not found",
+		},
+		{
+			name:       "rename a chat of others",
+			method:     http.MethodPut,
+			path:       "/chats/" + forbiddenChatID + "/name",
+			body:       renamechat.Request{Name: "renamed"},
+			wantStatus: http.StatusForbidden,
+			wantError:  forbidden,
+		},
+		{
+			name:       "set avatar of a missing chat",
+			method:     http.MethodPut,
+			path:       "/chats/" + missingChatID + "/avatar",
+			body:       setchatavatar.Request{AvatarURL: "https://example.com/avatar.png"},
+			wantStatus: http.StatusNotFound,
+			wantError:  "chat not found",
+		},
+		{
+			name:       "add participants to a chat of others",
+			method:     http.MethodPost,
+			path:       "/chats/" + forbiddenChatID + "/participants",
+			body:       addparticipants.Request{ParticipantIDs: []string{"alice"}},
+			wantStatus: http.StatusForbidden,
+			wantError:  forbidden,
+		},
+		{
+			name:       "remove participant of a missing chat",
+			method:     http.MethodDelete,
+			path:       "/chats/" + missingChatID + "/participants/alice",
+			wantStatus: http.StatusNotFound,
+			wantError:  "chat not found",
+		},
+		{
+			name:       "leave a missing chat",
+			method:     http.MethodPost,
+			path:       "/chats/" + missingChatID + "/leave",
+			wantStatus: http.StatusNotFound,
+			wantError:  "chat not found",
+		},
+		{
+			name:       "send message to a chat of others",
+			method:     http.MethodPost,
+			path:       "/chats/" + forbiddenChatID + "/messages",
+			body:       sendmessage.Request{MessageText: "hi"},
+			wantStatus: http.StatusForbidden,
+			wantError:  grpcgateway.ErrMessageRejected.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp struct {
+				Status string `json:"status"`
+				Error  string `json:"error"`
+			}
+			if code := do(t, router, tt.method, tt.path, tt.body, &resp); code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, code)
+			}
+			if resp.Error != tt.wantError {
+				t.Errorf("expected error %q, got %q", tt.wantError, resp.Error)
+			}
+		})
+	}
+}
+
+func TestSendMessage(t *testing.T) {
+	router, services := newTestRouter(t)
+
+	var resp sendmessage.Response
+	code := do(t, router, http.MethodPost, "/chats/group:1/messages", sendmessage.Request{MessageText: "hi"}, &resp)
+	if code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, code)
+	}
+
+	msg := services.lastRequest().(*pb.SendMessageRequest).GetMessage()
+	if msg.GetMessageId() != resp.MessageID || msg.GetSentTs() != resp.SentTS {
+		t.Errorf("expected message %s sent at %s, got %v", resp.MessageID, resp.SentTS, msg)
+	}
+	// the message is sent on behalf of the authenticated user
+	if msg.GetSenderId() != userID || msg.GetChatId() != "group:1" || msg.GetMessageText() != "hi" {
+		t.Errorf("unexpected message %v", msg)
+	}
+}
+
+// newTestRouter returns router with chat routes of an authenticated user, served by stand-ins of
+// chat-history and chat-client services which share the recorder
+func newTestRouter(t *testing.T) (http.Handler, *recorder) {
+	t.Helper()
+
+	services := &recorder{}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	gRPCServer := grpc.NewServer()
+	pb.RegisterChatHistoryServiceServer(gRPCServer, &chatHistory{recorder: services})
+	pb.RegisterChatClientServiceServer(gRPCServer, &chatClient{recorder: services})
+	go func() {
+		_ = gRPCServer.Serve(l)
+	}()
+
+	g := grpcgateway.NewGRPCGateway(static.NewRegistry(map[string][]string{
+		"chat-history": {l.Addr().String()},
+		"chat-client":  {l.Addr().String()},
+	}))
+
+	t.Cleanup(func() {
+		_ = g.Close()
+		gRPCServer.Stop()
+	})
+
+	auditRecorder, err := audit.New("log", "", "facade-test")
+	if err != nil {
+		t.Fatalf("failed to create audit recorder: %v", err)
+	}
+
+	router := chi.NewRouter()
+	router.Use(auth.New(token.NewParser(1, secret), sessions.NewTracker(g, time.Minute), auditRecorder))
+
+	router.Get("/chats", listchats.New(g))
+	router.Get("/chats/{chatID}/messages", getmessages.New(g))
+	router.Post("/chats", creategroup.New(g))
+	router.Put("/chats/{chatID}/name", renamechat.New(g))
+	router.Put("/chats/{chatID}/avatar", setchatavatar.New(g))
+	router.Post("/chats/{chatID}/participants", addparticipants.New(g))
+	router.Delete("/chats/{chatID}/participants/{userID}", removeparticipant.New(g))
+	router.Post("/chats/{chatID}/leave", leavechat.New(g))
+	router.Post("/chats/{chatID}/messages", sendmessage.New(g))
+
+	return router, services
+}
+
+// do sends the request as the authenticated user, decodes the response into resp and returns its status
+func do(t *testing.T, router http.Handler, method string, path string, body any, resp any) int {
+	t.Helper()
+
+	var reqBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reqBody).Encode(body); err != nil {
+			t.Fatalf("failed to encode request: %v", err)
+		}
+	}
+
+	authToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"uid":    userID,
+		"app_id": 1,
+		"exp":    time.Now().Add(time.Minute).Unix(),
+	}).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+
+	req := httptest.NewRequest(method, path, &reqBody)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+authToken)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if err := json.NewDecoder(rec.Body).Decode(resp); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+
+	return rec.Code
+}
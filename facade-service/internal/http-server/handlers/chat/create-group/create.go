@@ -0,0 +1,71 @@
+package create_group
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	pb "github.com/zoninnik89/messenger/common/api"
+	grpcgateway "github.com/zoninnik89/messenger/facade-service/internal/gateway"
+	chaterror "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/chat/chat-error"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/auth"
+	"github.com/zoninnik89/messenger/facade-service/internal/lib/response"
+	"github.com/zoninnik89/messenger/facade-service/internal/logging"
+)
+
+type Request struct {
+	Name           string   `json:"name" validate:"required"`
+	AvatarURL      string   `json:"avatar_url,omitempty"`
+	ParticipantIDs []string `json:"participant_ids"`
+}
+
+type Response struct {
+	response.Response
+	ChatID string `json:"chat_id"`
+}
+
+// New returns handler which creates a group owned by the authenticated user.
+func New(g *grpcgateway.Gateway) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.chat.create-group.New"
+		logger := logging.GetLogger().Sugar()
+
+		var req Request
+		requestID := middleware.GetReqID(r.Context())
+		userID := auth.UserID(r.Context())
+
+		logger.Infow("received create group request", "op", op, "request_id", requestID)
+
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			logger.Errorw("error while decoding request body", "op", op, "request_id", requestID, "err", err)
+			render.JSON(w, r, response.Error("failed to decode request body"))
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			logger.Infow("invalid request", "op", op, "request_id", requestID, "error", err)
+			render.JSON(w, r, response.ValidationError(err.(validator.ValidationErrors)))
+			return
+		}
+
+		res, err := g.CreateGroup(
+			r.Context(),
+			&pb.CreateGroupRequest{
+				UserId:         userID,
+				Name:           req.Name,
+				AvatarUrl:      req.AvatarURL,
+				ParticipantIds: req.ParticipantIDs,
+			},
+			requestID,
+		)
+		if err != nil {
+			chaterror.Render(w, r, err)
+			return
+		}
+
+		logger.Infow("group created", "op", op, "request_id", requestID, "chatID", res.GetChat().GetChatId())
+
+		render.JSON(w, r, Response{Response: response.OK(), ChatID: res.GetChat().GetChatId()})
+	}
+}
@@ -1 +1,115 @@
 package get_messages_stream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+	pb "github.com/zoninnik89/messenger/common/api"
+	grpcgateway "github.com/zoninnik89/messenger/facade-service/internal/gateway"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/auth"
+	"github.com/zoninnik89/messenger/facade-service/internal/logging"
+	"github.com/zoninnik89/messenger/facade-service/internal/sessions"
+)
+
+// heartbeatInterval keeps proxies from closing idle streams, the session is checked on every heartbeat
+const heartbeatInterval = 15 * time.Second
+
+// New returns handler which streams messages of the authenticated user as server-sent events,
+// an alternative to the websocket for clients behind proxies which block upgrades.
+//
+// Every message is sent as "message" event with the message as JSON data. The stream ends with
+// "error" event if the backend stream fails or the session is revoked.
+func New(g *grpcgateway.Gateway, tracker *sessions.Tracker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.chat.get-messages-stream.New"
+		logger := logging.GetLogger().Sugar()
+
+		requestID := middleware.GetReqID(r.Context())
+		userID := auth.UserID(r.Context())
+		sessionID := auth.SessionID(r.Context())
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			logger.Errorw("response writer doesn't support flushing", "op", op, "request_id", requestID)
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+
+		logger.Infow("received messages stream request", "op", op, "request_id", requestID, "userID", userID)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		// nginx buffers responses by default, which would hold events back
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		// cancelling the context on return stops the gateway, its sends give up once the context is done
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		messages := make(chan *pb.Message)
+		// buffered, as nobody receives the error once the handler returned
+		streamErr := make(chan error, 1)
+
+		go func() {
+			streamErr <- g.GetMessagesStream(ctx, &pb.GetMessagesStreamRequest{UserId: userID}, messages)
+		}()
+
+		// set to nil once the gateway closes it, the stream error follows
+		incoming := (<-chan *pb.Message)(messages)
+
+		ticker := time.NewTicker(heartbeatInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				logger.Infow("client closed messages stream", "op", op, "request_id", requestID)
+				return
+			case err := <-streamErr:
+				if err != nil {
+					logger.Errorw("failed to get message stream", "op", op, "request_id", requestID, "error", err)
+					writeEvent(w, "error", "failed to get message stream on backend")
+					flusher.Flush()
+				}
+				return
+			case msg, ok := <-incoming:
+				if !ok {
+					incoming = nil
+					continue
+				}
+
+				data, err := json.Marshal(msg)
+				if err != nil {
+					logger.Errorw("failed to marshal message", "op", op, "request_id", requestID, "error", err)
+					continue
+				}
+
+				fmt.Fprintf(w, "id: %s\nevent: message\ndata: %s\n\n", msg.GetMessageId(), data)
+				flusher.Flush()
+			case <-ticker.C:
+				if err := tracker.Check(ctx, sessionID); err != nil {
+					logger.Infow("session revoked, closing messages stream", "op", op, "request_id", requestID)
+					writeEvent(w, "error", err.Error())
+					flusher.Flush()
+					return
+				}
+
+				// comment lines are ignored by clients
+				fmt.Fprint(w, ": ping\n\n")
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, event string, message string) {
+	data, _ := json.Marshal(message)
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+}
@@ -0,0 +1,211 @@
+package get_messages_stream
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	pb "github.com/zoninnik89/messenger/common/api"
+	"github.com/zoninnik89/messenger/common/discovery/static"
+	"github.com/zoninnik89/messenger/facade-service/internal/audit"
+	grpcgateway "github.com/zoninnik89/messenger/facade-service/internal/gateway"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/auth"
+	"github.com/zoninnik89/messenger/facade-service/internal/lib/token"
+	"github.com/zoninnik89/messenger/facade-service/internal/sessions"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const secret = "test-secret"
+
+// chatClient streams the given messages to every subscriber, then fails with err or waits for the end of the call
+type chatClient struct {
+	pb.UnimplementedChatClientServiceServer
+
+	messages []*pb.Message
+	err      error
+}
+
+func (c *chatClient) GetMessagesStream(_ *pb.GetMessagesStreamRequest, stream grpc.ServerStreamingServer[pb.Message]) error {
+	for _, msg := range c.messages {
+		if err := stream.Send(msg); err != nil {
+			return err
+		}
+	}
+
+	if c.err != nil {
+		return c.err
+	}
+
+	<-stream.Context().Done()
+	return nil
+}
+
+type event struct {
+	name string
+	data string
+}
+
+func TestNew_StreamsMessages(t *testing.T) {
+	msg := &pb.Message{MessageId: "message", ChatId: "chat", SenderId: "sender", MessageText: "hello"}
+	body := openStream(t, startGateway(t, &chatClient{messages: []*pb.Message{msg}}))
+
+	got := readEvent(t, body)
+	if got.name != "message" {
+		t.Fatalf("expected message event, got %+v", got)
+	}
+
+	var received pb.Message
+	if err := json.Unmarshal([]byte(got.data), &received); err != nil {
+		t.Fatalf("invalid message %s: %v", got.data, err)
+	}
+	if received.GetMessageId() != msg.GetMessageId() || received.GetMessageText() != msg.GetMessageText() {
+		t.Errorf("expected message %v, got %v", msg, &received)
+	}
+}
+
+func TestNew_EndsWithErrorEvent(t *testing.T) {
+	tests := []struct {
+		name    string
+		gateway func(t *testing.T) *grpcgateway.Gateway
+	}{
+		{
+			name: "stream fails",
+			gateway: func(t *testing.T) *grpcgateway.Gateway {
+				return startGateway(t, &chatClient{err: status.Error(codes.Unavailable, "gone")})
+			},
+		},
+		{
+			name: "chat-client unavailable",
+			gateway: func(t *testing.T) *grpcgateway.Gateway {
+				g := grpcgateway.NewGRPCGateway(static.NewRegistry(nil))
+				// connections of a closed gateway fail before the stream is opened
+				_ = g.Close()
+
+				return g
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := openStream(t, tt.gateway(t))
+
+			if got := readEvent(t, body); got.name != "error" {
+				t.Fatalf("expected error event, got %+v", got)
+			}
+
+			rest, err := io.ReadAll(body)
+			if err != nil {
+				t.Fatalf("stream didn't end: %v", err)
+			}
+			if len(rest) > 0 {
+				t.Errorf("expected the stream to end after the error, got %q", rest)
+			}
+		})
+	}
+}
+
+// startGateway returns gateway to chat-client service served in the test process
+func startGateway(t *testing.T, srv pb.ChatClientServiceServer) *grpcgateway.Gateway {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	gRPCServer := grpc.NewServer()
+	pb.RegisterChatClientServiceServer(gRPCServer, srv)
+	go func() {
+		_ = gRPCServer.Serve(l)
+	}()
+
+	g := grpcgateway.NewGRPCGateway(static.NewRegistry(map[string][]string{"chat-client": {l.Addr().String()}}))
+
+	t.Cleanup(func() {
+		_ = g.Close()
+		gRPCServer.Stop()
+	})
+
+	return g
+}
+
+// openStream requests the stream as an authenticated user and returns the body of the response
+func openStream(t *testing.T, g *grpcgateway.Gateway) *bufio.Reader {
+	t.Helper()
+
+	recorder, err := audit.New("log", "", "facade-test")
+	if err != nil {
+		t.Fatalf("failed to create audit recorder: %v", err)
+	}
+
+	tracker := sessions.NewTracker(g, time.Minute)
+	handler := auth.New(token.NewParser(1, secret), tracker, recorder)(New(g, tracker))
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	authToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"uid":    "user",
+		"app_id": 1,
+		"exp":    time.Now().Add(time.Minute).Unix(),
+	}).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+authToken)
+
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatalf("failed to open stream: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = resp.Body.Close()
+	})
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	if got := resp.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Fatalf("expected event stream, got %s", got)
+	}
+
+	return bufio.NewReader(resp.Body)
+}
+
+// readEvent returns the next event of the stream, skipping comments
+func readEvent(t *testing.T, body *bufio.Reader) event {
+	t.Helper()
+
+	var e event
+	for {
+		line, err := body.ReadString('\n')
+		if err != nil {
+			t.Fatalf("failed to read event: %v", err)
+		}
+
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "" && e.name != "":
+			return e
+		case strings.HasPrefix(line, "event: "):
+			e.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			e.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
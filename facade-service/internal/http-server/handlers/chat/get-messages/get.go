@@ -0,0 +1,74 @@
+package get_messages
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	pb "github.com/zoninnik89/messenger/common/api"
+	grpcgateway "github.com/zoninnik89/messenger/facade-service/internal/gateway"
+	chaterror "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/chat/chat-error"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/auth"
+	"github.com/zoninnik89/messenger/facade-service/internal/lib/response"
+	"github.com/zoninnik89/messenger/facade-service/internal/logging"
+)
+
+type Message struct {
+	MessageID   string `json:"message_id"`
+	SenderID    string `json:"sender_id"`
+	MessageText string `json:"message_text"`
+	SentTS      string `json:"sent_ts"`
+}
+
+type Response struct {
+	response.Response
+	Messages []Message `json:"messages"`
+}
+
+// New returns handler which returns history of the chat given in {chatID} URL parameter, oldest messages first.
+// Optional "from_ts" and "to_ts" query parameters limit the history to messages sent in between, inclusive.
+func New(g *grpcgateway.Gateway) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.chat.get-messages.New"
+		logger := logging.GetLogger().Sugar()
+
+		requestID := middleware.GetReqID(r.Context())
+		userID := auth.UserID(r.Context())
+		chatID := chi.URLParam(r, "chatID")
+
+		logger.Infow("received get messages request", "op", op, "request_id", requestID, "chatID", chatID)
+
+		if chatID == "" {
+			render.JSON(w, r, response.Error("chat id is required"))
+			return
+		}
+
+		res, err := g.GetMessages(
+			r.Context(),
+			&pb.GetMessagesRequest{
+				UserId: userID,
+				ChatId: chatID,
+				FromTs: r.URL.Query().Get("from_ts"),
+				ToTs:   r.URL.Query().Get("to_ts"),
+			},
+			requestID,
+		)
+		if err != nil {
+			chaterror.Render(w, r, err)
+			return
+		}
+
+		resp := Response{Response: response.OK(), Messages: make([]Message, 0, len(res.GetMessage()))}
+		for _, msg := range res.GetMessage() {
+			resp.Messages = append(resp.Messages, Message{
+				MessageID:   msg.GetMessageId(),
+				SenderID:    msg.GetSenderId(),
+				MessageText: msg.GetMessageText(),
+				SentTS:      msg.GetSentTs(),
+			})
+		}
+
+		render.JSON(w, r, resp)
+	}
+}
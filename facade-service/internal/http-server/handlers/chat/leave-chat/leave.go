@@ -0,0 +1,44 @@
+package leave_chat
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	pb "github.com/zoninnik89/messenger/common/api"
+	grpcgateway "github.com/zoninnik89/messenger/facade-service/internal/gateway"
+	chaterror "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/chat/chat-error"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/auth"
+	"github.com/zoninnik89/messenger/facade-service/internal/lib/response"
+	"github.com/zoninnik89/messenger/facade-service/internal/logging"
+)
+
+// New returns handler which removes the authenticated user from the group given in {chatID} URL parameter.
+func New(g *grpcgateway.Gateway) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.chat.leave-chat.New"
+		logger := logging.GetLogger().Sugar()
+
+		requestID := middleware.GetReqID(r.Context())
+		userID := auth.UserID(r.Context())
+		chatID := chi.URLParam(r, "chatID")
+
+		logger.Infow("received leave chat request", "op", op, "request_id", requestID, "chatID", chatID)
+
+		if chatID == "" {
+			render.JSON(w, r, response.Error("chat id is required"))
+			return
+		}
+
+		_, err := g.LeaveChat(r.Context(), &pb.LeaveChatRequest{UserId: userID, ChatId: chatID}, requestID)
+		if err != nil {
+			chaterror.Render(w, r, err)
+			return
+		}
+
+		logger.Infow("chat left", "op", op, "request_id", requestID, "chatID", chatID)
+
+		render.JSON(w, r, response.OK())
+	}
+}
@@ -0,0 +1,93 @@
+package list_chats
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	pb "github.com/zoninnik89/messenger/common/api"
+	grpcgateway "github.com/zoninnik89/messenger/facade-service/internal/gateway"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/auth"
+	"github.com/zoninnik89/messenger/facade-service/internal/lib/response"
+	"github.com/zoninnik89/messenger/facade-service/internal/logging"
+)
+
+type Message struct {
+	MessageID   string `json:"message_id"`
+	SenderID    string `json:"sender_id"`
+	MessageText string `json:"message_text"`
+	SentTS      string `json:"sent_ts"`
+}
+
+type Chat struct {
+	ChatID         string   `json:"chat_id"`
+	Kind           string   `json:"kind"`
+	Name           string   `json:"name,omitempty"`
+	AvatarURL      string   `json:"avatar_url,omitempty"`
+	OwnerID        string   `json:"owner_id,omitempty"`
+	ParticipantIDs []string `json:"participant_ids"`
+	CreatedAt      int64    `json:"created_at"`
+	LastMessage    *Message `json:"last_message"`
+	UnreadCount    int64    `json:"unread_count"`
+}
+
+type Response struct {
+	response.Response
+	Chats []Chat `json:"chats"`
+}
+
+// New returns handler which lists chats of the authenticated user with the last message and the number
+// of unread messages, most recently active chats first.
+func New(g *grpcgateway.Gateway) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.chat.list-chats.New"
+		logger := logging.GetLogger().Sugar()
+
+		requestID := middleware.GetReqID(r.Context())
+		userID := auth.UserID(r.Context())
+
+		logger.Infow("received list chats request", "op", op, "request_id", requestID)
+
+		res, err := g.ListChats(r.Context(), &pb.ListChatsRequest{UserId: userID}, requestID)
+		if err != nil {
+			render.JSON(w, r, response.Error("internal server error"))
+			return
+		}
+
+		resp := Response{Response: response.OK(), Chats: make([]Chat, 0, len(res.GetChats()))}
+
+		for _, chat := range res.GetChats() {
+			item := Chat{
+				ChatID:         chat.GetChatId(),
+				Kind:           kind(chat.GetKind()),
+				Name:           chat.GetName(),
+				AvatarURL:      chat.GetAvatarUrl(),
+				OwnerID:        chat.GetOwnerId(),
+				ParticipantIDs: chat.GetParticipantIds(),
+				CreatedAt:      chat.GetCreatedAt(),
+				UnreadCount:    chat.GetUnreadCount(),
+			}
+
+			if msg := chat.GetLastMessage(); msg != nil {
+				item.LastMessage = &Message{
+					MessageID:   msg.GetMessageId(),
+					SenderID:    msg.GetSenderId(),
+					MessageText: msg.GetMessageText(),
+					SentTS:      msg.GetSentTs(),
+				}
+			}
+
+			resp.Chats = append(resp.Chats, item)
+		}
+
+		render.JSON(w, r, resp)
+	}
+}
+
+func kind(k pb.ChatKind) string {
+	if k == pb.ChatKind_CHAT_KIND_GROUP {
+		return "group"
+	}
+
+	return "direct"
+}
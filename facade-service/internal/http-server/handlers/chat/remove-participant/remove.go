@@ -0,0 +1,50 @@
+package remove_participant
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	pb "github.com/zoninnik89/messenger/common/api"
+	grpcgateway "github.com/zoninnik89/messenger/facade-service/internal/gateway"
+	chaterror "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/chat/chat-error"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/auth"
+	"github.com/zoninnik89/messenger/facade-service/internal/lib/response"
+	"github.com/zoninnik89/messenger/facade-service/internal/logging"
+)
+
+// New returns handler which removes the user given in {userID} URL parameter from the group given
+// in {chatID} URL parameter. Only the owner may remove other participants, removing yourself leaves the group.
+func New(g *grpcgateway.Gateway) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.chat.remove-participant.New"
+		logger := logging.GetLogger().Sugar()
+
+		requestID := middleware.GetReqID(r.Context())
+		userID := auth.UserID(r.Context())
+		chatID := chi.URLParam(r, "chatID")
+		participantID := chi.URLParam(r, "userID")
+
+		logger.Infow("received remove participant request", "op", op, "request_id", requestID, "chatID", chatID)
+
+		if chatID == "" || participantID == "" {
+			render.JSON(w, r, response.Error("chat id and user id are required"))
+			return
+		}
+
+		_, err := g.RemoveParticipant(
+			r.Context(),
+			&pb.RemoveParticipantRequest{UserId: userID, ChatId: chatID, ParticipantId: participantID},
+			requestID,
+		)
+		if err != nil {
+			chaterror.Render(w, r, err)
+			return
+		}
+
+		logger.Infow("participant removed", "op", op, "request_id", requestID, "chatID", chatID)
+
+		render.JSON(w, r, response.OK())
+	}
+}
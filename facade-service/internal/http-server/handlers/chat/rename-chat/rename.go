@@ -0,0 +1,66 @@
+package rename_chat
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	pb "github.com/zoninnik89/messenger/common/api"
+	grpcgateway "github.com/zoninnik89/messenger/facade-service/internal/gateway"
+	chaterror "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/chat/chat-error"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/auth"
+	"github.com/zoninnik89/messenger/facade-service/internal/lib/response"
+	"github.com/zoninnik89/messenger/facade-service/internal/logging"
+)
+
+type Request struct {
+	Name string `json:"name" validate:"required"`
+}
+
+// New returns handler which renames the group given in {chatID} URL parameter, any participant may rename it.
+func New(g *grpcgateway.Gateway) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.chat.rename-chat.New"
+		logger := logging.GetLogger().Sugar()
+
+		var req Request
+		requestID := middleware.GetReqID(r.Context())
+		userID := auth.UserID(r.Context())
+		chatID := chi.URLParam(r, "chatID")
+
+		logger.Infow("received rename chat request", "op", op, "request_id", requestID, "chatID", chatID)
+
+		if chatID == "" {
+			render.JSON(w, r, response.Error("chat id is required"))
+			return
+		}
+
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			logger.Errorw("error while decoding request body", "op", op, "request_id", requestID, "err", err)
+			render.JSON(w, r, response.Error("failed to decode request body"))
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			logger.Infow("invalid request", "op", op, "request_id", requestID, "error", err)
+			render.JSON(w, r, response.ValidationError(err.(validator.ValidationErrors)))
+			return
+		}
+
+		_, err := g.RenameChat(
+			r.Context(),
+			&pb.RenameChatRequest{UserId: userID, ChatId: chatID, Name: req.Name},
+			requestID,
+		)
+		if err != nil {
+			chaterror.Render(w, r, err)
+			return
+		}
+
+		logger.Infow("chat renamed", "op", op, "request_id", requestID, "chatID", chatID)
+
+		render.JSON(w, r, response.OK())
+	}
+}
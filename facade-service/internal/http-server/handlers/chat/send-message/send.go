@@ -1,25 +1,25 @@
 package send_message
 
 import (
-	"context"
 	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
+	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
 	"github.com/go-chi/render"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 	pb "github.com/zoninnik89/messenger/common/api"
 	grpcgateway "github.com/zoninnik89/messenger/facade-service/internal/gateway"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/auth"
 	"github.com/zoninnik89/messenger/facade-service/internal/lib/response"
 	"github.com/zoninnik89/messenger/facade-service/internal/logging"
 )
 
 type Request struct {
 	MessageText string `json:"message_text" validate:"required"`
-	ChatID      string `json:"chat_id" validate:"required"`
 }
 
 type Response struct {
@@ -28,13 +28,22 @@ type Response struct {
 	SentTS    string `json:"sent_ts"`
 }
 
-func New(g *grpcgateway.Gateway, senderID string) http.HandlerFunc {
+// New returns handler which sends message to the chat given in {chatID} URL parameter
+// on behalf of the authenticated user.
+func New(g *grpcgateway.Gateway) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.chat.send-message.New"
 		logger := logging.GetLogger().Sugar()
 
 		var req Request
 		requestID := middleware.GetReqID(r.Context())
+		senderID := auth.UserID(r.Context())
+		chatID := chi.URLParam(r, "chatID")
+
+		if chatID == "" {
+			render.JSON(w, r, response.Error("chat id is required"))
+			return
+		}
 
 		err := render.DecodeJSON(r.Body, &req)
 		if err != nil {
@@ -62,24 +71,28 @@ func New(g *grpcgateway.Gateway, senderID string) http.HandlerFunc {
 
 		messageID := uuid.New().String()
 		sentTS := time.Now().Unix()
-		senderID := senderID
 
 		message := &pb.Message{
 			MessageId:   messageID,
-			ChatId:      req.ChatID,
+			ChatId:      chatID,
 			SenderId:    senderID,
 			MessageText: req.MessageText,
 			SentTs:      strconv.FormatInt(sentTS, 10),
 		}
 
 		res, err := g.SendMessage(
-			context.Background(),
+			r.Context(),
 			&pb.SendMessageRequest{
 				Message: message,
 			},
 		)
 
 		if err != nil {
+			if errors.Is(err, grpcgateway.ErrMessageRejected) {
+				render.Status(r, http.StatusForbidden)
+				render.JSON(w, r, response.Error(err.Error()))
+				return
+			}
 			if errors.Is(err, grpcgateway.ErrInternalServerError) {
 				render.JSON(w, r, response.Error("internal server error"))
 				return
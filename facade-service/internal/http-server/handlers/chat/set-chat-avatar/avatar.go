@@ -0,0 +1,60 @@
+package set_chat_avatar
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	pb "github.com/zoninnik89/messenger/common/api"
+	grpcgateway "github.com/zoninnik89/messenger/facade-service/internal/gateway"
+	chaterror "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/chat/chat-error"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/auth"
+	"github.com/zoninnik89/messenger/facade-service/internal/lib/response"
+	"github.com/zoninnik89/messenger/facade-service/internal/logging"
+)
+
+type Request struct {
+	// AvatarURL is empty to remove the avatar
+	AvatarURL string `json:"avatar_url"`
+}
+
+// New returns handler which sets avatar of the group given in {chatID} URL parameter, any participant may change it.
+func New(g *grpcgateway.Gateway) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.chat.set-chat-avatar.New"
+		logger := logging.GetLogger().Sugar()
+
+		var req Request
+		requestID := middleware.GetReqID(r.Context())
+		userID := auth.UserID(r.Context())
+		chatID := chi.URLParam(r, "chatID")
+
+		logger.Infow("received set chat avatar request", "op", op, "request_id", requestID, "chatID", chatID)
+
+		if chatID == "" {
+			render.JSON(w, r, response.Error("chat id is required"))
+			return
+		}
+
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			logger.Errorw("error while decoding request body", "op", op, "request_id", requestID, "err", err)
+			render.JSON(w, r, response.Error("failed to decode request body"))
+			return
+		}
+
+		_, err := g.SetChatAvatar(
+			r.Context(),
+			&pb.SetChatAvatarRequest{UserId: userID, ChatId: chatID, AvatarUrl: req.AvatarURL},
+			requestID,
+		)
+		if err != nil {
+			chaterror.Render(w, r, err)
+			return
+		}
+
+		logger.Infow("chat avatar set", "op", op, "request_id", requestID, "chatID", chatID)
+
+		render.JSON(w, r, response.OK())
+	}
+}
@@ -0,0 +1,75 @@
+package confirm_totp
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	pb "github.com/zoninnik89/messenger/common/api"
+	grpcgateway "github.com/zoninnik89/messenger/facade-service/internal/gateway"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/auth"
+	"github.com/zoninnik89/messenger/facade-service/internal/lib/response"
+	"github.com/zoninnik89/messenger/facade-service/internal/logging"
+)
+
+type Request struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type Response struct {
+	response.Response
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// New returns handler which enables two-factor authentication of the authenticated user
+// after checking a code generated with the enrolled secret.
+func New(g *grpcgateway.Gateway) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.mfa.confirm-totp.New"
+		logger := logging.GetLogger().Sugar()
+
+		var req Request
+		requestID := middleware.GetReqID(r.Context())
+
+		logger.Infow("received confirm totp request", "op", op, "request_id", requestID)
+
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			logger.Errorw("error while decoding request body", "op", op, "request_id", requestID, "err", err)
+
+			render.JSON(w, r, response.Error("failed to decode request body"))
+
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			render.JSON(w, r, response.ValidationError(validateErr))
+
+			return
+		}
+
+		res, err := g.ConfirmTOTP(
+			r.Context(),
+			&pb.ConfirmTOTPRequest{UserId: auth.UserID(r.Context()), Code: req.Code},
+			requestID,
+		)
+		if err != nil {
+			if errors.Is(err, grpcgateway.ErrInvalidMFARequest) {
+				render.JSON(w, r, response.Error(err.Error()))
+				return
+			}
+			render.JSON(w, r, response.Error("internal server error"))
+			return
+		}
+
+		logger.Infow("two-factor authentication enabled", "op", op, "request_id", requestID)
+
+		render.JSON(w, r, Response{
+			Response:      response.OK(),
+			RecoveryCodes: res.GetRecoveryCodes(),
+		})
+	}
+}
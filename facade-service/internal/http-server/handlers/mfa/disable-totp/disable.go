@@ -0,0 +1,77 @@
+package disable_totp
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	pb "github.com/zoninnik89/messenger/common/api"
+	grpcgateway "github.com/zoninnik89/messenger/facade-service/internal/gateway"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/auth"
+	"github.com/zoninnik89/messenger/facade-service/internal/lib/response"
+	"github.com/zoninnik89/messenger/facade-service/internal/logging"
+)
+
+type Request struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// New returns handler which disables two-factor authentication of the authenticated user
+// after checking a TOTP or recovery code.
+func New(g *grpcgateway.Gateway) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.mfa.disable-totp.New"
+		logger := logging.GetLogger().Sugar()
+
+		var req Request
+		requestID := middleware.GetReqID(r.Context())
+
+		logger.Infow("received disable totp request", "op", op, "request_id", requestID)
+
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			logger.Errorw("error while decoding request body", "op", op, "request_id", requestID, "err", err)
+
+			render.JSON(w, r, response.Error("failed to decode request body"))
+
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			render.JSON(w, r, response.ValidationError(validateErr))
+
+			return
+		}
+
+		_, err := g.DisableTOTP(
+			r.Context(),
+			&pb.DisableTOTPRequest{UserId: auth.UserID(r.Context()), Code: req.Code},
+			requestID,
+		)
+		if err != nil {
+			if throttledErr := new(grpcgateway.TooManyAttemptsError); errors.As(err, &throttledErr) {
+				if throttledErr.RetryAfter > 0 {
+					w.Header().Set("Retry-After", strconv.Itoa(int(throttledErr.RetryAfter.Seconds())))
+				}
+
+				render.Status(r, http.StatusTooManyRequests)
+				render.JSON(w, r, response.Error("too many attempts, try again later"))
+				return
+			}
+			if errors.Is(err, grpcgateway.ErrInvalidMFARequest) {
+				render.JSON(w, r, response.Error(err.Error()))
+				return
+			}
+			render.JSON(w, r, response.Error("internal server error"))
+			return
+		}
+
+		logger.Infow("two-factor authentication disabled", "op", op, "request_id", requestID)
+
+		render.JSON(w, r, response.OK())
+	}
+}
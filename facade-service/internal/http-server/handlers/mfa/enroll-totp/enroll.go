@@ -0,0 +1,49 @@
+package enroll_totp
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	pb "github.com/zoninnik89/messenger/common/api"
+	grpcgateway "github.com/zoninnik89/messenger/facade-service/internal/gateway"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/auth"
+	"github.com/zoninnik89/messenger/facade-service/internal/lib/response"
+	"github.com/zoninnik89/messenger/facade-service/internal/logging"
+)
+
+type Response struct {
+	response.Response
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// New returns handler which generates TOTP secret for the authenticated user. The provisioning URI
+// is meant to be shown as QR code to be scanned by an authenticator app.
+func New(g *grpcgateway.Gateway) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.mfa.enroll-totp.New"
+		logger := logging.GetLogger().Sugar()
+
+		requestID := middleware.GetReqID(r.Context())
+
+		logger.Infow("received enroll totp request", "op", op, "request_id", requestID)
+
+		res, err := g.EnrollTOTP(r.Context(), &pb.EnrollTOTPRequest{UserId: auth.UserID(r.Context())}, requestID)
+		if err != nil {
+			if errors.Is(err, grpcgateway.ErrInvalidMFARequest) {
+				render.JSON(w, r, response.Error(err.Error()))
+				return
+			}
+			render.JSON(w, r, response.Error("internal server error"))
+			return
+		}
+
+		render.JSON(w, r, Response{
+			Response:        response.OK(),
+			Secret:          res.GetSecret(),
+			ProvisioningURI: res.GetProvisioningUri(),
+		})
+	}
+}
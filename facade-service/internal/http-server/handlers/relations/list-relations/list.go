@@ -0,0 +1,62 @@
+package list_relations
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	pb "github.com/zoninnik89/messenger/common/api"
+	grpcgateway "github.com/zoninnik89/messenger/facade-service/internal/gateway"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/auth"
+	"github.com/zoninnik89/messenger/facade-service/internal/lib/response"
+	"github.com/zoninnik89/messenger/facade-service/internal/logging"
+)
+
+type Relation struct {
+	UserID    string `json:"user_id"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+type Response struct {
+	response.Response
+	Blocked []Relation `json:"blocked"`
+	Muted   []Relation `json:"muted"`
+}
+
+// New returns handler which lists users blocked and muted by the authenticated user.
+func New(g *grpcgateway.Gateway) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.relations.list-relations.New"
+		logger := logging.GetLogger().Sugar()
+
+		requestID := middleware.GetReqID(r.Context())
+		userID := auth.UserID(r.Context())
+
+		logger.Infow("received list relations request", "op", op, "request_id", requestID)
+
+		res, err := g.ListRelations(r.Context(), &pb.ListRelationsRequest{UserId: userID}, requestID)
+		if err != nil {
+			render.JSON(w, r, response.Error("internal server error"))
+			return
+		}
+
+		resp := Response{
+			Response: response.OK(),
+			Blocked:  []Relation{},
+			Muted:    []Relation{},
+		}
+
+		for _, relation := range res.GetRelations() {
+			item := Relation{UserID: relation.GetTargetId(), CreatedAt: relation.GetCreatedAt()}
+
+			switch relation.GetKind() {
+			case pb.RelationKind_RELATION_KIND_BLOCK:
+				resp.Blocked = append(resp.Blocked, item)
+			case pb.RelationKind_RELATION_KIND_MUTE:
+				resp.Muted = append(resp.Muted, item)
+			}
+		}
+
+		render.JSON(w, r, resp)
+	}
+}
@@ -0,0 +1,63 @@
+package remove_relation
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	pb "github.com/zoninnik89/messenger/common/api"
+	grpcgateway "github.com/zoninnik89/messenger/facade-service/internal/gateway"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/auth"
+	"github.com/zoninnik89/messenger/facade-service/internal/lib/response"
+	"github.com/zoninnik89/messenger/facade-service/internal/logging"
+)
+
+// New returns handler which unblocks or unmutes (depending on kind) the user given in {userID} URL parameter
+// on behalf of the authenticated user.
+func New(g *grpcgateway.Gateway, kind pb.RelationKind) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.relations.remove-relation.New"
+		logger := logging.GetLogger().Sugar()
+
+		requestID := middleware.GetReqID(r.Context())
+		userID := auth.UserID(r.Context())
+		targetID := chi.URLParam(r, "userID")
+
+		logger.Infow("received remove relation request", "op", op, "request_id", requestID, "kind", kind.String())
+
+		if targetID == "" {
+			render.JSON(w, r, response.Error("user id is required"))
+			return
+		}
+
+		_, err := g.RemoveRelation(
+			r.Context(),
+			&pb.RemoveRelationRequest{
+				UserId:   userID,
+				TargetId: targetID,
+				Kind:     kind,
+			},
+			requestID,
+		)
+
+		if err != nil {
+			if errors.Is(err, grpcgateway.ErrRelationNotFound) {
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, response.Error("relation not found"))
+				return
+			}
+			if errors.Is(err, grpcgateway.ErrInvalidRelation) {
+				render.JSON(w, r, response.Error(err.Error()))
+				return
+			}
+			render.JSON(w, r, response.Error("internal server error"))
+			return
+		}
+
+		logger.Infow("relation removed", "op", op, "request_id", requestID, "kind", kind.String())
+
+		render.JSON(w, r, response.OK())
+	}
+}
@@ -0,0 +1,70 @@
+package list_sessions
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	pb "github.com/zoninnik89/messenger/common/api"
+	grpcgateway "github.com/zoninnik89/messenger/facade-service/internal/gateway"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/auth"
+	"github.com/zoninnik89/messenger/facade-service/internal/lib/response"
+	"github.com/zoninnik89/messenger/facade-service/internal/logging"
+)
+
+type Session struct {
+	SessionID  string `json:"session_id"`
+	DeviceName string `json:"device_name"`
+	IP         string `json:"ip"`
+	UserAgent  string `json:"user_agent"`
+	CreatedAt  int64  `json:"created_at"`
+	LastUsedAt int64  `json:"last_used_at"`
+	ExpiresAt  int64  `json:"expires_at"`
+	// Current marks the session the request was made with
+	Current bool `json:"current"`
+}
+
+type Response struct {
+	response.Response
+	Sessions []Session `json:"sessions"`
+}
+
+// New returns handler which lists devices the authenticated user is signed in on.
+func New(g *grpcgateway.Gateway) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.sessions.list-sessions.New"
+		logger := logging.GetLogger().Sugar()
+
+		requestID := middleware.GetReqID(r.Context())
+		userID := auth.UserID(r.Context())
+		currentSessionID := auth.SessionID(r.Context())
+
+		logger.Infow("received list sessions request", "op", op, "request_id", requestID)
+
+		res, err := g.ListSessions(r.Context(), &pb.ListSessionsRequest{UserId: userID}, requestID)
+		if err != nil {
+			render.JSON(w, r, response.Error("internal server error"))
+			return
+		}
+
+		resp := Response{
+			Response: response.OK(),
+			Sessions: make([]Session, 0, len(res.GetSessions())),
+		}
+
+		for _, session := range res.GetSessions() {
+			resp.Sessions = append(resp.Sessions, Session{
+				SessionID:  session.GetSessionId(),
+				DeviceName: session.GetDeviceName(),
+				IP:         session.GetIp(),
+				UserAgent:  session.GetUserAgent(),
+				CreatedAt:  session.GetCreatedAt(),
+				LastUsedAt: session.GetLastUsedAt(),
+				ExpiresAt:  session.GetExpiresAt(),
+				Current:    session.GetSessionId() == currentSessionID,
+			})
+		}
+
+		render.JSON(w, r, resp)
+	}
+}
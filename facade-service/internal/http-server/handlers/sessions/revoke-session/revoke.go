@@ -0,0 +1,79 @@
+package revoke_session
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	pb "github.com/zoninnik89/messenger/common/api"
+	grpcgateway "github.com/zoninnik89/messenger/facade-service/internal/gateway"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/auth"
+	"github.com/zoninnik89/messenger/facade-service/internal/lib/response"
+	"github.com/zoninnik89/messenger/facade-service/internal/logging"
+	"github.com/zoninnik89/messenger/facade-service/internal/sessions"
+)
+
+// New returns handler which signs the authenticated user out of the session given in {sessionID} URL parameter.
+//
+// Live connections of the session held by this instance are closed at once, other instances close theirs
+// on the account event published by sso service.
+func New(g *grpcgateway.Gateway, tracker *sessions.Tracker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.sessions.revoke-session.New"
+		logger := logging.GetLogger().Sugar()
+
+		requestID := middleware.GetReqID(r.Context())
+		userID := auth.UserID(r.Context())
+		sessionID := chi.URLParam(r, "sessionID")
+
+		logger.Infow("received revoke session request", "op", op, "request_id", requestID)
+
+		if sessionID == "" {
+			render.JSON(w, r, response.Error("session id is required"))
+			return
+		}
+
+		// request context carries client IP recorded to the audit log
+		_, err := g.RevokeSession(
+			r.Context(),
+			&pb.RevokeSessionRequest{UserId: userID, SessionId: sessionID},
+			requestID,
+		)
+		if err != nil {
+			if errors.Is(err, grpcgateway.ErrSessionNotFound) {
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, response.Error("session not found"))
+				return
+			}
+			if errors.Is(err, grpcgateway.ErrInvalidSessionRequest) {
+				render.JSON(w, r, response.Error(err.Error()))
+				return
+			}
+			render.JSON(w, r, response.Error("internal server error"))
+			return
+		}
+
+		tracker.Revoke(sessionID)
+
+		logger.Infow("session revoked", "op", op, "request_id", requestID, "sessionID", sessionID)
+
+		if sessionID == auth.SessionID(r.Context()) {
+			clearAuthCookie(w)
+		}
+
+		render.JSON(w, r, response.OK())
+	}
+}
+
+func clearAuthCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     "auth_token",
+		Value:    "",
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   -1,
+	})
+}
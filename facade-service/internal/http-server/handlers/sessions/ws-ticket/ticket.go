@@ -0,0 +1,52 @@
+package ws_ticket
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/auth"
+	"github.com/zoninnik89/messenger/facade-service/internal/lib/response"
+	"github.com/zoninnik89/messenger/facade-service/internal/logging"
+	"github.com/zoninnik89/messenger/facade-service/internal/tickets"
+)
+
+type Response struct {
+	response.Response
+	Ticket string `json:"ticket"`
+	// ExpiresIn is the number of seconds the ticket may be used in
+	ExpiresIn int `json:"expires_in"`
+}
+
+// New returns handler which issues a one-time ticket for opening a websocket connection on behalf of
+// the authenticated session, the connection is opened at /ws?ticket=<ticket>.
+func New(t *tickets.Tickets) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.sessions.ws-ticket.New"
+		logger := logging.GetLogger().Sugar()
+
+		requestID := middleware.GetReqID(r.Context())
+		userID := auth.UserID(r.Context())
+
+		ticket, err := t.Issue(r.Context(), tickets.Grant{
+			UserID:    userID,
+			SessionID: auth.SessionID(r.Context()),
+			Scopes:    auth.Scopes(r.Context()),
+		})
+		if err != nil {
+			logger.Errorw("failed to issue websocket ticket", "op", op, "request_id", requestID, "error", err)
+
+			render.JSON(w, r, response.Error("internal server error"))
+
+			return
+		}
+
+		logger.Infow("websocket ticket issued", "op", op, "request_id", requestID, "userID", userID)
+
+		render.JSON(w, r, Response{
+			Response:  response.OK(),
+			Ticket:    ticket,
+			ExpiresIn: int(t.TTL().Seconds()),
+		})
+	}
+}
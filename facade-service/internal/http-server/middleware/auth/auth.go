@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	pb "github.com/zoninnik89/messenger/common/api"
+	"github.com/zoninnik89/messenger/facade-service/internal/audit"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/clientip"
+	"github.com/zoninnik89/messenger/facade-service/internal/lib/response"
+	"github.com/zoninnik89/messenger/facade-service/internal/lib/token"
+	"github.com/zoninnik89/messenger/facade-service/internal/logging"
+	"github.com/zoninnik89/messenger/facade-service/internal/sessions"
+)
+
+type ctxKey struct{}
+
+type sessionCtxKey struct{}
+
+type scopesCtxKey struct{}
+
+// New returns middleware which authenticates requests by JWT token passed either in
+// "Authorization: Bearer <token>" header or in "auth_token" cookie set on login.
+// ID of the authenticated user is available to handlers via UserID, ID of the session via SessionID.
+// Tokens of revoked sessions are rejected, scopes of tokens are checked by RequireScope.
+//
+// Requests with invalid tokens are recorded to the audit log.
+func New(tokens *token.Parser, tracker *sessions.Tracker, recorder *audit.Recorder) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			const op = "middleware.auth.New"
+			logger := logging.GetLogger().Sugar()
+			requestID := middleware.GetReqID(r.Context())
+
+			tokenString := tokenFromRequest(r)
+			if tokenString == "" {
+				logger.Infow("missing auth token", "op", op, "request_id", requestID)
+
+				render.Status(r, http.StatusUnauthorized)
+				render.JSON(w, r, response.Error("unauthorized"))
+
+				return
+			}
+
+			claims, err := tokens.Parse(tokenString)
+			if err != nil {
+				logger.Infow("invalid auth token", "op", op, "request_id", requestID, "error", err)
+
+				recorder.Record(r.Context(), &pb.AuditEvent{
+					Type:     pb.AuditEventType_AUDIT_EVENT_TYPE_ACCESS_DENIED,
+					ClientIp: clientip.FromRequest(r),
+					Details:  map[string]string{"reason": "invalid auth token", "path": r.URL.Path},
+				})
+
+				render.Status(r, http.StatusUnauthorized)
+				render.JSON(w, r, response.Error("unauthorized"))
+
+				return
+			}
+
+			if err := tracker.Check(r.Context(), claims.SessionID); err != nil {
+				logger.Infow("session revoked", "op", op, "request_id", requestID, "sessionID", claims.SessionID)
+
+				recorder.Record(r.Context(), &pb.AuditEvent{
+					Type:     pb.AuditEventType_AUDIT_EVENT_TYPE_ACCESS_DENIED,
+					UserId:   claims.UserID,
+					ClientIp: clientip.FromRequest(r),
+					Details:  map[string]string{"reason": "session revoked", "path": r.URL.Path},
+				})
+
+				render.Status(r, http.StatusUnauthorized)
+				render.JSON(w, r, response.Error("session revoked"))
+
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), ctxKey{}, claims.UserID)
+			ctx = context.WithValue(ctx, sessionCtxKey{}, claims.SessionID)
+			ctx = context.WithValue(ctx, scopesCtxKey{}, claims.Scopes)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// RequireScope returns middleware which rejects requests authenticated by New with tokens not allowed
+// to be used for the scope, see token.Allows.
+//
+// Rejected requests are recorded to the audit log.
+func RequireScope(scope string, recorder *audit.Recorder) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			const op = "middleware.auth.RequireScope"
+
+			if !token.Allows(Scopes(r.Context()), scope) {
+				logging.GetLogger().Sugar().Infow("insufficient scope", "op", op,
+					"request_id", middleware.GetReqID(r.Context()), "userID", UserID(r.Context()), "scope", scope)
+
+				recorder.Record(r.Context(), &pb.AuditEvent{
+					Type:     pb.AuditEventType_AUDIT_EVENT_TYPE_ACCESS_DENIED,
+					UserId:   UserID(r.Context()),
+					ClientIp: clientip.FromRequest(r),
+					Details:  map[string]string{"reason": "insufficient scope", "scope": scope, "path": r.URL.Path},
+				})
+
+				render.Status(r, http.StatusForbidden)
+				render.JSON(w, r, response.Error("insufficient scope"))
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// UserID returns ID of the user authenticated by the middleware, or empty string.
+func UserID(ctx context.Context) string {
+	userID, _ := ctx.Value(ctxKey{}).(string)
+	return userID
+}
+
+// SessionID returns ID of the session authenticated by the middleware, or empty string.
+func SessionID(ctx context.Context) string {
+	sessionID, _ := ctx.Value(sessionCtxKey{}).(string)
+	return sessionID
+}
+
+// Scopes returns scopes of the token authenticated by the middleware, nil if the token isn't restricted.
+func Scopes(ctx context.Context) []string {
+	scopes, _ := ctx.Value(scopesCtxKey{}).([]string)
+	return scopes
+}
+
+func tokenFromRequest(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); header != "" {
+		return strings.TrimPrefix(header, "Bearer ")
+	}
+
+	if cookie, err := r.Cookie("auth_token"); err == nil {
+		return cookie.Value
+	}
+
+	return ""
+}
@@ -0,0 +1,141 @@
+package clientip
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/zoninnik89/messenger/common"
+)
+
+// DeviceNameHeader is the request header clients may name the device they run on with,
+// the name is shown in the list of sessions of the user.
+const DeviceNameHeader = "X-Device-Name"
+
+// New returns middleware which passes IP, user agent and device name of the client to gRPC requests
+// made with the request context.
+//
+// It must be used after RealIP, so that the IP of the client behind a trusted proxy is used.
+func New() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ctx := common.WithClientIP(r.Context(), FromRequest(r))
+			ctx = common.WithClientDevice(ctx, r.UserAgent(), r.Header.Get(DeviceNameHeader))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// ParseProxies parses addresses of trusted proxies, given either as IPs or as networks in CIDR notation.
+func ParseProxies(proxies []string) ([]netip.Prefix, error) {
+	const op = "clientip.ParseProxies"
+
+	prefixes := make([]netip.Prefix, 0, len(proxies))
+	for _, proxy := range proxies {
+		if strings.Contains(proxy, "/") {
+			prefix, err := netip.ParsePrefix(proxy)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", op, err)
+			}
+
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+
+		addr, err := netip.ParseAddr(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+
+	return prefixes, nil
+}
+
+// RealIP returns middleware which sets remote address of requests forwarded by the trusted proxies to IP
+// of the client, taken from X-Forwarded-For or X-Real-IP headers. The headers of other requests are ignored,
+// as any client may set them, so limits and lockouts keyed by IP couldn't be bypassed with them.
+//
+// X-Forwarded-For is read from the right, skipping addresses of trusted proxies, so a client can't
+// pretend to be another one by prepending addresses to the header.
+func RealIP(trustedProxies []netip.Prefix) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if isTrusted(trustedProxies, FromRequest(r)) {
+				if ip := forwardedIP(trustedProxies, r.Header); ip != "" {
+					r.RemoteAddr = ip
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// FromRequest returns IP of the client which made the request.
+func FromRequest(r *http.Request) string {
+	return FromAddr(r.RemoteAddr)
+}
+
+// FromAddr returns IP of the client with the remote address of its request, e.g. the peer address of
+// a Connect request.
+func FromAddr(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		// RealIP sets remote address to a bare IP
+		return addr
+	}
+
+	return host
+}
+
+// forwardedIP returns the nearest address in X-Forwarded-For which isn't a trusted proxy, or X-Real-IP
+// when X-Forwarded-For isn't set.
+func forwardedIP(trustedProxies []netip.Prefix, header http.Header) string {
+	if forwardedFor := header.Values("X-Forwarded-For"); len(forwardedFor) > 0 {
+		addrs := strings.Split(strings.Join(forwardedFor, ","), ",")
+		for i := len(addrs) - 1; i >= 0; i-- {
+			addr, err := netip.ParseAddr(strings.TrimSpace(addrs[i]))
+			if err != nil {
+				return ""
+			}
+
+			if !isTrusted(trustedProxies, addr.String()) {
+				return addr.Unmap().String()
+			}
+		}
+
+		return ""
+	}
+
+	addr, err := netip.ParseAddr(strings.TrimSpace(header.Get("X-Real-IP")))
+	if err != nil {
+		return ""
+	}
+
+	return addr.Unmap().String()
+}
+
+func isTrusted(trustedProxies []netip.Prefix, ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+
+	addr = addr.Unmap()
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
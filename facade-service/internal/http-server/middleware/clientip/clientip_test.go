@@ -0,0 +1,92 @@
+package clientip
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRealIP(t *testing.T) {
+	trustedProxies, err := ParseProxies([]string{"10.0.0.0/8", "192.168.1.1"})
+	if err != nil {
+		t.Fatalf("failed to parse proxies: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{
+			name:       "direct client",
+			remoteAddr: "203.0.113.7:51000",
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "direct client with forged headers",
+			remoteAddr: "203.0.113.7:51000",
+			headers:    map[string]string{"X-Forwarded-For": "198.51.100.1", "X-Real-IP": "198.51.100.2"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "trusted proxy",
+			remoteAddr: "10.1.2.3:443",
+			headers:    map[string]string{"X-Forwarded-For": "198.51.100.1"},
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "chain of trusted proxies",
+			remoteAddr: "192.168.1.1:443",
+			headers:    map[string]string{"X-Forwarded-For": "198.51.100.1, 10.0.0.5"},
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "address prepended by the client",
+			remoteAddr: "10.1.2.3:443",
+			headers:    map[string]string{"X-Forwarded-For": "198.51.100.9, 203.0.113.7"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "real IP set by trusted proxy",
+			remoteAddr: "10.1.2.3:443",
+			headers:    map[string]string{"X-Real-IP": "198.51.100.2"},
+			want:       "198.51.100.2",
+		},
+		{
+			name:       "invalid header of trusted proxy",
+			remoteAddr: "10.1.2.3:443",
+			headers:    map[string]string{"X-Forwarded-For": "unknown"},
+			want:       "10.1.2.3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			handler := RealIP(trustedProxies)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				got = FromRequest(r)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got != tt.want {
+				t.Errorf("expected client IP %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseProxies_Invalid(t *testing.T) {
+	for _, proxy := range []string{"10.0.0.0/33", "proxy.local", ""} {
+		if _, err := ParseProxies([]string{proxy}); err == nil {
+			t.Errorf("expected %q to be rejected", proxy)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package origin
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/zoninnik89/messenger/facade-service/internal/lib/response"
+	"github.com/zoninnik89/messenger/facade-service/internal/logging"
+)
+
+// Any allows every origin when it is in the allowlist.
+const Any = "*"
+
+// Allowlist is the origins of web clients allowed to call the API and open websocket connections,
+// e.g. "https://messenger.example.com".
+type Allowlist []string
+
+// Allowed reports whether requests from the origin are allowed. Origins are compared case-insensitively,
+// as browsers send them in lower case while configs may not.
+func (a Allowlist) Allowed(origin string) bool {
+	for _, allowed := range a {
+		if allowed == Any || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AllowedRequest reports whether the request may be served. Requests without "Origin" header are
+// made by other clients than browsers, which aren't protected by origin checks, so they are allowed.
+func (a Allowlist) AllowedRequest(r *http.Request) bool {
+	requestOrigin := r.Header.Get("Origin")
+	return requestOrigin == "" || a.Allowed(requestOrigin)
+}
+
+// New returns middleware which rejects requests from origins missing in the allowlist with 403 status.
+//
+// CORS headers only keep browsers from reading responses, so without it a page of another origin could
+// still make requests authenticated with the auth cookie.
+func New(allowlist Allowlist) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			const op = "middleware.origin.New"
+
+			if !allowlist.AllowedRequest(r) {
+				logging.GetLogger().Sugar().Infow(
+					"request from not allowed origin",
+					"op", op,
+					"request_id", middleware.GetReqID(r.Context()),
+					"origin", r.Header.Get("Origin"),
+					"path", r.URL.Path,
+				)
+
+				render.Status(r, http.StatusForbidden)
+				render.JSON(w, r, response.Error("origin not allowed"))
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,75 @@
+package throttle
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/auth"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/clientip"
+	"github.com/zoninnik89/messenger/facade-service/internal/lib/response"
+	"github.com/zoninnik89/messenger/facade-service/internal/logging"
+	"github.com/zoninnik89/messenger/facade-service/internal/ratelimit"
+)
+
+// KeyFunc names the bucket the request takes a token from.
+type KeyFunc func(r *http.Request) ratelimit.Key
+
+// New returns middleware which takes a token from the bucket of every key of the request. Requests are
+// rejected with 429 status and "Retry-After" header once a bucket runs out of tokens.
+func New(limiter *ratelimit.Limiter, keys ...KeyFunc) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			const op = "middleware.throttle.New"
+
+			requestKeys := make([]ratelimit.Key, 0, len(keys))
+			for _, key := range keys {
+				requestKeys = append(requestKeys, key(r))
+			}
+
+			res := limiter.Allow(r.Context(), requestKeys...)
+			if !res.Allowed {
+				logging.GetLogger().Sugar().Infow(
+					"request rate limited",
+					"op", op,
+					"request_id", middleware.GetReqID(r.Context()),
+					"scope", res.Scope,
+					"path", r.URL.Path,
+					"retry_after", res.RetryAfter,
+				)
+
+				// the header is in whole seconds, rounded up so clients don't retry too early
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(res.RetryAfter.Seconds()))))
+				render.Status(r, http.StatusTooManyRequests)
+				render.JSON(w, r, response.Error("rate limited"))
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// IP keys the request by IP of the client in the scope, like clientip middleware it must be used after
+// clientip.RealIP.
+func IP(scope ratelimit.Scope) KeyFunc {
+	return func(r *http.Request) ratelimit.Key {
+		return ratelimit.Key{Scope: scope, ID: clientip.FromRequest(r)}
+	}
+}
+
+// User keys the request by the user authenticated by auth middleware.
+func User(r *http.Request) ratelimit.Key {
+	return ratelimit.Key{Scope: ratelimit.ScopeUser, ID: auth.UserID(r.Context())}
+}
+
+// Chat keys the request by the chat given in {chatID} URL parameter.
+func Chat(r *http.Request) ratelimit.Key {
+	return ratelimit.Key{Scope: ratelimit.ScopeChat, ID: chi.URLParam(r, "chatID")}
+}
@@ -0,0 +1,57 @@
+package tracing
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/zoninnik89/messenger/common/telemetry"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName names the tracer of spans of requests
+const instrumentationName = "github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/tracing"
+
+// New returns middleware which traces requests, continuing traces the clients sent in "traceparent" header.
+// The request ID is passed along with the trace to services called with the request context.
+//
+// It must be used after middleware.RequestID. Spans are named by the route pattern, so a span of a websocket
+// connection lasts as long as the connection, its messages are traced separately.
+func New() func(next http.Handler) http.Handler {
+	tracer := telemetry.Tracer(instrumentationName)
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ctx := telemetry.FromHTTP(r.Context(), r.Header)
+			ctx = telemetry.WithRequestID(ctx, middleware.GetReqID(ctx))
+
+			ctx, span := tracer.Start(ctx, r.Method,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(semconv.HTTPRequestMethodKey.String(r.Method), semconv.URLPath(r.URL.Path)),
+			)
+			defer span.End()
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r.WithContext(ctx))
+
+			// the pattern is known once the request is routed
+			if rctx := chi.RouteContext(ctx); rctx != nil && rctx.RoutePattern() != "" {
+				span.SetName(r.Method + " " + rctx.RoutePattern())
+				span.SetAttributes(semconv.HTTPRoute(rctx.RoutePattern()))
+			}
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,17 @@
+package openapi
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// Spec is the OpenAPI 3 document of the facade HTTP API, including schemas of websocket frames.
+//
+//go:embed openapi.json
+var Spec []byte
+
+// Handler serves the OpenAPI document.
+func Handler(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(Spec)
+}
@@ -0,0 +1,182 @@
+package router
+
+import (
+	"net/http"
+	"net/netip"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/cors"
+	pb "github.com/zoninnik89/messenger/common/api"
+	"github.com/zoninnik89/messenger/facade-service/internal/audit"
+	"github.com/zoninnik89/messenger/facade-service/internal/config"
+	connectserver "github.com/zoninnik89/messenger/facade-service/internal/connect-server"
+	grpcgateway "github.com/zoninnik89/messenger/facade-service/internal/gateway"
+	deleteaccount "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/account/delete-account"
+	exportdata "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/account/export-data"
+	forgotpassword "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/auth/forgot-password"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/auth/login"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/auth/oidc"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/auth/register"
+	resendverification "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/auth/resend-verification"
+	resetpassword "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/auth/reset-password"
+	verifyemail "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/auth/verify-email"
+	addparticipants "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/chat/add-participants"
+	creategroup "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/chat/create-group"
+	getmessages "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/chat/get-messages"
+	getmessagesstream "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/chat/get-messages-stream"
+	leavechat "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/chat/leave-chat"
+	listchats "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/chat/list-chats"
+	removeparticipant "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/chat/remove-participant"
+	renamechat "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/chat/rename-chat"
+	sendmessage "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/chat/send-message"
+	setchatavatar "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/chat/set-chat-avatar"
+	confirmtotp "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/mfa/confirm-totp"
+	disabletotp "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/mfa/disable-totp"
+	enrolltotp "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/mfa/enroll-totp"
+	addrelation "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/relations/add-relation"
+	listrelations "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/relations/list-relations"
+	removerelation "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/relations/remove-relation"
+	listsessions "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/sessions/list-sessions"
+	revokesession "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/sessions/revoke-session"
+	wsticket "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/sessions/ws-ticket"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/auth"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/clientip"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/origin"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/throttle"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/tracing"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/openapi"
+	"github.com/zoninnik89/messenger/facade-service/internal/lib/token"
+	"github.com/zoninnik89/messenger/facade-service/internal/ratelimit"
+	"github.com/zoninnik89/messenger/facade-service/internal/sessions"
+	"github.com/zoninnik89/messenger/facade-service/internal/tickets"
+	websocketserver "github.com/zoninnik89/messenger/facade-service/internal/websocket-server"
+)
+
+var (
+	// connectRequestHeaders are sent by Connect and gRPC-Web clients
+	connectRequestHeaders = []string{"Connect-Protocol-Version", "Connect-Timeout-Ms", "Grpc-Timeout", "X-Grpc-Web", "X-User-Agent"}
+	// connectResponseHeaders are read by Connect and gRPC-Web clients, Message-Id is set by SendMessage
+	connectResponseHeaders = []string{"Grpc-Status", "Grpc-Message", "Grpc-Status-Details-Bin", "Message-Id"}
+	// traceHeaders let web clients continue their traces in the facade
+	traceHeaders = []string{"Traceparent", "Tracestate"}
+)
+
+// New returns router of the facade HTTP API.
+//
+// Every route must be described in the OpenAPI document served at /openapi.json,
+// the tests of this package fail if the router and the document drift apart.
+func New(
+	cfg *config.Config,
+	gateway *grpcgateway.Gateway,
+	tokens *token.Parser,
+	tracker *sessions.Tracker,
+	auditRecorder *audit.Recorder,
+	wsServer *websocketserver.WebsocketServer,
+	limiter *ratelimit.Limiter,
+	wsTickets *tickets.Tickets,
+	trustedProxies []netip.Prefix,
+) *chi.Mux {
+	router := chi.NewRouter()
+	router.Use(middleware.RequestID)
+	router.Use(clientip.RealIP(trustedProxies))
+	router.Use(clientip.New())
+	router.Use(tracing.New())
+	router.Use(middleware.Logger)
+	router.Use(middleware.Recoverer)
+
+	origins := origin.Allowlist(cfg.CORS.AllowedOrigins)
+
+	// Basic CORS configuration
+	router.Use(cors.Handler(cors.Options{
+		AllowOriginFunc: func(_ *http.Request, requestOrigin string) bool {
+			return origins.Allowed(requestOrigin)
+		},
+		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}, // Allow specific methods
+		AllowedHeaders: append(
+			[]string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "auth_token", clientip.DeviceNameHeader},
+			append(connectRequestHeaders, traceHeaders...)...,
+		),
+		ExposedHeaders:   append([]string{"Link"}, connectResponseHeaders...),
+		AllowCredentials: true, // Allow cookies to be sent
+		MaxAge:           300,  // Maximum value for the preflight request cache
+	}))
+	router.Use(origin.New(origins))
+
+	// limited after CORS headers are set, so browsers let the web client read rejections
+	router.Use(throttle.New(limiter, throttle.IP(ratelimit.ScopeIP)))
+
+	router.Get("/openapi.json", openapi.Handler)
+
+	router.Group(func(r chi.Router) {
+		r.Use(throttle.New(limiter, throttle.IP(ratelimit.ScopeLogin)))
+
+		r.Post("/login", login.New(gateway, cfg.Auth.AppID))
+		r.Post("/login/mfa", login.NewMFA(gateway))
+		r.Post("/register", register.New(gateway))
+		r.Post("/verify-email", verifyemail.New(gateway))
+		r.Post("/verify-email/resend", resendverification.New(gateway))
+		r.Post("/password/forgot", forgotpassword.New(gateway))
+		r.Post("/password/reset", resetpassword.New(gateway))
+	})
+
+	router.Get("/oauth/{provider}/login", oidc.New(gateway, cfg.Auth.AppID))
+	router.Get("/oauth/{provider}/callback", oidc.NewCallback(gateway, cfg.OIDC.AfterLoginURL))
+
+	router.Group(func(r chi.Router) {
+		r.Use(auth.New(tokens, tracker, auditRecorder))
+
+		r.Group(func(r chi.Router) {
+			r.Use(auth.RequireScope(token.ScopeAccount, auditRecorder))
+
+			r.Get("/relations", listrelations.New(gateway))
+			r.Put("/relations/blocks/{userID}", addrelation.New(gateway, pb.RelationKind_RELATION_KIND_BLOCK))
+			r.Delete("/relations/blocks/{userID}", removerelation.New(gateway, pb.RelationKind_RELATION_KIND_BLOCK))
+			r.Put("/relations/mutes/{userID}", addrelation.New(gateway, pb.RelationKind_RELATION_KIND_MUTE))
+			r.Delete("/relations/mutes/{userID}", removerelation.New(gateway, pb.RelationKind_RELATION_KIND_MUTE))
+
+			r.Post("/mfa/totp", enrolltotp.New(gateway))
+			r.Post("/mfa/totp/confirm", confirmtotp.New(gateway))
+			r.Post("/mfa/totp/disable", disabletotp.New(gateway))
+
+			r.Delete("/account", deleteaccount.New(gateway))
+			r.Get("/account/export", exportdata.New(gateway, auditRecorder))
+
+			r.Post("/oauth/{provider}/link", oidc.NewLink(gateway, cfg.Auth.AppID))
+
+			r.Get("/sessions", listsessions.New(gateway))
+			r.Delete("/sessions/{sessionID}", revokesession.New(gateway, tracker))
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(auth.RequireScope(token.ScopeChatRead, auditRecorder))
+
+			r.Get("/chats", listchats.New(gateway))
+			r.Get("/chats/{chatID}/messages", getmessages.New(gateway))
+			r.Get("/stream", getmessagesstream.New(gateway, tracker))
+
+			r.Post("/ws/ticket", wsticket.New(wsTickets))
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(auth.RequireScope(token.ScopeChatWrite, auditRecorder))
+
+			r.Post("/chats", creategroup.New(gateway))
+			r.Put("/chats/{chatID}/name", renamechat.New(gateway))
+			r.Put("/chats/{chatID}/avatar", setchatavatar.New(gateway))
+			r.Post("/chats/{chatID}/participants", addparticipants.New(gateway))
+			r.Delete("/chats/{chatID}/participants/{userID}", removeparticipant.New(gateway))
+			r.Post("/chats/{chatID}/leave", leavechat.New(gateway))
+			r.With(throttle.New(limiter, throttle.User, throttle.Chat)).Post("/chats/{chatID}/messages", sendmessage.New(gateway))
+		})
+
+		// procedures of messenger.proto for the web client generated from it
+		for procedure, handler := range connectserver.New(gateway, tracker, limiter).Handlers() {
+			r.With(auth.RequireScope(connectserver.Scope(procedure), auditRecorder)).Method(http.MethodPost, procedure, handler)
+		}
+	})
+
+	router.Get("/ws", wsServer.ServeHTTP)
+
+	return router
+}
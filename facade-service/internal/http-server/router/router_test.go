@@ -0,0 +1,448 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/golang-jwt/jwt/v5"
+	pb "github.com/zoninnik89/messenger/common/api"
+	"github.com/zoninnik89/messenger/facade-service/internal/audit"
+	"github.com/zoninnik89/messenger/facade-service/internal/config"
+	grpcgateway "github.com/zoninnik89/messenger/facade-service/internal/gateway"
+	deleteaccount "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/account/delete-account"
+	forgotpassword "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/auth/forgot-password"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/auth/login"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/auth/oidc"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/auth/register"
+	resendverification "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/auth/resend-verification"
+	resetpassword "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/auth/reset-password"
+	verifyemail "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/auth/verify-email"
+	addparticipants "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/chat/add-participants"
+	creategroup "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/chat/create-group"
+	getmessages "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/chat/get-messages"
+	listchats "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/chat/list-chats"
+	renamechat "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/chat/rename-chat"
+	sendmessage "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/chat/send-message"
+	setchatavatar "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/chat/set-chat-avatar"
+	confirmtotp "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/mfa/confirm-totp"
+	disabletotp "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/mfa/disable-totp"
+	enrolltotp "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/mfa/enroll-totp"
+	listrelations "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/relations/list-relations"
+	listsessions "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/sessions/list-sessions"
+	wsticket "github.com/zoninnik89/messenger/facade-service/internal/http-server/handlers/sessions/ws-ticket"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/origin"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/openapi"
+	"github.com/zoninnik89/messenger/facade-service/internal/lib/response"
+	"github.com/zoninnik89/messenger/facade-service/internal/lib/token"
+	"github.com/zoninnik89/messenger/facade-service/internal/ratelimit"
+	"github.com/zoninnik89/messenger/facade-service/internal/sessions"
+	"github.com/zoninnik89/messenger/facade-service/internal/tickets"
+	websocketserver "github.com/zoninnik89/messenger/facade-service/internal/websocket-server"
+)
+
+// schemaTypes maps schemas of the document to types encoded or decoded by the handlers.
+var schemaTypes = map[string][]any{
+	"Response":                  {response.Response{}},
+	"LoginRequest":              {login.Request{}},
+	"LoginResponse":             {login.Response{}},
+	"MFALoginRequest":           {login.MFARequest{}},
+	"RegisterRequest":           {register.Request{}},
+	"RegisterResponse":          {register.Response{}},
+	"VerifyEmailRequest":        {verifyemail.Request{}},
+	"VerifyEmailResponse":       {verifyemail.Response{}},
+	"ResendVerificationRequest": {resendverification.Request{}},
+	"ForgotPasswordRequest":     {forgotpassword.Request{}},
+	"ResetPasswordRequest":      {resetpassword.Request{}},
+	"DeleteAccountRequest":      {deleteaccount.Request{}},
+	"EnrollTOTPResponse":        {enrolltotp.Response{}},
+	"ConfirmTOTPRequest":        {confirmtotp.Request{}},
+	"ConfirmTOTPResponse":       {confirmtotp.Response{}},
+	"DisableTOTPRequest":        {disabletotp.Request{}},
+	"LinkProviderResponse":      {oidc.LinkResponse{}},
+	"Relation":                  {listrelations.Relation{}},
+	"RelationsResponse":         {listrelations.Response{}},
+	"Session":                   {listsessions.Session{}},
+	"SessionsResponse":          {listsessions.Response{}},
+	"ChatMessage":               {listchats.Message{}, getmessages.Message{}},
+	"Chat":                      {listchats.Chat{}},
+	"ChatsResponse":             {listchats.Response{}},
+	"CreateGroupRequest":        {creategroup.Request{}},
+	"CreateGroupResponse":       {creategroup.Response{}},
+	"RenameChatRequest":         {renamechat.Request{}},
+	"SetChatAvatarRequest":      {setchatavatar.Request{}},
+	"AddParticipantsRequest":    {addparticipants.Request{}},
+	"MessagesResponse":          {getmessages.Response{}},
+	"SendMessageRequest":        {sendmessage.Request{}},
+	"SendMessageResponse":       {sendmessage.Response{}},
+	"Message":                   {pb.Message{}},
+	"WebsocketSendFrame":        {websocketserver.Message{}},
+	"WebsocketRateLimitedFrame": {websocketserver.RateLimitedFrame{}},
+	"WebsocketTicketResponse":   {wsticket.Response{}},
+}
+
+type document struct {
+	Paths      map[string]map[string]operation `json:"paths"`
+	Components struct {
+		Schemas map[string]*schema `json:"schemas"`
+	} `json:"components"`
+}
+
+type operation struct {
+	Parameters []parameter    `json:"parameters"`
+	Responses  map[string]any `json:"responses"`
+}
+
+type parameter struct {
+	Name string `json:"name"`
+	In   string `json:"in"`
+}
+
+type schema struct {
+	Ref        string             `json:"$ref"`
+	AllOf      []*schema          `json:"allOf"`
+	Properties map[string]*schema `json:"properties"`
+	Required   []string           `json:"required"`
+}
+
+func TestRouter_MatchesSpec(t *testing.T) {
+	doc := loadSpec(t)
+
+	var routes []string
+	err := chi.Walk(newTestRouter(t), func(method string, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		routes = append(routes, method+" "+route)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to walk router: %v", err)
+	}
+
+	var documented []string
+	for path, operations := range doc.Paths {
+		for method := range operations {
+			documented = append(documented, strings.ToUpper(method)+" "+path)
+		}
+	}
+
+	for _, route := range routes {
+		if !slices.Contains(documented, route) {
+			t.Errorf("route %q is not documented", route)
+		}
+	}
+
+	for _, route := range documented {
+		if !slices.Contains(routes, route) {
+			t.Errorf("documented route %q is not routed", route)
+		}
+	}
+}
+
+func TestRouter_ServesSpec(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+	rec := httptest.NewRecorder()
+
+	newTestRouter(t).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected JSON content type, got %q", ct)
+	}
+	if rec.Body.String() != string(openapi.Spec) {
+		t.Errorf("served document differs from the embedded one")
+	}
+}
+
+func TestSpec_PathParametersDeclared(t *testing.T) {
+	doc := loadSpec(t)
+	placeholder := regexp.MustCompile(`\{([^}]+)\}`)
+
+	for path, operations := range doc.Paths {
+		for method, op := range operations {
+			for _, match := range placeholder.FindAllStringSubmatch(path, -1) {
+				declared := slices.ContainsFunc(op.Parameters, func(p parameter) bool {
+					return p.In == "path" && p.Name == match[1]
+				})
+				if !declared {
+					t.Errorf("%s %s doesn't declare path parameter %q", method, path, match[1])
+				}
+			}
+
+			if len(op.Responses) == 0 {
+				t.Errorf("%s %s has no responses", method, path)
+			}
+		}
+	}
+}
+
+func TestSpec_RefsResolve(t *testing.T) {
+	var raw any
+	if err := json.Unmarshal(openapi.Spec, &raw); err != nil {
+		t.Fatalf("invalid document: %v", err)
+	}
+
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			if ref, ok := v["$ref"].(string); ok && !resolves(raw, ref) {
+				t.Errorf("unresolved reference %q", ref)
+			}
+			for _, child := range v {
+				walk(child)
+			}
+		case []any:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+
+	walk(raw)
+}
+
+func TestSpec_SchemasMatchTypes(t *testing.T) {
+	doc := loadSpec(t)
+
+	for name, values := range schemaTypes {
+		s, ok := doc.Components.Schemas[name]
+		if !ok {
+			t.Errorf("schema %s is not documented", name)
+			continue
+		}
+
+		properties, required := flatten(doc, s)
+
+		for _, value := range values {
+			typ := reflect.TypeOf(value)
+			fields, validated := jsonFields(typ)
+
+			if !equal(fields, properties) {
+				t.Errorf("schema %s has properties %v, %s has fields %v", name, properties, typ, fields)
+			}
+
+			for _, field := range validated {
+				if !slices.Contains(required, field) {
+					t.Errorf("field %s of %s is required, but not in schema %s", field, typ, name)
+				}
+			}
+		}
+	}
+}
+
+func TestRouter_ChecksOrigin(t *testing.T) {
+	cfg := &config.Config{CORS: config.CORSConfig{AllowedOrigins: []string{"https://messenger.example.com"}}}
+	router := newTestRouterWithConfig(t, cfg)
+
+	tests := []struct {
+		name       string
+		origin     string
+		wantStatus int
+		wantCORS   bool
+	}{
+		{name: "allowed origin", origin: "https://messenger.example.com", wantStatus: http.StatusOK, wantCORS: true},
+		{name: "allowed origin in other case", origin: "https://Messenger.Example.com", wantStatus: http.StatusOK, wantCORS: true},
+		{name: "other origin", origin: "https://evil.example.com", wantStatus: http.StatusForbidden},
+		{name: "no origin", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Origin") != ""; got != tt.wantCORS {
+				t.Errorf("expected CORS headers %v, got %v", tt.wantCORS, got)
+			}
+		})
+	}
+
+	// websocket upgrades from other origins are rejected before authentication
+	req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected websocket upgrade to be forbidden, got %d", rec.Code)
+	}
+}
+
+func newTestRouter(t *testing.T) *chi.Mux {
+	t.Helper()
+
+	return newTestRouterWithConfig(t, &config.Config{})
+}
+
+func newTestRouterWithConfig(t *testing.T, cfg *config.Config) *chi.Mux {
+	t.Helper()
+
+	gateway := grpcgateway.NewGRPCGateway(nil)
+	tokens := token.NewParser(1, "test-secret")
+	tracker := sessions.NewTracker(gateway, time.Minute)
+
+	recorder, err := audit.New("log", "", "facade-test")
+	if err != nil {
+		t.Fatalf("failed to create audit recorder: %v", err)
+	}
+
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), nil)
+	wsTickets := tickets.NewTickets(tickets.NewMemoryStore(), time.Minute)
+
+	return New(
+		cfg,
+		gateway,
+		tokens,
+		tracker,
+		recorder,
+		websocketserver.NewWebsocketServer(gateway, tokens, tracker, limiter, origin.Allowlist(cfg.CORS.AllowedOrigins), wsTickets, cfg.Websocket),
+		limiter,
+		wsTickets,
+		nil,
+	)
+}
+
+func loadSpec(t *testing.T) document {
+	t.Helper()
+
+	var doc document
+	if err := json.Unmarshal(openapi.Spec, &doc); err != nil {
+		t.Fatalf("invalid document: %v", err)
+	}
+
+	return doc
+}
+
+// resolves reports whether the local reference points to a value of the document
+func resolves(doc any, ref string) bool {
+	if !strings.HasPrefix(ref, "#/") {
+		return false
+	}
+
+	node := doc
+	for _, key := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		m, ok := node.(map[string]any)
+		if !ok {
+			return false
+		}
+		if node, ok = m[key]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// flatten returns names of properties and required properties of the schema, including those of allOf schemas
+func flatten(doc document, s *schema) (properties []string, required []string) {
+	if s.Ref != "" {
+		return flatten(doc, doc.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")])
+	}
+
+	for _, sub := range s.AllOf {
+		subProperties, subRequired := flatten(doc, sub)
+		properties = append(properties, subProperties...)
+		required = append(required, subRequired...)
+	}
+
+	for name := range s.Properties {
+		properties = append(properties, name)
+	}
+
+	return properties, append(required, s.Required...)
+}
+
+// jsonFields returns JSON names of fields of the struct, including embedded ones, and names of fields
+// validated as required
+func jsonFields(typ reflect.Type) (fields []string, validated []string) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+
+		if field.Anonymous {
+			embedded, embeddedValidated := jsonFields(field.Type)
+			fields = append(fields, embedded...)
+			validated = append(validated, embeddedValidated...)
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || name == "" || name == "-" {
+			continue
+		}
+
+		fields = append(fields, name)
+
+		if slices.Contains(strings.Split(field.Tag.Get("validate"), ","), "required") {
+			validated = append(validated, name)
+		}
+	}
+
+	return fields, validated
+}
+
+func equal(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	sort.Strings(a)
+	sort.Strings(b)
+
+	return slices.Equal(a, b)
+}
+
+func TestRouter_ChecksScopes(t *testing.T) {
+	router := newTestRouter(t)
+
+	readOnly, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"uid":    "user",
+		"app_id": 1,
+		"exp":    time.Now().Add(time.Minute).Unix(),
+		"scope":  token.ScopeChatRead,
+	}).SignedString([]byte("test-secret"))
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{name: "granted scope", method: http.MethodPost, path: "/ws/ticket", wantStatus: http.StatusOK},
+		{name: "chat write", method: http.MethodPost, path: "/chats/1/messages", wantStatus: http.StatusForbidden},
+		{name: "account", method: http.MethodGet, path: "/relations", wantStatus: http.StatusForbidden},
+		{name: "connect procedure", method: http.MethodPost, path: pb.ChatClientService_SendMessage_FullMethodName, wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader("{}"))
+			req.Header.Set("Authorization", "Bearer "+readOnly)
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body)
+			}
+		})
+	}
+}
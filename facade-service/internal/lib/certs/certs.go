@@ -0,0 +1,79 @@
+// Package certs keeps the TLS certificate of the facade, which can be reloaded from its files while the
+// server runs.
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/zoninnik89/messenger/facade-service/internal/logging"
+)
+
+// Reloader serves the certificate last loaded from the files to TLS handshakes.
+type Reloader struct {
+	certFile string
+	keyFile  string
+
+	cert atomic.Pointer[tls.Certificate]
+}
+
+// NewReloader loads the certificate and its key from PEM files.
+func NewReloader(certFile string, keyFile string) (*Reloader, error) {
+	const op = "certs.NewReloader"
+
+	r := &Reloader{certFile: certFile, keyFile: keyFile}
+	if err := r.Reload(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return r, nil
+}
+
+// Reload loads the files again, new handshakes use the loaded certificate. If loading fails, the previous
+// certificate is kept.
+func (r *Reloader) Reload() error {
+	const op = "certs.Reload"
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	r.cert.Store(&cert)
+
+	return nil
+}
+
+// GetCertificate returns the loaded certificate, it is meant for tls.Config.GetCertificate.
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.cert.Load(), nil
+}
+
+// ReloadOnSIGHUP reloads the files every time the process receives SIGHUP, until the context is done.
+func (r *Reloader) ReloadOnSIGHUP(ctx context.Context) {
+	const op = "certs.ReloadOnSIGHUP"
+	logger := logging.GetLogger().Sugar()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case <-signals:
+			if err := r.Reload(); err != nil {
+				logger.Errorw("failed to reload TLS certificate, the previous one is kept", "op", op, "error", err)
+				continue
+			}
+
+			logger.Infow("TLS certificate reloaded", "op", op, "certFile", r.certFile)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,75 @@
+// Package export builds archives with personal data of a user.
+package export
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+type Profile struct {
+	UserID        string     `json:"user_id"`
+	Login         string     `json:"login"`
+	EmailVerified bool       `json:"email_verified"`
+	MFAEnabled    bool       `json:"mfa_enabled"`
+	Relations     []Relation `json:"relations"`
+}
+
+type Relation struct {
+	UserID    string `json:"user_id"`
+	Kind      string `json:"kind"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+type Message struct {
+	ChatID      string `json:"chat_id"`
+	MessageID   string `json:"message_id"`
+	MessageText string `json:"message_text"`
+	SentTS      string `json:"sent_ts"`
+}
+
+// Archive is the content of the export, every field is written to its own JSON file.
+type Archive struct {
+	Profile  Profile
+	Messages []Message
+}
+
+// WriteZip writes the archive as a zip with "profile.json" and "messages.json" files.
+func WriteZip(w io.Writer, archive Archive, createdAt time.Time) error {
+	const op = "export.WriteZip"
+
+	zw := zip.NewWriter(w)
+
+	files := []struct {
+		name    string
+		content any
+	}{
+		{name: "profile.json", content: archive.Profile},
+		{name: "messages.json", content: archive.Messages},
+	}
+
+	for _, file := range files {
+		fw, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     file.name,
+			Method:   zip.Deflate,
+			Modified: createdAt,
+		})
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		enc := json.NewEncoder(fw)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(file.content); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWriteZip(t *testing.T) {
+	archive := Archive{
+		Profile: Profile{
+			UserID:        "user",
+			Login:         "user@example.com",
+			EmailVerified: true,
+			Relations:     []Relation{{UserID: "other", Kind: "block", CreatedAt: 1700000000}},
+		},
+		Messages: []Message{
+			{ChatID: "chat", MessageID: "message-1", MessageText: "hello", SentTS: "1700000001"},
+			{ChatID: "chat", MessageID: "message-2", MessageText: "bye", SentTS: "1700000002"},
+		},
+	}
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	if err := WriteZip(&buf, archive, createdAt); err != nil {
+		t.Fatalf("failed to write zip: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("invalid zip: %v", err)
+	}
+
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+
+		if !f.Modified.Equal(createdAt) {
+			t.Errorf("expected %s to be modified at %v, got %v", f.Name, createdAt, f.Modified)
+		}
+	}
+	if want := []string{"profile.json", "messages.json"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("expected entries %v, got %v", want, names)
+	}
+
+	var profile Profile
+	readJSON(t, zr.File[0], &profile)
+	if !reflect.DeepEqual(profile, archive.Profile) {
+		t.Errorf("expected profile %+v, got %+v", archive.Profile, profile)
+	}
+
+	var messages []Message
+	readJSON(t, zr.File[1], &messages)
+	if !reflect.DeepEqual(messages, archive.Messages) {
+		t.Errorf("expected messages %+v, got %+v", archive.Messages, messages)
+	}
+}
+
+func TestWriteZip_NoMessages(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteZip(&buf, Archive{Profile: Profile{UserID: "user"}, Messages: []Message{}}, time.Now()); err != nil {
+		t.Fatalf("failed to write zip: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("invalid zip: %v", err)
+	}
+
+	rc, err := zr.File[1].Open()
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", zr.File[1].Name, err)
+	}
+	defer rc.Close()
+
+	content, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", zr.File[1].Name, err)
+	}
+
+	// clients get an empty list rather than null
+	if got := string(bytes.TrimSpace(content)); got != "[]" {
+		t.Errorf("expected empty list, got %s", got)
+	}
+}
+
+func readJSON(t *testing.T, f *zip.File, v any) {
+	t.Helper()
+
+	rc, err := f.Open()
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", f.Name, err)
+	}
+	defer rc.Close()
+
+	if err := json.NewDecoder(rc).Decode(v); err != nil {
+		t.Fatalf("invalid %s: %v", f.Name, err)
+	}
+}
@@ -0,0 +1,105 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// Scopes of the facade API an app may be registered with in sso service.
+const (
+	// ScopeChatRead allows reading chats and receiving messages
+	ScopeChatRead = "chat:read"
+	// ScopeChatWrite allows sending messages and managing chats
+	ScopeChatWrite = "chat:write"
+	// ScopeAccount allows managing the account, its sessions and relations to other users
+	ScopeAccount = "account"
+)
+
+// Parser validates JWT tokens issued by sso service for the facade app.
+type Parser struct {
+	appID  int
+	secret []byte
+}
+
+// NewParser returns parser of tokens issued for the app with the given ID and signed with its secret.
+// The secret must be updated whenever it is rotated in sso service.
+func NewParser(appID int, secret string) *Parser {
+	return &Parser{
+		appID:  appID,
+		secret: []byte(secret),
+	}
+}
+
+// Claims are claims of a valid token the facade relies on.
+type Claims struct {
+	UserID string
+	// SessionID is the sso session the token was issued for, empty for tokens issued before sessions were tracked
+	SessionID string
+	// Scopes are scopes of the app the token was issued for, nil if the app was registered without scopes
+	Scopes []string
+}
+
+// Allows reports whether a token with the given scopes may be used for the scope. Tokens of apps registered
+// without scopes carry no scope claim and aren't restricted.
+func Allows(scopes []string, scope string) bool {
+	return scopes == nil || slices.Contains(scopes, scope)
+}
+
+// ParseUserID validates the token and returns ID of the user it was issued to.
+func (p *Parser) ParseUserID(tokenString string) (string, error) {
+	claims, err := p.Parse(tokenString)
+	if err != nil {
+		return "", err
+	}
+
+	return claims.UserID, nil
+}
+
+// Parse validates the token and returns its claims.
+func (p *Parser) Parse(tokenString string) (Claims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		// Check the signing method and provide the secret key
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return p.secret, nil
+	})
+
+	if err != nil {
+		return Claims{}, err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return Claims{}, ErrInvalidToken
+	}
+
+	// numbers in JSON claims are decoded as float64
+	if appID, ok := claims["app_id"].(float64); !ok || int(appID) != p.appID {
+		return Claims{}, ErrInvalidToken
+	}
+
+	userID, ok := claims["uid"].(string)
+	if !ok || userID == "" {
+		return Claims{}, ErrInvalidToken
+	}
+
+	sessionID, _ := claims["sid"].(string)
+
+	// scopes are space separated, an empty claim restricts the token to no scope at all
+	var scopes []string
+	if scope, ok := claims["scope"].(string); ok {
+		scopes = strings.Fields(scope)
+		if scopes == nil {
+			scopes = []string{}
+		}
+	}
+
+	return Claims{UserID: userID, SessionID: sessionID, Scopes: scopes}, nil
+}
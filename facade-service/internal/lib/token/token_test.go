@@ -0,0 +1,95 @@
+package token
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testAppID  = 1
+	testSecret = "test-secret"
+)
+
+func sign(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+
+	tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+
+	return tokenString
+}
+
+func TestParser_ParseScopes(t *testing.T) {
+	tests := []struct {
+		name  string
+		scope any
+		want  []string
+	}{
+		{name: "app without scopes", scope: nil, want: nil},
+		{name: "app with scopes", scope: "chat:read account", want: []string{"chat:read", "account"}},
+		{name: "empty scope claim", scope: "", want: []string{}},
+	}
+
+	parser := NewParser(testAppID, testSecret)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := jwt.MapClaims{
+				"uid":    "user",
+				"sid":    "session",
+				"app_id": testAppID,
+				"exp":    time.Now().Add(time.Minute).Unix(),
+			}
+			if tt.scope != nil {
+				claims["scope"] = tt.scope
+			}
+
+			got, err := parser.Parse(sign(t, claims))
+			if err != nil {
+				t.Fatalf("failed to parse token: %v", err)
+			}
+
+			if !reflect.DeepEqual(got.Scopes, tt.want) {
+				t.Errorf("expected scopes %#v, got %#v", tt.want, got.Scopes)
+			}
+		})
+	}
+}
+
+func TestAllows(t *testing.T) {
+	tests := []struct {
+		name   string
+		scopes []string
+		want   bool
+	}{
+		{name: "unrestricted", scopes: nil, want: true},
+		{name: "granted", scopes: []string{ScopeChatRead, ScopeChatWrite}, want: true},
+		{name: "not granted", scopes: []string{ScopeChatRead}, want: false},
+		{name: "no scopes", scopes: []string{}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Allows(tt.scopes, ScopeChatWrite); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParser_RejectsTokenOfOtherApp(t *testing.T) {
+	tokenString := sign(t, jwt.MapClaims{
+		"uid":    "user",
+		"app_id": testAppID + 1,
+		"exp":    time.Now().Add(time.Minute).Unix(),
+	})
+
+	if _, err := NewParser(testAppID, testSecret).Parse(tokenString); err == nil {
+		t.Error("expected token of other app to be rejected")
+	}
+}
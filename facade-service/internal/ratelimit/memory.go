@@ -0,0 +1,92 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often buckets which were refilled to capacity are dropped, a full bucket
+// behaves the same as a missing one.
+const sweepInterval = time.Minute
+
+type bucket struct {
+	limit   Limit
+	tokens  float64
+	updated time.Time
+}
+
+// refill adds tokens for the time passed since the bucket was updated.
+func (b *bucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.updated); elapsed > 0 {
+		b.tokens = math.Min(float64(b.limit.burst()), b.tokens+float64(elapsed)/float64(b.limit.interval()))
+	}
+	b.updated = now
+}
+
+// MemoryStore keeps the buckets in memory, so every instance limits clients on its own.
+type MemoryStore struct {
+	// now returns current time, tests replace it
+	now func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		now:       time.Now,
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+func (s *MemoryStore) Take(_ context.Context, buckets ...Bucket) (Result, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	taken := make([]*bucket, 0, len(buckets))
+	for i, bk := range buckets {
+		b, ok := s.buckets[bk.Key]
+		if !ok {
+			b = &bucket{tokens: float64(bk.Limit.burst()), updated: now}
+			s.buckets[bk.Key] = b
+		}
+
+		// the bucket remembers the limit, so that the sweep refills it at the same rate
+		b.limit = bk.Limit
+		b.refill(now)
+
+		if b.tokens < 1 {
+			return Result{Allowed: false, RetryAfter: time.Duration(math.Ceil((1 - b.tokens) * float64(bk.Limit.interval())))}, i, nil
+		}
+
+		taken = append(taken, b)
+	}
+
+	for _, b := range taken {
+		b.tokens--
+	}
+
+	return Result{Allowed: true}, -1, nil
+}
+
+// sweep drops buckets refilled to capacity, so buckets of clients which went away don't pile up.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		b.refill(now)
+		if b.tokens >= float64(b.limit.burst()) {
+			delete(s.buckets, key)
+		}
+	}
+}
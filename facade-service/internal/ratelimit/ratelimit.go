@@ -0,0 +1,168 @@
+// Package ratelimit limits how often clients send messages and make requests with token buckets, kept either
+// in memory of the instance or in Redis shared by instances of the facade.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/zoninnik89/messenger/facade-service/internal/config"
+	"github.com/zoninnik89/messenger/facade-service/internal/logging"
+	"go.uber.org/zap"
+)
+
+// Scope is what a bucket limits, every scope has its own limit and buckets of its keys.
+type Scope string
+
+const (
+	// ScopeUser limits messages sent by a user, keyed by user ID
+	ScopeUser Scope = "user"
+	// ScopeChat limits messages sent to a chat, keyed by chat ID
+	ScopeChat Scope = "chat"
+	// ScopeIP limits requests and messages of a client, keyed by IP
+	ScopeIP Scope = "ip"
+	// ScopeLogin limits logins and other attempts to authenticate of a client, keyed by IP
+	ScopeLogin Scope = "login"
+)
+
+// Limit is a token bucket which holds up to Burst tokens and is refilled with Rate tokens every Period.
+type Limit struct {
+	Rate   int
+	Period time.Duration
+	Burst  int
+}
+
+// Disabled reports whether the limit lets everything through.
+func (l Limit) Disabled() bool {
+	return l.Rate <= 0 || l.Period <= 0
+}
+
+// interval returns how often a token is added to the bucket.
+func (l Limit) interval() time.Duration {
+	return l.Period / time.Duration(l.Rate)
+}
+
+// burst returns the capacity of the bucket, which holds at least one token.
+func (l Limit) burst() int {
+	return max(l.Burst, 1)
+}
+
+// Result tells whether a token was taken from the bucket, and if not, how long until the bucket has one.
+type Result struct {
+	Allowed    bool
+	RetryAfter time.Duration
+	// Scope is the scope of the bucket which denied the token
+	Scope Scope
+}
+
+// Bucket is the bucket kept under the key, which is refilled according to the limit.
+type Bucket struct {
+	Key   string
+	Limit Limit
+}
+
+// Store keeps the buckets. Take takes a token from every bucket only if all of them have one, otherwise
+// nothing is taken and the result of the first bucket without tokens is returned with its index.
+type Store interface {
+	Take(ctx context.Context, buckets ...Bucket) (Result, int, error)
+}
+
+// Key names the bucket of the ID in the scope.
+type Key struct {
+	Scope Scope
+	ID    string
+}
+
+// Limiter takes tokens from buckets of the scopes according to their limits.
+type Limiter struct {
+	logger *zap.SugaredLogger
+	store  Store
+	limits map[Scope]Limit
+}
+
+// New returns limiter with the store and the limits of the config, the store is either "memory" or "redis".
+func New(cfg config.RateLimitConfig, redisCfg config.RedisConfig) (*Limiter, error) {
+	const op = "ratelimit.New"
+
+	var store Store
+	switch cfg.Store {
+	case "memory":
+		store = NewMemoryStore()
+	case "redis":
+		store = NewRedisStore(redisCfg.Address, redisCfg.Password, redisCfg.DB)
+	default:
+		return nil, fmt.Errorf("%s: unknown rate limit store: %s", op, cfg.Store)
+	}
+
+	return NewLimiter(store, map[Scope]Limit{
+		ScopeUser:  limitFromConfig(cfg.UserMessages),
+		ScopeChat:  limitFromConfig(cfg.ChatMessages),
+		ScopeIP:    limitFromConfig(cfg.IPRequests),
+		ScopeLogin: limitFromConfig(cfg.IPLogins),
+	}), nil
+}
+
+// NewLimiter returns limiter keeping buckets in the store, scopes missing in limits aren't limited.
+func NewLimiter(store Store, limits map[Scope]Limit) *Limiter {
+	return &Limiter{
+		logger: logging.GetLogger().Sugar(),
+		store:  store,
+		limits: limits,
+	}
+}
+
+// Allow takes a token from the bucket of every key if all of them have one, the first bucket without tokens
+// denies the request and no token is taken from the others.
+//
+// Keys with empty IDs are skipped. Limits fail open: if the store fails, the error is logged
+// and the tokens are considered taken, so an unavailable store doesn't take the facade down.
+func (l *Limiter) Allow(ctx context.Context, keys ...Key) Result {
+	const op = "ratelimit.Allow"
+
+	buckets := make([]Bucket, 0, len(keys))
+	scopes := make([]Scope, 0, len(keys))
+	for _, key := range keys {
+		limit, ok := l.limits[key.Scope]
+		if !ok || limit.Disabled() || key.ID == "" {
+			continue
+		}
+
+		buckets = append(buckets, Bucket{Key: bucketKey(key), Limit: limit})
+		scopes = append(scopes, key.Scope)
+	}
+
+	if len(buckets) == 0 {
+		return Result{Allowed: true}
+	}
+
+	res, denied, err := l.store.Take(ctx, buckets...)
+	if err != nil {
+		l.logger.Warnw("failed to take tokens, request is let through", "op", op, "scopes", scopes, "error", err)
+		return Result{Allowed: true}
+	}
+
+	if !res.Allowed {
+		res.Scope = scopes[denied]
+	}
+
+	return res
+}
+
+// Close closes connections of the store, if it has any.
+func (l *Limiter) Close() error {
+	if closer, ok := l.store.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+func bucketKey(key Key) string {
+	return "ratelimit:" + string(key.Scope) + ":" + key.ID
+}
+
+func limitFromConfig(cfg config.LimitConfig) Limit {
+	return Limit{Rate: cfg.Rate, Period: cfg.Period, Burst: cfg.Burst}
+}
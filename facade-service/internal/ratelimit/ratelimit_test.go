@@ -0,0 +1,234 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+// clock is time of a test, it only moves when the test advances it
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newMemoryStore(_ *testing.T, c *clock) Store {
+	s := NewMemoryStore()
+	s.now = c.Now
+
+	return s
+}
+
+func newRedisStore(t *testing.T, c *clock) Store {
+	server := miniredis.RunT(t)
+
+	s := NewRedisStore(server.Addr(), "", 0)
+	s.now = c.Now
+	t.Cleanup(func() { _ = s.Close() })
+
+	return s
+}
+
+var stores = map[string]func(t *testing.T, c *clock) Store{
+	"memory": newMemoryStore,
+	"redis":  newRedisStore,
+}
+
+// take takes a token from the single bucket of the key
+func take(ctx context.Context, s Store, key string, limit Limit) (Result, error) {
+	res, _, err := s.Take(ctx, Bucket{Key: key, Limit: limit})
+	return res, err
+}
+
+func TestStore_BurstThenRefill(t *testing.T) {
+	limit := Limit{Rate: 2, Period: time.Second, Burst: 3}
+
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			c := &clock{now: time.Unix(1700000000, 0)}
+			s := newStore(t, c)
+			ctx := context.Background()
+
+			for i := 0; i < limit.Burst; i++ {
+				res, err := take(ctx, s, "key", limit)
+				if err != nil {
+					t.Fatalf("take %d failed: %v", i, err)
+				}
+				if !res.Allowed {
+					t.Fatalf("take %d within burst was denied", i)
+				}
+			}
+
+			res, err := take(ctx, s, "key", limit)
+			if err != nil {
+				t.Fatalf("take failed: %v", err)
+			}
+			if res.Allowed {
+				t.Fatalf("take over burst was allowed")
+			}
+			if res.RetryAfter != 500*time.Millisecond {
+				t.Errorf("expected retry after 500ms, got %v", res.RetryAfter)
+			}
+
+			// other keys have their own buckets
+			if res, _ := take(ctx, s, "other", limit); !res.Allowed {
+				t.Errorf("take of another key was denied")
+			}
+
+			c.Advance(200 * time.Millisecond)
+			if res, _ := take(ctx, s, "key", limit); res.Allowed || res.RetryAfter != 300*time.Millisecond {
+				t.Errorf("expected denial with retry after 300ms, got %+v", res)
+			}
+
+			c.Advance(300 * time.Millisecond)
+			if res, _ := take(ctx, s, "key", limit); !res.Allowed {
+				t.Errorf("take after refill was denied")
+			}
+			if res, _ := take(ctx, s, "key", limit); res.Allowed {
+				t.Errorf("second take after refill of one token was allowed")
+			}
+
+			// the bucket doesn't hold more than the burst however long it was idle
+			c.Advance(time.Hour)
+			for i := 0; i < limit.Burst; i++ {
+				if res, _ := take(ctx, s, "key", limit); !res.Allowed {
+					t.Fatalf("take %d after idle was denied", i)
+				}
+			}
+			if res, _ := take(ctx, s, "key", limit); res.Allowed {
+				t.Errorf("take over burst after idle was allowed")
+			}
+		})
+	}
+}
+
+func TestStore_TakesFromAllBucketsOrNone(t *testing.T) {
+	roomy := Limit{Rate: 1, Period: time.Second, Burst: 2}
+	tight := Limit{Rate: 1, Period: time.Second, Burst: 1}
+
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			c := &clock{now: time.Unix(1700000000, 0)}
+			s := newStore(t, c)
+			ctx := context.Background()
+
+			if res, _ := take(ctx, s, "tight", tight); !res.Allowed {
+				t.Fatalf("take within burst was denied")
+			}
+
+			res, denied, err := s.Take(ctx, Bucket{Key: "roomy", Limit: roomy}, Bucket{Key: "tight", Limit: tight})
+			if err != nil {
+				t.Fatalf("take failed: %v", err)
+			}
+			if res.Allowed || denied != 1 {
+				t.Fatalf("expected denial by the second bucket, got %+v of bucket %d", res, denied)
+			}
+			if res.RetryAfter != time.Second {
+				t.Errorf("expected retry after 1s, got %v", res.RetryAfter)
+			}
+
+			// the denied take left the first bucket full
+			for i := 0; i < roomy.Burst; i++ {
+				if res, _ := take(ctx, s, "roomy", roomy); !res.Allowed {
+					t.Fatalf("take %d from the bucket of a denied take was denied", i)
+				}
+			}
+
+			c.Advance(time.Second)
+			res, denied, err = s.Take(ctx, Bucket{Key: "roomy", Limit: roomy}, Bucket{Key: "tight", Limit: tight})
+			if err != nil {
+				t.Fatalf("take failed: %v", err)
+			}
+			if !res.Allowed {
+				t.Fatalf("take after refill was denied: %+v of bucket %d", res, denied)
+			}
+			for _, key := range []string{"roomy", "tight"} {
+				if res, _ := take(ctx, s, key, tight); res.Allowed {
+					t.Errorf("token of %s wasn't taken", key)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryStore_SweepsFullBuckets(t *testing.T) {
+	c := &clock{now: time.Unix(1700000000, 0)}
+	s := NewMemoryStore()
+	s.now = c.Now
+	s.lastSweep = c.now
+
+	ctx := context.Background()
+	fast := Limit{Rate: 10, Period: time.Second, Burst: 1}
+	slow := Limit{Rate: 1, Period: time.Hour, Burst: 1}
+
+	_, _ = take(ctx, s, "fast", fast)
+	_, _ = take(ctx, s, "slow", slow)
+
+	c.Advance(sweepInterval)
+	_, _ = take(ctx, s, "other", fast)
+
+	if _, ok := s.buckets["fast"]; ok {
+		t.Errorf("refilled bucket was not swept")
+	}
+	if _, ok := s.buckets["slow"]; !ok {
+		t.Errorf("bucket of a slower limit was swept before it was refilled")
+	}
+}
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, ...Bucket) (Result, int, error) {
+	return Result{}, -1, errors.New("store is down")
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	ctx := context.Background()
+	limits := map[Scope]Limit{
+		ScopeUser: {Rate: 1, Period: time.Minute, Burst: 1},
+		ScopeChat: {Rate: 1, Period: time.Minute, Burst: 2},
+		ScopeIP:   {},
+	}
+
+	l := NewLimiter(NewMemoryStore(), limits)
+	user := Key{Scope: ScopeUser, ID: "user"}
+	chat := Key{Scope: ScopeChat, ID: "chat"}
+
+	if res := l.Allow(ctx, user, chat); !res.Allowed {
+		t.Fatalf("first message was denied: %+v", res)
+	}
+
+	res := l.Allow(ctx, chat, user)
+	if res.Allowed || res.Scope != ScopeUser {
+		t.Errorf("expected denial by user limit, got %+v", res)
+	}
+
+	// the denied message took no token of the chat, which has one left
+	if res := l.Allow(ctx, chat); !res.Allowed {
+		t.Errorf("denied message took a token of the chat: %+v", res)
+	}
+
+	// disabled and missing limits and empty IDs let everything through
+	for i := 0; i < 10; i++ {
+		keys := []Key{{Scope: ScopeIP, ID: "127.0.0.1"}, {Scope: ScopeLogin, ID: "127.0.0.1"}, {Scope: ScopeUser}}
+		if res := l.Allow(ctx, keys...); !res.Allowed {
+			t.Fatalf("unlimited request was denied: %+v", res)
+		}
+	}
+
+	failing := NewLimiter(failingStore{}, limits)
+	for i := 0; i < 10; i++ {
+		if res := failing.Allow(ctx, user); !res.Allowed {
+			t.Fatalf("request was denied while the store fails")
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// takeScript takes a token from every bucket kept in a hash with "tokens" and "updated" fields, updated is
+// the time of the last take in milliseconds. Time is passed by the instance rather than taken from the
+// server, so the script works on servers merely compatible with Redis. Interval and burst of the bucket
+// of KEYS[i] are passed after the time, in ARGV[2*i] and ARGV[2*i+1].
+//
+// Tokens are only taken if every bucket has one, otherwise the script returns how many milliseconds until
+// the first bucket without tokens has one and its zero-based index.
+// A bucket expires once it would be refilled to capacity, a missing bucket is full.
+var takeScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+
+local tokens = {}
+for i, key in ipairs(KEYS) do
+	local interval = tonumber(ARGV[2 * i])
+	local burst = tonumber(ARGV[2 * i + 1])
+
+	local bucket = redis.call("HMGET", key, "tokens", "updated")
+	local available = tonumber(bucket[1])
+	local updated = tonumber(bucket[2])
+	if available == nil or updated == nil then
+		available = burst
+		updated = now
+	end
+
+	if now > updated then
+		available = math.min(burst, available + (now - updated) / interval)
+	end
+
+	if available < 1 then
+		return {0, math.ceil((1 - available) * interval), i - 1}
+	end
+
+	tokens[i] = available
+end
+
+for i, key in ipairs(KEYS) do
+	local interval = tonumber(ARGV[2 * i])
+	local burst = tonumber(ARGV[2 * i + 1])
+	local available = tokens[i] - 1
+
+	redis.call("HSET", key, "tokens", tostring(available), "updated", tostring(now))
+	redis.call("PEXPIRE", key, math.ceil((burst - available) * interval) + 1)
+end
+
+return {1, 0, -1}
+`)
+
+// RedisStore keeps the buckets in Redis or a server compatible with it, so instances share the limits.
+type RedisStore struct {
+	client *redis.Client
+	// now returns current time, tests replace it
+	now func() time.Time
+}
+
+func NewRedisStore(address string, password string, db int) *RedisStore {
+	return &RedisStore{
+		client: redis.NewClient(&redis.Options{
+			Addr:     address,
+			Password: password,
+			DB:       db,
+		}),
+		now: time.Now,
+	}
+}
+
+func (s *RedisStore) Take(ctx context.Context, buckets ...Bucket) (Result, int, error) {
+	const op = "ratelimit.RedisStore.Take"
+
+	keys := make([]string, 0, len(buckets))
+	args := make([]any, 0, 1+2*len(buckets))
+	args = append(args, s.now().UnixMilli())
+	for _, b := range buckets {
+		keys = append(keys, b.Key)
+		args = append(args, float64(b.Limit.interval())/float64(time.Millisecond), b.Limit.burst())
+	}
+
+	res, err := takeScript.Run(ctx, s.client, keys, args...).Int64Slice()
+	if err != nil {
+		return Result{}, -1, fmt.Errorf("%s: %w", op, err)
+	}
+	if len(res) != 3 {
+		return Result{}, -1, fmt.Errorf("%s: unexpected script result: %v", op, res)
+	}
+
+	if res[0] == 1 {
+		return Result{Allowed: true}, -1, nil
+	}
+	if res[2] < 0 || res[2] >= int64(len(buckets)) {
+		return Result{}, -1, fmt.Errorf("%s: unexpected script result: %v", op, res)
+	}
+
+	return Result{Allowed: false, RetryAfter: time.Duration(max(res[1], 1)) * time.Millisecond}, int(res[2]), nil
+}
+
+// Close closes connections to the server.
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,196 @@
+// Package sessions keeps track of sso sessions of authenticated clients, so that requests and live
+// connections of sessions the user has signed out of are rejected.
+package sessions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+	"github.com/go-chi/chi/middleware"
+	pb "github.com/zoninnik89/messenger/common/api"
+	grpcgateway "github.com/zoninnik89/messenger/facade-service/internal/gateway"
+	"github.com/zoninnik89/messenger/facade-service/internal/logging"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+)
+
+// pollTimeout bounds waiting for an account event, so that consuming stops soon after the context is cancelled
+const pollTimeout = time.Second
+
+var ErrSessionRevoked = errors.New("session revoked")
+
+type sessionToucher interface {
+	TouchSession(ctx context.Context, req *pb.TouchSessionRequest, requestID string) (*pb.TouchSessionResponse, error)
+}
+
+// Tracker checks sessions in sso service and notifies listeners about revoked sessions.
+// It is safe for concurrent use.
+type Tracker struct {
+	logger        *zap.SugaredLogger
+	gw            sessionToucher
+	checkInterval time.Duration
+
+	mu        sync.Mutex
+	checks    map[string]check
+	lastPrune time.Time
+	listeners []func(sessionID string)
+}
+
+type check struct {
+	at      time.Time
+	revoked bool
+}
+
+// NewTracker returns tracker which checks every session in sso service at most once per checkInterval.
+func NewTracker(gw sessionToucher, checkInterval time.Duration) *Tracker {
+	return &Tracker{
+		logger:        logging.GetLogger().Sugar(),
+		gw:            gw,
+		checkInterval: checkInterval,
+		checks:        make(map[string]check),
+	}
+}
+
+// Check returns ErrSessionRevoked if the session was revoked or has expired.
+//
+// Sessions are not rejected while sso service is unavailable, tokens are still verified by their signature.
+// Tokens issued before sessions were tracked have no session and always pass.
+func (t *Tracker) Check(ctx context.Context, sessionID string) error {
+	const op = "sessions.Check"
+
+	if sessionID == "" {
+		return nil
+	}
+
+	now := time.Now()
+
+	t.mu.Lock()
+	t.prune(now)
+	c, ok := t.checks[sessionID]
+	t.mu.Unlock()
+
+	if ok && c.revoked {
+		return ErrSessionRevoked
+	}
+	if ok && now.Sub(c.at) < t.checkInterval {
+		return nil
+	}
+
+	_, err := t.gw.TouchSession(ctx, &pb.TouchSessionRequest{SessionId: sessionID}, middleware.GetReqID(ctx))
+	if err != nil {
+		if errors.Is(err, grpcgateway.ErrSessionNotFound) {
+			t.Revoke(sessionID)
+
+			return ErrSessionRevoked
+		}
+
+		// the failure is remembered too, so that sso service isn't flooded while it recovers
+		t.logger.Warnw("failed to check session", "op", op, "sessionID", sessionID, "error", err)
+	}
+
+	t.mu.Lock()
+	if c, ok := t.checks[sessionID]; !ok || !c.revoked {
+		t.checks[sessionID] = check{at: now}
+	}
+	t.mu.Unlock()
+
+	return nil
+}
+
+// Revoke rejects the session from now on and notifies listeners registered with OnRevoke.
+func (t *Tracker) Revoke(sessionID string) {
+	if sessionID == "" {
+		return
+	}
+
+	t.mu.Lock()
+	t.checks[sessionID] = check{at: time.Now(), revoked: true}
+	listeners := t.listeners
+	t.mu.Unlock()
+
+	for _, listener := range listeners {
+		listener(sessionID)
+	}
+}
+
+// OnRevoke registers function called with ID of every revoked session.
+func (t *Tracker) OnRevoke(listener func(sessionID string)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.listeners = append(t.listeners, listener)
+}
+
+// ConsumeRevocations revokes sessions reported by account events of sso service until the context is cancelled.
+func (t *Tracker) ConsumeRevocations(ctx context.Context, consumer *kafka.Consumer) {
+	const op = "sessions.ConsumeRevocations"
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		msg, err := consumer.ReadMessage(pollTimeout)
+		if err != nil {
+			if kafkaErr, ok := err.(kafka.Error); ok && kafkaErr.Code() == kafka.ErrTimedOut {
+				continue
+			}
+
+			t.logger.Warnw("failed to read account event", "op", op, "error", err)
+			continue
+		}
+
+		var event pb.AccountEvent
+		if err := proto.Unmarshal(msg.Value, &event); err != nil {
+			t.logger.Warnw("failed to unmarshal account event", "op", op, "error", err)
+			continue
+		}
+
+		if event.GetType() != pb.AccountEventType_ACCOUNT_EVENT_TYPE_SESSION_REVOKED {
+			continue
+		}
+
+		t.logger.Infow("session revoked", "op", op, "userID", event.GetUserId(), "sessionID", event.GetSessionId())
+
+		t.Revoke(event.GetSessionId())
+	}
+}
+
+// NewConsumer creates a consumer of account events. Every facade instance holds its own connections,
+// so each of them must use its own group to receive all events.
+func NewConsumer(kafkaAddress string, groupID string) (*kafka.Consumer, error) {
+	const op = "sessions.NewConsumer"
+
+	consumer, err := kafka.NewConsumer(&kafka.ConfigMap{
+		"bootstrap.servers": kafkaAddress,
+		"client.id":         "facade-sessions-consumer",
+		"group.id":          groupID,
+		"auto.offset.reset": "latest",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return consumer, nil
+}
+
+// prune forgets sessions which are due for another check, revoked sessions are checked again too
+// and stay rejected as sso service doesn't find them. The caller must hold the mutex.
+func (t *Tracker) prune(now time.Time) {
+	if now.Sub(t.lastPrune) < t.checkInterval {
+		return
+	}
+	t.lastPrune = now
+
+	for sessionID, c := range t.checks {
+		if now.Sub(c.at) >= t.checkInterval {
+			delete(t.checks, sessionID)
+		}
+	}
+}
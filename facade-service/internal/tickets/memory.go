@@ -0,0 +1,63 @@
+package tickets
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type entry struct {
+	grant   Grant
+	expires time.Time
+}
+
+// MemoryStore keeps tickets in memory, so a ticket is only accepted by the instance which issued it.
+type MemoryStore struct {
+	// now returns current time, tests replace it
+	now func() time.Time
+
+	mu      sync.Mutex
+	tickets map[string]entry
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		now:     time.Now,
+		tickets: make(map[string]entry),
+	}
+}
+
+func (s *MemoryStore) Save(_ context.Context, ticket string, grant Grant, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+
+	// tickets are issued rarely, so expired ones are dropped on every save rather than in background
+	for t, e := range s.tickets {
+		if !now.Before(e.expires) {
+			delete(s.tickets, t)
+		}
+	}
+
+	s.tickets[ticket] = entry{grant: grant, expires: now.Add(ttl)}
+
+	return nil
+}
+
+func (s *MemoryStore) Take(_ context.Context, ticket string) (Grant, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.tickets[ticket]
+	if !ok {
+		return Grant{}, ErrInvalidTicket
+	}
+	delete(s.tickets, ticket)
+
+	if !s.now().Before(e.expires) {
+		return Grant{}, ErrInvalidTicket
+	}
+
+	return e.grant, nil
+}
@@ -0,0 +1,70 @@
+package tickets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore keeps tickets in Redis or a server compatible with it, so any instance accepts them.
+type RedisStore struct {
+	client *redis.Client
+}
+
+func NewRedisStore(address string, password string, db int) *RedisStore {
+	return &RedisStore{
+		client: redis.NewClient(&redis.Options{
+			Addr:     address,
+			Password: password,
+			DB:       db,
+		}),
+	}
+}
+
+func (s *RedisStore) Save(ctx context.Context, ticket string, grant Grant, ttl time.Duration) error {
+	const op = "tickets.RedisStore.Save"
+
+	value, err := json.Marshal(grant)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.client.Set(ctx, ticketKey(ticket), value, ttl).Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Take gets and deletes the ticket at once, so concurrent connections can't both redeem it.
+func (s *RedisStore) Take(ctx context.Context, ticket string) (Grant, error) {
+	const op = "tickets.RedisStore.Take"
+
+	value, err := s.client.GetDel(ctx, ticketKey(ticket)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return Grant{}, ErrInvalidTicket
+		}
+		return Grant{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var grant Grant
+	if err := json.Unmarshal(value, &grant); err != nil {
+		return Grant{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return grant, nil
+}
+
+// Close closes connections to the server.
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
+
+func ticketKey(ticket string) string {
+	return "ws-ticket:" + ticket
+}
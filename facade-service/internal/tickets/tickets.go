@@ -0,0 +1,107 @@
+// Package tickets issues short-lived one-time tickets for opening websocket connections. Browsers can't set
+// headers of websocket requests, so rather than putting the long-lived auth token into the URL, where it ends
+// up in logs of proxies, the web client exchanges it for a ticket which is useless once the connection is open.
+package tickets
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/zoninnik89/messenger/facade-service/internal/config"
+)
+
+// ticketBytes is the number of random bytes of a ticket
+const ticketBytes = 32
+
+var ErrInvalidTicket = errors.New("invalid or expired ticket")
+
+// Grant is what a ticket allows: opening a connection on behalf of the session of the user.
+type Grant struct {
+	UserID    string `json:"user_id"`
+	SessionID string `json:"session_id"`
+	// Scopes are scopes of the token the ticket was issued for, see token.Allows
+	Scopes []string `json:"scopes"`
+}
+
+// Store keeps grants of tickets until they expire.
+type Store interface {
+	Save(ctx context.Context, ticket string, grant Grant, ttl time.Duration) error
+	// Take returns the grant of the ticket and deletes it, or ErrInvalidTicket if there is none
+	Take(ctx context.Context, ticket string) (Grant, error)
+}
+
+// Tickets issues tickets and redeems them once.
+type Tickets struct {
+	store Store
+	ttl   time.Duration
+}
+
+// New returns tickets expiring after the TTL of the config and kept in the store of the config,
+// either "memory" or "redis".
+func New(cfg config.WebsocketConfig, redisCfg config.RedisConfig) (*Tickets, error) {
+	const op = "tickets.New"
+
+	var store Store
+	switch cfg.TicketStore {
+	case "memory":
+		store = NewMemoryStore()
+	case "redis":
+		store = NewRedisStore(redisCfg.Address, redisCfg.Password, redisCfg.DB)
+	default:
+		return nil, fmt.Errorf("%s: unknown ticket store: %s", op, cfg.TicketStore)
+	}
+
+	return NewTickets(store, cfg.TicketTTL), nil
+}
+
+func NewTickets(store Store, ttl time.Duration) *Tickets {
+	return &Tickets{store: store, ttl: ttl}
+}
+
+// TTL returns how long an issued ticket may be redeemed.
+func (t *Tickets) TTL() time.Duration {
+	return t.ttl
+}
+
+// Issue returns a new ticket with the grant.
+func (t *Tickets) Issue(ctx context.Context, grant Grant) (string, error) {
+	const op = "tickets.Issue"
+
+	b := make([]byte, ticketBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	ticket := base64.RawURLEncoding.EncodeToString(b)
+
+	if err := t.store.Save(ctx, ticket, grant, t.ttl); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return ticket, nil
+}
+
+// Redeem returns the grant of the ticket, the ticket can't be redeemed again.
+func (t *Tickets) Redeem(ctx context.Context, ticket string) (Grant, error) {
+	const op = "tickets.Redeem"
+
+	grant, err := t.store.Take(ctx, ticket)
+	if err != nil {
+		return Grant{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return grant, nil
+}
+
+// Close closes connections of the store, if it has any.
+func (t *Tickets) Close() error {
+	if closer, ok := t.store.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
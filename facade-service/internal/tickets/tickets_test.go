@@ -0,0 +1,103 @@
+package tickets
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func TestTickets_RedeemOnce(t *testing.T) {
+	stores := map[string]func(t *testing.T) Store{
+		"memory": func(*testing.T) Store { return NewMemoryStore() },
+		"redis": func(t *testing.T) Store {
+			s := NewRedisStore(miniredis.RunT(t).Addr(), "", 0)
+			t.Cleanup(func() { _ = s.Close() })
+			return s
+		},
+	}
+
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			tickets := NewTickets(newStore(t), time.Minute)
+			grant := Grant{UserID: "user", SessionID: "session", Scopes: []string{"chat:read"}}
+
+			ticket, err := tickets.Issue(ctx, grant)
+			if err != nil {
+				t.Fatalf("failed to issue ticket: %v", err)
+			}
+
+			other, err := tickets.Issue(ctx, grant)
+			if err != nil {
+				t.Fatalf("failed to issue ticket: %v", err)
+			}
+			if other == ticket {
+				t.Fatalf("tickets are not unique")
+			}
+
+			got, err := tickets.Redeem(ctx, ticket)
+			if err != nil {
+				t.Fatalf("failed to redeem ticket: %v", err)
+			}
+			if !reflect.DeepEqual(got, grant) {
+				t.Errorf("expected grant %+v, got %+v", grant, got)
+			}
+
+			if _, err := tickets.Redeem(ctx, ticket); !errors.Is(err, ErrInvalidTicket) {
+				t.Errorf("expected second redeem to fail with invalid ticket, got %v", err)
+			}
+			if _, err := tickets.Redeem(ctx, "unknown"); !errors.Is(err, ErrInvalidTicket) {
+				t.Errorf("expected unknown ticket to be invalid, got %v", err)
+			}
+
+			// tickets of unrestricted tokens stay unrestricted
+			unrestricted, err := tickets.Issue(ctx, Grant{UserID: "user"})
+			if err != nil {
+				t.Fatalf("failed to issue ticket: %v", err)
+			}
+			if got, err := tickets.Redeem(ctx, unrestricted); err != nil || got.Scopes != nil {
+				t.Errorf("expected grant without scopes, got %+v, err %v", got, err)
+			}
+		})
+	}
+}
+
+func TestMemoryStore_Expiry(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+
+	if err := s.Save(ctx, "expired", Grant{UserID: "user"}, time.Second); err != nil {
+		t.Fatalf("failed to save ticket: %v", err)
+	}
+	if err := s.Save(ctx, "valid", Grant{UserID: "user"}, time.Minute); err != nil {
+		t.Fatalf("failed to save ticket: %v", err)
+	}
+
+	now = now.Add(time.Second)
+
+	if _, err := s.Take(ctx, "expired"); !errors.Is(err, ErrInvalidTicket) {
+		t.Errorf("expected expired ticket to be invalid, got %v", err)
+	}
+	if _, err := s.Take(ctx, "valid"); err != nil {
+		t.Errorf("failed to take valid ticket: %v", err)
+	}
+
+	// expired tickets are dropped when others are saved
+	if err := s.Save(ctx, "stale", Grant{}, time.Second); err != nil {
+		t.Fatalf("failed to save ticket: %v", err)
+	}
+	now = now.Add(time.Second)
+	if err := s.Save(ctx, "new", Grant{}, time.Second); err != nil {
+		t.Fatalf("failed to save ticket: %v", err)
+	}
+	if _, ok := s.tickets["stale"]; ok {
+		t.Errorf("expired ticket was not dropped")
+	}
+}
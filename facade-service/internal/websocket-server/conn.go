@@ -0,0 +1,180 @@
+package websocketserver
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	pb "github.com/zoninnik89/messenger/common/api"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// outboundQueueSize bounds frames waiting to be written to a connection, a client which doesn't read
+	// them fast enough is disconnected rather than holding memory of the facade
+	outboundQueueSize = 256
+	// writeTimeout bounds writing a single frame
+	writeTimeout = 10 * time.Second
+)
+
+// frame is a data frame queued to a connection
+type frame struct {
+	// messageType is either websocket.TextMessage or websocket.BinaryMessage
+	messageType int
+	data        []byte
+}
+
+// conn is a websocket connection of a client. Gorilla connections support one concurrent writer, so frames
+// are only written by the writer goroutine of the connection, other goroutines queue them with send.
+type conn struct {
+	ws        *websocket.Conn
+	userID    string
+	sessionID string
+	clientIP  string
+	protocol  protocol
+	// scopes of the token the connection was opened with, see token.Allows
+	scopes []string
+
+	// trace links spans of messages the client sends to the span of the request which opened the connection,
+	// the ID of that request is passed along with the messages
+	trace     trace.Link
+	requestID string
+
+	outbound chan frame
+
+	closeOnce sync.Once
+	// closing is closed once the connection is asked to close, closeMessage is the close frame to send then
+	closing      chan struct{}
+	closeMessage []byte
+	// readDone is closed once the read loop ends, the writer waits for it to let the client answer the close frame
+	readDone chan struct{}
+	// closed is closed once the writer has closed the connection
+	closed chan struct{}
+}
+
+func newConn(ws *websocket.Conn, userID string, sessionID string, clientIP string, p protocol) *conn {
+	return &conn{
+		ws:        ws,
+		userID:    userID,
+		sessionID: sessionID,
+		clientIP:  clientIP,
+		protocol:  p,
+		outbound:  make(chan frame, outboundQueueSize),
+		closing:   make(chan struct{}),
+		readDone:  make(chan struct{}),
+		closed:    make(chan struct{}),
+	}
+}
+
+// send queues the text frame, see sendFrame.
+func (c *conn) send(data []byte) bool {
+	return c.sendFrame(frame{messageType: websocket.TextMessage, data: data})
+}
+
+// sendMessage queues the chat message framed by the protocol of the connection, see sendFrame.
+func (c *conn) sendMessage(msg *pb.Message) (bool, error) {
+	f, err := c.protocol.encode(msg)
+	if err != nil {
+		return false, err
+	}
+
+	return c.sendFrame(f), nil
+}
+
+// sendFrame queues the frame. It reports false if the frame is dropped because the connection is closing,
+// or because the queue is full, in which case the client is too slow and the connection is closed.
+func (c *conn) sendFrame(f frame) bool {
+	select {
+	case <-c.closing:
+		return false
+	default:
+	}
+
+	select {
+	case c.outbound <- f:
+		return true
+	default:
+		if c.close(websocket.CloseTryAgainLater, "client is too slow") {
+			metrics.Add(metricSlowClosed, 1)
+		}
+		return false
+	}
+}
+
+// close asks the writer to write frames queued so far, send the close frame with the code and reason,
+// and close the connection. Only the first call has effect, it reports whether the call was the first.
+func (c *conn) close(code int, reason string) bool {
+	first := false
+	c.closeOnce.Do(func() {
+		c.closeMessage = websocket.FormatCloseMessage(code, reason)
+		close(c.closing)
+		first = true
+	})
+
+	return first
+}
+
+// writeLoop writes queued frames and pings the client every ping interval until the connection is closing,
+// then closes it.
+func (c *conn) writeLoop(pingInterval time.Duration) {
+	defer close(c.closed)
+
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case f := <-c.outbound:
+			if err := c.write(f); err != nil {
+				c.abort()
+				return
+			}
+		case <-ticker.C:
+			// the client answers with pong, which extends the read deadline
+			if err := c.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout)); err != nil {
+				c.abort()
+				return
+			}
+		case <-c.closing:
+			c.drain()
+			return
+		}
+	}
+}
+
+// drain writes frames left in the queue and the close frame, then closes the connection once the client
+// answers the close frame or the close timeout passes.
+func (c *conn) drain() {
+	defer c.ws.Close()
+
+	// the writer is the only receiver, so the queue can't be emptied behind its back
+	for len(c.outbound) > 0 {
+		if err := c.write(<-c.outbound); err != nil {
+			return
+		}
+	}
+
+	if err := c.ws.WriteControl(websocket.CloseMessage, c.closeMessage, time.Now().Add(closeTimeout)); err != nil {
+		return
+	}
+
+	select {
+	case <-c.readDone:
+	case <-time.After(closeTimeout):
+	}
+}
+
+// abort closes the connection once writing to it fails, the client is gone, so there is nobody to send
+// the close frame to.
+func (c *conn) abort() {
+	c.close(websocket.CloseAbnormalClosure, "")
+	_ = c.ws.Close()
+}
+
+func (c *conn) write(f frame) error {
+	if err := c.ws.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+		return err
+	}
+
+	return c.ws.WriteMessage(f.messageType, f.data)
+}
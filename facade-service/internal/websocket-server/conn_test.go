@@ -0,0 +1,140 @@
+package websocketserver
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// newTestConn returns the server side connection with its writer running, and the client side of it.
+func newTestConn(t *testing.T, pingInterval time.Duration) (*conn, *websocket.Conn) {
+	t.Helper()
+
+	conns := make(chan *conn, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("failed to upgrade: %v", err)
+			return
+		}
+
+		c := newConn(ws, "user", "session", "127.0.0.1", protocolJSON)
+		go c.writeLoop(pingInterval)
+		go func() {
+			defer close(c.readDone)
+			for {
+				if _, _, err := ws.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}()
+		conns <- c
+	}))
+	t.Cleanup(srv.Close)
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	return <-conns, client
+}
+
+func TestConn_WritesQueuedFramesBeforeCloseFrame(t *testing.T) {
+	c, client := newTestConn(t, time.Minute)
+
+	frames := []string{"first", "second", "third"}
+	for _, frame := range frames {
+		if !c.send([]byte(frame)) {
+			t.Fatalf("frame %q wasn't queued", frame)
+		}
+	}
+	c.close(websocket.CloseGoingAway, shutdownReason)
+
+	if c.send([]byte("late")) {
+		t.Error("frame was queued after close")
+	}
+
+	for _, want := range frames {
+		_, data, err := client.ReadMessage()
+		if err != nil {
+			t.Fatalf("failed to read %q: %v", want, err)
+		}
+		if string(data) != want {
+			t.Errorf("frame = %q, want %q", data, want)
+		}
+	}
+
+	_, _, err := client.ReadMessage()
+	var closeErr *websocket.CloseError
+	if !errors.As(err, &closeErr) {
+		t.Fatalf("error = %v, want close error", err)
+	}
+	if closeErr.Code != websocket.CloseGoingAway || closeErr.Text != shutdownReason {
+		t.Errorf("close = %d %q, want %d %q", closeErr.Code, closeErr.Text, websocket.CloseGoingAway, shutdownReason)
+	}
+
+	select {
+	case <-c.closed:
+	case <-time.After(2 * closeTimeout):
+		t.Error("connection wasn't closed")
+	}
+}
+
+func TestConn_ClosesSlowClient(t *testing.T) {
+	c, _ := newTestConn(t, time.Minute)
+
+	// the client never reads, so the queue fills up once socket buffers do
+	frame := []byte(strings.Repeat("x", 64*1024))
+	deadline := time.After(10 * time.Second)
+	for c.send(frame) {
+		select {
+		case <-deadline:
+			t.Fatal("queue never filled up")
+		default:
+		}
+	}
+
+	select {
+	case <-c.closing:
+	default:
+		t.Fatal("connection isn't closing")
+	}
+	if !strings.Contains(string(c.closeMessage), "client is too slow") {
+		t.Errorf("close message = %q", c.closeMessage)
+	}
+}
+
+func TestConn_PingsClient(t *testing.T) {
+	_, client := newTestConn(t, 10*time.Millisecond)
+
+	pings := make(chan struct{}, 10)
+	client.SetPingHandler(func(string) error {
+		select {
+		case pings <- struct{}{}:
+		default:
+		}
+		return nil
+	})
+	go func() {
+		for {
+			if _, _, err := client.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-pings:
+		case <-time.After(time.Second):
+			t.Fatalf("ping %d wasn't received", i+1)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package websocketserver
+
+import "expvar"
+
+// metrics of websocket connections, published by expvar as "websocket"
+var metrics = expvar.NewMap("websocket")
+
+const (
+	// metricConnections is the number of open connections
+	metricConnections = "connections"
+	// metricIdleClosed counts connections closed as clients sent nothing, not even pongs, for the pong wait
+	metricIdleClosed = "idle_closed"
+	// metricSlowClosed counts connections closed as clients didn't read queued frames fast enough
+	metricSlowClosed = "slow_closed"
+)
@@ -0,0 +1,84 @@
+package websocketserver
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/gorilla/websocket"
+	pb "github.com/zoninnik89/messenger/common/api"
+	"google.golang.org/protobuf/proto"
+)
+
+// protobufSubprotocol is offered by clients which want chat messages framed as protobuf
+const protobufSubprotocol = "messenger.protobuf"
+
+var errBinaryFrame = errors.New("binary frames need " + protobufSubprotocol + " subprotocol")
+
+// protocol is how chat messages are framed on a connection. Frames other than chat messages, such as
+// errors and rate limit notices, are JSON text frames with every protocol.
+type protocol int
+
+const (
+	// protocolJSON frames chat messages as JSON text frames, it is used unless the client asks for another
+	protocolJSON protocol = iota
+	// protocolProtobuf frames chat messages the client receives and sends as binary frames with pb.Message,
+	// the client may still send JSON text frames
+	protocolProtobuf
+)
+
+// selectSubprotocol returns the subprotocol to select of those offered by the client. Only one can be
+// selected, so the protobuf subprotocol takes precedence over "bearer", whose token is read regardless of
+// it being selected.
+func selectSubprotocol(offered []string) string {
+	selected := ""
+	for _, subprotocol := range offered {
+		switch subprotocol {
+		case protobufSubprotocol:
+			return protobufSubprotocol
+		case bearerSubprotocol:
+			selected = bearerSubprotocol
+		}
+	}
+
+	return selected
+}
+
+// protocolOf returns the protocol of the connection with the selected subprotocol.
+func protocolOf(subprotocol string) protocol {
+	if subprotocol == protobufSubprotocol {
+		return protocolProtobuf
+	}
+
+	return protocolJSON
+}
+
+// encode returns the frame with the chat message.
+func (p protocol) encode(msg *pb.Message) (frame, error) {
+	if p == protocolProtobuf {
+		data, err := proto.Marshal(msg)
+		return frame{messageType: websocket.BinaryMessage, data: data}, err
+	}
+
+	data, err := json.Marshal(msg)
+	return frame{messageType: websocket.TextMessage, data: data}, err
+}
+
+// decode returns the message the client sent in the frame.
+func (p protocol) decode(messageType int, data []byte) (Message, error) {
+	if messageType != websocket.BinaryMessage {
+		var msg Message
+		err := json.Unmarshal(data, &msg)
+		return msg, err
+	}
+
+	if p != protocolProtobuf {
+		return Message{}, errBinaryFrame
+	}
+
+	var msg pb.Message
+	if err := proto.Unmarshal(data, &msg); err != nil {
+		return Message{}, err
+	}
+
+	return Message{Type: "message", ChatID: msg.GetChatId(), MessageText: msg.GetMessageText()}, nil
+}
@@ -0,0 +1,83 @@
+package websocketserver
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	pb "github.com/zoninnik89/messenger/common/api"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestSelectSubprotocol(t *testing.T) {
+	tests := []struct {
+		name    string
+		offered []string
+		want    string
+	}{
+		{name: "none", offered: nil, want: ""},
+		{name: "unknown", offered: []string{"chat"}, want: ""},
+		{name: "bearer", offered: []string{"bearer", "token"}, want: bearerSubprotocol},
+		{name: "protobuf", offered: []string{protobufSubprotocol}, want: protobufSubprotocol},
+		{name: "protobuf over bearer", offered: []string{"bearer", "token", protobufSubprotocol}, want: protobufSubprotocol},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := selectSubprotocol(tt.offered); got != tt.want {
+				t.Errorf("selectSubprotocol() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProtocol_Protobuf(t *testing.T) {
+	p := protocolOf(protobufSubprotocol)
+
+	msg := &pb.Message{ChatId: "chat", SenderId: "user", MessageId: "id", MessageText: "hi", SentTs: "1"}
+	f, err := p.encode(msg)
+	if err != nil {
+		t.Fatalf("encode() error = %v", err)
+	}
+	if f.messageType != websocket.BinaryMessage {
+		t.Errorf("message type = %d, want binary", f.messageType)
+	}
+
+	var decoded pb.Message
+	if err := proto.Unmarshal(f.data, &decoded); err != nil {
+		t.Fatalf("frame isn't pb.Message: %v", err)
+	}
+	if !proto.Equal(msg, &decoded) {
+		t.Errorf("decoded = %v, want %v", &decoded, msg)
+	}
+
+	got, err := p.decode(websocket.BinaryMessage, f.data)
+	if err != nil {
+		t.Fatalf("decode() error = %v", err)
+	}
+	if got.ChatID != "chat" || got.MessageText != "hi" {
+		t.Errorf("decode() = %+v", got)
+	}
+
+	// text frames are still JSON
+	got, err = p.decode(websocket.TextMessage, []byte(`{"type":"message","chat_id":"chat","message_text":"hi"}`))
+	if err != nil || got.ChatID != "chat" {
+		t.Errorf("decode() = %+v, %v", got, err)
+	}
+}
+
+func TestProtocol_JSONRejectsBinaryFrames(t *testing.T) {
+	p := protocolOf("")
+
+	f, err := p.encode(&pb.Message{ChatId: "chat"})
+	if err != nil {
+		t.Fatalf("encode() error = %v", err)
+	}
+	if f.messageType != websocket.TextMessage {
+		t.Errorf("message type = %d, want text", f.messageType)
+	}
+
+	if _, err := p.decode(websocket.BinaryMessage, nil); !errors.Is(err, errBinaryFrame) {
+		t.Errorf("decode() error = %v, want %v", err, errBinaryFrame)
+	}
+}
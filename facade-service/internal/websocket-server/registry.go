@@ -0,0 +1,121 @@
+package websocketserver
+
+import "sync"
+
+// registry keeps live connections by user and by session. Once shut down, it refuses new connections,
+// so the server can wait for the registered ones to be removed.
+type registry struct {
+	mu        sync.Mutex
+	byUser    map[string]map[*conn]struct{}
+	bySession map[string]map[*conn]struct{}
+	shutdown  bool
+
+	// live counts registered connections
+	live sync.WaitGroup
+}
+
+func newRegistry() *registry {
+	return &registry{
+		byUser:    make(map[string]map[*conn]struct{}),
+		bySession: make(map[string]map[*conn]struct{}),
+	}
+}
+
+// add registers the connection, it reports false if the registry is shut down.
+func (r *registry) add(c *conn) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.shutdown {
+		return false
+	}
+
+	addTo(r.byUser, c.userID, c)
+	addTo(r.bySession, c.sessionID, c)
+	r.live.Add(1)
+
+	return true
+}
+
+// remove deregisters the connection, removing it again has no effect.
+func (r *registry) remove(c *conn) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byUser[c.userID][c]; !ok {
+		return
+	}
+
+	removeFrom(r.byUser, c.userID, c)
+	removeFrom(r.bySession, c.sessionID, c)
+	r.live.Done()
+}
+
+// user returns connections of the user.
+func (r *registry) user(userID string) []*conn {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return list(r.byUser[userID])
+}
+
+// session returns connections opened with the session.
+func (r *registry) session(sessionID string) []*conn {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return list(r.bySession[sessionID])
+}
+
+// shut refuses new connections and returns the registered ones.
+func (r *registry) shut() []*conn {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.shutdown = true
+
+	var conns []*conn
+	for _, userConns := range r.byUser {
+		conns = append(conns, list(userConns)...)
+	}
+
+	return conns
+}
+
+// isShutdown reports whether the registry refuses new connections.
+func (r *registry) isShutdown() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.shutdown
+}
+
+// wait blocks until all registered connections are removed.
+func (r *registry) wait() {
+	r.live.Wait()
+}
+
+func addTo(index map[string]map[*conn]struct{}, key string, c *conn) {
+	conns, ok := index[key]
+	if !ok {
+		conns = make(map[*conn]struct{})
+		index[key] = conns
+	}
+	conns[c] = struct{}{}
+}
+
+func removeFrom(index map[string]map[*conn]struct{}, key string, c *conn) {
+	delete(index[key], c)
+	if len(index[key]) == 0 {
+		delete(index, key)
+	}
+}
+
+func list(conns map[*conn]struct{}) []*conn {
+	res := make([]*conn, 0, len(conns))
+	for c := range conns {
+		res = append(res, c)
+	}
+
+	return res
+}
@@ -0,0 +1,62 @@
+package websocketserver
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRegistry(t *testing.T) {
+	r := newRegistry()
+
+	first := &conn{userID: "user", sessionID: "first"}
+	second := &conn{userID: "user", sessionID: "second"}
+	other := &conn{userID: "other", sessionID: "third"}
+	for _, c := range []*conn{first, second, other} {
+		if !r.add(c) {
+			t.Fatal("connection wasn't added")
+		}
+	}
+
+	if got := len(r.user("user")); got != 2 {
+		t.Errorf("connections of user = %d, want 2", got)
+	}
+	if got := r.session("first"); len(got) != 1 || got[0] != first {
+		t.Errorf("connections of session = %v, want the first", got)
+	}
+
+	r.remove(first)
+	r.remove(first)
+	if got := len(r.user("user")); got != 1 {
+		t.Errorf("connections of user = %d, want 1", got)
+	}
+	if got := len(r.session("first")); got != 0 {
+		t.Errorf("connections of removed session = %d, want 0", got)
+	}
+
+	if got := len(r.shut()); got != 2 {
+		t.Errorf("shut returned %d connections, want 2", got)
+	}
+	if r.add(&conn{userID: "late", sessionID: "late"}) {
+		t.Error("connection was added after shutdown")
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		r.wait()
+		close(drained)
+	}()
+
+	r.remove(second)
+	select {
+	case <-drained:
+		t.Fatal("wait returned with a live connection")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	r.remove(other)
+	select {
+	case <-drained:
+	case <-time.After(time.Second):
+		t.Fatal("wait didn't return once connections were removed")
+	}
+}
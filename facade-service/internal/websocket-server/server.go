@@ -3,41 +3,112 @@ package websocketserver
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 	pb "github.com/zoninnik89/messenger/common/api"
+	"github.com/zoninnik89/messenger/common/telemetry"
+	"github.com/zoninnik89/messenger/facade-service/internal/config"
 	grpcgateway "github.com/zoninnik89/messenger/facade-service/internal/gateway"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/clientip"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/origin"
+	"github.com/zoninnik89/messenger/facade-service/internal/lib/token"
 	"github.com/zoninnik89/messenger/facade-service/internal/logging"
+	"github.com/zoninnik89/messenger/facade-service/internal/ratelimit"
+	"github.com/zoninnik89/messenger/facade-service/internal/sessions"
+	"github.com/zoninnik89/messenger/facade-service/internal/tickets"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
-var upgrader = websocket.Upgrader{
-	ReadBufferSize:  1024,
-	WriteBufferSize: 1024,
-	CheckOrigin: func(r *http.Request) bool {
-		return true // Allow connections from any origin
-	},
-}
+// instrumentationName names the tracer of spans of messages clients send
+const instrumentationName = "github.com/zoninnik89/messenger/facade-service/internal/websocket-server"
+
+// closeTimeout bounds sending the close frame to a client, and waiting for the client to answer it
+const closeTimeout = time.Second
+
+// shutdownReason is the reason of the close frame sent to clients when the server shuts down
+const shutdownReason = "server is shutting down"
+
+// idleReason is the reason of the close frame sent to clients which sent nothing for the pong wait
+const idleReason = "idle timeout"
+
+const (
+	// ticketParam is the query parameter passing a ticket issued at /ws/ticket
+	ticketParam = "ticket"
+	// tokenParam is the deprecated query parameter passing the auth token
+	tokenParam = "token"
+	// bearerSubprotocol is offered by browsers with the auth token as the next subprotocol, the server
+	// selects it so the token isn't echoed back
+	bearerSubprotocol = "bearer"
+	authCookie        = "auth_token"
+)
+
+var errMissingCredentials = errors.New("missing ticket or auth token")
 
 type WebsocketServer struct {
-	logger *zap.SugaredLogger
-	gw     *grpcgateway.Gateway
-	conns  map[*websocket.Conn]bool
+	logger   *zap.SugaredLogger
+	upgrader websocket.Upgrader
+	gw       *grpcgateway.Gateway
+	tokens   *token.Parser
+	tracker  *sessions.Tracker
+	limiter  *ratelimit.Limiter
+	tickets  *tickets.Tickets
+	tracer   trace.Tracer
+
+	pingInterval time.Duration
+	pongWait     time.Duration
+
+	registry *registry
 }
 
-func NewWebsocketServer(g *grpcgateway.Gateway) *WebsocketServer {
+// NewWebsocketServer returns the server, connections of sessions revoked in the tracker are closed.
+// Messages sent over the connections are limited per user, chat and client IP by the limiter.
+// Browsers may only open connections from the origins of the allowlist. Clients are pinged every ping
+// interval of the config, and connections which receive nothing from the client for the pong wait are closed.
+func NewWebsocketServer(
+	g *grpcgateway.Gateway,
+	tokens *token.Parser,
+	tracker *sessions.Tracker,
+	limiter *ratelimit.Limiter,
+	origins origin.Allowlist,
+	t *tickets.Tickets,
+	cfg config.WebsocketConfig,
+) *WebsocketServer {
 	l := logging.GetLogger().Sugar()
-	return &WebsocketServer{
-		conns:  make(map[*websocket.Conn]bool),
-		gw:     g,
-		logger: l,
+	s := &WebsocketServer{
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			CheckOrigin:     origins.AllowedRequest,
+			// subprotocols are selected by ServeHTTP, see selectSubprotocol
+			EnableCompression: cfg.Compression,
+		},
+		registry: newRegistry(),
+		gw:       g,
+		tokens:   tokens,
+		tracker:  tracker,
+		limiter:  limiter,
+		tickets:  t,
+		tracer:   telemetry.Tracer(instrumentationName),
+		logger:   l,
+
+		pingInterval: cfg.PingInterval,
+		pongWait:     cfg.PongWait,
 	}
+
+	tracker.OnRevoke(s.CloseSession)
+
+	return s
 }
 
 type Message struct {
@@ -46,198 +117,373 @@ type Message struct {
 	MessageText string `json:"message_text"`
 }
 
+// RateLimitedFrame is sent instead of sending the message when the client sends messages too often.
+type RateLimitedFrame struct {
+	Type   string `json:"type"`
+	ChatID string `json:"chat_id"`
+	// Scope is the limit the message exceeded, see ratelimit scopes
+	Scope        ratelimit.Scope `json:"scope"`
+	RetryAfterMs int64           `json:"retry_after_ms"`
+}
+
+const rateLimitedFrameType = "rate_limited"
+
+// ServeHTTP authenticates the client and upgrades the connection to websocket. The client is authenticated
+// either by a ticket issued at /ws/ticket and passed in "ticket" query parameter, or by the auth token,
+// see tokenFromRequest.
 func (s *WebsocketServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	// Access the cookies
-	jwtToken := r.URL.Query().Get("token")
-	if jwtToken == "" {
-		s.logger.Errorw("missing or invalid JWT token", "error", "missing token", "token", jwtToken)
-		w.WriteHeader(http.StatusUnauthorized)
+	if s.registry.isShutdown() {
+		w.WriteHeader(http.StatusServiceUnavailable)
 		return
 	}
 
-	// remove the "Bearer " prefix if present
-	if len(jwtToken) > 7 && jwtToken[:7] == "Bearer " {
-		jwtToken = jwtToken[7:]
+	grant, err := s.authenticate(r)
+	if err != nil {
+		s.logger.Infow("failed to authenticate WebSocket connection", "error", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
 	}
+	userID := grant.UserID
 
-	userID, err := s.validateJWTToken(jwtToken)
-	if err != nil {
-		s.logger.Errorw("invalid JWT token", "error", err)
+	if err := s.tracker.Check(r.Context(), grant.SessionID); err != nil {
+		s.logger.Infow("session revoked", "userID", userID, "sessionID", grant.SessionID)
 		w.WriteHeader(http.StatusUnauthorized)
 		return
 	}
 
-	s.logger.Infow("valid JWT token received, proceeding with WebSocket upgrade", "userID", userID)
+	if !token.Allows(grant.Scopes, token.ScopeChatRead) {
+		s.logger.Infow("insufficient scope", "userID", userID, "scope", token.ScopeChatRead)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	s.logger.Infow("client authenticated, proceeding with WebSocket upgrade", "userID", userID)
+
+	var header http.Header
+	if subprotocol := selectSubprotocol(websocket.Subprotocols(r)); subprotocol != "" {
+		header = http.Header{"Sec-Websocket-Protocol": {subprotocol}}
+	}
 
 	// Upgrade to WebSocket if the token is valid
-	ws, err := upgrader.Upgrade(w, r, nil)
+	ws, err := s.upgrader.Upgrade(w, r, header)
 	if err != nil {
 		s.logger.Errorw("failed to upgrade to WebSocket", "error", err)
 		return
 	}
 
-	s.logger.Infow("WebSocket upgrade successful", "userID", userID)
+	s.logger.Infow("WebSocket upgrade successful", "userID", userID, "subprotocol", ws.Subprotocol())
 
-	s.HandleWS(ws, userID)
+	s.HandleWS(r.Context(), ws, grant, clientip.FromRequest(r))
 }
 
-func (s *WebsocketServer) HandleWS(ws *websocket.Conn, userID string) {
+// HandleWS serves the upgraded connection opened on behalf of the grant. requestCtx is the context of the request
+// which opened the connection, messages the client sends are traced with links to its span, the connection
+// doesn't end with it.
+func (s *WebsocketServer) HandleWS(requestCtx context.Context, ws *websocket.Conn, grant tickets.Grant, clientIP string) {
 	const op = "websocketserver.handleWS"
 
-	ws.SetReadDeadline(time.Now().Add(60 * time.Second)) // Set the initial read deadline
+	userID := grant.UserID
+	c := newConn(ws, userID, grant.SessionID, clientIP, protocolOf(ws.Subprotocol()))
+	c.scopes = grant.Scopes
+	c.trace = trace.LinkFromContext(requestCtx)
+	c.requestID = telemetry.RequestID(requestCtx)
+	if !s.registry.add(c) {
+		// the server started shutting down during the upgrade
+		ws.WriteControl(
+			websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseGoingAway, shutdownReason),
+			time.Now().Add(closeTimeout),
+		)
+		ws.Close()
+		return
+	}
 
-	// Start a goroutine to send periodic ping messages
-	go func() {
-		ticker := time.NewTicker(15 * time.Second)
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-ticker.C:
-				pingMessage := map[string]string{
-					"type": "ping",
-				}
-				messageData, _ := json.Marshal(pingMessage)
-				if err := ws.WriteMessage(websocket.TextMessage, messageData); err != nil {
-					s.logger.Errorw("failed to send ping message", "op", op, "error", err)
-					ws.Close()
-					return
-				} else {
-					//s.logger.Infow("ping message sent", "op", op)
-				}
-			}
-		}
-	}()
+	metrics.Add(metricConnections, 1)
+	s.logger.Infow("connection registered", "op", op, "userID", userID, "userConnections", len(s.registry.user(userID)))
+
+	// the stream of messages ends with the connection
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// the client has the pong wait to answer the first ping, every pong gives it another one
+	ws.SetReadDeadline(time.Now().Add(s.pongWait))
+	ws.SetPongHandler(func(string) error {
+		return ws.SetReadDeadline(time.Now().Add(s.pongWait))
+	})
 
-	s.conns[ws] = true // add mutex
+	go c.writeLoop(s.pingInterval)
+	go s.watchSession(ctx, c)
 
 	messagesChan := make(chan *pb.Message)
-	done := make(chan struct{})
 
 	errMessage, _ := json.Marshal("failed to get message stream on backend")
 
 	// Start a goroutine to establish the gRPC stream and read messages
 	go func() {
-		err := s.gw.GetMessagesStream(context.Background(), &pb.GetMessagesStreamRequest{UserId: userID}, messagesChan)
+		err := s.gw.GetMessagesStream(ctx, &pb.GetMessagesStreamRequest{UserId: userID}, messagesChan)
 		if err != nil {
 			s.logger.Errorw("failed to get message stream", "op", op, "error", err)
-			ws.WriteMessage(websocket.TextMessage, errMessage)
-			ws.Close()
+			c.send(errMessage)
+			c.close(websocket.CloseInternalServerErr, "message stream failed")
 			return
 		}
 	}()
 
-	// Start a goroutine to read messages from the gRPC stream and send to WebSocket
+	// Start a goroutine to read messages from the gRPC stream and queue them to the connection,
+	// the gateway closes the channel once the stream ends or fails to open
 	go func() {
-		for {
-			select {
-			case msg := <-messagesChan:
-				// Serialize the message to JSON
-				messageData, err := json.Marshal(msg)
-				if err != nil {
-					s.logger.Errorw("failed to marshal message", "op", op, "error", err)
-					continue
-				}
-
-				// Send the message to the WebSocket
-				if err := ws.WriteMessage(websocket.TextMessage, messageData); err != nil {
-					s.logger.Errorw("failed to send message to WebSocket", "op", op, "error", err)
-					break
-				}
-			case <-done:
-				// Stop reading messages when done signal is received
-				return
+		for msg := range messagesChan {
+			sent, err := c.sendMessage(msg)
+			if err != nil {
+				s.logger.Errorw("failed to marshal message", "op", op, "error", err)
+				continue
+			}
+
+			if !sent {
+				s.logger.Warnw("message dropped, connection is closing", "op", op, "userID", userID, "messageID", msg.GetMessageId())
 			}
 		}
 	}()
 
-	// Run the ReadLoop in a separate goroutine
-	go s.ReadLoop(ws, userID, done)
+	go func() {
+		s.readLoop(c)
 
+		cancel()
+		s.cleanupConnection(c)
+	}()
 }
 
-func (s *WebsocketServer) ReadLoop(ws *websocket.Conn, userID string, done chan struct{}) {
+func (s *WebsocketServer) readLoop(c *conn) {
 	const op = "websocketserver.readLoop"
 
+	defer close(c.readDone)
+
+	ws, userID := c.ws, c.userID
+
 	for {
 		// Read a message from the WebSocket
 		messageType, messageData, err := ws.ReadMessage()
 		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				metrics.Add(metricIdleClosed, 1)
+				s.logger.Infow("closing idle WebSocket connection", "op", op, "userID", userID, "pongWait", s.pongWait)
+				c.close(websocket.CloseNormalClosure, idleReason)
+				return
+			}
+
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				s.logger.Errorw("unexpected WebSocket closure", "op", op, "err", err)
 			} else {
 				s.logger.Infow("client closed WebSocket connection", "op", op, "err", err)
 			}
-			break
+			return
 		}
-		s.logger.Infow("message received", "op", op, "userID", userID, "messageType", messageType, "message", string(messageData))
+		// any frame shows the client is alive, not only pongs
+		ws.SetReadDeadline(time.Now().Add(s.pongWait))
 
-		var messageParsed Message
-		err = json.Unmarshal(messageData, &messageParsed)
+		messageParsed, err := c.protocol.decode(messageType, messageData)
 		if err != nil {
 			errUnmarshalMessage, _ := json.Marshal(err.Error())
-			ws.WriteMessage(websocket.TextMessage, errUnmarshalMessage)
-			s.logger.Errorw("error unmarshaling JSON message", "op", op, "err", err)
+			c.send(errUnmarshalMessage)
+			s.logger.Errorw("error decoding message", "op", op, "err", err)
 			continue
 		}
 
+		s.logger.Infow("message received", "op", op, "userID", userID, "messageType", messageType, "message", messageParsed.MessageText)
+
+		// clients written for JSON pings, which were sent before ping control frames, may still send pongs
 		if messageParsed.Type == "pong" {
-			//s.logger.Infow("pong received from user", "op", op, "userID", userID)
-			ws.SetReadDeadline(time.Now().Add(16 * time.Second)) // Extend the deadline when a pong is received
 			continue
 		}
 
-		messageID := uuid.New().String()
+		s.handleMessage(c, messageParsed)
+	}
+}
+
+// handleMessage sends the chat message the client sent. Every message is traced in its own span, which is
+// linked to the span of the request which opened the connection, as that span lasts as long as the connection.
+func (s *WebsocketServer) handleMessage(c *conn, msg Message) {
+	const op = "websocketserver.handleMessage"
+
+	userID := c.userID
+
+	ctx, span := s.tracer.Start(telemetry.WithRequestID(context.Background(), c.requestID), "websocket message",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithLinks(c.trace),
+	)
+	defer span.End()
 
-		messageToBeSent := &pb.Message{
-			ChatId:      messageParsed.ChatID,
-			SenderId:    userID,
-			MessageId:   messageID,
-			MessageText: messageParsed.MessageText,
-			SentTs:      strconv.FormatInt(time.Now().Unix(), 10),
+	if !token.Allows(c.scopes, token.ScopeChatWrite) {
+		s.logger.Infow("insufficient scope", "op", op, "userID", userID, "scope", token.ScopeChatWrite)
+
+		errInsufficientScope, _ := json.Marshal("insufficient scope")
+		c.send(errInsufficientScope)
+		return
+	}
+
+	res := s.limiter.Allow(
+		ctx,
+		ratelimit.Key{Scope: ratelimit.ScopeUser, ID: userID},
+		ratelimit.Key{Scope: ratelimit.ScopeChat, ID: msg.ChatID},
+		ratelimit.Key{Scope: ratelimit.ScopeIP, ID: c.clientIP},
+	)
+	if !res.Allowed {
+		s.logger.Infow("message rate limited", "op", op, "userID", userID, "chatID", msg.ChatID, "scope", res.Scope, "retryAfter", res.RetryAfter)
+
+		rateLimited, _ := json.Marshal(RateLimitedFrame{
+			Type:         rateLimitedFrameType,
+			ChatID:       msg.ChatID,
+			Scope:        res.Scope,
+			RetryAfterMs: res.RetryAfter.Milliseconds(),
+		})
+		c.send(rateLimited)
+		return
+	}
+
+	messageID := uuid.New().String()
+	span.SetAttributes(semconv.MessagingMessageID(messageID))
+
+	messageToBeSent := &pb.Message{
+		ChatId:      msg.ChatID,
+		SenderId:    userID,
+		MessageId:   messageID,
+		MessageText: msg.MessageText,
+		SentTs:      strconv.FormatInt(time.Now().Unix(), 10),
+	}
+
+	_, err := s.gw.SendMessage(
+		ctx,
+		&pb.SendMessageRequest{
+			Message: messageToBeSent,
+		},
+	)
+	if err != nil {
+		s.logger.Errorw("failed to send message to Chat client via GRPC", "op", op, "messageID", messageID, "error", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		if errors.Is(err, grpcgateway.ErrMessageRejected) {
+			errMessageRejected, _ := json.Marshal(err.Error())
+			c.send(errMessageRejected)
 		}
+	} else {
+		s.logger.Infow("successfully sent message to Chat client via GRPC", "op", op, "userID", userID, "messageID", messageID)
+	}
+}
 
-		_, err = s.gw.SendMessage(
-			context.Background(),
-			&pb.SendMessageRequest{
-				Message: messageToBeSent,
-			},
-		)
-		if err != nil {
-			s.logger.Errorw("failed to send message to Chat client via GRPC", "op", op, "messageID", messageID, "error", err)
-		} else {
-			s.logger.Infow("successfully sent message to Chat client via GRPC", "op", op, "userID", userID, "messageID", messageID)
+// watchSession closes the connection once its session is revoked. Revocations are also pushed by
+// the tracker, the check catches those missed by this instance.
+func (s *WebsocketServer) watchSession(ctx context.Context, c *conn) {
+	ticker := time.NewTicker(s.pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.tracker.Check(ctx, c.sessionID); err != nil {
+				s.CloseSession(c.sessionID)
+				return
+			}
+		case <-c.closing:
+			return
 		}
+	}
+}
+
+// CloseSession closes live connections opened with the session, clients are told the session was revoked
+// by policy violation close code.
+func (s *WebsocketServer) CloseSession(sessionID string) {
+	const op = "websocketserver.CloseSession"
 
+	conns := s.registry.session(sessionID)
+	for _, c := range conns {
+		// the read loop fails on the closed connection and cleans it up
+		c.close(websocket.ClosePolicyViolation, sessions.ErrSessionRevoked.Error())
 	}
 
-	close(done)
-	s.cleanupConnection(ws)
+	if len(conns) > 0 {
+		s.logger.Infow("connections of revoked session closed", "op", op, "sessionID", sessionID, "count", len(conns))
+	}
 }
 
-func (s *WebsocketServer) cleanupConnection(ws *websocket.Conn) {
-	// Remove the connection from the map and close the WebSocket
-	delete(s.conns, ws)
-	ws.Close()
+// Shutdown stops accepting connections and closes live ones with going away close code once frames queued
+// to them are written. It waits until the connections are closed or the context is done.
+func (s *WebsocketServer) Shutdown(ctx context.Context) error {
+	const op = "websocketserver.Shutdown"
+
+	conns := s.registry.shut()
+	for _, c := range conns {
+		c.close(websocket.CloseGoingAway, shutdownReason)
+	}
+
+	s.logger.Infow("closing connections", "op", op, "count", len(conns))
+
+	drained := make(chan struct{})
+	go func() {
+		s.registry.wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%s: %w", op, ctx.Err())
+	}
 }
 
-func (s *WebsocketServer) validateJWTToken(tokenString string) (string, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Check the signing method and provide the secret key
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte("secret"), nil // TO DO: Replace with a real secret going forward
-	})
+// cleanupConnection closes the connection once its writer is done and removes it from the registry.
+func (s *WebsocketServer) cleanupConnection(c *conn) {
+	c.close(websocket.CloseNormalClosure, "")
+	<-c.closed
+	s.registry.remove(c)
+	metrics.Add(metricConnections, -1)
+}
+
+// authenticate returns the user and session the connection is opened on behalf of.
+func (s *WebsocketServer) authenticate(r *http.Request) (tickets.Grant, error) {
+	if ticket := r.URL.Query().Get(ticketParam); ticket != "" {
+		return s.tickets.Redeem(r.Context(), ticket)
+	}
+
+	tokenString := tokenFromRequest(r)
+	if tokenString == "" {
+		return tickets.Grant{}, errMissingCredentials
+	}
 
+	claims, err := s.validateJWTToken(tokenString)
 	if err != nil {
-		return "", err
+		return tickets.Grant{}, err
+	}
+
+	return tickets.Grant{UserID: claims.UserID, SessionID: claims.SessionID, Scopes: claims.Scopes}, nil
+}
+
+// tokenFromRequest returns the auth token passed in one of the ways websocket clients can pass it:
+//   - "Authorization: Bearer <token>" header, by clients other than browsers;
+//   - "bearer" subprotocol followed by the token in "Sec-WebSocket-Protocol" header, by browsers which
+//     can't set other headers;
+//   - "auth_token" cookie set on login;
+//   - "token" query parameter. It is deprecated, as URLs end up in logs of proxies.
+func tokenFromRequest(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); header != "" {
+		return strings.TrimPrefix(header, "Bearer ")
+	}
+
+	protocols := websocket.Subprotocols(r)
+	for i, protocol := range protocols {
+		if protocol == bearerSubprotocol && i+1 < len(protocols) {
+			return protocols[i+1]
+		}
 	}
 
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		userID := claims["uid"].(string)
-		return userID, nil
+	if cookie, err := r.Cookie(authCookie); err == nil && cookie.Value != "" {
+		return cookie.Value
 	}
 
-	return "", fmt.Errorf("invalid token")
+	return strings.TrimPrefix(r.URL.Query().Get(tokenParam), "Bearer ")
+}
+
+func (s *WebsocketServer) validateJWTToken(tokenString string) (token.Claims, error) {
+	return s.tokens.Parse(tokenString)
 }
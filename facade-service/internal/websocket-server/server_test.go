@@ -0,0 +1,71 @@
+package websocketserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTokenFromRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(r *http.Request)
+		want    string
+	}{
+		{
+			name:    "authorization header",
+			prepare: func(r *http.Request) { r.Header.Set("Authorization", "Bearer header-token") },
+			want:    "header-token",
+		},
+		{
+			name:    "bearer subprotocol",
+			prepare: func(r *http.Request) { r.Header.Set("Sec-WebSocket-Protocol", "bearer, protocol-token") },
+			want:    "protocol-token",
+		},
+		{
+			name:    "bearer subprotocol after others",
+			prepare: func(r *http.Request) { r.Header.Set("Sec-WebSocket-Protocol", "chat, bearer, protocol-token") },
+			want:    "protocol-token",
+		},
+		{
+			name:    "bearer subprotocol without token",
+			prepare: func(r *http.Request) { r.Header.Set("Sec-WebSocket-Protocol", "bearer") },
+			want:    "",
+		},
+		{
+			name:    "cookie",
+			prepare: func(r *http.Request) { r.AddCookie(&http.Cookie{Name: authCookie, Value: "cookie-token"}) },
+			want:    "cookie-token",
+		},
+		{
+			name:    "deprecated query parameter",
+			prepare: func(r *http.Request) { r.URL.RawQuery = "token=Bearer+query-token" },
+			want:    "query-token",
+		},
+		{
+			name: "header takes precedence",
+			prepare: func(r *http.Request) {
+				r.Header.Set("Authorization", "Bearer header-token")
+				r.AddCookie(&http.Cookie{Name: authCookie, Value: "cookie-token"})
+				r.URL.RawQuery = "token=query-token"
+			},
+			want: "header-token",
+		},
+		{
+			name:    "none",
+			prepare: func(*http.Request) {},
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/ws", nil)
+			tt.prepare(r)
+
+			if got := tokenFromRequest(r); got != tt.want {
+				t.Errorf("expected token %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+// Package server runs facade service in the process of a test.
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/zoninnik89/messenger/common/discovery"
+	"github.com/zoninnik89/messenger/facade-service/internal/audit"
+	"github.com/zoninnik89/messenger/facade-service/internal/config"
+	grpcgateway "github.com/zoninnik89/messenger/facade-service/internal/gateway"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/clientip"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/middleware/origin"
+	"github.com/zoninnik89/messenger/facade-service/internal/http-server/router"
+	"github.com/zoninnik89/messenger/facade-service/internal/lib/token"
+	"github.com/zoninnik89/messenger/facade-service/internal/ratelimit"
+	"github.com/zoninnik89/messenger/facade-service/internal/sessions"
+	"github.com/zoninnik89/messenger/facade-service/internal/tickets"
+	websocketserver "github.com/zoninnik89/messenger/facade-service/internal/websocket-server"
+)
+
+const host = "127.0.0.1"
+
+// shutdownTimeout bounds closing websocket connections of clients which don't answer close frames
+const shutdownTimeout = 3 * time.Second
+
+// Server is facade service listening on an ephemeral port.
+type Server struct {
+	// Addr is the address of the HTTP server
+	Addr string
+	// URL is the base URL of the HTTP API, websocket connections are accepted at URL + "/ws" with "ws" scheme
+	URL string
+	Cfg *config.Config
+
+	httpServer *http.Server
+	wsServer   *websocketserver.WebsocketServer
+	gateway    *grpcgateway.Gateway
+	registry   discovery.Registry
+	instanceID string
+}
+
+// Start starts the service with the local config and registers it in the registry. Backend services are
+// found in the registry, audit events are only logged and revoked sessions are found by checking them in sso.
+func Start(registry discovery.Registry) (*Server, error) {
+	const op = "server.Start"
+
+	cfg := config.MustLoadByPath(filepath.Join(moduleDir(), "config", "local.yaml"))
+	cfg.Audit.Publisher = "log"
+	cfg.Sessions.Consumer = "none"
+
+	gateway := grpcgateway.NewGRPCGateway(registry)
+	tokens := token.NewParser(cfg.Auth.AppID, cfg.Auth.AppSecret)
+
+	auditRecorder, err := audit.New(cfg.Audit.Publisher, cfg.Audit.KafkaAddress, cfg.HTTPServer.Name)
+	if err != nil {
+		_ = gateway.Close()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	tracker := sessions.NewTracker(gateway, cfg.Sessions.CheckInterval)
+
+	limiter, err := ratelimit.New(cfg.RateLimit, cfg.Redis)
+	if err != nil {
+		_ = gateway.Close()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	wsTickets, err := tickets.New(cfg.Websocket, cfg.Redis)
+	if err != nil {
+		_ = gateway.Close()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	wsServer := websocketserver.NewWebsocketServer(
+		gateway,
+		tokens,
+		tracker,
+		limiter,
+		origin.Allowlist(cfg.CORS.AllowedOrigins),
+		wsTickets,
+		cfg.Websocket,
+	)
+
+	trustedProxies, err := clientip.ParseProxies(cfg.HTTPServer.TrustedProxies)
+	if err != nil {
+		_ = gateway.Close()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	l, err := net.Listen("tcp", net.JoinHostPort(host, "0"))
+	if err != nil {
+		_ = gateway.Close()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	handler := router.New(cfg, gateway, tokens, tracker, auditRecorder, wsServer, limiter, wsTickets, trustedProxies)
+	httpServer := &http.Server{Handler: handler}
+	go func() {
+		_ = httpServer.Serve(l)
+	}()
+
+	port := l.Addr().(*net.TCPAddr).Port
+	cfg.HTTPServer.Port = port
+
+	s := &Server{
+		Addr:       net.JoinHostPort(host, strconv.Itoa(port)),
+		Cfg:        cfg,
+		httpServer: httpServer,
+		wsServer:   wsServer,
+		gateway:    gateway,
+		registry:   registry,
+		instanceID: discovery.GenerateInstanceID(cfg.HTTPServer.Name),
+	}
+	s.URL = "http://" + s.Addr
+
+	if err := registry.Register(context.Background(), s.instanceID, host, port, cfg.HTTPServer.Name); err != nil {
+		s.Stop()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return s, nil
+}
+
+// Stop deregisters the service, closes websocket connections, the HTTP server and connections to backend services.
+func (s *Server) Stop() {
+	_ = s.registry.Deregister(context.Background(), s.instanceID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	_ = s.wsServer.Shutdown(ctx)
+
+	_ = s.httpServer.Close()
+	_ = s.gateway.Close()
+}
+
+// moduleDir returns the root directory of facade module, so the server starts the same way from any test package
+func moduleDir() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..")
+}
@@ -2,8 +2,10 @@ package main
 
 import (
 	"context"
+	"github.com/zoninnik89/messenger/common"
 	"github.com/zoninnik89/messenger/common/discovery"
-	"github.com/zoninnik89/messenger/common/discovery/consul"
+	"github.com/zoninnik89/messenger/common/discovery/backend"
+	"github.com/zoninnik89/messenger/common/telemetry"
 	"github.com/zoninnik89/messenger/pub-sub/internal/app"
 	"github.com/zoninnik89/messenger/pub-sub/internal/config"
 	c "github.com/zoninnik89/messenger/pub-sub/internal/consumer"
@@ -22,9 +24,22 @@ func main() {
 
 	logger.Info("starting pub-sub service")
 
-	registry, err := consul.NewRegistry(cfg.GRPC.Address, cfg.Consul.Port)
+	shutdownTracing, err := telemetry.Init(context.Background(), cfg.GRPC.Name, cfg.Tracing)
 	if err != nil {
-		logger.Panic("failed to connect to Consul", zap.Error(err))
+		logger.Panic("failed to set up tracing", zap.Error(err))
+		panic(err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Warn("failed to flush spans", zap.Error(err))
+		}
+	}()
+
+	registry, err := backend.New(cfg.Discovery, cfg.GRPC.Address, cfg.Consul.Port)
+	if err != nil {
+		logger.Panic("failed to create service registry", zap.Error(err))
 		panic(err)
 	}
 
@@ -44,7 +59,7 @@ func main() {
 		}
 	}()
 
-	defer func(registry *consul.Registry, ctx context.Context, instanceID string) {
+	defer func(registry discovery.Registry, ctx context.Context, instanceID string) {
 		err := registry.Deregister(ctx, instanceID)
 		if err != nil {
 			logger.Fatal("failed to deregister service", zap.Error(err))
@@ -61,14 +76,27 @@ func main() {
 	}
 
 	topics := []string{"messages"}
-	err = consumer.SubscribeTopics(topics, nil)
+	err = consumer.SubscribeTopics(topics)
 	if err != nil {
 		panic(err)
 	}
 
-	application := app.NewApp(cfg.GRPC.Port, cfg.Storage.ChanBuffer)
+	// chat memberships are kept in memory of every instance, so every instance consumes all account events
+	accountEventsConsumer, err := c.NewAccountEventsConsumer(instanceID)
+	if err != nil {
+		logger.Panic("failed to create account events consumer", zap.Error(err))
+		panic(err)
+	}
+
+	err = accountEventsConsumer.SubscribeTopics([]string{common.AccountEventsTopic})
+	if err != nil {
+		panic(err)
+	}
+
+	application := app.NewApp(cfg.GRPC.Port, cfg.Storage.ChanBuffer, registry, cfg.Relations.BlockersTTL, cfg.Chats.ParticipantsTTL)
 	go application.GRPCsrv.MustRun()
 	go application.GRPCsrv.MustConsume(ctxWithCancel, consumer)
+	go application.GRPCsrv.MustConsumeAccountEvents(ctxWithCancel, accountEventsConsumer)
 
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, syscall.SIGTERM, syscall.SIGINT)
@@ -79,6 +107,9 @@ func main() {
 
 	cancel()
 	application.GRPCsrv.Stop()
+	if err := application.Close(); err != nil {
+		logger.Warn("failed to close connections to services", zap.Error(err))
+	}
 
 	logger.Info("shut down gracefully")
 }
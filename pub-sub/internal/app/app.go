@@ -1,20 +1,38 @@
 package app
 
 import (
+	"github.com/zoninnik89/messenger/common/discovery"
 	grpcapp "github.com/zoninnik89/messenger/pub-sub/internal/app/grpc"
+	"github.com/zoninnik89/messenger/pub-sub/internal/chats"
+	"github.com/zoninnik89/messenger/pub-sub/internal/relations"
 	"github.com/zoninnik89/messenger/pub-sub/internal/service"
+	"time"
 )
 
 type App struct {
 	GRPCsrv *grpcapp.App
+
+	pool *discovery.Pool
 }
 
-func NewApp(grpcPort int, charBuffer int) *App {
-	pubSubService := service.NewPubSubService(charBuffer)
+// NewApp returns pub-sub application. Connections to services discovered in the registry are shared
+// by all messages, Close closes them.
+func NewApp(grpcPort int, charBuffer int, r discovery.Registry, blockersTTL time.Duration, participantsTTL time.Duration) *App {
+	pool := discovery.NewPool(r)
+	blockers := relations.NewBlockers(pool, blockersTTL)
+	muters := relations.NewMuters(pool, blockersTTL)
+	groups := chats.NewParticipants(pool, participantsTTL)
+	pubSubService := service.NewPubSubService(charBuffer, blockers, muters, groups)
 
 	grpcApp := grpcapp.NewApp(pubSubService, grpcPort)
 
 	return &App{
 		GRPCsrv: grpcApp,
+		pool:    pool,
 	}
 }
+
+// Close closes connections to the services.
+func (a *App) Close() error {
+	return a.pool.Close()
+}
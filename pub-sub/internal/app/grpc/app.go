@@ -3,7 +3,8 @@ package grpcapp
 import (
 	"context"
 	"fmt"
-	"github.com/confluentinc/confluent-kafka-go/kafka"
+	"github.com/zoninnik89/messenger/common/bus"
+	"github.com/zoninnik89/messenger/common/telemetry"
 	pubsubgrpc "github.com/zoninnik89/messenger/pub-sub/internal/grpc"
 	"github.com/zoninnik89/messenger/pub-sub/internal/logging"
 	"github.com/zoninnik89/messenger/pub-sub/internal/types"
@@ -25,7 +26,7 @@ func NewApp(
 	port int) *App {
 
 	l := logging.GetLogger().Sugar()
-	grpcServer := grpc.NewServer()
+	grpcServer := grpc.NewServer(telemetry.ServerOption())
 	pubsubgrpc.Register(grpcServer, pubSubService)
 
 	return &App{
@@ -43,18 +44,40 @@ func (a *App) MustRun() {
 	}
 }
 
-func (a *App) MustConsume(ctx context.Context, consumer *kafka.Consumer) {
+func (a *App) MustConsume(ctx context.Context, consumer bus.Consumer) {
 	const op = "grpcapp.MustConsume"
 
 	for {
 		status, err := a.service.ConsumeAndSendoutMessage(ctx, consumer)
+		if ctx.Err() != nil {
+			return
+		}
+
 		if err != nil {
-			a.logger.Warnw("error consuming a message", "op", op, err, zap.Error(err))
+			a.logger.Warnw("error consuming a message", "op", op, zap.Error(err))
+			time.Sleep(time.Second * 1)
 		} else {
 			a.logger.Infow("message was consumed", "op", op, "status", status)
 		}
+	}
+}
+
+func (a *App) MustConsumeAccountEvents(ctx context.Context, consumer bus.Consumer) {
+	const op = "grpcapp.MustConsumeAccountEvents"
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
 
-		time.Sleep(time.Second * 1)
+		userID, err := a.service.ConsumeAccountEvent(ctx, consumer)
+		if err != nil {
+			a.logger.Warnw("error consuming an account event", "op", op, zap.Error(err))
+		} else {
+			a.logger.Infow("account event was consumed", "op", op, "userID", userID)
+		}
 	}
 }
 
@@ -68,12 +91,21 @@ func (a *App) Run() error {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	addr := l.Addr().String()
+	if err := a.Serve(l); err != nil {
+		a.logger.Fatalw("failed to serve", "op", op, "err", err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
 
-	a.logger.Infow("grpc server is listening", "op", op, "addr", addr)
+	return nil
+}
+
+// Serve serves gRPC requests accepted by the listener until the server is stopped.
+func (a *App) Serve(l net.Listener) error {
+	const op = "grpcapp.Serve"
+
+	a.logger.Infow("grpc server is listening", "op", op, "addr", l.Addr().String())
 
 	if err := a.grpcServer.Serve(l); err != nil {
-		a.logger.Fatalw("failed to serve", "op", op, "err", err)
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
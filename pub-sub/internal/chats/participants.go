@@ -0,0 +1,64 @@
+package chats
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	pb "github.com/zoninnik89/messenger/common/api"
+	"github.com/zoninnik89/messenger/common/discovery"
+	"github.com/zoninnik89/messenger/pub-sub/internal/storage"
+)
+
+const chatHistoryServiceName = "chat-history"
+
+type cachedParticipants struct {
+	participants *storage.HashSet
+	expiresAt    time.Time
+}
+
+// Participants resolves participants of group chats. Groups are kept by chat-history service, results are
+// cached for ttl, so fan-out does not hit chat-history for every message. Users added to or removed from
+// a group may miss or still get its messages until the cached participants expire.
+type Participants struct {
+	pool  *discovery.Pool
+	ttl   time.Duration
+	cache sync.Map
+}
+
+// NewParticipants returns participants asked from chat-history service over a connection of the pool.
+func NewParticipants(pool *discovery.Pool, ttl time.Duration) *Participants {
+	return &Participants{
+		pool: pool,
+		ttl:  ttl,
+	}
+}
+
+// ParticipantsOf returns a set of IDs of users participating in the chat.
+func (p *Participants) ParticipantsOf(ctx context.Context, chatID string) (*storage.HashSet, error) {
+	const op = "chats.ParticipantsOf"
+
+	if cached, ok := p.cache.Load(chatID); ok && time.Now().Before(cached.(cachedParticipants).expiresAt) {
+		return cached.(cachedParticipants).participants, nil
+	}
+
+	conn, err := p.pool.Conn(chatHistoryServiceName)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	res, err := pb.NewChatHistoryServiceClient(conn).GetChatParticipants(ctx, &pb.GetChatParticipantsRequest{ChatId: chatID})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	participants := storage.NewHashSet()
+	for _, id := range res.GetParticipantIds() {
+		participants.Add(id)
+	}
+
+	p.cache.Store(chatID, cachedParticipants{participants: participants, expiresAt: time.Now().Add(p.ttl)})
+
+	return participants, nil
+}
@@ -4,16 +4,22 @@ import (
 	"flag"
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/zoninnik89/messenger/common"
+	"github.com/zoninnik89/messenger/common/discovery/backend"
+	"github.com/zoninnik89/messenger/common/telemetry"
 	"os"
 	"time"
 )
 
 type Config struct {
-	Env     string        `yaml:"env" env-default:"local"`
-	GRPC    GRPCConfig    `yaml:"grpc"`
-	Kafka   KafkaConfig   `yaml:"kafka"`
-	Consul  ConsulConfig  `yaml:"consul"`
-	Storage StorageConfig `yaml:"storage"`
+	Env       string           `yaml:"env" env-default:"local"`
+	GRPC      GRPCConfig       `yaml:"grpc"`
+	Kafka     KafkaConfig      `yaml:"kafka"`
+	Consul    ConsulConfig     `yaml:"consul"`
+	Discovery backend.Config   `yaml:"discovery"`
+	Storage   StorageConfig    `yaml:"storage"`
+	Relations RelationsConfig  `yaml:"relations"`
+	Chats     ChatsConfig      `yaml:"chats"`
+	Tracing   telemetry.Config `yaml:"tracing"`
 }
 
 type GRPCConfig struct {
@@ -37,6 +43,16 @@ type StorageConfig struct {
 	ChanBuffer int `yaml:"chan_buffer"`
 }
 
+type RelationsConfig struct {
+	// BlockersTTL is how long blockers and muters of a sender are cached
+	BlockersTTL time.Duration `yaml:"blockers_ttl" env-default:"30s"`
+}
+
+type ChatsConfig struct {
+	// ParticipantsTTL is how long participants of a group are cached
+	ParticipantsTTL time.Duration `yaml:"participants_ttl" env-default:"5s"`
+}
+
 func MustLoad() *Config {
 	path := fetchConfigPath()
 	if path == "" {
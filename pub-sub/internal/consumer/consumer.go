@@ -3,13 +3,14 @@ package consumer
 import (
 	"github.com/confluentinc/confluent-kafka-go/kafka"
 	common "github.com/zoninnik89/messenger/common"
+	kafkabus "github.com/zoninnik89/messenger/common/bus/kafka"
 )
 
 var (
 	KafkaServerAddress = common.EnvString("KAFKA_SERVER_ADDRESS", "localhost:9092")
 )
 
-func NewKafkaConsumer() (*kafka.Consumer, error) {
+func NewKafkaConsumer() (*kafkabus.Consumer, error) {
 
 	configMap := &kafka.ConfigMap{
 		"bootstrap.servers": KafkaServerAddress,
@@ -17,10 +18,19 @@ func NewKafkaConsumer() (*kafka.Consumer, error) {
 		"group.id":          "pub-sub-group",
 	}
 
-	c, err := kafka.NewConsumer(configMap)
+	return kafkabus.NewConsumer(configMap)
+}
+
+// NewAccountEventsConsumer creates a consumer of account events, every pub-sub instance keeps its own
+// chat memberships in memory, so each of them must use its own group to receive all events
+func NewAccountEventsConsumer(groupID string) (*kafkabus.Consumer, error) {
 
-	if err != nil {
-		return nil, err
+	configMap := &kafka.ConfigMap{
+		"bootstrap.servers": KafkaServerAddress,
+		"client.id":         "pub-sub-account-events-consumer",
+		"group.id":          groupID,
+		"auto.offset.reset": "latest",
 	}
-	return c, nil
+
+	return kafkabus.NewConsumer(configMap)
 }
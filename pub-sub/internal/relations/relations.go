@@ -0,0 +1,115 @@
+package relations
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	pb "github.com/zoninnik89/messenger/common/api"
+	"github.com/zoninnik89/messenger/common/discovery"
+	"github.com/zoninnik89/messenger/pub-sub/internal/storage"
+)
+
+const ssoServiceName = "sso-service"
+
+type cachedUsers struct {
+	users     *storage.HashSet
+	expiresAt time.Time
+}
+
+// relatedUsers resolves users having a relation to a given user with list. Results are fetched
+// from sso service and cached for ttl, so fan-out does not hit sso for every message.
+type relatedUsers struct {
+	pool  *discovery.Pool
+	ttl   time.Duration
+	cache sync.Map
+	list  func(ctx context.Context, client pb.RelationsServiceClient, userID string) ([]string, error)
+}
+
+func (r *relatedUsers) of(ctx context.Context, userID string) (*storage.HashSet, error) {
+	if cached, ok := r.cache.Load(userID); ok && time.Now().Before(cached.(cachedUsers).expiresAt) {
+		return cached.(cachedUsers).users, nil
+	}
+
+	conn, err := r.pool.Conn(ssoServiceName)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := r.list(ctx, pb.NewRelationsServiceClient(conn), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	users := storage.NewHashSet()
+	for _, id := range ids {
+		users.Add(id)
+	}
+
+	r.cache.Store(userID, cachedUsers{users: users, expiresAt: time.Now().Add(r.ttl)})
+
+	return users, nil
+}
+
+// Blockers resolves which users have blocked a given user.
+type Blockers struct {
+	users relatedUsers
+}
+
+// NewBlockers returns blockers asked from sso service over a connection of the pool.
+func NewBlockers(pool *discovery.Pool, ttl time.Duration) *Blockers {
+	return &Blockers{
+		users: relatedUsers{
+			pool: pool,
+			ttl:  ttl,
+			list: func(ctx context.Context, client pb.RelationsServiceClient, userID string) ([]string, error) {
+				res, err := client.ListBlockers(ctx, &pb.ListBlockersRequest{UserId: userID})
+				return res.GetUserIds(), err
+			},
+		},
+	}
+}
+
+// BlockersOf returns a set of IDs of users who have blocked the given user.
+func (b *Blockers) BlockersOf(ctx context.Context, userID string) (*storage.HashSet, error) {
+	const op = "relations.BlockersOf"
+
+	blockers, err := b.users.of(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return blockers, nil
+}
+
+// Muters resolves which users have muted a given user.
+type Muters struct {
+	users relatedUsers
+}
+
+// NewMuters returns muters asked from sso service over a connection of the pool.
+func NewMuters(pool *discovery.Pool, ttl time.Duration) *Muters {
+	return &Muters{
+		users: relatedUsers{
+			pool: pool,
+			ttl:  ttl,
+			list: func(ctx context.Context, client pb.RelationsServiceClient, userID string) ([]string, error) {
+				res, err := client.ListMuters(ctx, &pb.ListMutersRequest{UserId: userID})
+				return res.GetUserIds(), err
+			},
+		},
+	}
+}
+
+// MutersOf returns a set of IDs of users who have muted the given user.
+func (m *Muters) MutersOf(ctx context.Context, userID string) (*storage.HashSet, error) {
+	const op = "relations.MutersOf"
+
+	muters, err := m.users.of(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return muters, nil
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"github.com/confluentinc/confluent-kafka-go/kafka"
+	"github.com/zoninnik89/messenger/common"
 	pb "github.com/zoninnik89/messenger/common/api"
+	"github.com/zoninnik89/messenger/common/bus"
+	"github.com/zoninnik89/messenger/common/telemetry"
 	"github.com/zoninnik89/messenger/pub-sub/internal/logging"
 	"github.com/zoninnik89/messenger/pub-sub/internal/storage"
+	"github.com/zoninnik89/messenger/pub-sub/internal/types"
 	"go.uber.org/zap"
 	"google.golang.org/protobuf/proto"
 	"strconv"
@@ -18,15 +21,26 @@ type PubSubService struct {
 	ChatsParticipants *storage.ChatParticipantsStorage
 	UserChats         *storage.UsersChats
 	Logger            *zap.SugaredLogger
+	blockers          types.BlockersProvider
+	muters            types.MutersProvider
+	groups            types.ParticipantsProvider
 	chanBuffer        int
 }
 
-func NewPubSubService(chanBuffer int) *PubSubService {
+func NewPubSubService(
+	chanBuffer int,
+	blockers types.BlockersProvider,
+	muters types.MutersProvider,
+	groups types.ParticipantsProvider,
+) *PubSubService {
 	return &PubSubService{
 		Connections:       storage.NewClientConnStorage(),
 		ChatsParticipants: storage.NewChatParticipantsStorage(),
 		UserChats:         storage.NewUsersChats(),
 		Logger:            logging.GetLogger().Sugar(),
+		blockers:          blockers,
+		muters:            muters,
+		groups:            groups,
 		chanBuffer:        chanBuffer,
 	}
 }
@@ -50,14 +64,15 @@ func (p *PubSubService) Subscribe(userID string, stream pb.PubSubService_Subscri
 
 	channel := make(chan *pb.Message, p.chanBuffer)
 
-	p.Connections.Add(userID, channel)
-
 	// change for a call to DB or cache, now the data is taken just from a hashmap with few users and chats
 	for i := 1; i <= 5; i++ {
 		p.ChatsParticipants.Add(strconv.Itoa(i), userID)
 		p.Logger.Infof("subscribed user: %s to chat: %s", userID, strconv.Itoa(i))
 	}
 
+	// the connection is added last, so once it is found the user gets messages of all of their chats
+	p.Connections.Add(userID, channel)
+
 	defer func() {
 		p.Logger.Infow("removing user connection from connections storage", "userID", userID)
 		p.removeUserConnection(userID)
@@ -83,22 +98,35 @@ func (p *PubSubService) Subscribe(userID string, stream pb.PubSubService_Subscri
 }
 
 // ConsumeAndSendoutMessage method
-func (p *PubSubService) ConsumeAndSendoutMessage(ctx context.Context, consumer *kafka.Consumer) (string, error) {
+func (p *PubSubService) ConsumeAndSendoutMessage(ctx context.Context, consumer bus.Consumer) (string, error) {
 	var op = "service.ConsumeMessage"
 
-	msg, err := consumer.ReadMessage(-1)
+	msg, err := consumer.ReadMessage(ctx)
 	if err != nil {
-		p.Logger.Fatalw("failed to read message", "op", op, "err", err)
+		p.Logger.Errorw("failed to read message", "op", op, "err", err)
 		return "", fmt.Errorf("%s: %w", op, err)
 	}
+
+	ctx, span := telemetry.StartProcess(ctx, msg.Topic, msg.Headers)
+	messageID, err := p.sendoutMessage(ctx, msg)
+	telemetry.End(span, err)
+
+	return messageID, err
+}
+
+// sendoutMessage sends the consumed message to connected participants of its chat, continuing the trace of ctx.
+func (p *PubSubService) sendoutMessage(ctx context.Context, msg *bus.Message) (string, error) {
+	var op = "service.ConsumeMessage"
+
 	var deserializedMessage pb.Message
 	if err := proto.Unmarshal(msg.Value, &deserializedMessage); err != nil {
-		p.Logger.Fatalw("failed to unmarshal message", "op", op, "err", err)
+		p.Logger.Errorw("failed to unmarshal message", "op", op, "err", err)
+		return "", fmt.Errorf("%s: %w", op, err)
 	}
 
 	messageID := deserializedMessage.GetMessageId()
 
-	p.Logger.Infow("validating message", "op", op, "message", messageID)
+	p.Logger.Infow("validating message", "op", op, "message", messageID, "request_id", telemetry.RequestID(ctx), "trace_id", telemetry.TraceID(ctx))
 
 	if err := p.validateMessage(&deserializedMessage); err != nil {
 		p.Logger.Errorw("invalid message", "op", op, "err", err)
@@ -110,11 +138,27 @@ func (p *PubSubService) ConsumeAndSendoutMessage(ctx context.Context, consumer *
 	messageText := deserializedMessage.GetMessageText()
 	sentTime := deserializedMessage.GetSentTs()
 
-	chatParticipants, err := p.ChatsParticipants.Get(chatID)
+	chatParticipants, err := p.chatParticipants(ctx, chatID)
 	if err != nil {
+		p.Logger.Errorw("failed to get chat participants", "op", op, "chatID", chatID, "error", err)
 		return "", fmt.Errorf("%s: error validating message %v: %w", op, messageID, ErrChatNotExists)
 	}
 
+	// Users who blocked the sender don't receive sender's messages, so the message isn't sent out unless they are known
+	blockers, err := p.blockers.BlockersOf(ctx, senderID)
+	if err != nil {
+		p.Logger.Errorw("failed to get blockers of the sender", "op", op, "senderID", senderID, "error", err)
+		return "", fmt.Errorf("%s: error getting blockers of sender of message %v: %w", op, messageID, err)
+	}
+
+	// Users who muted the sender still have sender's messages in the history, but they aren't delivered live.
+	// Muting is cosmetic, so if muters are unknown the message is sent out to all of them.
+	muters, err := p.muters.MutersOf(ctx, senderID)
+	if err != nil {
+		p.Logger.Warnw("failed to get muters of the sender", "op", op, "senderID", senderID, "error", err)
+		muters = storage.NewHashSet()
+	}
+
 	// If there are no available recipients
 	//if len(chatParticipants.Store) <= 1 {
 	//	return "", fmt.Errorf("%s: error validating message %v: %w", op, messageID, ErrNoChatSubscribers)
@@ -122,6 +166,16 @@ func (p *PubSubService) ConsumeAndSendoutMessage(ctx context.Context, consumer *
 
 	// Send the message to all clients
 	for recipientID := range chatParticipants.Store {
+		if blockers.Contains(recipientID) {
+			p.Logger.Infow("recipient blocked the sender, skipping", "op", op, "recipientID", recipientID, "messageID", messageID)
+			continue
+		}
+
+		if muters.Contains(recipientID) {
+			p.Logger.Infow("recipient muted the sender, skipping", "op", op, "recipientID", recipientID, "messageID", messageID)
+			continue
+		}
+
 		channel, err := p.Connections.Get(recipientID)
 		if err != nil {
 			p.Logger.Errorw("unsuccessful user chan retrieval", "op", op, "recipientID", recipientID, "error", err)
@@ -151,6 +205,63 @@ func (p *PubSubService) ConsumeAndSendoutMessage(ctx context.Context, consumer *
 	return messageID, nil
 }
 
+// ConsumeAccountEvent method removes chat memberships and the connection of deleted users
+func (p *PubSubService) ConsumeAccountEvent(ctx context.Context, consumer bus.Consumer) (string, error) {
+	var op = "service.ConsumeAccountEvent"
+
+	msg, err := consumer.ReadMessage(ctx)
+	if err != nil {
+		p.Logger.Errorw("failed to read account event", "op", op, "err", err)
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	var event pb.AccountEvent
+	if err := proto.Unmarshal(msg.Value, &event); err != nil {
+		p.Logger.Errorw("failed to unmarshal account event", "op", op, "err", err)
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	userID := event.GetUserId()
+	if userID == "" {
+		return "", fmt.Errorf("%s: %w", op, ErrMessageMissingField)
+	}
+
+	if event.GetType() != pb.AccountEventType_ACCOUNT_EVENT_TYPE_DELETED {
+		p.Logger.Infow("skipping account event", "op", op, "userID", userID, "type", event.GetType())
+		return userID, nil
+	}
+
+	p.ChatsParticipants.RemoveUser(userID)
+	p.UserChats.Remove(userID)
+
+	// the stream of the user stays open until the client disconnects, but nothing is routed to it anymore
+	if err := p.Connections.Remove(userID); err == nil {
+		p.Logger.Infow("connection of deleted user removed", "op", op, "userID", userID)
+	}
+
+	p.Logger.Infow("deleted user removed from chats", "op", op, "userID", userID)
+
+	return userID, nil
+}
+
+// chatParticipants returns participants of the chat, direct chats always consist of the two users encoded in chat ID
+// and participants of groups are kept by chat-history service
+func (p *PubSubService) chatParticipants(ctx context.Context, chatID string) (*storage.HashSet, error) {
+	if userID, otherUserID, ok := common.ParseDirectChatID(chatID); ok {
+		participants := storage.NewHashSet()
+		participants.Add(userID)
+		participants.Add(otherUserID)
+
+		return participants, nil
+	}
+
+	if common.IsGroupChatID(chatID) {
+		return p.groups.ParticipantsOf(ctx, chatID)
+	}
+
+	return p.ChatsParticipants.Get(chatID)
+}
+
 func (p *PubSubService) removeUserConnection(userID string) {
 	var op = "service.RemoveClient"
 
@@ -87,3 +87,11 @@ func (m *ChatParticipantsStorage) Remove(chatID string, userID string) error {
 	chat.(*HashSet).Remove(userID)
 	return nil
 }
+
+// RemoveUser removes the user from all chats
+func (m *ChatParticipantsStorage) RemoveUser(userID string) {
+	m.store.Range(func(_, chat any) bool {
+		chat.(*HashSet).Remove(userID)
+		return true
+	})
+}
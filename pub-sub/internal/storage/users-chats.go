@@ -12,3 +12,8 @@ func NewUsersChats() *UsersChats {
 		},
 	}
 }
+
+// Remove forgets chats of the user
+func (u *UsersChats) Remove(userID string) {
+	delete(u.Users, userID)
+}
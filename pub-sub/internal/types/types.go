@@ -2,13 +2,27 @@ package types
 
 import (
 	"context"
-	"github.com/confluentinc/confluent-kafka-go/kafka"
 	pb "github.com/zoninnik89/messenger/common/api"
+	"github.com/zoninnik89/messenger/common/bus"
+	"github.com/zoninnik89/messenger/pub-sub/internal/storage"
 )
 
 type PubSubServiceInterface interface {
 	Subscribe(userID string, stream pb.PubSubService_SubscribeServer) error
-	ConsumeAndSendoutMessage(ctx context.Context, consumer *kafka.Consumer) (string, error)
+	ConsumeAndSendoutMessage(ctx context.Context, consumer bus.Consumer) (string, error)
+	ConsumeAccountEvent(ctx context.Context, consumer bus.Consumer) (string, error)
+}
+
+type BlockersProvider interface {
+	BlockersOf(ctx context.Context, userID string) (*storage.HashSet, error)
+}
+
+type MutersProvider interface {
+	MutersOf(ctx context.Context, userID string) (*storage.HashSet, error)
+}
+
+type ParticipantsProvider interface {
+	ParticipantsOf(ctx context.Context, chatID string) (*storage.HashSet, error)
 }
 
 //type Client struct {
@@ -19,7 +19,8 @@ func TestMessageProduceConsume_HappyPath(t *testing.T) {
 	userID2 := "user2"
 
 	messageId := gofakeit.UUID()
-	chatID := "chat1"
+	// subscribers join chats "1" to "5"
+	chatID := "1"
 	senderID := gofakeit.UUID()
 	messageText := gofakeit.Word()
 
@@ -43,23 +44,30 @@ func TestMessageProduceConsume_HappyPath(t *testing.T) {
 	var receivedMessages []*pb.Message
 	var receivedMessages2 []*pb.Message
 
+	timeout := time.After(5 * time.Second)
+
+loop:
 	for {
 		select {
 		case msg, ok := <-receivedMessagesChan:
-			if ok {
-				receivedMessages = append(receivedMessages, msg)
+			if !ok {
+				receivedMessagesChan = nil
+				continue
 			}
+			receivedMessages = append(receivedMessages, msg)
 		case msg, ok := <-receivedMessagesChan2:
-			if ok {
-				receivedMessages2 = append(receivedMessages2, msg)
+			if !ok {
+				receivedMessagesChan2 = nil
+				continue
 			}
-		case <-time.After(5 * time.Second):
+			receivedMessages2 = append(receivedMessages2, msg)
+		case <-timeout:
 			cancel()
 			cancel2()
-			break
+			break loop
 		}
 
-		// Break the loop when both channels are closed
+		// Break the loop when both subscribers received the message
 		if len(receivedMessages) == 1 && len(receivedMessages2) == 1 {
 			cancel()
 			cancel2()
@@ -108,21 +116,28 @@ func TestMessageProduceConsume_OneChatParticipant(t *testing.T) {
 	var receivedMessages []*pb.Message
 	var receivedMessages2 []*pb.Message
 
+	timeout := time.After(2 * time.Second)
+
+loop:
 	for {
 		select {
 		case msg, ok := <-receivedMessagesChan:
-			if ok {
-				receivedMessages = append(receivedMessages, msg)
+			if !ok {
+				receivedMessagesChan = nil
+				continue
 			}
+			receivedMessages = append(receivedMessages, msg)
 		case msg, ok := <-receivedMessagesChan2:
-			if ok {
-				receivedMessages2 = append(receivedMessages2, msg)
+			if !ok {
+				receivedMessagesChan2 = nil
+				continue
 			}
-		case <-time.After(2 * time.Second):
+			receivedMessages2 = append(receivedMessages2, msg)
+		case <-timeout:
 			cancel()
 			receivedMessagesChan = nil
 			receivedMessagesChan2 = nil
-			break
+			break loop
 		}
 
 		if receivedMessagesChan == nil && receivedMessagesChan2 == nil {
@@ -134,3 +149,124 @@ func TestMessageProduceConsume_OneChatParticipant(t *testing.T) {
 	assert.Equal(t, len(receivedMessages), 0)
 	assert.Equal(t, len(receivedMessagesChan2), 0)
 }
+
+func TestMessageProduceConsume_MalformedMessage(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	userID := "user1"
+
+	messageId := gofakeit.UUID()
+	// subscribers join chats "1" to "5"
+	chatID := "1"
+	senderID := gofakeit.UUID()
+	messageText := gofakeit.Word()
+
+	ctxWithCancel, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	receivedMessagesChan := make(chan *pb.Message, 10)
+
+	go st.SubscribeToChat(ctxWithCancel, userID, receivedMessagesChan)
+
+	time.Sleep(1 * time.Second)
+
+	// the malformed message is skipped, the service keeps consuming the chat
+	err := st.Queue.Publish(ctx, "messages", []byte(chatID), []byte("not a message"))
+	require.NoError(t, err)
+
+	err = st.SendMessage(ctx, messageId, chatID, senderID, messageText)
+	require.NoError(t, err)
+
+	select {
+	case msg, ok := <-receivedMessagesChan:
+		require.True(t, ok, "subscription ended")
+		assert.Equal(t, messageId, msg.MessageId)
+	case <-time.After(5 * time.Second):
+		t.Fatal("message wasn't received")
+	}
+}
+
+func TestMessageProduceConsume_BlockedSender(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	userID := "user1"
+
+	blockedMessageID := gofakeit.UUID()
+	messageId := gofakeit.UUID()
+	// subscribers join chats "1" to "5"
+	chatID := "1"
+	blockedSenderID := gofakeit.UUID()
+	senderID := gofakeit.UUID()
+
+	ctxWithCancel, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	receivedMessagesChan := make(chan *pb.Message, 10)
+
+	go st.SubscribeToChat(ctxWithCancel, userID, receivedMessagesChan)
+
+	time.Sleep(1 * time.Second)
+
+	st.Block(userID, blockedSenderID)
+
+	err := st.SendMessage(ctx, blockedMessageID, chatID, blockedSenderID, gofakeit.Word())
+	require.NoError(t, err)
+
+	err = st.SendMessage(ctx, messageId, chatID, senderID, gofakeit.Word())
+	require.NoError(t, err)
+
+	// messages of a chat are sent out in order, so the message of the blocked sender would come first
+	select {
+	case msg, ok := <-receivedMessagesChan:
+		require.True(t, ok, "subscription ended")
+		assert.Equal(t, messageId, msg.MessageId)
+	case <-time.After(5 * time.Second):
+		t.Fatal("message wasn't received")
+	}
+}
+
+func TestMessageProduceConsume_BlockersUnknown(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	userID := "user1"
+
+	undeliveredMessageID := gofakeit.UUID()
+	messageId := gofakeit.UUID()
+	// subscribers join chats "1" to "5"
+	chatID := "1"
+	senderID := gofakeit.UUID()
+
+	ctxWithCancel, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	receivedMessagesChan := make(chan *pb.Message, 10)
+
+	go st.SubscribeToChat(ctxWithCancel, userID, receivedMessagesChan)
+
+	time.Sleep(1 * time.Second)
+
+	// the user might have blocked the sender, so the message isn't sent out while sso can't tell
+	st.SetSSOUnavailable(true)
+
+	err := st.SendMessage(ctx, undeliveredMessageID, chatID, senderID, gofakeit.Word())
+	require.NoError(t, err)
+
+	select {
+	case msg := <-receivedMessagesChan:
+		t.Fatalf("message %v was sent out without knowing blockers of the sender", msg.GetMessageId())
+	case <-time.After(2 * time.Second):
+	}
+
+	st.SetSSOUnavailable(false)
+
+	err = st.SendMessage(ctx, messageId, chatID, senderID, gofakeit.Word())
+	require.NoError(t, err)
+
+	select {
+	case msg, ok := <-receivedMessagesChan:
+		require.True(t, ok, "subscription ended")
+		assert.Equal(t, messageId, msg.MessageId)
+	case <-time.After(5 * time.Second):
+		t.Fatal("message wasn't received")
+	}
+}
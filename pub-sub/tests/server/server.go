@@ -0,0 +1,145 @@
+// Package server runs pub-sub service in the process of a test, consuming messages from an in-memory bus.
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/zoninnik89/messenger/common"
+	"github.com/zoninnik89/messenger/common/bus"
+	"github.com/zoninnik89/messenger/common/bus/memory"
+	"github.com/zoninnik89/messenger/common/discovery"
+	grpcapp "github.com/zoninnik89/messenger/pub-sub/internal/app/grpc"
+	"github.com/zoninnik89/messenger/pub-sub/internal/chats"
+	"github.com/zoninnik89/messenger/pub-sub/internal/config"
+	"github.com/zoninnik89/messenger/pub-sub/internal/relations"
+	"github.com/zoninnik89/messenger/pub-sub/internal/service"
+)
+
+const (
+	host = "127.0.0.1"
+	// subscribedPollInterval is how often WaitSubscribed checks the connections of the service
+	subscribedPollInterval = 10 * time.Millisecond
+)
+
+// Server is pub-sub service listening on an ephemeral port.
+type Server struct {
+	// Addr is the address of the gRPC server
+	Addr string
+	Cfg  *config.Config
+
+	app        *grpcapp.App
+	service    *service.PubSubService
+	consumers  []bus.Consumer
+	pool       *discovery.Pool
+	cancel     context.CancelFunc
+	registry   discovery.Registry
+	instanceID string
+}
+
+// Start starts the service with the local config and registers it in the registry. Like with Kafka, the
+// service receives messages published to the broker after it started. Blockers and muters of senders are
+// asked from sso service found in the registry, messages aren't sent out if there is none. Participants of
+// groups are asked from chat-history service found in the registry.
+func Start(registry discovery.Registry, broker *memory.Broker) (*Server, error) {
+	const op = "server.Start"
+
+	cfg := config.MustLoadByPath(filepath.Join(moduleDir(), "config", "local.yaml"))
+	instanceID := discovery.GenerateInstanceID(cfg.GRPC.Name)
+
+	messages := broker.NewConsumer("pub-sub-group", memory.Latest)
+	if err := messages.SubscribeTopics([]string{"messages"}); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// chat memberships are kept in memory of every instance, so every instance consumes all account events
+	accountEvents := broker.NewConsumer(instanceID, memory.Latest)
+	if err := accountEvents.SubscribeTopics([]string{common.AccountEventsTopic}); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	pool := discovery.NewPool(registry)
+	blockers := relations.NewBlockers(pool, cfg.Relations.BlockersTTL)
+	muters := relations.NewMuters(pool, cfg.Relations.BlockersTTL)
+	groups := chats.NewParticipants(pool, cfg.Chats.ParticipantsTTL)
+	pubSubService := service.NewPubSubService(cfg.Storage.ChanBuffer, blockers, muters, groups)
+	application := grpcapp.NewApp(pubSubService, 0)
+
+	l, err := net.Listen("tcp", net.JoinHostPort(host, "0"))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		_ = application.Serve(l)
+	}()
+	go application.MustConsume(ctx, messages)
+	go application.MustConsumeAccountEvents(ctx, accountEvents)
+
+	port := l.Addr().(*net.TCPAddr).Port
+	s := &Server{
+		Addr:       net.JoinHostPort(host, strconv.Itoa(port)),
+		Cfg:        cfg,
+		app:        application,
+		service:    pubSubService,
+		consumers:  []bus.Consumer{messages, accountEvents},
+		pool:       pool,
+		cancel:     cancel,
+		registry:   registry,
+		instanceID: instanceID,
+	}
+
+	if err := registry.Register(context.Background(), instanceID, host, port, cfg.GRPC.Name); err != nil {
+		s.Stop()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return s, nil
+}
+
+// WaitSubscribed waits until the user is subscribed, from then on the user gets messages of their chats.
+func (s *Server) WaitSubscribed(ctx context.Context, userID string) error {
+	const op = "server.WaitSubscribed"
+
+	ticker := time.NewTicker(subscribedPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.service.Connections.Get(userID); err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s: %w", op, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop deregisters the service, stops consuming and stops the gRPC server gracefully,
+// so subscriptions must be ended by their clients first.
+func (s *Server) Stop() {
+	_ = s.registry.Deregister(context.Background(), s.instanceID)
+
+	s.cancel()
+	for _, consumer := range s.consumers {
+		_ = consumer.Close()
+	}
+
+	s.app.Stop()
+	_ = s.pool.Close()
+}
+
+// moduleDir returns the root directory of pub-sub module, so the server starts the same way from any test package
+func moduleDir() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..")
+}
@@ -0,0 +1,60 @@
+package suite
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	pb "github.com/zoninnik89/messenger/common/api"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// sso stands in for relations of sso service, nobody has blocked or muted anybody unless tests say otherwise
+type sso struct {
+	pb.UnimplementedRelationsServiceServer
+
+	mu          sync.Mutex
+	blockers    map[string][]string
+	unavailable bool
+}
+
+func newSSO() *sso {
+	return &sso{blockers: make(map[string][]string)}
+}
+
+func (s *sso) ListBlockers(_ context.Context, req *pb.ListBlockersRequest) (*pb.ListBlockersResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.unavailable {
+		return nil, status.Error(codes.Unavailable, "sso is unavailable")
+	}
+
+	return &pb.ListBlockersResponse{UserIds: slices.Clone(s.blockers[req.GetUserId()])}, nil
+}
+
+func (s *sso) ListMuters(_ context.Context, _ *pb.ListMutersRequest) (*pb.ListMutersResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.unavailable {
+		return nil, status.Error(codes.Unavailable, "sso is unavailable")
+	}
+
+	return &pb.ListMutersResponse{}, nil
+}
+
+func (s *sso) block(userID string, targetID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.blockers[targetID] = append(s.blockers[targetID], userID)
+}
+
+func (s *sso) setUnavailable(unavailable bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.unavailable = unavailable
+}
@@ -2,31 +2,48 @@ package suite
 
 import (
 	"context"
-	"github.com/confluentinc/confluent-kafka-go/kafka"
 	pb "github.com/zoninnik89/messenger/common/api"
+	"github.com/zoninnik89/messenger/common/bus"
+	"github.com/zoninnik89/messenger/common/bus/memory"
+	"github.com/zoninnik89/messenger/common/discovery/static"
 	"github.com/zoninnik89/messenger/pub-sub/internal/config"
-	producer "github.com/zoninnik89/messenger/pub-sub/tests/suite/mock-kafka-producer"
+	"github.com/zoninnik89/messenger/pub-sub/tests/server"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/proto"
 	"io"
 	"log"
 	"net"
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 )
 
-const (
-	grpcHost = "localhost"
-)
+// partitions of topics of the in-memory bus
+const partitions = 3
 
 type Suite struct {
 	*testing.T
 	Cfg          *config.Config
 	PubSubClient pb.PubSubServiceClient
-	Queue        *producer.Producer
+	Queue        bus.Producer
+
+	sso *sso
 }
 
+// instance is pub-sub service running in the test process, all suites of a test share it
+type instance struct {
+	address string
+	queue   bus.Producer
+	sso     *sso
+}
+
+var (
+	mu        sync.Mutex
+	instances = make(map[string]*instance)
+)
+
 func New(t *testing.T) (context.Context, *Suite) {
 	t.Helper()
 	//t.Parallel()
@@ -39,24 +56,85 @@ func New(t *testing.T) (context.Context, *Suite) {
 		cancelCtx()
 	})
 
-	cc, err := grpc.NewClient(grpcAddress(cfg),
+	pubSub := start(t)
+
+	cc, err := grpc.NewClient(pubSub.address,
 		grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err != nil {
 		return nil, nil
 	}
 
-	p := producer.NewKafkaProducer()
+	// closing the connection ends subscriptions, so the service can stop gracefully
+	t.Cleanup(func() {
+		_ = cc.Close()
+	})
 
 	return ctx, &Suite{
 		T:            t,
 		Cfg:          cfg,
 		PubSubClient: pb.NewPubSubServiceClient(cc),
-		Queue:        p,
+		Queue:        pubSub.queue,
+		sso:          pubSub.sso,
 	}
 }
 
-func grpcAddress(cfg *config.Config) string {
-	return net.JoinHostPort(grpcHost, strconv.Itoa(cfg.GRPC.Port))
+// start runs pub-sub service for the test on an ephemeral port, consuming messages from an in-memory bus.
+// Blockers and muters of senders are asked from a stand-in of sso service, see Block.
+func start(t *testing.T) *instance {
+	t.Helper()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if pubSub, ok := instances[t.Name()]; ok {
+		return pubSub
+	}
+
+	broker := memory.NewBroker(partitions)
+
+	relations := newSSO()
+	ssoServer := grpc.NewServer()
+	pb.RegisterRelationsServiceServer(ssoServer, relations)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	go func() { _ = ssoServer.Serve(l) }()
+
+	registry := static.NewRegistry(map[string][]string{
+		"sso-service": {l.Addr().String()},
+	})
+
+	srv, err := server.Start(registry, broker)
+	if err != nil {
+		ssoServer.Stop()
+		t.Fatalf("failed to start pub-sub: %v", err)
+	}
+
+	pubSub := &instance{address: srv.Addr, queue: broker.NewProducer(), sso: relations}
+	instances[t.Name()] = pubSub
+
+	t.Cleanup(func() {
+		srv.Stop()
+		ssoServer.Stop()
+
+		mu.Lock()
+		delete(instances, t.Name())
+		mu.Unlock()
+	})
+
+	return pubSub
+}
+
+// Block makes sso know that userID has blocked targetID.
+func (s *Suite) Block(userID string, targetID string) {
+	s.sso.block(userID, targetID)
+}
+
+// SetSSOUnavailable makes sso fail to tell blockers and muters of users.
+func (s *Suite) SetSSOUnavailable(unavailable bool) {
+	s.sso.setUnavailable(unavailable)
 }
 
 func (s *Suite) SubscribeToChat(ctx context.Context, userID string, messages chan<- *pb.Message) {
@@ -128,21 +206,11 @@ func (s *Suite) SendMessage(
 		SentTs:      strconv.FormatInt(time.Now().Unix(), 10),
 	}
 
-	deliveryChan := make(chan kafka.Event)
-	err := s.Queue.Publish(msg, "messages", nil, deliveryChan)
-
+	serialized, err := proto.Marshal(msg)
 	if err != nil {
 		return err
 	}
 
-	e := <-deliveryChan
-	dmsg := e.(*kafka.Message)
-
-	if dmsg.TopicPartition.Error != nil {
-		return dmsg.TopicPartition.Error
-	}
-
-	close(deliveryChan)
-	return nil
+	return s.Queue.Publish(ctx, "messages", []byte(chatID), serialized)
 
 }
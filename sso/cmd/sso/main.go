@@ -3,7 +3,8 @@ package main
 import (
 	"context"
 	"github.com/zoninnik89/messenger/common/discovery"
-	"github.com/zoninnik89/messenger/common/discovery/consul"
+	"github.com/zoninnik89/messenger/common/discovery/backend"
+	"github.com/zoninnik89/messenger/common/telemetry"
 	"github.com/zoninnik89/messenger/sso/internal/app"
 	"github.com/zoninnik89/messenger/sso/internal/config"
 	"github.com/zoninnik89/messenger/sso/internal/logging"
@@ -20,9 +21,22 @@ func main() {
 
 	logger.Info("starting sso service")
 
-	registry, err := consul.NewRegistry(cfg.GRPC.Address, cfg.Consul.Port)
+	shutdownTracing, err := telemetry.Init(context.Background(), cfg.GRPC.Name, cfg.Tracing)
 	if err != nil {
-		logger.Panic("failed to connect to Consul", zap.Error(err))
+		logger.Panic("failed to set up tracing", zap.Error(err))
+		panic(err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Warnw("failed to flush spans", "error", err)
+		}
+	}()
+
+	registry, err := backend.New(cfg.Discovery, cfg.GRPC.Address, cfg.Consul.Port)
+	if err != nil {
+		logger.Panic("failed to create service registry", zap.Error(err))
 		panic(err)
 	}
 
@@ -48,16 +62,23 @@ func main() {
 		}
 	}()
 
-	defer func(registry *consul.Registry, ctx context.Context, instanceID string) {
+	defer func(registry discovery.Registry, ctx context.Context, instanceID string) {
 		err := registry.Deregister(ctx, instanceID)
 		if err != nil {
 			logger.Fatal("failed to deregister service", zap.Error(err))
 		}
 	}(registry, ctx, instanceID)
 
-	application := app.NewApp(cfg.GRPC.Port, logger, cfg.StoragePath, cfg.TokenTTL)
+	application := app.NewApp(logger, cfg)
 	go application.GRPCsrv.MustRun()
 
+	ingestCtx, stopIngest := context.WithCancel(ctx)
+	ingestDone := make(chan struct{})
+	go func() {
+		application.IngestAuditEvents(ingestCtx, logger)
+		close(ingestDone)
+	}()
+
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, syscall.SIGTERM, syscall.SIGINT)
 
@@ -65,6 +86,8 @@ func main() {
 
 	logger.Infow("shutting down gracefully", "signal", s)
 
+	stopIngest()
+	<-ingestDone
 	application.GRPCsrv.Stop()
 
 	logger.Info("shut down gracefully")
@@ -1,28 +1,220 @@
 package app
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+	"github.com/zoninnik89/messenger/common"
 	grpcapp "github.com/zoninnik89/messenger/sso/internal/app/grpc"
+	"github.com/zoninnik89/messenger/sso/internal/config"
+	"github.com/zoninnik89/messenger/sso/internal/events"
+	"github.com/zoninnik89/messenger/sso/internal/lib/oidc"
+	"github.com/zoninnik89/messenger/sso/internal/lib/password"
+	"github.com/zoninnik89/messenger/sso/internal/mail"
+	"github.com/zoninnik89/messenger/sso/internal/services/apps"
+	"github.com/zoninnik89/messenger/sso/internal/services/audit"
 	"github.com/zoninnik89/messenger/sso/internal/services/auth"
+	"github.com/zoninnik89/messenger/sso/internal/services/relations"
+	"github.com/zoninnik89/messenger/sso/internal/storage/postgres"
 	"github.com/zoninnik89/messenger/sso/internal/storage/sqlite"
+	"github.com/zoninnik89/messenger/sso/internal/types"
 	"go.uber.org/zap"
-	"time"
 )
 
 type App struct {
-	GRPCsrv *grpcapp.App
+	GRPCsrv       *grpcapp.App
+	AuditIngester *audit.Ingester
+	// AuditConsumer is nil if ingestion of audit events is disabled
+	AuditConsumer *kafka.Consumer
 }
 
-func NewApp(grpcPort int, logger *zap.SugaredLogger, storagePath string, tokenTTL time.Duration) *App {
-	storage, err := sqlite.NewStorage(storagePath)
+func NewApp(logger *zap.SugaredLogger, cfg *config.Config) *App {
+	storage, err := newStorage(cfg.StorageDriver, cfg.StoragePath)
 	if err != nil {
 		panic(err)
 	}
 
-	authService := auth.NewAuthService(logger, storage, storage, storage, tokenTTL)
+	mailSender, err := newMailSender(logger, cfg.Mail)
+	if err != nil {
+		panic(err)
+	}
+
+	eventPublisher, err := newEventPublisher(logger, cfg.Events)
+	if err != nil {
+		panic(err)
+	}
+
+	auditRecorder := audit.NewRecorder(logger, storage, eventPublisher, cfg.GRPC.Name)
+
+	accountSettings := auth.AccountSettings{
+		PasswordPolicy: password.Policy{
+			MinLength:      cfg.PasswordPolicy.MinLength,
+			RequireUpper:   cfg.PasswordPolicy.RequireUpper,
+			RequireLower:   cfg.PasswordPolicy.RequireLower,
+			RequireDigit:   cfg.PasswordPolicy.RequireDigit,
+			RequireSpecial: cfg.PasswordPolicy.RequireSpecial,
+		},
+		VerificationTokenTTL: cfg.Account.VerificationTokenTTL,
+		ResetTokenTTL:        cfg.Account.ResetTokenTTL,
+		RequireVerifiedEmail: cfg.Account.RequireVerifiedEmail,
+		Lockout: auth.LockoutSettings{
+			MaxLoginAttempts: cfg.Lockout.MaxLoginAttempts,
+			MaxIPAttempts:    cfg.Lockout.MaxIPAttempts,
+			Window:           cfg.Lockout.Window,
+			BaseDelay:        cfg.Lockout.BaseDelay,
+			MaxDelay:         cfg.Lockout.MaxDelay,
+			LockoutDuration:  cfg.Lockout.LockoutDuration,
+		},
+		MFA: auth.MFASettings{
+			Issuer:        cfg.MFA.Issuer,
+			ChallengeTTL:  cfg.MFA.ChallengeTTL,
+			RecoveryCodes: cfg.MFA.RecoveryCodes,
+		},
+		OIDC: auth.OIDCSettings{
+			StateTTL:  cfg.OIDC.StateTTL,
+			Providers: newOIDCProviders(cfg.OIDC),
+		},
+	}
+
+	authService := auth.NewAuthService(
+		logger,
+		storage,
+		storage,
+		storage,
+		storage,
+		mailSender,
+		storage,
+		storage,
+		storage,
+		storage,
+		eventPublisher,
+		auditRecorder,
+		accountSettings,
+		cfg.TokenTTL,
+	)
+
+	relationsService := relations.NewRelationsService(logger, storage, storage)
 
-	grpcApp := grpcapp.NewApp(logger, authService, grpcPort)
+	appsService := apps.NewAppsService(logger, storage, storage, auditRecorder)
+
+	auditService := audit.NewAuditService(logger, storage)
+
+	grpcApp := grpcapp.NewApp(
+		logger,
+		authService,
+		relationsService,
+		appsService,
+		auditService,
+		cfg.Admin.Token,
+		cfg.GRPC.Port,
+	)
+
+	application := &App{
+		GRPCsrv:       grpcApp,
+		AuditIngester: audit.NewIngester(logger, storage, cfg.GRPC.Name),
+	}
+
+	if cfg.Audit.Ingest {
+		application.AuditConsumer, err = newAuditConsumer(cfg.Events.KafkaAddress, cfg.Audit.ConsumerGroup)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	return application
+}
+
+// IngestAuditEvents stores audit events of other services until the context is cancelled, then closes
+// the consumer. It returns immediately if ingestion is disabled.
+func (a *App) IngestAuditEvents(ctx context.Context, logger *zap.SugaredLogger) {
+	const op = "app.IngestAuditEvents"
+
+	if a.AuditConsumer == nil {
+		return
+	}
+
+	defer func() {
+		if err := a.AuditConsumer.Close(); err != nil {
+			logger.Warnw("failed to close audit consumer", "op", op, "error", err)
+		}
+	}()
+
+	for ctx.Err() == nil {
+		eventID, err := a.AuditIngester.Ingest(ctx, a.AuditConsumer)
+		if errors.Is(err, audit.ErrNoEvent) {
+			continue
+		}
+		if err != nil {
+			logger.Warnw("failed to ingest audit event", "op", op, "error", err)
+			continue
+		}
+
+		logger.Debugw("audit event consumed", "op", op, "eventID", eventID)
+	}
+}
+
+func newStorage(driver string, storagePath string) (types.Storage, error) {
+	switch driver {
+	case "sqlite":
+		return sqlite.NewStorage(storagePath)
+	case "postgres":
+		return postgres.NewStorage(storagePath)
+	default:
+		return nil, fmt.Errorf("unknown storage driver: %s", driver)
+	}
+}
+
+func newOIDCProviders(cfg config.OIDCConfig) map[string]*oidc.Provider {
+	providers := make(map[string]*oidc.Provider, len(cfg.Providers))
+	for _, p := range cfg.Providers {
+		providers[p.Name] = oidc.NewProvider(oidc.Config{
+			Issuer:       p.Issuer,
+			ClientID:     p.ClientID,
+			ClientSecret: p.ClientSecret,
+			RedirectURL:  p.RedirectURL,
+			Scopes:       p.Scopes,
+		}, cfg.Timeout)
+	}
+
+	return providers
+}
+
+func newMailSender(logger *zap.SugaredLogger, cfg config.MailConfig) (types.MailSender, error) {
+	switch cfg.Sender {
+	case "log":
+		return mail.NewLogSender(logger, cfg.From), nil
+	case "file":
+		return mail.NewFileSender(cfg.Dir, cfg.From)
+	default:
+		return nil, fmt.Errorf("unknown mail sender: %s", cfg.Sender)
+	}
+}
+
+func newAuditConsumer(kafkaAddress string, groupID string) (*kafka.Consumer, error) {
+	consumer, err := kafka.NewConsumer(&kafka.ConfigMap{
+		"bootstrap.servers": kafkaAddress,
+		"group.id":          groupID,
+		"auto.offset.reset": "earliest",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := consumer.SubscribeTopics([]string{common.AuditEventsTopic}, nil); err != nil {
+		return nil, err
+	}
+
+	return consumer, nil
+}
 
-	return &App{
-		GRPCsrv: grpcApp,
+func newEventPublisher(logger *zap.SugaredLogger, cfg config.EventsConfig) (types.EventPublisher, error) {
+	switch cfg.Publisher {
+	case "log":
+		return events.NewLogPublisher(logger), nil
+	case "kafka":
+		return events.NewKafkaPublisher(cfg.KafkaAddress)
+	default:
+		return nil, fmt.Errorf("unknown event publisher: %s", cfg.Publisher)
 	}
 }
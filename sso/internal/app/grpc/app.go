@@ -2,7 +2,11 @@ package grpcapp
 
 import (
 	"fmt"
+	"github.com/zoninnik89/messenger/common/telemetry"
+	appsgrpc "github.com/zoninnik89/messenger/sso/internal/grpc/apps"
+	auditgrpc "github.com/zoninnik89/messenger/sso/internal/grpc/audit"
 	authgrpc "github.com/zoninnik89/messenger/sso/internal/grpc/auth"
+	relationsgrpc "github.com/zoninnik89/messenger/sso/internal/grpc/relations"
 	"github.com/zoninnik89/messenger/sso/internal/types"
 	"go.uber.org/zap"
 	grpc "google.golang.org/grpc"
@@ -16,9 +20,20 @@ type App struct {
 	port       int
 }
 
-func NewApp(l *zap.SugaredLogger, authService types.Auth, port int) *App {
-	grpcServer := grpc.NewServer()
+func NewApp(
+	l *zap.SugaredLogger,
+	authService types.Auth,
+	relationsService types.Relations,
+	appsService types.Apps,
+	auditService types.Audit,
+	adminToken string,
+	port int,
+) *App {
+	grpcServer := grpc.NewServer(telemetry.ServerOption())
 	authgrpc.Register(grpcServer, authService)
+	relationsgrpc.Register(grpcServer, relationsService)
+	appsgrpc.Register(grpcServer, appsService, adminToken)
+	auditgrpc.Register(grpcServer, auditService, adminToken)
 
 	return &App{grpcServer: grpcServer, logger: l, port: port}
 }
@@ -39,10 +54,21 @@ func (a *App) Run() error {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	a.logger.Infow("grpc server is running", "add", l.Addr().String())
+	if err := a.Serve(l); err != nil {
+		a.logger.Fatalw("failed to serve", "op", op, "error", err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Serve serves gRPC requests accepted by the listener until the server is stopped.
+func (a *App) Serve(l net.Listener) error {
+	const op = "grpcapp.Serve"
+
+	a.logger.Infow("grpc server is running", "op", op, "addr", l.Addr().String())
 
 	if err := a.grpcServer.Serve(l); err != nil {
-		a.logger.Fatalw("failed to serve", "op", op, "error", err)
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
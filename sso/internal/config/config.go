@@ -7,14 +7,28 @@ import (
 
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/zoninnik89/messenger/common"
+	"github.com/zoninnik89/messenger/common/discovery/backend"
+	"github.com/zoninnik89/messenger/common/telemetry"
 )
 
 type Config struct {
-	Env         string        `yaml:"env" env-default:"local"`
-	StoragePath string        `yaml:"storage_path" env-required:"true"`
-	TokenTTL    time.Duration `yaml:"token_ttl" env-required:"true"`
-	GRPC        GRPCConfig    `yaml:"grpc"`
-	Consul      ConsulConfig  `yaml:"consul"`
+	Env            string               `yaml:"env" env-default:"local"`
+	StorageDriver  string               `yaml:"storage_driver" env:"SSO_STORAGE_DRIVER" env-default:"sqlite"` // "sqlite" or "postgres"
+	StoragePath    string               `yaml:"storage_path" env:"SSO_STORAGE_PATH" env-required:"true"`      // database file or postgres connection string
+	TokenTTL       time.Duration        `yaml:"token_ttl" env-required:"true"`
+	GRPC           GRPCConfig           `yaml:"grpc"`
+	Consul         ConsulConfig         `yaml:"consul"`
+	Discovery      backend.Config       `yaml:"discovery"`
+	PasswordPolicy PasswordPolicyConfig `yaml:"password_policy"`
+	Account        AccountConfig        `yaml:"account"`
+	Mail           MailConfig           `yaml:"mail"`
+	Lockout        LockoutConfig        `yaml:"lockout"`
+	MFA            MFAConfig            `yaml:"mfa"`
+	Admin          AdminConfig          `yaml:"admin"`
+	Events         EventsConfig         `yaml:"events"`
+	Audit          AuditConfig          `yaml:"audit"`
+	OIDC           OIDCConfig           `yaml:"oidc"`
+	Tracing        telemetry.Config     `yaml:"tracing"`
 }
 
 type GRPCConfig struct {
@@ -28,6 +42,76 @@ type ConsulConfig struct {
 	Port int `yaml:"port"`
 }
 
+type PasswordPolicyConfig struct {
+	MinLength      int  `yaml:"min_length" env-default:"8"`
+	RequireUpper   bool `yaml:"require_upper" env-default:"true"`
+	RequireLower   bool `yaml:"require_lower" env-default:"true"`
+	RequireDigit   bool `yaml:"require_digit" env-default:"true"`
+	RequireSpecial bool `yaml:"require_special" env-default:"false"`
+}
+
+type AccountConfig struct {
+	VerificationTokenTTL time.Duration `yaml:"verification_token_ttl" env-default:"24h"`
+	ResetTokenTTL        time.Duration `yaml:"reset_token_ttl" env-default:"1h"`
+	RequireVerifiedEmail bool          `yaml:"require_verified_email" env-default:"false"`
+}
+
+type LockoutConfig struct {
+	MaxLoginAttempts int           `yaml:"max_login_attempts" env-default:"5"`
+	MaxIPAttempts    int           `yaml:"max_ip_attempts" env-default:"100"`
+	Window           time.Duration `yaml:"window" env-default:"15m"`
+	BaseDelay        time.Duration `yaml:"base_delay" env-default:"1s"`
+	MaxDelay         time.Duration `yaml:"max_delay" env-default:"30s"`
+	LockoutDuration  time.Duration `yaml:"lockout_duration" env-default:"15m"`
+}
+
+type MFAConfig struct {
+	Issuer        string        `yaml:"issuer" env-default:"Messenger"`
+	ChallengeTTL  time.Duration `yaml:"challenge_ttl" env-default:"5m"`
+	RecoveryCodes int           `yaml:"recovery_codes" env-default:"10"`
+}
+
+type EventsConfig struct {
+	// Publisher is either "log" or "kafka"
+	Publisher    string `yaml:"publisher" env-default:"log"`
+	KafkaAddress string `yaml:"kafka_address" env:"KAFKA_SERVER_ADDRESS" env-default:"localhost:9092"`
+}
+
+type AuditConfig struct {
+	// Ingest enables storing audit events published by other services to Kafka,
+	// events of sso are stored directly
+	Ingest        bool   `yaml:"ingest" env:"SSO_AUDIT_INGEST" env-default:"false"`
+	ConsumerGroup string `yaml:"consumer_group" env-default:"sso-audit"`
+}
+
+type OIDCConfig struct {
+	StateTTL  time.Duration        `yaml:"state_ttl" env-default:"10m"`
+	Timeout   time.Duration        `yaml:"timeout" env-default:"10s"` // timeout of requests to providers
+	Providers []OIDCProviderConfig `yaml:"providers"`
+}
+
+// OIDCProviderConfig registers an OpenID Connect provider, Name is used in URLs of the facade.
+type OIDCProviderConfig struct {
+	Name         string   `yaml:"name"`
+	Issuer       string   `yaml:"issuer"`
+	ClientID     string   `yaml:"client_id"`
+	ClientSecret string   `yaml:"client_secret"`
+	RedirectURL  string   `yaml:"redirect_url"`
+	Scopes       []string `yaml:"scopes"`
+}
+
+type AdminConfig struct {
+	// Token authorizes app registry and audit log requests, both are disabled if it is empty
+	Token string `yaml:"token" env:"SSO_ADMIN_TOKEN"`
+}
+
+type MailConfig struct {
+	// Sender is either "log" or "file"
+	Sender string `yaml:"sender" env-default:"log"`
+	From   string `yaml:"from" env-default:"no-reply@messenger.local"`
+	Dir    string `yaml:"dir" env-default:"./storage/mail"`
+}
+
 func MustLoad() *Config {
 	path := fetchConfigPath()
 	if path == "" {
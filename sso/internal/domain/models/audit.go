@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+type AuditEventType string
+
+const (
+	AuditLoginSucceeded      AuditEventType = "login_succeeded"
+	AuditLoginFailed         AuditEventType = "login_failed"
+	AuditLoginThrottled      AuditEventType = "login_throttled"
+	AuditUserRegistered      AuditEventType = "user_registered"
+	AuditPasswordReset       AuditEventType = "password_reset"
+	AuditMFAEnabled          AuditEventType = "mfa_enabled"
+	AuditMFADisabled         AuditEventType = "mfa_disabled"
+	AuditAccountDeleted      AuditEventType = "account_deleted"
+	AuditAppCreated          AuditEventType = "app_created"
+	AuditAppSecretRotated    AuditEventType = "app_secret_rotated"
+	AuditAppDisabled         AuditEventType = "app_disabled"
+	AuditAccountDataExported AuditEventType = "account_data_exported"
+	AuditAccessDenied        AuditEventType = "access_denied"
+	AuditIdentityLinked      AuditEventType = "identity_linked"
+	AuditSessionRevoked      AuditEventType = "session_revoked"
+)
+
+// AuditEvent is an append-only record of a security-relevant action.
+type AuditEvent struct {
+	ID         string
+	Type       AuditEventType
+	UserID     string
+	Login      string
+	AppID      int
+	ClientIP   string
+	Source     string
+	OccurredAt time.Time
+	Details    map[string]string
+}
+
+// AuditFilter selects audit events, zero fields don't restrict the selection.
+type AuditFilter struct {
+	UserID string
+	From   time.Time
+	To     time.Time
+	Limit  int
+}
@@ -1,7 +1,19 @@
 package models
 
+import "time"
+
 type App struct {
 	ID     int
 	Name   string
 	Secret string
+	// TokenTTL is zero if tokens of the app are issued with default TTL
+	TokenTTL   time.Duration
+	Scopes     []string
+	DisabledAt time.Time
+	CreatedAt  time.Time
+}
+
+// Disabled reports whether the app can no longer be used to log in.
+func (a App) Disabled() bool {
+	return !a.DisabledAt.IsZero()
 }
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// Identity links a user to an account at an external OpenID Connect provider.
+type Identity struct {
+	Provider string
+	// Subject is the "sub" claim of the provider, it is stable unlike email
+	Subject   string
+	UserID    string
+	Email     string
+	CreatedAt time.Time
+}
+
+// OIDCState is kept between redirecting the user to a provider and the provider redirecting back,
+// only hash of the state parameter is stored.
+type OIDCState struct {
+	Hash         string
+	Provider     string
+	CodeVerifier string
+	Nonce        string
+	AppID        int
+	// LinkUserID is set if the provider account is linked to a logged in user instead of logging in
+	LinkUserID string
+	ExpiresAt  time.Time
+}
+
+// OIDCLogin is the outcome of login via an external provider.
+type OIDCLogin struct {
+	UserID string
+	// Token is empty if the user has two-factor authentication enabled, MFAToken is set instead
+	Token    string
+	MFAToken string
+	// Linked is set if the provider account was linked to the user during this login
+	Linked bool
+}
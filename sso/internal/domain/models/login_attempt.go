@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// LoginAttempts holds failed login attempts counted for a key, either a login or a client IP.
+type LoginAttempts struct {
+	Key           string
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   time.Time
+}
+
+// Lockout is an audit record of a temporary login lockout.
+type Lockout struct {
+	Key         string
+	Login       string
+	IP          string
+	Failures    int
+	LockedUntil time.Time
+	CreatedAt   time.Time
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// MFA holds TOTP two-factor authentication settings of a user.
+type MFA struct {
+	UserID     string
+	TOTPSecret string
+	// LastUsedStep is the TOTP time step of the last accepted code, codes of this or earlier steps are rejected
+	LastUsedStep int64
+	// ConfirmedAt is zero until the user confirms enrollment with a valid code
+	ConfirmedAt time.Time
+	CreatedAt   time.Time
+}
+
+// Enabled reports whether the second factor is required on login.
+func (m MFA) Enabled() bool {
+	return !m.ConfirmedAt.IsZero()
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+type RelationKind string
+
+const (
+	RelationBlock RelationKind = "block"
+	RelationMute  RelationKind = "mute"
+)
+
+type Relation struct {
+	UserID    string
+	TargetID  string
+	Kind      RelationKind
+	CreatedAt time.Time
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// Session is a login of the user on a device, auth tokens carry ID of the session they were issued for.
+type Session struct {
+	ID         string
+	UserID     string
+	AppID      int
+	DeviceName string
+	UserAgent  string
+	// IP is the client IP the session was last used from
+	IP         string
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+	ExpiresAt  time.Time
+	// RevokedAt is zero until the user signs the device out
+	RevokedAt time.Time
+}
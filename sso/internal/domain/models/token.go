@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+type TokenPurpose string
+
+const (
+	TokenEmailVerification TokenPurpose = "email_verification"
+	TokenPasswordReset     TokenPurpose = "password_reset"
+	TokenMFAChallenge      TokenPurpose = "mfa_challenge"
+)
+
+// Token is a single-use expiring token sent to the user out of band, only its hash is stored.
+type Token struct {
+	Hash      string
+	UserID    string
+	Purpose   TokenPurpose
+	ExpiresAt time.Time
+	// AppID is set for MFA challenges, so that the auth token is issued for the app the user logs in to
+	AppID int
+}
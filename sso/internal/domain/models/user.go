@@ -1,7 +1,16 @@
 package models
 
 type User struct {
-	ID       string
-	Login    string
-	PassHash []byte
+	ID            string
+	Login         string
+	PassHash      []byte
+	EmailVerified bool
+}
+
+// AccountData is personal data of the user kept by SSO service, returned on data export.
+type AccountData struct {
+	UserID        string
+	Login         string
+	EmailVerified bool
+	MFAEnabled    bool
 }
@@ -0,0 +1,74 @@
+package events
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+)
+
+// LogPublisher doesn't deliver events, it only writes them to the log. Intended for local development.
+type LogPublisher struct {
+	logger *zap.SugaredLogger
+}
+
+func NewLogPublisher(logger *zap.SugaredLogger) *LogPublisher {
+	return &LogPublisher{logger: logger}
+}
+
+func (p *LogPublisher) Publish(ctx context.Context, topic string, key string, event proto.Message) error {
+	p.logger.Infow("event published", "topic", topic, "key", key, "event", event)
+	return nil
+}
+
+// KafkaPublisher publishes protobuf encoded events to Kafka and waits until they are delivered.
+type KafkaPublisher struct {
+	producer *kafka.Producer
+}
+
+func NewKafkaPublisher(address string) (*KafkaPublisher, error) {
+	const op = "events.NewKafkaPublisher"
+
+	p, err := kafka.NewProducer(&kafka.ConfigMap{
+		"bootstrap.servers": address,
+		"acks":              "all",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &KafkaPublisher{producer: p}, nil
+}
+
+func (p *KafkaPublisher) Publish(ctx context.Context, topic string, key string, event proto.Message) error {
+	const op = "events.KafkaPublisher.Publish"
+
+	value, err := proto.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	delivery := make(chan kafka.Event, 1)
+
+	err = p.producer.Produce(&kafka.Message{
+		TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: kafka.PartitionAny},
+		Key:            []byte(key),
+		Value:          value,
+	}, delivery)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	select {
+	case e := <-delivery:
+		if msg, ok := e.(*kafka.Message); ok && msg.TopicPartition.Error != nil {
+			return fmt.Errorf("%s: %w", op, msg.TopicPartition.Error)
+		}
+
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%s: %w", op, ctx.Err())
+	}
+}
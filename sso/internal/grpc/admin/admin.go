@@ -0,0 +1,33 @@
+// Package admin authorizes requests to administrative APIs of sso.
+package admin
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Authorize checks admin token passed as "authorization: Bearer <token>" metadata.
+// If adminToken is empty, all requests are rejected.
+func Authorize(ctx context.Context, adminToken string) error {
+	if adminToken == "" {
+		return status.Error(codes.PermissionDenied, "admin api is disabled")
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return status.Error(codes.Unauthenticated, "admin token required")
+	}
+
+	token := strings.TrimPrefix(values[0], "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+		return status.Error(codes.PermissionDenied, "invalid admin token")
+	}
+
+	return nil
+}
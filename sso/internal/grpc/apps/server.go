@@ -0,0 +1,135 @@
+package apps
+
+import (
+	"context"
+	"errors"
+	pb "github.com/zoninnik89/messenger/common/api"
+	"github.com/zoninnik89/messenger/sso/internal/domain/models"
+	"github.com/zoninnik89/messenger/sso/internal/grpc/admin"
+	"github.com/zoninnik89/messenger/sso/internal/services/apps"
+	"github.com/zoninnik89/messenger/sso/internal/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+type serverAPI struct {
+	pb.UnimplementedAppServiceServer
+	service    types.Apps
+	adminToken string
+}
+
+// Register registers app registry API. Requests must carry the given admin token,
+// if it is empty, all requests are rejected.
+func Register(srv *grpc.Server, svs types.Apps, adminToken string) {
+	pb.RegisterAppServiceServer(srv, &serverAPI{service: svs, adminToken: adminToken})
+}
+
+func (s *serverAPI) CreateApp(ctx context.Context, req *pb.CreateAppRequest) (*pb.CreateAppResponse, error) {
+	if err := admin.Authorize(ctx, s.adminToken); err != nil {
+		return nil, err
+	}
+
+	if req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "name required")
+	}
+
+	app, err := s.service.CreateApp(
+		ctx,
+		req.GetName(),
+		time.Duration(req.GetTokenTtlSeconds())*time.Second,
+		req.GetScopes(),
+	)
+	if err != nil {
+		return nil, appError(err)
+	}
+
+	return &pb.CreateAppResponse{
+		App:    appToProto(app),
+		Secret: app.Secret,
+	}, nil
+}
+
+func (s *serverAPI) ListApps(ctx context.Context, _ *pb.ListAppsRequest) (*pb.ListAppsResponse, error) {
+	if err := admin.Authorize(ctx, s.adminToken); err != nil {
+		return nil, err
+	}
+
+	apps, err := s.service.ListApps(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "internal server error")
+	}
+
+	res := &pb.ListAppsResponse{}
+	for _, app := range apps {
+		res.Apps = append(res.Apps, appToProto(app))
+	}
+
+	return res, nil
+}
+
+func (s *serverAPI) RotateAppSecret(ctx context.Context, req *pb.RotateAppSecretRequest) (*pb.RotateAppSecretResponse, error) {
+	if err := admin.Authorize(ctx, s.adminToken); err != nil {
+		return nil, err
+	}
+
+	if req.GetAppId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "app id required")
+	}
+
+	secret, err := s.service.RotateAppSecret(ctx, int(req.GetAppId()))
+	if err != nil {
+		return nil, appError(err)
+	}
+
+	return &pb.RotateAppSecretResponse{Secret: secret}, nil
+}
+
+func (s *serverAPI) DisableApp(ctx context.Context, req *pb.DisableAppRequest) (*pb.DisableAppResponse, error) {
+	if err := admin.Authorize(ctx, s.adminToken); err != nil {
+		return nil, err
+	}
+
+	if req.GetAppId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "app id required")
+	}
+
+	if err := s.service.DisableApp(ctx, int(req.GetAppId())); err != nil {
+		return nil, appError(err)
+	}
+
+	return &pb.DisableAppResponse{Status: "disabled"}, nil
+}
+
+func appError(err error) error {
+	if validationErr := new(apps.ValidationError); errors.As(err, &validationErr) {
+		return status.Error(codes.InvalidArgument, validationErr.Reason)
+	}
+
+	if errors.Is(err, apps.ErrAppExists) {
+		return status.Error(codes.AlreadyExists, "app already exists")
+	}
+
+	if errors.Is(err, apps.ErrAppNotFound) {
+		return status.Error(codes.NotFound, "app not found")
+	}
+
+	return status.Error(codes.Internal, "internal server error")
+}
+
+func appToProto(app models.App) *pb.App {
+	res := &pb.App{
+		Id:              int32(app.ID),
+		Name:            app.Name,
+		TokenTtlSeconds: int64(app.TokenTTL.Seconds()),
+		Scopes:          app.Scopes,
+		Disabled:        app.Disabled(),
+	}
+
+	if !app.CreatedAt.IsZero() {
+		res.CreatedAt = app.CreatedAt.Unix()
+	}
+
+	return res
+}
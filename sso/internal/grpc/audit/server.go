@@ -0,0 +1,68 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	pb "github.com/zoninnik89/messenger/common/api"
+	"github.com/zoninnik89/messenger/sso/internal/domain/models"
+	"github.com/zoninnik89/messenger/sso/internal/grpc/admin"
+	"github.com/zoninnik89/messenger/sso/internal/services/audit"
+	"github.com/zoninnik89/messenger/sso/internal/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type serverAPI struct {
+	pb.UnimplementedAuditServiceServer
+	service    types.Audit
+	adminToken string
+}
+
+// Register registers audit log API. Requests must carry the given admin token,
+// if it is empty, all requests are rejected.
+func Register(srv *grpc.Server, svs types.Audit, adminToken string) {
+	pb.RegisterAuditServiceServer(srv, &serverAPI{service: svs, adminToken: adminToken})
+}
+
+func (s *serverAPI) ListAuditEvents(
+	ctx context.Context,
+	req *pb.ListAuditEventsRequest,
+) (*pb.ListAuditEventsResponse, error) {
+	if err := admin.Authorize(ctx, s.adminToken); err != nil {
+		return nil, err
+	}
+
+	if req.GetFrom() < 0 || req.GetTo() < 0 || req.GetLimit() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "time range and limit must not be negative")
+	}
+
+	filter := models.AuditFilter{
+		UserID: req.GetUserId(),
+		Limit:  int(req.GetLimit()),
+	}
+	if req.GetFrom() > 0 {
+		filter.From = time.UnixMilli(req.GetFrom())
+	}
+	if req.GetTo() > 0 {
+		filter.To = time.UnixMilli(req.GetTo())
+	}
+
+	events, err := s.service.ListAuditEvents(ctx, filter)
+	if err != nil {
+		if errors.Is(err, audit.ErrInvalidFilter) {
+			return nil, status.Error(codes.InvalidArgument, "from must be before to")
+		}
+
+		return nil, status.Error(codes.Internal, "internal server error")
+	}
+
+	res := &pb.ListAuditEventsResponse{}
+	for _, event := range events {
+		res.Events = append(res.Events, audit.EventToProto(event))
+	}
+
+	return res, nil
+}
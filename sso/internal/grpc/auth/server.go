@@ -3,12 +3,19 @@ package auth
 import (
 	"context"
 	"errors"
+	"github.com/zoninnik89/messenger/common"
 	pb "github.com/zoninnik89/messenger/common/api"
+	"github.com/zoninnik89/messenger/sso/internal/lib/oidc"
+	"github.com/zoninnik89/messenger/sso/internal/lib/password"
 	"github.com/zoninnik89/messenger/sso/internal/services/auth"
 	"github.com/zoninnik89/messenger/sso/internal/types"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"math"
+	"net/mail"
+	"strconv"
 )
 
 type serverAPI struct {
@@ -22,6 +29,9 @@ func Register(srv *grpc.Server, svs types.Auth) {
 
 const (
 	emptyValue = 0
+
+	// retryAfterMetadataKey is the response header with number of seconds the client should wait before retrying
+	retryAfterMetadataKey = "retry-after"
 )
 
 func (s *serverAPI) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
@@ -29,8 +39,12 @@ func (s *serverAPI) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginR
 		return nil, err
 	}
 
-	token, err := s.service.Login(ctx, req.GetLogin(), req.GetPassword(), int(req.GetAppId()))
+	token, mfaToken, err := s.service.Login(ctx, req.GetLogin(), req.GetPassword(), common.ClientIP(ctx), int(req.GetAppId()))
 	if err != nil {
+		if throttledErr := new(auth.ThrottledError); errors.As(err, &throttledErr) {
+			return nil, throttledStatus(ctx, throttledErr)
+		}
+
 		if errors.Is(err, auth.ErrInvalidCredentials) {
 			return nil, status.Error(codes.InvalidArgument, "invalid login or password")
 		}
@@ -39,9 +53,20 @@ func (s *serverAPI) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginR
 			return nil, status.Error(codes.InvalidArgument, "invalid app id")
 		}
 
+		if errors.Is(err, auth.ErrEmailNotVerified) {
+			return nil, status.Error(codes.FailedPrecondition, "email is not verified")
+		}
+
 		return nil, status.Error(codes.Internal, "internal server error")
 	}
 
+	if mfaToken != "" {
+		return &pb.LoginResponse{
+			MfaRequired: true,
+			MfaToken:    mfaToken,
+		}, nil
+	}
+
 	return &pb.LoginResponse{
 		Token: token,
 	}, nil
@@ -57,6 +82,9 @@ func (s *serverAPI) Register(ctx context.Context, req *pb.RegisterRequest) (*pb.
 		if errors.Is(err, auth.ErrUserAlreadyExists) {
 			return nil, status.Error(codes.AlreadyExists, "user already exists")
 		}
+		if policyErr := new(password.PolicyError); errors.As(err, &policyErr) {
+			return nil, status.Error(codes.InvalidArgument, policyErr.Reason)
+		}
 		return nil, status.Error(codes.Internal, "internal server error")
 	}
 
@@ -65,6 +93,372 @@ func (s *serverAPI) Register(ctx context.Context, req *pb.RegisterRequest) (*pb.
 	}, nil
 }
 
+func (s *serverAPI) VerifyEmail(ctx context.Context, req *pb.VerifyEmailRequest) (*pb.VerifyEmailResponse, error) {
+	if req.GetToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "token required")
+	}
+
+	userID, err := s.service.VerifyEmail(ctx, req.GetToken())
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidToken) {
+			return nil, status.Error(codes.InvalidArgument, "invalid or expired token")
+		}
+		return nil, status.Error(codes.Internal, "internal server error")
+	}
+
+	return &pb.VerifyEmailResponse{
+		UserId: userID,
+	}, nil
+}
+
+func (s *serverAPI) ResendVerificationEmail(
+	ctx context.Context,
+	req *pb.ResendVerificationEmailRequest,
+) (*pb.ResendVerificationEmailResponse, error) {
+	if err := validateEmail(req.GetLogin()); err != nil {
+		return nil, err
+	}
+
+	if err := s.service.ResendVerificationEmail(ctx, req.GetLogin()); err != nil {
+		return nil, status.Error(codes.Internal, "internal server error")
+	}
+
+	return &pb.ResendVerificationEmailResponse{Status: "sent"}, nil
+}
+
+func (s *serverAPI) RequestPasswordReset(
+	ctx context.Context,
+	req *pb.RequestPasswordResetRequest,
+) (*pb.RequestPasswordResetResponse, error) {
+	if err := validateEmail(req.GetLogin()); err != nil {
+		return nil, err
+	}
+
+	if err := s.service.RequestPasswordReset(ctx, req.GetLogin()); err != nil {
+		return nil, status.Error(codes.Internal, "internal server error")
+	}
+
+	return &pb.RequestPasswordResetResponse{Status: "sent"}, nil
+}
+
+func (s *serverAPI) ResetPassword(ctx context.Context, req *pb.ResetPasswordRequest) (*pb.ResetPasswordResponse, error) {
+	if req.GetToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "token required")
+	}
+	if req.GetNewPassword() == "" {
+		return nil, status.Error(codes.InvalidArgument, "password required")
+	}
+
+	if err := s.service.ResetPassword(ctx, req.GetToken(), req.GetNewPassword()); err != nil {
+		if policyErr := new(password.PolicyError); errors.As(err, &policyErr) {
+			return nil, status.Error(codes.InvalidArgument, policyErr.Reason)
+		}
+		if errors.Is(err, auth.ErrInvalidToken) {
+			return nil, status.Error(codes.InvalidArgument, "invalid or expired token")
+		}
+		return nil, status.Error(codes.Internal, "internal server error")
+	}
+
+	return &pb.ResetPasswordResponse{Status: "password reset"}, nil
+}
+
+func (s *serverAPI) VerifyMFA(ctx context.Context, req *pb.VerifyMFARequest) (*pb.VerifyMFAResponse, error) {
+	if req.GetMfaToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "mfa token required")
+	}
+	if req.GetCode() == "" {
+		return nil, status.Error(codes.InvalidArgument, "code required")
+	}
+
+	token, err := s.service.VerifyMFA(ctx, req.GetMfaToken(), req.GetCode(), common.ClientIP(ctx))
+	if err != nil {
+		if throttledErr := new(auth.ThrottledError); errors.As(err, &throttledErr) {
+			return nil, throttledStatus(ctx, throttledErr)
+		}
+		if errors.Is(err, auth.ErrInvalidToken) {
+			return nil, status.Error(codes.InvalidArgument, "invalid or expired mfa token")
+		}
+		if errors.Is(err, auth.ErrInvalidMFACode) {
+			return nil, status.Error(codes.InvalidArgument, "invalid code")
+		}
+		if errors.Is(err, auth.ErrInvalidAppID) {
+			return nil, status.Error(codes.InvalidArgument, "invalid app id")
+		}
+		return nil, status.Error(codes.Internal, "internal server error")
+	}
+
+	return &pb.VerifyMFAResponse{Token: token}, nil
+}
+
+func (s *serverAPI) EnrollTOTP(ctx context.Context, req *pb.EnrollTOTPRequest) (*pb.EnrollTOTPResponse, error) {
+	if req.GetUserId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user id required")
+	}
+
+	secret, uri, err := s.service.EnrollTOTP(ctx, req.GetUserId())
+	if err != nil {
+		return nil, mfaError(err)
+	}
+
+	return &pb.EnrollTOTPResponse{
+		Secret:          secret,
+		ProvisioningUri: uri,
+	}, nil
+}
+
+func (s *serverAPI) ConfirmTOTP(ctx context.Context, req *pb.ConfirmTOTPRequest) (*pb.ConfirmTOTPResponse, error) {
+	if req.GetUserId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user id required")
+	}
+	if req.GetCode() == "" {
+		return nil, status.Error(codes.InvalidArgument, "code required")
+	}
+
+	recoveryCodes, err := s.service.ConfirmTOTP(ctx, req.GetUserId(), req.GetCode())
+	if err != nil {
+		return nil, mfaError(err)
+	}
+
+	return &pb.ConfirmTOTPResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+func (s *serverAPI) DisableTOTP(ctx context.Context, req *pb.DisableTOTPRequest) (*pb.DisableTOTPResponse, error) {
+	if req.GetUserId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user id required")
+	}
+	if req.GetCode() == "" {
+		return nil, status.Error(codes.InvalidArgument, "code required")
+	}
+
+	if err := s.service.DisableTOTP(ctx, req.GetUserId(), req.GetCode()); err != nil {
+		if throttledErr := new(auth.ThrottledError); errors.As(err, &throttledErr) {
+			return nil, throttledStatus(ctx, throttledErr)
+		}
+		return nil, mfaError(err)
+	}
+
+	return &pb.DisableTOTPResponse{Status: "disabled"}, nil
+}
+
+func (s *serverAPI) DeleteAccount(ctx context.Context, req *pb.DeleteAccountRequest) (*pb.DeleteAccountResponse, error) {
+	if req.GetUserId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user id required")
+	}
+	if req.GetPassword() == "" {
+		return nil, status.Error(codes.InvalidArgument, "password required")
+	}
+
+	if err := s.service.DeleteAccount(ctx, req.GetUserId(), req.GetPassword(), common.ClientIP(ctx)); err != nil {
+		if throttledErr := new(auth.ThrottledError); errors.As(err, &throttledErr) {
+			return nil, throttledStatus(ctx, throttledErr)
+		}
+
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			return nil, status.Error(codes.InvalidArgument, "invalid password")
+		}
+
+		if errors.Is(err, auth.ErrUserNotFound) {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+
+		return nil, status.Error(codes.Internal, "internal server error")
+	}
+
+	return &pb.DeleteAccountResponse{Status: "deleted"}, nil
+}
+
+func (s *serverAPI) ExportAccountData(
+	ctx context.Context,
+	req *pb.ExportAccountDataRequest,
+) (*pb.ExportAccountDataResponse, error) {
+	if req.GetUserId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user id required")
+	}
+
+	data, err := s.service.ExportAccountData(ctx, req.GetUserId())
+	if err != nil {
+		if errors.Is(err, auth.ErrUserNotFound) {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+
+		return nil, status.Error(codes.Internal, "internal server error")
+	}
+
+	return &pb.ExportAccountDataResponse{
+		UserId:        data.UserID,
+		Login:         data.Login,
+		EmailVerified: data.EmailVerified,
+		MfaEnabled:    data.MFAEnabled,
+	}, nil
+}
+
+func (s *serverAPI) StartOIDCLogin(
+	ctx context.Context,
+	req *pb.StartOIDCLoginRequest,
+) (*pb.StartOIDCLoginResponse, error) {
+	if req.GetProvider() == "" {
+		return nil, status.Error(codes.InvalidArgument, "provider required")
+	}
+	if req.GetAppId() == emptyValue {
+		return nil, status.Error(codes.InvalidArgument, "app id required")
+	}
+
+	authURL, err := s.service.StartOIDCLogin(ctx, req.GetProvider(), int(req.GetAppId()), req.GetLinkUserId())
+	if err != nil {
+		return nil, oidcError(err)
+	}
+
+	return &pb.StartOIDCLoginResponse{AuthorizationUrl: authURL}, nil
+}
+
+func (s *serverAPI) CompleteOIDCLogin(
+	ctx context.Context,
+	req *pb.CompleteOIDCLoginRequest,
+) (*pb.CompleteOIDCLoginResponse, error) {
+	if req.GetProvider() == "" {
+		return nil, status.Error(codes.InvalidArgument, "provider required")
+	}
+	if req.GetState() == "" {
+		return nil, status.Error(codes.InvalidArgument, "state required")
+	}
+	if req.GetCode() == "" {
+		return nil, status.Error(codes.InvalidArgument, "code required")
+	}
+
+	res, err := s.service.CompleteOIDCLogin(ctx, req.GetProvider(), req.GetState(), req.GetCode(), common.ClientIP(ctx))
+	if err != nil {
+		return nil, oidcError(err)
+	}
+
+	return &pb.CompleteOIDCLoginResponse{
+		Token:       res.Token,
+		MfaRequired: res.MFAToken != "",
+		MfaToken:    res.MFAToken,
+		UserId:      res.UserID,
+		Linked:      res.Linked,
+	}, nil
+}
+
+func (s *serverAPI) ListSessions(ctx context.Context, req *pb.ListSessionsRequest) (*pb.ListSessionsResponse, error) {
+	if req.GetUserId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user id required")
+	}
+
+	sessions, err := s.service.ListSessions(ctx, req.GetUserId())
+	if err != nil {
+		return nil, status.Error(codes.Internal, "internal server error")
+	}
+
+	res := &pb.ListSessionsResponse{Sessions: make([]*pb.Session, 0, len(sessions))}
+	for _, session := range sessions {
+		res.Sessions = append(res.Sessions, &pb.Session{
+			SessionId:  session.ID,
+			AppId:      int32(session.AppID),
+			DeviceName: session.DeviceName,
+			Ip:         session.IP,
+			UserAgent:  session.UserAgent,
+			CreatedAt:  session.CreatedAt.Unix(),
+			LastUsedAt: session.LastUsedAt.Unix(),
+			ExpiresAt:  session.ExpiresAt.Unix(),
+		})
+	}
+
+	return res, nil
+}
+
+func (s *serverAPI) RevokeSession(ctx context.Context, req *pb.RevokeSessionRequest) (*pb.RevokeSessionResponse, error) {
+	if req.GetUserId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user id required")
+	}
+	if req.GetSessionId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "session id required")
+	}
+
+	if err := s.service.RevokeSession(ctx, req.GetUserId(), req.GetSessionId(), common.ClientIP(ctx)); err != nil {
+		return nil, sessionError(err)
+	}
+
+	return &pb.RevokeSessionResponse{Status: "revoked"}, nil
+}
+
+func (s *serverAPI) TouchSession(ctx context.Context, req *pb.TouchSessionRequest) (*pb.TouchSessionResponse, error) {
+	if req.GetSessionId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "session id required")
+	}
+
+	userID, err := s.service.TouchSession(ctx, req.GetSessionId(), common.ClientIP(ctx))
+	if err != nil {
+		return nil, sessionError(err)
+	}
+
+	return &pb.TouchSessionResponse{UserId: userID}, nil
+}
+
+// throttledStatus tells the client when to retry via response header and returns ResourceExhausted status.
+func throttledStatus(ctx context.Context, err *auth.ThrottledError) error {
+	retryAfter := int(math.Ceil(err.RetryAfter.Seconds()))
+	_ = grpc.SetHeader(ctx, metadata.Pairs(retryAfterMetadataKey, strconv.Itoa(retryAfter)))
+
+	return status.Error(codes.ResourceExhausted, "too many login attempts, try again later")
+}
+
+func mfaError(err error) error {
+	if errors.Is(err, auth.ErrUserNotFound) {
+		return status.Error(codes.NotFound, "user not found")
+	}
+	if errors.Is(err, auth.ErrMFAAlreadyEnabled) {
+		return status.Error(codes.FailedPrecondition, "two-factor authentication is already enabled")
+	}
+	if errors.Is(err, auth.ErrMFANotEnrolled) {
+		return status.Error(codes.FailedPrecondition, "two-factor authentication is not enrolled")
+	}
+	if errors.Is(err, auth.ErrInvalidMFACode) {
+		return status.Error(codes.InvalidArgument, "invalid code")
+	}
+
+	return status.Error(codes.Internal, "internal server error")
+}
+
+func oidcError(err error) error {
+	if errors.Is(err, auth.ErrUnknownProvider) {
+		return status.Error(codes.NotFound, "unknown identity provider")
+	}
+	if errors.Is(err, oidc.ErrDiscoveryFailed) {
+		return status.Error(codes.Unavailable, "identity provider is unavailable")
+	}
+	if errors.Is(err, auth.ErrInvalidAppID) {
+		return status.Error(codes.InvalidArgument, "invalid app id")
+	}
+	if errors.Is(err, auth.ErrUserNotFound) {
+		return status.Error(codes.NotFound, "user not found")
+	}
+	if errors.Is(err, auth.ErrInvalidToken) {
+		return status.Error(codes.InvalidArgument, "invalid or expired state")
+	}
+	if errors.Is(err, auth.ErrOIDCLoginFailed) {
+		return status.Error(codes.Unauthenticated, "identity provider login failed")
+	}
+	if errors.Is(err, auth.ErrIdentityAlreadyLinked) {
+		return status.Error(codes.AlreadyExists, "identity provider account is already linked")
+	}
+	if errors.Is(err, auth.ErrProviderEmailNotVerified) {
+		return status.Error(codes.FailedPrecondition, "email is not verified by identity provider")
+	}
+	if errors.Is(err, auth.ErrEmailNotVerified) {
+		return status.Error(codes.FailedPrecondition, "email is not verified")
+	}
+
+	return status.Error(codes.Internal, "internal server error")
+}
+
+func sessionError(err error) error {
+	if errors.Is(err, auth.ErrSessionNotFound) {
+		return status.Error(codes.NotFound, "session not found")
+	}
+
+	return status.Error(codes.Internal, "internal server error")
+}
+
 func validateLoginData(req *pb.LoginRequest) error {
 	if req.GetLogin() == "" {
 		return status.Error(codes.InvalidArgument, "login required")
@@ -80,8 +474,8 @@ func validateLoginData(req *pb.LoginRequest) error {
 }
 
 func validateRegisterData(req *pb.RegisterRequest) error {
-	if req.GetLogin() == "" {
-		return status.Error(codes.InvalidArgument, "login required")
+	if err := validateEmail(req.GetLogin()); err != nil {
+		return err
 	}
 	if req.GetPassword() == "" {
 		return status.Error(codes.InvalidArgument, "password required")
@@ -89,3 +483,17 @@ func validateRegisterData(req *pb.RegisterRequest) error {
 
 	return nil
 }
+
+func validateEmail(login string) error {
+	if login == "" {
+		return status.Error(codes.InvalidArgument, "login required")
+	}
+
+	// login must be a bare address, "Name <address>" forms are not accepted
+	addr, err := mail.ParseAddress(login)
+	if err != nil || addr.Address != login {
+		return status.Error(codes.InvalidArgument, "login must be a valid email")
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package relations
+
+import (
+	"context"
+	"errors"
+	pb "github.com/zoninnik89/messenger/common/api"
+	"github.com/zoninnik89/messenger/sso/internal/domain/models"
+	"github.com/zoninnik89/messenger/sso/internal/services/relations"
+	"github.com/zoninnik89/messenger/sso/internal/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type serverAPI struct {
+	pb.UnimplementedRelationsServiceServer
+	service types.Relations
+}
+
+func Register(srv *grpc.Server, svs types.Relations) {
+	pb.RegisterRelationsServiceServer(srv, &serverAPI{service: svs})
+}
+
+func (s *serverAPI) AddRelation(ctx context.Context, req *pb.AddRelationRequest) (*pb.AddRelationResponse, error) {
+	if err := validateRelationData(req.GetUserId(), req.GetTargetId()); err != nil {
+		return nil, err
+	}
+
+	kind, err := relationKind(req.GetKind())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.service.AddRelation(ctx, req.GetUserId(), req.GetTargetId(), kind); err != nil {
+		return nil, relationError(err)
+	}
+
+	return &pb.AddRelationResponse{Status: "added"}, nil
+}
+
+func (s *serverAPI) RemoveRelation(ctx context.Context, req *pb.RemoveRelationRequest) (*pb.RemoveRelationResponse, error) {
+	if err := validateRelationData(req.GetUserId(), req.GetTargetId()); err != nil {
+		return nil, err
+	}
+
+	kind, err := relationKind(req.GetKind())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.service.RemoveRelation(ctx, req.GetUserId(), req.GetTargetId(), kind); err != nil {
+		return nil, relationError(err)
+	}
+
+	return &pb.RemoveRelationResponse{Status: "removed"}, nil
+}
+
+func (s *serverAPI) ListRelations(ctx context.Context, req *pb.ListRelationsRequest) (*pb.ListRelationsResponse, error) {
+	if req.GetUserId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user id required")
+	}
+
+	relations, err := s.service.ListRelations(ctx, req.GetUserId())
+	if err != nil {
+		return nil, status.Error(codes.Internal, "internal server error")
+	}
+
+	res := &pb.ListRelationsResponse{}
+	for _, relation := range relations {
+		res.Relations = append(res.Relations, &pb.Relation{
+			UserId:    relation.UserID,
+			TargetId:  relation.TargetID,
+			Kind:      relationKindToProto(relation.Kind),
+			CreatedAt: relation.CreatedAt.Unix(),
+		})
+	}
+
+	return res, nil
+}
+
+func (s *serverAPI) IsBlocked(ctx context.Context, req *pb.IsBlockedRequest) (*pb.IsBlockedResponse, error) {
+	if err := validateRelationData(req.GetUserId(), req.GetTargetId()); err != nil {
+		return nil, err
+	}
+
+	blocked, err := s.service.IsBlocked(ctx, req.GetUserId(), req.GetTargetId())
+	if err != nil {
+		return nil, status.Error(codes.Internal, "internal server error")
+	}
+
+	return &pb.IsBlockedResponse{Blocked: blocked}, nil
+}
+
+func (s *serverAPI) ListBlockers(ctx context.Context, req *pb.ListBlockersRequest) (*pb.ListBlockersResponse, error) {
+	if req.GetUserId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user id required")
+	}
+
+	blockers, err := s.service.ListBlockers(ctx, req.GetUserId())
+	if err != nil {
+		return nil, status.Error(codes.Internal, "internal server error")
+	}
+
+	return &pb.ListBlockersResponse{UserIds: blockers}, nil
+}
+
+func (s *serverAPI) ListMuters(ctx context.Context, req *pb.ListMutersRequest) (*pb.ListMutersResponse, error) {
+	if req.GetUserId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user id required")
+	}
+
+	muters, err := s.service.ListMuters(ctx, req.GetUserId())
+	if err != nil {
+		return nil, status.Error(codes.Internal, "internal server error")
+	}
+
+	return &pb.ListMutersResponse{UserIds: muters}, nil
+}
+
+func validateRelationData(userID string, targetID string) error {
+	if userID == "" {
+		return status.Error(codes.InvalidArgument, "user id required")
+	}
+	if targetID == "" {
+		return status.Error(codes.InvalidArgument, "target id required")
+	}
+
+	return nil
+}
+
+func relationError(err error) error {
+	if errors.Is(err, relations.ErrSelfRelation) {
+		return status.Error(codes.InvalidArgument, "user cannot block or mute themselves")
+	}
+
+	if errors.Is(err, relations.ErrInvalidKind) {
+		return status.Error(codes.InvalidArgument, "invalid relation kind")
+	}
+
+	if errors.Is(err, relations.ErrRelationNotFound) {
+		return status.Error(codes.NotFound, "relation not found")
+	}
+
+	return status.Error(codes.Internal, "internal server error")
+}
+
+func relationKind(kind pb.RelationKind) (models.RelationKind, error) {
+	switch kind {
+	case pb.RelationKind_RELATION_KIND_BLOCK:
+		return models.RelationBlock, nil
+	case pb.RelationKind_RELATION_KIND_MUTE:
+		return models.RelationMute, nil
+	default:
+		return "", status.Error(codes.InvalidArgument, "relation kind required")
+	}
+}
+
+func relationKindToProto(kind models.RelationKind) pb.RelationKind {
+	switch kind {
+	case models.RelationBlock:
+		return pb.RelationKind_RELATION_KIND_BLOCK
+	case models.RelationMute:
+		return pb.RelationKind_RELATION_KIND_MUTE
+	default:
+		return pb.RelationKind_RELATION_KIND_UNSPECIFIED
+	}
+}
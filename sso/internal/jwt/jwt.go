@@ -3,10 +3,14 @@ package jwt
 import (
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/zoninnik89/messenger/sso/internal/domain/models"
+	"strings"
 	"time"
 )
 
-func NewToken(user models.User, app models.App, duration time.Duration) (string, error) {
+// NewToken issues token of the user for the app signed with the app secret.
+// Scopes of the app are embedded as space separated "scope" claim, the session the token
+// belongs to as "sid" claim.
+func NewToken(user models.User, app models.App, sessionID string, duration time.Duration) (string, error) {
 	token := jwt.New(jwt.SigningMethodHS256)
 
 	claims := token.Claims.(jwt.MapClaims)
@@ -14,6 +18,12 @@ func NewToken(user models.User, app models.App, duration time.Duration) (string,
 	claims["login"] = user.Login
 	claims["exp"] = time.Now().Add(duration).Unix()
 	claims["app_id"] = app.ID
+	if sessionID != "" {
+		claims["sid"] = sessionID
+	}
+	if len(app.Scopes) > 0 {
+		claims["scope"] = strings.Join(app.Scopes, " ")
+	}
 
 	tokenString, err := token.SignedString([]byte(app.Secret))
 	if err != nil {
@@ -0,0 +1,26 @@
+package oidc
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+
+	"github.com/zoninnik89/messenger/sso/internal/lib/securetoken"
+)
+
+// NewCodeVerifier returns a random PKCE code verifier and its S256 code challenge.
+func NewCodeVerifier() (verifier string, challenge string, err error) {
+	// 32 random bytes encode to 43 characters, the minimal verifier length allowed by RFC 7636
+	verifier, _, err = securetoken.New()
+	if err != nil {
+		return "", "", err
+	}
+
+	return verifier, CodeChallenge(verifier), nil
+}
+
+// CodeChallenge returns S256 code challenge of the verifier.
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,76 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+	"unicode"
+)
+
+// bcrypt, used for password hashing, ignores everything past 72 bytes
+const maxLengthBytes = 72
+
+var ErrWeakPassword = errors.New("password does not satisfy password policy")
+
+// PolicyError describes the password policy requirement which is not met.
+type PolicyError struct {
+	Reason string
+}
+
+func (e *PolicyError) Error() string {
+	return ErrWeakPassword.Error() + ": " + e.Reason
+}
+
+func (e *PolicyError) Unwrap() error {
+	return ErrWeakPassword
+}
+
+// Policy describes strength requirements for user passwords.
+type Policy struct {
+	MinLength      int
+	RequireUpper   bool
+	RequireLower   bool
+	RequireDigit   bool
+	RequireSpecial bool
+}
+
+// Validate checks the password against the policy.
+//
+// Returned error is a *PolicyError describing the first requirement which is not met.
+func (p Policy) Validate(password string) error {
+	if len([]rune(password)) < p.MinLength {
+		return &PolicyError{Reason: fmt.Sprintf("password must be at least %d characters long", p.MinLength)}
+	}
+
+	if len(password) > maxLengthBytes {
+		return &PolicyError{Reason: fmt.Sprintf("password must be at most %d bytes long", maxLengthBytes)}
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSpecial bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSpecial = true
+		}
+	}
+
+	if p.RequireUpper && !hasUpper {
+		return &PolicyError{Reason: "password must contain an uppercase letter"}
+	}
+	if p.RequireLower && !hasLower {
+		return &PolicyError{Reason: "password must contain a lowercase letter"}
+	}
+	if p.RequireDigit && !hasDigit {
+		return &PolicyError{Reason: "password must contain a digit"}
+	}
+	if p.RequireSpecial && !hasSpecial {
+		return &PolicyError{Reason: "password must contain a special character"}
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+// Package useragent derives human-readable device names from User-Agent headers.
+package useragent
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// maxDeviceNameLength limits device names stored with sessions, user agents are client controlled
+	maxDeviceNameLength = 100
+	// maxUserAgentLength limits user agents stored with sessions, in bytes
+	maxUserAgentLength = 512
+)
+
+// order matters, user agents of most browsers mention the browsers they are derived from
+var browsers = []struct{ token, name string }{
+	{"Edg/", "Edge"},
+	{"OPR/", "Opera"},
+	{"Firefox/", "Firefox"},
+	{"Chrome/", "Chrome"},
+	{"Safari/", "Safari"},
+	{"curl/", "curl"},
+}
+
+var systems = []struct{ token, name string }{
+	{"Android", "Android"},
+	{"iPhone", "iOS"},
+	{"iPad", "iPadOS"},
+	{"Windows", "Windows"},
+	{"Mac OS X", "macOS"},
+	{"CrOS", "ChromeOS"},
+	{"Linux", "Linux"},
+}
+
+// DeviceName returns a name like "Firefox on Linux" for the user agent,
+// unknown user agents are named by their first product token.
+func DeviceName(userAgent string) string {
+	userAgent = strings.TrimSpace(userAgent)
+	if userAgent == "" {
+		return "Unknown device"
+	}
+
+	browser := ""
+	for _, b := range browsers {
+		if strings.Contains(userAgent, b.token) {
+			browser = b.name
+			break
+		}
+	}
+
+	system := ""
+	for _, s := range systems {
+		if strings.Contains(userAgent, s.token) {
+			system = s.name
+			break
+		}
+	}
+
+	switch {
+	case browser != "" && system != "":
+		return browser + " on " + system
+	case browser != "":
+		return browser
+	case system != "":
+		return system
+	}
+
+	product, _, _ := strings.Cut(userAgent, " ")
+	product, _, _ = strings.Cut(product, "/")
+
+	return Truncate(product)
+}
+
+// Truncate shortens the device name to the length stored with sessions.
+func Truncate(name string) string {
+	runes := []rune(strings.TrimSpace(name))
+	if len(runes) > maxDeviceNameLength {
+		runes = runes[:maxDeviceNameLength]
+	}
+
+	return string(runes)
+}
+
+// TruncateUserAgent shortens the user agent to the length stored with sessions. The user agent is cut
+// on a character boundary and invalid UTF-8 is replaced, as storages reject invalid text.
+func TruncateUserAgent(userAgent string) string {
+	userAgent = strings.ToValidUTF8(userAgent, string(utf8.RuneError))
+	if len(userAgent) <= maxUserAgentLength {
+		return userAgent
+	}
+
+	end := maxUserAgentLength
+	for end > 0 && !utf8.RuneStart(userAgent[end]) {
+		end--
+	}
+
+	return userAgent[:end]
+}
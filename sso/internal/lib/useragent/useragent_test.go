@@ -0,0 +1,48 @@
+package useragent
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateUserAgent(t *testing.T) {
+	tests := []struct {
+		name      string
+		userAgent string
+		want      string
+	}{
+		{
+			name:      "short",
+			userAgent: "Mozilla/5.0",
+			want:      "Mozilla/5.0",
+		},
+		{
+			name:      "ascii",
+			userAgent: strings.Repeat("a", maxUserAgentLength+10),
+			want:      strings.Repeat("a", maxUserAgentLength),
+		},
+		{
+			name:      "multi-byte character across the limit",
+			userAgent: strings.Repeat("a", maxUserAgentLength-1) + "é",
+			want:      strings.Repeat("a", maxUserAgentLength-1),
+		},
+		{
+			name:      "invalid UTF-8",
+			userAgent: "Mozilla\xff/5.0",
+			want:      "Mozilla�/5.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TruncateUserAgent(tt.userAgent)
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("truncated user agent %q is not valid UTF-8", got)
+			}
+		})
+	}
+}
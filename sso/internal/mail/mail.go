@@ -0,0 +1,58 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/zoninnik89/messenger/sso/internal/domain/models"
+	"go.uber.org/zap"
+)
+
+// LogSender doesn't deliver mails, it only writes them to the log. Intended for local development.
+type LogSender struct {
+	logger *zap.SugaredLogger
+	from   string
+}
+
+func NewLogSender(logger *zap.SugaredLogger, from string) *LogSender {
+	return &LogSender{logger: logger, from: from}
+}
+
+func (s *LogSender) Send(ctx context.Context, mail models.Mail) error {
+	s.logger.Infow("mail sent", "from", s.from, "to", mail.To, "subject", mail.Subject, "body", mail.Body)
+	return nil
+}
+
+// FileSender writes every mail to a separate file in the given directory, so that
+// local tooling and tests can read what would have been delivered.
+type FileSender struct {
+	dir  string
+	from string
+}
+
+func NewFileSender(dir string, from string) (*FileSender, error) {
+	const op = "mail.NewFileSender"
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &FileSender{dir: dir, from: from}, nil
+}
+
+func (s *FileSender) Send(ctx context.Context, mail models.Mail) error {
+	const op = "mail.FileSender.Send"
+
+	name := fmt.Sprintf("%d-%s.txt", time.Now().UnixNano(), strings.ReplaceAll(mail.To, string(filepath.Separator), "_"))
+	content := fmt.Sprintf("From: %s\nTo: %s\nSubject: %s\n\n%s\n", s.from, mail.To, mail.Subject, mail.Body)
+
+	if err := os.WriteFile(filepath.Join(s.dir, name), []byte(content), 0o644); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
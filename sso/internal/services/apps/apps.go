@@ -0,0 +1,192 @@
+package apps
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/zoninnik89/messenger/sso/internal/domain/models"
+	"github.com/zoninnik89/messenger/sso/internal/lib/securetoken"
+	storagepkg "github.com/zoninnik89/messenger/sso/internal/storage"
+	"github.com/zoninnik89/messenger/sso/internal/types"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+type Apps struct {
+	logger      *zap.SugaredLogger
+	appSaver    types.AppSaver
+	appProvider types.AppProvider
+	audit       types.AuditRecorder
+}
+
+var (
+	ErrAppExists   = errors.New("app already exists")
+	ErrAppNotFound = errors.New("app not found")
+	ErrInvalidApp  = errors.New("invalid app")
+)
+
+// ValidationError describes why app data was rejected.
+type ValidationError struct {
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return ErrInvalidApp.Error() + ": " + e.Reason
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidApp
+}
+
+// NewAppsService returns a new instance of the Apps service
+func NewAppsService(
+	logger *zap.SugaredLogger,
+	appSaver types.AppSaver,
+	appProvider types.AppProvider,
+	audit types.AuditRecorder,
+) *Apps {
+
+	return &Apps{
+		logger:      logger,
+		appSaver:    appSaver,
+		appProvider: appProvider,
+		audit:       audit,
+	}
+}
+
+// CreateApp registers a new app with a generated secret.
+//
+// Zero tokenTTL means tokens of the app are issued with default TTL.
+func (a *Apps) CreateApp(
+	ctx context.Context,
+	name string,
+	tokenTTL time.Duration,
+	scopes []string,
+) (models.App, error) {
+	const op = "apps.CreateApp"
+
+	if err := validateApp(name, tokenTTL, scopes); err != nil {
+		return models.App{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	secret, _, err := securetoken.New()
+	if err != nil {
+		return models.App{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	app := models.App{
+		Name:      name,
+		Secret:    secret,
+		TokenTTL:  tokenTTL,
+		Scopes:    scopes,
+		CreatedAt: time.Now(),
+	}
+
+	app.ID, err = a.appSaver.SaveApp(ctx, app)
+	if err != nil {
+		if errors.Is(err, storagepkg.ErrAppExists) {
+			a.logger.Warnw("app already exists", "op", op, "name", name)
+
+			return models.App{}, fmt.Errorf("%s: %w", op, ErrAppExists)
+		}
+
+		a.logger.Errorw("failed to save app", "op", op, "error", err)
+
+		return models.App{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	a.logger.Infow("app created", "op", op, "appID", app.ID, "name", name)
+
+	a.audit.Record(ctx, models.AuditEvent{
+		Type:    models.AuditAppCreated,
+		AppID:   app.ID,
+		Details: map[string]string{"name": name},
+	})
+
+	return app, nil
+}
+
+// ListApps returns all registered apps, secrets are not included.
+func (a *Apps) ListApps(ctx context.Context) ([]models.App, error) {
+	const op = "apps.ListApps"
+
+	apps, err := a.appProvider.Apps(ctx)
+	if err != nil {
+		a.logger.Errorw("failed to get apps", "op", op, "error", err)
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for i := range apps {
+		apps[i].Secret = ""
+	}
+
+	return apps, nil
+}
+
+// RotateAppSecret replaces secret of the app with a new generated one and returns it.
+func (a *Apps) RotateAppSecret(ctx context.Context, appID int) (string, error) {
+	const op = "apps.RotateAppSecret"
+
+	secret, _, err := securetoken.New()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := a.appSaver.UpdateAppSecret(ctx, appID, secret); err != nil {
+		if errors.Is(err, storagepkg.ErrAppNotFound) {
+			return "", fmt.Errorf("%s: %w", op, ErrAppNotFound)
+		}
+
+		a.logger.Errorw("failed to update app secret", "op", op, "appID", appID, "error", err)
+
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	a.logger.Infow("app secret rotated", "op", op, "appID", appID)
+
+	a.audit.Record(ctx, models.AuditEvent{Type: models.AuditAppSecretRotated, AppID: appID})
+
+	return secret, nil
+}
+
+// DisableApp disables the app, so that users can no longer log in to it.
+func (a *Apps) DisableApp(ctx context.Context, appID int) error {
+	const op = "apps.DisableApp"
+
+	if err := a.appSaver.DisableApp(ctx, appID); err != nil {
+		if errors.Is(err, storagepkg.ErrAppNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrAppNotFound)
+		}
+
+		a.logger.Errorw("failed to disable app", "op", op, "appID", appID, "error", err)
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	a.logger.Infow("app disabled", "op", op, "appID", appID)
+
+	a.audit.Record(ctx, models.AuditEvent{Type: models.AuditAppDisabled, AppID: appID})
+
+	return nil
+}
+
+func validateApp(name string, tokenTTL time.Duration, scopes []string) error {
+	if strings.TrimSpace(name) == "" {
+		return &ValidationError{Reason: "name required"}
+	}
+
+	if tokenTTL < 0 {
+		return &ValidationError{Reason: "token ttl must not be negative"}
+	}
+
+	for _, scope := range scopes {
+		// scopes are stored and embedded in tokens space separated
+		if scope == "" || strings.ContainsAny(scope, " \t\n") {
+			return &ValidationError{Reason: fmt.Sprintf("invalid scope %q", scope)}
+		}
+	}
+
+	return nil
+}
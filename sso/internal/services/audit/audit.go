@@ -0,0 +1,93 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	pb "github.com/zoninnik89/messenger/common/api"
+	"github.com/zoninnik89/messenger/sso/internal/domain/models"
+	"github.com/zoninnik89/messenger/sso/internal/types"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultLimit = 100
+	maxLimit     = 1000
+
+	typePrefix = "AUDIT_EVENT_TYPE_"
+)
+
+var ErrInvalidFilter = errors.New("invalid audit filter")
+
+type Audit struct {
+	logger  *zap.SugaredLogger
+	storage types.AuditStorage
+}
+
+// NewAuditService returns a new instance of the Audit service
+func NewAuditService(logger *zap.SugaredLogger, storage types.AuditStorage) *Audit {
+	return &Audit{
+		logger:  logger,
+		storage: storage,
+	}
+}
+
+// ListAuditEvents returns audit events selected by the filter, newest first.
+//
+// Zero limit is replaced with the default one, limits above the maximum are capped.
+func (a *Audit) ListAuditEvents(ctx context.Context, filter models.AuditFilter) ([]models.AuditEvent, error) {
+	const op = "audit.ListAuditEvents"
+
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidFilter)
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = defaultLimit
+	}
+	if filter.Limit > maxLimit {
+		filter.Limit = maxLimit
+	}
+
+	events, err := a.storage.AuditEvents(ctx, filter)
+	if err != nil {
+		a.logger.Errorw("failed to get audit events", "op", op, "error", err)
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return events, nil
+}
+
+// EventToProto converts the event to its wire format.
+func EventToProto(event models.AuditEvent) *pb.AuditEvent {
+	return &pb.AuditEvent{
+		EventId:    event.ID,
+		Type:       pb.AuditEventType(pb.AuditEventType_value[typePrefix+strings.ToUpper(string(event.Type))]),
+		UserId:     event.UserID,
+		Login:      event.Login,
+		AppId:      int32(event.AppID),
+		ClientIp:   event.ClientIP,
+		Source:     event.Source,
+		OccurredAt: event.OccurredAt.UnixMilli(),
+		Details:    event.Details,
+	}
+}
+
+// EventFromProto converts the event received from other services.
+func EventFromProto(event *pb.AuditEvent) models.AuditEvent {
+	return models.AuditEvent{
+		ID:         event.GetEventId(),
+		Type:       models.AuditEventType(strings.ToLower(strings.TrimPrefix(event.GetType().String(), typePrefix))),
+		UserID:     event.GetUserId(),
+		Login:      event.GetLogin(),
+		AppID:      int(event.GetAppId()),
+		ClientIP:   event.GetClientIp(),
+		Source:     event.GetSource(),
+		OccurredAt: time.UnixMilli(event.GetOccurredAt()),
+		Details:    event.GetDetails(),
+	}
+}
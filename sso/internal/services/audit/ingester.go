@@ -0,0 +1,72 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+	pb "github.com/zoninnik89/messenger/common/api"
+	"github.com/zoninnik89/messenger/sso/internal/types"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+)
+
+// pollTimeout bounds waiting for an event, so that ingestion stops soon after its context is cancelled
+const pollTimeout = time.Second
+
+// ErrNoEvent is returned by Ingest if no event arrived within the poll timeout
+var ErrNoEvent = errors.New("no audit event")
+
+// Ingester stores audit events published by other services, so that they can be queried
+// together with events of sso.
+type Ingester struct {
+	logger  *zap.SugaredLogger
+	storage types.AuditStorage
+	source  string
+}
+
+// NewIngester returns ingester which skips events of the given source, they are already stored by Recorder.
+func NewIngester(logger *zap.SugaredLogger, storage types.AuditStorage, source string) *Ingester {
+	return &Ingester{
+		logger:  logger,
+		storage: storage,
+		source:  source,
+	}
+}
+
+// Ingest reads one event from the consumer and stores it, it returns ID of the event.
+func (i *Ingester) Ingest(ctx context.Context, consumer *kafka.Consumer) (string, error) {
+	const op = "audit.Ingest"
+
+	msg, err := consumer.ReadMessage(pollTimeout)
+	if err != nil {
+		if kafkaErr, ok := err.(kafka.Error); ok && kafkaErr.Code() == kafka.ErrTimedOut {
+			return "", ErrNoEvent
+		}
+
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	var event pb.AuditEvent
+	if err := proto.Unmarshal(msg.Value, &event); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	if event.GetSource() == i.source {
+		return event.GetEventId(), nil
+	}
+
+	if event.GetEventId() == "" {
+		return "", fmt.Errorf("%s: event id required", op)
+	}
+
+	if err := i.storage.SaveAuditEvent(ctx, EventFromProto(&event)); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	i.logger.Infow("audit event ingested", "op", op, "eventID", event.GetEventId(), "source", event.GetSource())
+
+	return event.GetEventId(), nil
+}
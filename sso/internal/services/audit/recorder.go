@@ -0,0 +1,68 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zoninnik89/messenger/common"
+	"github.com/zoninnik89/messenger/sso/internal/domain/models"
+	"github.com/zoninnik89/messenger/sso/internal/types"
+	"go.uber.org/zap"
+)
+
+// publishTimeout bounds publishing of a single event, which happens after the audited request is answered.
+const publishTimeout = 10 * time.Second
+
+// Recorder saves audit events of sso and publishes them to the audit events topic.
+type Recorder struct {
+	logger    *zap.SugaredLogger
+	storage   types.AuditStorage
+	publisher types.EventPublisher
+	source    string
+}
+
+// NewRecorder returns recorder which marks events with the given source, usually the service name.
+func NewRecorder(
+	logger *zap.SugaredLogger,
+	storage types.AuditStorage,
+	publisher types.EventPublisher,
+	source string,
+) *Recorder {
+	return &Recorder{
+		logger:    logger,
+		storage:   storage,
+		publisher: publisher,
+		source:    source,
+	}
+}
+
+// Record saves the event and publishes it in background.
+//
+// ID, source and time of the event are set by the recorder. If client IP is not set, IP of the
+// gRPC request carried by the context is used.
+func (r *Recorder) Record(ctx context.Context, event models.AuditEvent) {
+	const op = "audit.Record"
+
+	event.ID = uuid.New().String()
+	event.Source = r.source
+	event.OccurredAt = time.Now()
+	if event.ClientIP == "" {
+		event.ClientIP = common.ClientIP(ctx)
+	}
+
+	if err := r.storage.SaveAuditEvent(ctx, event); err != nil {
+		r.logger.Errorw("failed to save audit event", "op", op, "type", event.Type, "userID", event.UserID, "error", err)
+	}
+
+	// publishing must not be cancelled together with the request which caused the event
+	go func(ctx context.Context) {
+		ctx, cancel := context.WithTimeout(ctx, publishTimeout)
+		defer cancel()
+
+		err := r.publisher.Publish(ctx, common.AuditEventsTopic, event.UserID, EventToProto(event))
+		if err != nil {
+			r.logger.Errorw("failed to publish audit event", "op", op, "eventID", event.ID, "error", err)
+		}
+	}(context.WithoutCancel(ctx))
+}
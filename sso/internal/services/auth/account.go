@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zoninnik89/messenger/common"
+	pb "github.com/zoninnik89/messenger/common/api"
+	"github.com/zoninnik89/messenger/sso/internal/domain/models"
+	storagepkg "github.com/zoninnik89/messenger/sso/internal/storage"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// DeleteAccount deletes the user after checking the password and publishes account deleted event,
+// so that other services erase or anonymize data of the user.
+//
+// The event is published before the user is deleted, so if publishing fails nothing is deleted and
+// the client may retry. Services erase data of the user idempotently, so events of retries do no harm.
+//
+// Wrong passwords are throttled the same way as failed logins.
+func (a *Auth) DeleteAccount(ctx context.Context, userID string, password string, clientIP string) error {
+	const op = "auth.DeleteAccount"
+
+	user, err := a.usrProvider.UserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storagepkg.ErrUserNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+
+		a.logger.Errorw("failed to get user", "op", op, "userID", userID, "error", err)
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := a.checkLoginAttempts(ctx, user.Login, clientIP); err != nil {
+		a.recordLoginThrottled(ctx, user.ID, user.Login, clientIP, 0, err)
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword(user.PassHash, []byte(password)); err != nil {
+		a.logger.Infow("password does not match", "op", op, "userID", userID)
+		a.registerLoginFailure(ctx, user.Login, clientIP)
+		a.recordLoginFailed(ctx, user, clientIP, 0, "invalid password on account deletion")
+
+		return fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
+	}
+
+	event := &pb.AccountEvent{
+		UserId:     userID,
+		Type:       pb.AccountEventType_ACCOUNT_EVENT_TYPE_DELETED,
+		OccurredAt: time.Now().Unix(),
+	}
+
+	if err := a.events.Publish(ctx, common.AccountEventsTopic, userID, event); err != nil {
+		a.logger.Errorw("failed to publish account deleted event", "op", op, "userID", userID, "error", err)
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := a.usrSaver.DeleteUser(ctx, userID); err != nil {
+		if errors.Is(err, storagepkg.ErrUserNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+
+		a.logger.Errorw("failed to delete user", "op", op, "userID", userID, "error", err)
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	a.resetLoginAttempts(ctx, user.Login)
+
+	// audit events are kept after the account is deleted, they are needed to investigate abuse
+	a.audit.Record(ctx, models.AuditEvent{
+		Type:     models.AuditAccountDeleted,
+		UserID:   userID,
+		Login:    user.Login,
+		ClientIP: clientIP,
+	})
+
+	a.logger.Infow("account deleted", "op", op, "userID", userID)
+
+	return nil
+}
+
+// ExportAccountData returns personal data of the user kept by SSO service.
+func (a *Auth) ExportAccountData(ctx context.Context, userID string) (models.AccountData, error) {
+	const op = "auth.ExportAccountData"
+
+	user, err := a.usrProvider.UserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storagepkg.ErrUserNotFound) {
+			return models.AccountData{}, fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+
+		a.logger.Errorw("failed to get user", "op", op, "userID", userID, "error", err)
+
+		return models.AccountData{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	mfaEnabled, err := a.mfaEnabled(ctx, userID)
+	if err != nil {
+		a.logger.Errorw("failed to get mfa", "op", op, "userID", userID, "error", err)
+
+		return models.AccountData{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	a.logger.Infow("account data exported", "op", op, "userID", userID)
+
+	return models.AccountData{
+		UserID:        user.ID,
+		Login:         user.Login,
+		EmailVerified: user.EmailVerified,
+		MFAEnabled:    mfaEnabled,
+	}, nil
+}
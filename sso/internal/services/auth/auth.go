@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"github.com/zoninnik89/messenger/sso/internal/jwt"
+	"github.com/zoninnik89/messenger/sso/internal/domain/models"
+	"github.com/zoninnik89/messenger/sso/internal/lib/password"
+	"github.com/zoninnik89/messenger/sso/internal/lib/securetoken"
 	storagepkg "github.com/zoninnik89/messenger/sso/internal/storage"
 	"github.com/zoninnik89/messenger/sso/internal/types"
 	"go.uber.org/zap"
@@ -17,13 +19,35 @@ type Auth struct {
 	usrSaver    types.UserSaver
 	usrProvider types.UserProvider
 	appProvider types.AppProvider
+	tokens      types.TokenStorage
+	mailSender  types.MailSender
+	attempts    types.LoginAttemptsStorage
+	mfa         types.MFAStorage
+	identities  types.IdentityStorage
+	sessions    types.SessionStorage
+	events      types.EventPublisher
+	audit       types.AuditRecorder
+	account     AccountSettings
 	tokenTTL    time.Duration
 }
 
+// AccountSettings configures password policy, email verification and password reset.
+type AccountSettings struct {
+	PasswordPolicy       password.Policy
+	VerificationTokenTTL time.Duration
+	ResetTokenTTL        time.Duration
+	RequireVerifiedEmail bool
+	Lockout              LockoutSettings
+	MFA                  MFASettings
+	OIDC                 OIDCSettings
+}
+
 var (
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrInvalidAppID       = errors.New("invalid app id")
 	ErrUserAlreadyExists  = errors.New("user already exists")
+	ErrEmailNotVerified   = errors.New("email is not verified")
+	ErrInvalidToken       = errors.New("invalid or expired token")
 )
 
 // NewAuthService returns a new instance of the Auth service
@@ -32,6 +56,15 @@ func NewAuthService(
 	userSaver types.UserSaver,
 	userProvider types.UserProvider,
 	appProvider types.AppProvider,
+	tokens types.TokenStorage,
+	mailSender types.MailSender,
+	attempts types.LoginAttemptsStorage,
+	mfa types.MFAStorage,
+	identities types.IdentityStorage,
+	sessions types.SessionStorage,
+	events types.EventPublisher,
+	audit types.AuditRecorder,
+	account AccountSettings,
 	tokenTTL time.Duration,
 ) *Auth {
 
@@ -40,6 +73,15 @@ func NewAuthService(
 		usrSaver:    userSaver,
 		usrProvider: userProvider,
 		appProvider: appProvider,
+		tokens:      tokens,
+		mailSender:  mailSender,
+		attempts:    attempts,
+		mfa:         mfa,
+		identities:  identities,
+		sessions:    sessions,
+		events:      events,
+		audit:       audit,
+		account:     account,
 		tokenTTL:    tokenTTL,
 	}
 }
@@ -48,54 +90,109 @@ func NewAuthService(
 //
 // If user exists, but password is incorrect, returns error.
 // If user doesn't exist, returns error.
+// If there were too many failed attempts for the login or from the client IP, returns *ThrottledError
+// without checking the password.
+// If user has two-factor authentication enabled, returns MFA challenge token to be passed to VerifyMFA
+// instead of auth token.
 func (a *Auth) Login(
 	ctx context.Context,
 	login string,
 	password string,
+	clientIP string,
 	appID int,
-) (string, error) {
+) (string, string, error) {
 	const op = "auth.Login"
 
 	a.logger.Info("attempting to login a user")
 
+	if err := a.checkLoginAttempts(ctx, login, clientIP); err != nil {
+		a.recordLoginThrottled(ctx, "", login, clientIP, appID, err)
+
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
 	user, err := a.usrProvider.GetUser(ctx, login)
 	if err != nil {
 		if errors.Is(err, storagepkg.ErrUserNotFound) {
 			a.logger.Errorw("user not found", "error", err)
+			a.registerLoginFailure(ctx, login, clientIP)
+			a.recordLoginFailed(ctx, models.User{Login: login}, clientIP, appID, "unknown login")
 
-			return "", fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
+			return "", "", fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
 		}
 
 		if errors.Is(err, storagepkg.ErrAppNotFound) {
 			a.logger.Errorw("app not found", "error", err)
 
-			return "", fmt.Errorf("%s: %w", op, ErrInvalidAppID)
+			return "", "", fmt.Errorf("%s: %w", op, ErrInvalidAppID)
 		}
 
 		a.logger.Warnw("failed to get user", "error", err)
-		return "", fmt.Errorf("%s: %w", op, err)
+		return "", "", fmt.Errorf("%s: %w", op, err)
 	}
 
 	if err := bcrypt.CompareHashAndPassword(user.PassHash, []byte(password)); err != nil {
 		a.logger.Errorw("password does not match", "error", err)
-		return "", fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
+		a.registerLoginFailure(ctx, login, clientIP)
+		a.recordLoginFailed(ctx, user, clientIP, appID, "invalid password")
+
+		return "", "", fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
 	}
 
-	app, err := a.appProvider.GetApp(ctx, appID)
+	if a.account.RequireVerifiedEmail && !user.EmailVerified {
+		a.logger.Infow("email is not verified", "op", op, "user", user.ID)
+		a.recordLoginFailed(ctx, user, clientIP, appID, "email is not verified")
+
+		return "", "", fmt.Errorf("%s: %w", op, ErrEmailNotVerified)
+	}
+
+	app, err := a.app(ctx, appID)
 	if err != nil {
-		return "", fmt.Errorf("%s: %w", op, err)
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	mfaEnabled, err := a.mfaEnabled(ctx, user.ID)
+	if err != nil {
+		a.logger.Errorw("failed to get mfa", "op", op, "user", user.ID, "error", err)
+
+		return "", "", fmt.Errorf("%s: %w", op, err)
 	}
 
+	if mfaEnabled {
+		// failed attempts are kept until the second factor is passed, so that knowing
+		// the password doesn't allow to guess codes without throttling
+		mfaToken, err := a.issueMFAChallenge(ctx, user.ID, app.ID)
+		if err != nil {
+			a.logger.Errorw("failed to issue mfa challenge", "op", op, "user", user.ID, "error", err)
+
+			return "", "", fmt.Errorf("%s: %w", op, err)
+		}
+
+		a.logger.Infow("second factor required", "op", op, "user", user.ID)
+
+		return "", mfaToken, nil
+	}
+
+	a.resetLoginAttempts(ctx, login)
+
 	a.logger.Infow("logged in", "user", user.ID)
 
-	token, err := jwt.NewToken(user, app, a.tokenTTL)
+	token, err := a.issueAuthToken(ctx, user, app, clientIP)
 	if err != nil {
 		a.logger.Warnw("failed to generate token", "error", err)
 
-		return "", fmt.Errorf("%s: %w", op, err)
+		return "", "", fmt.Errorf("%s: %w", op, err)
 	}
 
-	return token, nil
+	a.audit.Record(ctx, models.AuditEvent{
+		Type:     models.AuditLoginSucceeded,
+		UserID:   user.ID,
+		Login:    user.Login,
+		AppID:    app.ID,
+		ClientIP: clientIP,
+	})
+
+	return token, "", nil
 }
 
 // RegisterNewUser registers new user in the system and returns user ID.
@@ -109,6 +206,13 @@ func (a *Auth) RegisterNewUser(
 	const op = "auth.RegisterNewUser"
 
 	a.logger.Info("registering new user")
+
+	if err := a.account.PasswordPolicy.Validate(password); err != nil {
+		a.logger.Infow("password does not satisfy policy", "op", op, "error", err)
+
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
 	passHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
 		a.logger.Error("failed to hash password", zap.Error(err))
@@ -131,5 +235,265 @@ func (a *Auth) RegisterNewUser(
 
 	a.logger.Infow("user registered", "op", op, "userID", id)
 
+	a.audit.Record(ctx, models.AuditEvent{Type: models.AuditUserRegistered, UserID: id, Login: email})
+
+	if err := a.sendVerificationEmail(ctx, models.User{ID: id, Login: email}); err != nil {
+		// user can request another verification email, so registration still succeeds
+		a.logger.Warnw("failed to send verification email", "op", op, "userID", id, "error", err)
+	}
+
 	return id, nil
 }
+
+// VerifyEmail marks email of the user the verification token was issued to as verified.
+//
+// If token doesn't exist, was already used or expired, returns error.
+func (a *Auth) VerifyEmail(ctx context.Context, token string) (string, error) {
+	const op = "auth.VerifyEmail"
+
+	t, err := a.tokens.ConsumeToken(ctx, securetoken.Hash(token), models.TokenEmailVerification)
+	if err != nil {
+		if errors.Is(err, storagepkg.ErrTokenNotFound) {
+			a.logger.Infow("invalid verification token", "op", op)
+
+			return "", fmt.Errorf("%s: %w", op, ErrInvalidToken)
+		}
+
+		a.logger.Errorw("failed to consume verification token", "op", op, "error", err)
+
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := a.usrSaver.SetEmailVerified(ctx, t.UserID); err != nil {
+		a.logger.Errorw("failed to mark email as verified", "op", op, "userID", t.UserID, "error", err)
+
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	a.logger.Infow("email verified", "op", op, "userID", t.UserID)
+
+	return t.UserID, nil
+}
+
+// ResendVerificationEmail issues a new verification token for the user and sends it by email.
+//
+// Unknown and already verified logins are silently ignored, so the method can't be used to enumerate users.
+func (a *Auth) ResendVerificationEmail(ctx context.Context, login string) error {
+	const op = "auth.ResendVerificationEmail"
+
+	user, err := a.usrProvider.GetUser(ctx, login)
+	if err != nil {
+		if errors.Is(err, storagepkg.ErrUserNotFound) {
+			a.logger.Infow("verification requested for unknown user", "op", op)
+
+			return nil
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if user.EmailVerified {
+		a.logger.Infow("email is already verified", "op", op, "userID", user.ID)
+
+		return nil
+	}
+
+	if err := a.sendVerificationEmail(ctx, user); err != nil {
+		a.logger.Errorw("failed to send verification email", "op", op, "userID", user.ID, "error", err)
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// RequestPasswordReset issues a password reset token for the user and sends it by email.
+//
+// Unknown logins are silently ignored, so the method can't be used to enumerate users.
+func (a *Auth) RequestPasswordReset(ctx context.Context, login string) error {
+	const op = "auth.RequestPasswordReset"
+
+	user, err := a.usrProvider.GetUser(ctx, login)
+	if err != nil {
+		if errors.Is(err, storagepkg.ErrUserNotFound) {
+			a.logger.Infow("password reset requested for unknown user", "op", op)
+
+			return nil
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	token, err := a.issueToken(ctx, user.ID, models.TokenPasswordReset, a.account.ResetTokenTTL)
+	if err != nil {
+		a.logger.Errorw("failed to issue password reset token", "op", op, "userID", user.ID, "error", err)
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = a.mailSender.Send(ctx, models.Mail{
+		To:      user.Login,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Use the following token to reset your password, it is valid for %s.\n\ntoken: %s\n\n"+
+				"If you didn't request password reset, ignore this email.",
+			a.account.ResetTokenTTL, token,
+		),
+	})
+	if err != nil {
+		a.logger.Errorw("failed to send password reset email", "op", op, "userID", user.ID, "error", err)
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	a.logger.Infow("password reset requested", "op", op, "userID", user.ID)
+
+	return nil
+}
+
+// ResetPassword sets a new password for the user the reset token was issued to.
+//
+// If the new password does not satisfy password policy, or token doesn't exist, was already used or expired, returns error.
+func (a *Auth) ResetPassword(ctx context.Context, token string, newPassword string) error {
+	const op = "auth.ResetPassword"
+
+	// validate before consuming the token, so that a weak password doesn't burn it
+	if err := a.account.PasswordPolicy.Validate(newPassword); err != nil {
+		a.logger.Infow("password does not satisfy policy", "op", op, "error", err)
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	t, err := a.tokens.ConsumeToken(ctx, securetoken.Hash(token), models.TokenPasswordReset)
+	if err != nil {
+		if errors.Is(err, storagepkg.ErrTokenNotFound) {
+			a.logger.Infow("invalid password reset token", "op", op)
+
+			return fmt.Errorf("%s: %w", op, ErrInvalidToken)
+		}
+
+		a.logger.Errorw("failed to consume password reset token", "op", op, "error", err)
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	passHash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		a.logger.Error("failed to hash password", zap.Error(err))
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := a.usrSaver.UpdatePassword(ctx, t.UserID, passHash); err != nil {
+		a.logger.Errorw("failed to update password", "op", op, "userID", t.UserID, "error", err)
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// the reset token was delivered by email, so the user has proven they own the address
+	if err := a.usrSaver.SetEmailVerified(ctx, t.UserID); err != nil {
+		a.logger.Warnw("failed to mark email as verified", "op", op, "userID", t.UserID, "error", err)
+	}
+
+	a.logger.Infow("password reset", "op", op, "userID", t.UserID)
+
+	a.audit.Record(ctx, models.AuditEvent{Type: models.AuditPasswordReset, UserID: t.UserID})
+
+	return nil
+}
+
+// recordLoginFailed records failed authentication, the user may have only the login set if it is unknown.
+func (a *Auth) recordLoginFailed(ctx context.Context, user models.User, clientIP string, appID int, reason string) {
+	a.audit.Record(ctx, models.AuditEvent{
+		Type:     models.AuditLoginFailed,
+		UserID:   user.ID,
+		Login:    user.Login,
+		AppID:    appID,
+		ClientIP: clientIP,
+		Details:  map[string]string{"reason": reason},
+	})
+}
+
+// recordLoginThrottled records authentication rejected by brute-force protection.
+func (a *Auth) recordLoginThrottled(ctx context.Context, userID string, login string, clientIP string, appID int, err error) {
+	event := models.AuditEvent{
+		Type:     models.AuditLoginThrottled,
+		UserID:   userID,
+		Login:    login,
+		AppID:    appID,
+		ClientIP: clientIP,
+	}
+
+	if throttledErr := new(ThrottledError); errors.As(err, &throttledErr) {
+		event.Details = map[string]string{"retry_after": throttledErr.RetryAfter.String()}
+	}
+
+	a.audit.Record(ctx, event)
+}
+
+// app returns the app user logs in to, unknown and disabled apps are reported as ErrInvalidAppID.
+func (a *Auth) app(ctx context.Context, appID int) (models.App, error) {
+	app, err := a.appProvider.GetApp(ctx, appID)
+	if err != nil {
+		if errors.Is(err, storagepkg.ErrAppNotFound) {
+			a.logger.Infow("app not found", "appID", appID)
+
+			return models.App{}, ErrInvalidAppID
+		}
+
+		return models.App{}, err
+	}
+
+	if app.Disabled() {
+		a.logger.Infow("app is disabled", "appID", appID)
+
+		return models.App{}, ErrInvalidAppID
+	}
+
+	return app, nil
+}
+
+// appTokenTTL returns TTL of tokens issued for the app.
+func (a *Auth) appTokenTTL(app models.App) time.Duration {
+	if app.TokenTTL > 0 {
+		return app.TokenTTL
+	}
+
+	return a.tokenTTL
+}
+
+func (a *Auth) sendVerificationEmail(ctx context.Context, user models.User) error {
+	token, err := a.issueToken(ctx, user.ID, models.TokenEmailVerification, a.account.VerificationTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	return a.mailSender.Send(ctx, models.Mail{
+		To:      user.Login,
+		Subject: "Verify your email",
+		Body: fmt.Sprintf(
+			"Use the following token to verify your email, it is valid for %s.\n\ntoken: %s",
+			a.account.VerificationTokenTTL, token,
+		),
+	})
+}
+
+// issueToken generates a single-use token, stores its hash and returns the token itself.
+func (a *Auth) issueToken(ctx context.Context, userID string, purpose models.TokenPurpose, ttl time.Duration) (string, error) {
+	token, hash, err := securetoken.New()
+	if err != nil {
+		return "", err
+	}
+
+	err = a.tokens.SaveToken(ctx, models.Token{
+		Hash:      hash,
+		UserID:    userID,
+		Purpose:   purpose,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
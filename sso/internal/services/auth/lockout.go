@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zoninnik89/messenger/sso/internal/domain/models"
+)
+
+// LockoutSettings configures brute-force protection of Login.
+//
+// Every failed attempt of a login delays the next one by BaseDelay doubled per failure up to MaxDelay.
+// Once MaxLoginAttempts failures of a login or MaxIPAttempts failures from a client IP happen
+// within Window, the login or IP is locked for LockoutDuration.
+type LockoutSettings struct {
+	MaxLoginAttempts int
+	MaxIPAttempts    int
+	Window           time.Duration
+	BaseDelay        time.Duration
+	MaxDelay         time.Duration
+	LockoutDuration  time.Duration
+}
+
+var ErrTooManyAttempts = errors.New("too many login attempts")
+
+// ThrottledError is returned by Login when the attempt is rejected without checking credentials.
+type ThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *ThrottledError) Error() string {
+	return fmt.Sprintf("%s: retry after %s", ErrTooManyAttempts, e.RetryAfter)
+}
+
+func (e *ThrottledError) Unwrap() error {
+	return ErrTooManyAttempts
+}
+
+func loginAttemptsKey(login string) string {
+	return "login:" + login
+}
+
+func ipAttemptsKey(ip string) string {
+	return "ip:" + ip
+}
+
+// checkLoginAttempts returns *ThrottledError if the login or client IP is locked,
+// or if the login is still in backoff after a failed attempt.
+func (a *Auth) checkLoginAttempts(ctx context.Context, login string, clientIP string) error {
+	const op = "auth.checkLoginAttempts"
+
+	now := time.Now()
+
+	loginAttempts, err := a.attempts.LoginAttempts(ctx, loginAttemptsKey(login))
+	if err != nil {
+		a.logger.Errorw("failed to get login attempts", "op", op, "error", err)
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	retryAt := loginAttempts.LockedUntil
+	if loginAttempts.Failures > 0 && now.Sub(loginAttempts.LastFailureAt) < a.account.Lockout.Window {
+		if next := loginAttempts.LastFailureAt.Add(a.backoff(loginAttempts.Failures)); next.After(retryAt) {
+			retryAt = next
+		}
+	}
+
+	if clientIP != "" {
+		ipAttempts, err := a.attempts.LoginAttempts(ctx, ipAttemptsKey(clientIP))
+		if err != nil {
+			a.logger.Errorw("failed to get login attempts", "op", op, "error", err)
+
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		if ipAttempts.LockedUntil.After(retryAt) {
+			retryAt = ipAttempts.LockedUntil
+		}
+	}
+
+	if retryAt.After(now) {
+		a.logger.Warnw("login attempt throttled", "op", op, "login", login, "ip", clientIP, "retryAt", retryAt)
+
+		return &ThrottledError{RetryAfter: retryAt.Sub(now)}
+	}
+
+	return nil
+}
+
+// registerLoginFailure counts a failed attempt of the login and from the client IP and locks them
+// once they reach their limits.
+//
+// Counting errors are logged and not returned, so that they don't mask invalid credentials.
+func (a *Auth) registerLoginFailure(ctx context.Context, login string, clientIP string) {
+	a.addLoginFailure(ctx, loginAttemptsKey(login), login, clientIP, a.account.Lockout.MaxLoginAttempts)
+
+	if clientIP != "" {
+		a.addLoginFailure(ctx, ipAttemptsKey(clientIP), login, clientIP, a.account.Lockout.MaxIPAttempts)
+	}
+}
+
+func (a *Auth) addLoginFailure(ctx context.Context, key string, login string, clientIP string, maxAttempts int) {
+	const op = "auth.addLoginFailure"
+
+	now := time.Now()
+
+	failures, err := a.attempts.AddLoginFailure(ctx, key, now, now.Add(-a.account.Lockout.Window))
+	if err != nil {
+		a.logger.Errorw("failed to count login failure", "op", op, "key", key, "error", err)
+
+		return
+	}
+
+	if failures < maxAttempts {
+		return
+	}
+
+	lockout := models.Lockout{
+		Key:         key,
+		Login:       login,
+		IP:          clientIP,
+		Failures:    failures,
+		LockedUntil: now.Add(a.account.Lockout.LockoutDuration),
+		CreatedAt:   now,
+	}
+
+	if err := a.attempts.LockLogin(ctx, lockout); err != nil {
+		a.logger.Errorw("failed to lock login", "op", op, "key", key, "error", err)
+
+		return
+	}
+
+	a.logger.Warnw("login locked out",
+		"op", op,
+		"key", key,
+		"login", login,
+		"ip", clientIP,
+		"failures", failures,
+		"lockedUntil", lockout.LockedUntil,
+	)
+}
+
+// resetLoginAttempts forgets failed attempts of the login after successful authentication.
+//
+// Failures from the client IP are kept, so an attacker can't reset them by logging in to their own account.
+func (a *Auth) resetLoginAttempts(ctx context.Context, login string) {
+	const op = "auth.resetLoginAttempts"
+
+	if err := a.attempts.ResetLoginAttempts(ctx, loginAttemptsKey(login)); err != nil {
+		a.logger.Errorw("failed to reset login attempts", "op", op, "error", err)
+	}
+}
+
+// backoff returns delay before the next attempt after the given number of consecutive failures.
+func (a *Auth) backoff(failures int) time.Duration {
+	delay := a.account.Lockout.BaseDelay
+	for i := 1; i < failures; i++ {
+		delay *= 2
+		if delay >= a.account.Lockout.MaxDelay {
+			return a.account.Lockout.MaxDelay
+		}
+	}
+
+	return delay
+}
@@ -0,0 +1,348 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/zoninnik89/messenger/sso/internal/domain/models"
+	"github.com/zoninnik89/messenger/sso/internal/lib/securetoken"
+	"github.com/zoninnik89/messenger/sso/internal/lib/totp"
+	storagepkg "github.com/zoninnik89/messenger/sso/internal/storage"
+)
+
+// MFASettings configures TOTP two-factor authentication.
+type MFASettings struct {
+	// Issuer is shown next to the account in authenticator apps
+	Issuer        string
+	ChallengeTTL  time.Duration
+	RecoveryCodes int
+}
+
+const (
+	// recoveryCodeAlphabet omits characters which are easy to confuse, like "l" and "1"
+	recoveryCodeAlphabet   = "abcdefghjkmnpqrstuvwxyz23456789"
+	recoveryCodeHalfLength = 5
+)
+
+var (
+	ErrUserNotFound      = errors.New("user not found")
+	ErrMFAAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnrolled    = errors.New("two-factor authentication is not enrolled")
+	ErrInvalidMFACode    = errors.New("invalid two-factor authentication code")
+)
+
+// VerifyMFA completes login of a user with enabled two-factor authentication.
+//
+// The code is either a TOTP code or one of the recovery codes. Failed attempts are throttled
+// the same way as failed passwords.
+func (a *Auth) VerifyMFA(ctx context.Context, mfaToken string, code string, clientIP string) (string, error) {
+	const op = "auth.VerifyMFA"
+
+	challenge, err := a.tokens.Token(ctx, securetoken.Hash(mfaToken), models.TokenMFAChallenge)
+	if err != nil {
+		if errors.Is(err, storagepkg.ErrTokenNotFound) {
+			a.logger.Infow("invalid mfa challenge", "op", op)
+
+			return "", fmt.Errorf("%s: %w", op, ErrInvalidToken)
+		}
+
+		a.logger.Errorw("failed to get mfa challenge", "op", op, "error", err)
+
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	user, err := a.usrProvider.UserByID(ctx, challenge.UserID)
+	if err != nil {
+		a.logger.Errorw("failed to get user", "op", op, "userID", challenge.UserID, "error", err)
+
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := a.checkLoginAttempts(ctx, user.Login, clientIP); err != nil {
+		a.recordLoginThrottled(ctx, user.ID, user.Login, clientIP, challenge.AppID, err)
+
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := a.verifySecondFactor(ctx, user.ID, code); err != nil {
+		if errors.Is(err, ErrInvalidMFACode) {
+			a.logger.Infow("invalid mfa code", "op", op, "userID", user.ID)
+			a.registerLoginFailure(ctx, user.Login, clientIP)
+			a.recordLoginFailed(ctx, user, clientIP, challenge.AppID, "invalid two-factor authentication code")
+		}
+
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	// consume the challenge only after the code is verified, so that a typo doesn't make the user log in again
+	if _, err := a.tokens.ConsumeToken(ctx, challenge.Hash, models.TokenMFAChallenge); err != nil {
+		if errors.Is(err, storagepkg.ErrTokenNotFound) {
+			return "", fmt.Errorf("%s: %w", op, ErrInvalidToken)
+		}
+
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	a.resetLoginAttempts(ctx, user.Login)
+
+	app, err := a.app(ctx, challenge.AppID)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	token, err := a.issueAuthToken(ctx, user, app, clientIP)
+	if err != nil {
+		a.logger.Warnw("failed to generate token", "op", op, "error", err)
+
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	a.logger.Infow("logged in with second factor", "op", op, "user", user.ID)
+
+	a.audit.Record(ctx, models.AuditEvent{
+		Type:     models.AuditLoginSucceeded,
+		UserID:   user.ID,
+		Login:    user.Login,
+		AppID:    app.ID,
+		ClientIP: clientIP,
+		Details:  map[string]string{"second_factor": "true"},
+	})
+
+	return token, nil
+}
+
+// EnrollTOTP generates a new TOTP secret for the user. Two-factor authentication is not enabled
+// until the user confirms the secret with ConfirmTOTP.
+//
+// Enrolling again before confirmation replaces the secret.
+func (a *Auth) EnrollTOTP(ctx context.Context, userID string) (string, string, error) {
+	const op = "auth.EnrollTOTP"
+
+	user, err := a.usrProvider.UserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storagepkg.ErrUserNotFound) {
+			return "", "", fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	mfa, err := a.mfa.MFA(ctx, userID)
+	if err != nil && !errors.Is(err, storagepkg.ErrMFANotFound) {
+		a.logger.Errorw("failed to get mfa", "op", op, "userID", userID, "error", err)
+
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+	if mfa.Enabled() {
+		return "", "", fmt.Errorf("%s: %w", op, ErrMFAAlreadyEnabled)
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := a.mfa.SaveMFASecret(ctx, userID, secret); err != nil {
+		a.logger.Errorw("failed to save totp secret", "op", op, "userID", userID, "error", err)
+
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	a.logger.Infow("totp enrolled", "op", op, "userID", userID)
+
+	return secret, totp.ProvisioningURI(a.account.MFA.Issuer, user.Login, secret), nil
+}
+
+// ConfirmTOTP enables two-factor authentication once the user proves their authenticator app
+// generates valid codes, and returns recovery codes. Recovery codes are stored hashed,
+// so they can't be shown again.
+func (a *Auth) ConfirmTOTP(ctx context.Context, userID string, code string) ([]string, error) {
+	const op = "auth.ConfirmTOTP"
+
+	mfa, err := a.mfa.MFA(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storagepkg.ErrMFANotFound) {
+			return nil, fmt.Errorf("%s: %w", op, ErrMFANotEnrolled)
+		}
+
+		a.logger.Errorw("failed to get mfa", "op", op, "userID", userID, "error", err)
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if mfa.Enabled() {
+		return nil, fmt.Errorf("%s: %w", op, ErrMFAAlreadyEnabled)
+	}
+
+	step, ok := totp.Validate(mfa.TOTPSecret, code, time.Now())
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidMFACode)
+	}
+
+	recoveryCodes := make([]string, a.account.MFA.RecoveryCodes)
+	hashes := make([]string, a.account.MFA.RecoveryCodes)
+	for i := range recoveryCodes {
+		recoveryCode, err := newRecoveryCode()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		recoveryCodes[i] = recoveryCode
+		hashes[i] = securetoken.Hash(recoveryCode)
+	}
+
+	if err := a.mfa.ConfirmMFA(ctx, userID, step, hashes); err != nil {
+		if errors.Is(err, storagepkg.ErrMFANotFound) {
+			return nil, fmt.Errorf("%s: %w", op, ErrMFANotEnrolled)
+		}
+
+		a.logger.Errorw("failed to confirm mfa", "op", op, "userID", userID, "error", err)
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	a.logger.Infow("two-factor authentication enabled", "op", op, "userID", userID)
+
+	a.audit.Record(ctx, models.AuditEvent{Type: models.AuditMFAEnabled, UserID: userID})
+
+	return recoveryCodes, nil
+}
+
+// DisableTOTP turns two-factor authentication off after checking a TOTP or recovery code.
+func (a *Auth) DisableTOTP(ctx context.Context, userID string, code string) error {
+	const op = "auth.DisableTOTP"
+
+	user, err := a.usrProvider.UserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storagepkg.ErrUserNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// guessing codes here is throttled along with login, so a stolen session can't be used to brute-force them
+	if err := a.checkLoginAttempts(ctx, user.Login, ""); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := a.verifySecondFactor(ctx, userID, code); err != nil {
+		if errors.Is(err, ErrInvalidMFACode) {
+			a.registerLoginFailure(ctx, user.Login, "")
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := a.mfa.DeleteMFA(ctx, userID); err != nil {
+		a.logger.Errorw("failed to delete mfa", "op", op, "userID", userID, "error", err)
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	a.logger.Infow("two-factor authentication disabled", "op", op, "userID", userID)
+
+	a.audit.Record(ctx, models.AuditEvent{Type: models.AuditMFADisabled, UserID: userID, Login: user.Login})
+
+	return nil
+}
+
+// mfaEnabled reports whether the user must pass the second factor on login.
+func (a *Auth) mfaEnabled(ctx context.Context, userID string) (bool, error) {
+	mfa, err := a.mfa.MFA(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storagepkg.ErrMFANotFound) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return mfa.Enabled(), nil
+}
+
+// verifySecondFactor checks the TOTP or recovery code of the user and uses it up.
+func (a *Auth) verifySecondFactor(ctx context.Context, userID string, code string) error {
+	mfa, err := a.mfa.MFA(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storagepkg.ErrMFANotFound) {
+			return ErrMFANotEnrolled
+		}
+
+		return err
+	}
+	if !mfa.Enabled() {
+		return ErrMFANotEnrolled
+	}
+
+	code = strings.ToLower(strings.TrimSpace(code))
+
+	if step, ok := totp.Validate(mfa.TOTPSecret, code, time.Now()); ok {
+		if err := a.mfa.UseTOTPStep(ctx, userID, step); err != nil {
+			if errors.Is(err, storagepkg.ErrMFACodeUsed) {
+				return ErrInvalidMFACode
+			}
+
+			return err
+		}
+
+		return nil
+	}
+
+	if err := a.mfa.UseRecoveryCode(ctx, userID, securetoken.Hash(code)); err != nil {
+		if errors.Is(err, storagepkg.ErrRecoveryCodeNotFound) {
+			return ErrInvalidMFACode
+		}
+
+		return err
+	}
+
+	a.logger.Infow("recovery code used", "userID", userID)
+
+	return nil
+}
+
+// issueMFAChallenge returns a short-lived token which proves the user has passed the first factor.
+func (a *Auth) issueMFAChallenge(ctx context.Context, userID string, appID int) (string, error) {
+	token, hash, err := securetoken.New()
+	if err != nil {
+		return "", err
+	}
+
+	err = a.tokens.SaveToken(ctx, models.Token{
+		Hash:      hash,
+		UserID:    userID,
+		Purpose:   models.TokenMFAChallenge,
+		ExpiresAt: time.Now().Add(a.account.MFA.ChallengeTTL),
+		AppID:     appID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// newRecoveryCode returns a random code formatted as "xxxxx-xxxxx".
+func newRecoveryCode() (string, error) {
+	var b strings.Builder
+
+	max := big.NewInt(int64(len(recoveryCodeAlphabet)))
+	for i := 0; i < recoveryCodeHalfLength*2; i++ {
+		if i == recoveryCodeHalfLength {
+			b.WriteByte('-')
+		}
+
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(recoveryCodeAlphabet[n.Int64()])
+	}
+
+	return b.String(), nil
+}
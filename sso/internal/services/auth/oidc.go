@@ -0,0 +1,339 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zoninnik89/messenger/sso/internal/domain/models"
+	"github.com/zoninnik89/messenger/sso/internal/lib/oidc"
+	"github.com/zoninnik89/messenger/sso/internal/lib/securetoken"
+	storagepkg "github.com/zoninnik89/messenger/sso/internal/storage"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// OIDCSettings configures login via external OpenID Connect providers.
+type OIDCSettings struct {
+	// StateTTL limits time the user has to log in at the provider
+	StateTTL  time.Duration
+	Providers map[string]*oidc.Provider
+}
+
+var (
+	ErrUnknownProvider          = errors.New("unknown identity provider")
+	ErrOIDCLoginFailed          = errors.New("identity provider login failed")
+	ErrIdentityAlreadyLinked    = errors.New("identity provider account is already linked")
+	ErrProviderEmailNotVerified = errors.New("email is not verified by identity provider")
+)
+
+// StartOIDCLogin returns URL of the provider the user is redirected to for login.
+//
+// If linkUserID is set, the provider account is linked to this user once the login completes,
+// the caller must make sure the user is authenticated.
+func (a *Auth) StartOIDCLogin(ctx context.Context, providerName string, appID int, linkUserID string) (string, error) {
+	const op = "auth.StartOIDCLogin"
+
+	provider, ok := a.account.OIDC.Providers[providerName]
+	if !ok {
+		return "", fmt.Errorf("%s: %w", op, ErrUnknownProvider)
+	}
+
+	if _, err := a.app(ctx, appID); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	if linkUserID != "" {
+		if _, err := a.usrProvider.UserByID(ctx, linkUserID); err != nil {
+			if errors.Is(err, storagepkg.ErrUserNotFound) {
+				return "", fmt.Errorf("%s: %w", op, ErrUserNotFound)
+			}
+
+			return "", fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	state, stateHash, err := securetoken.New()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	nonce, _, err := securetoken.New()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	verifier, challenge, err := oidc.NewCodeVerifier()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, challenge)
+	if err != nil {
+		a.logger.Errorw("failed to build authorization url", "op", op, "provider", providerName, "error", err)
+
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = a.identities.SaveOIDCState(ctx, models.OIDCState{
+		Hash:         stateHash,
+		Provider:     providerName,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		AppID:        appID,
+		LinkUserID:   linkUserID,
+		ExpiresAt:    time.Now().Add(a.account.OIDC.StateTTL),
+	})
+	if err != nil {
+		a.logger.Errorw("failed to save oidc state", "op", op, "error", err)
+
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	a.logger.Infow("oidc login started", "op", op, "provider", providerName)
+
+	return authURL, nil
+}
+
+// CompleteOIDCLogin exchanges the authorization code the provider redirected back with and logs the user in.
+//
+// The user is found by the linked provider account. Unknown provider accounts are linked to the user
+// with the same login, or a new user is registered, both only if the provider has verified the email.
+// If user has two-factor authentication enabled, MFA challenge token is returned instead of auth token,
+// unless the login links the provider account to an already authenticated user.
+func (a *Auth) CompleteOIDCLogin(
+	ctx context.Context,
+	providerName string,
+	state string,
+	code string,
+	clientIP string,
+) (models.OIDCLogin, error) {
+	const op = "auth.CompleteOIDCLogin"
+
+	provider, ok := a.account.OIDC.Providers[providerName]
+	if !ok {
+		return models.OIDCLogin{}, fmt.Errorf("%s: %w", op, ErrUnknownProvider)
+	}
+
+	st, err := a.identities.ConsumeOIDCState(ctx, securetoken.Hash(state))
+	if err != nil {
+		if errors.Is(err, storagepkg.ErrOIDCStateNotFound) {
+			a.logger.Infow("invalid oidc state", "op", op, "provider", providerName)
+
+			return models.OIDCLogin{}, fmt.Errorf("%s: %w", op, ErrInvalidToken)
+		}
+
+		a.logger.Errorw("failed to consume oidc state", "op", op, "error", err)
+
+		return models.OIDCLogin{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if st.Provider != providerName {
+		a.logger.Infow("oidc state issued for another provider", "op", op, "provider", providerName)
+
+		return models.OIDCLogin{}, fmt.Errorf("%s: %w", op, ErrInvalidToken)
+	}
+
+	rawIDToken, err := provider.Exchange(ctx, code, st.CodeVerifier)
+	if err != nil {
+		a.logger.Warnw("failed to exchange authorization code", "op", op, "provider", providerName, "error", err)
+
+		return models.OIDCLogin{}, fmt.Errorf("%s: %w: %w", op, ErrOIDCLoginFailed, err)
+	}
+
+	claims, err := provider.VerifyIDToken(ctx, rawIDToken, st.Nonce)
+	if err != nil {
+		a.logger.Warnw("failed to verify id token", "op", op, "provider", providerName, "error", err)
+
+		return models.OIDCLogin{}, fmt.Errorf("%s: %w: %w", op, ErrOIDCLoginFailed, err)
+	}
+
+	user, linked, err := a.identityUser(ctx, providerName, claims, st.LinkUserID)
+	if err != nil {
+		a.logger.Infow("failed to resolve provider account", "op", op, "provider", providerName, "error", err)
+		a.recordLoginFailed(ctx, user, clientIP, st.AppID, err.Error())
+
+		return models.OIDCLogin{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if linked {
+		a.logger.Infow("provider account linked", "op", op, "provider", providerName, "user", user.ID)
+
+		a.audit.Record(ctx, models.AuditEvent{
+			Type:     models.AuditIdentityLinked,
+			UserID:   user.ID,
+			Login:    user.Login,
+			AppID:    st.AppID,
+			ClientIP: clientIP,
+			Details:  map[string]string{"provider": providerName},
+		})
+	}
+
+	if a.account.RequireVerifiedEmail && !user.EmailVerified {
+		a.logger.Infow("email is not verified", "op", op, "user", user.ID)
+		a.recordLoginFailed(ctx, user, clientIP, st.AppID, "email is not verified")
+
+		return models.OIDCLogin{}, fmt.Errorf("%s: %w", op, ErrEmailNotVerified)
+	}
+
+	app, err := a.app(ctx, st.AppID)
+	if err != nil {
+		return models.OIDCLogin{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	result := models.OIDCLogin{UserID: user.ID, Linked: linked}
+
+	// linking is started by an already authenticated user, the second factor was passed on that login
+	if st.LinkUserID == "" {
+		mfaEnabled, err := a.mfaEnabled(ctx, user.ID)
+		if err != nil {
+			a.logger.Errorw("failed to get mfa", "op", op, "user", user.ID, "error", err)
+
+			return models.OIDCLogin{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if mfaEnabled {
+			result.MFAToken, err = a.issueMFAChallenge(ctx, user.ID, app.ID)
+			if err != nil {
+				a.logger.Errorw("failed to issue mfa challenge", "op", op, "user", user.ID, "error", err)
+
+				return models.OIDCLogin{}, fmt.Errorf("%s: %w", op, err)
+			}
+
+			a.logger.Infow("second factor required", "op", op, "user", user.ID)
+
+			return result, nil
+		}
+	}
+
+	result.Token, err = a.issueAuthToken(ctx, user, app, clientIP)
+	if err != nil {
+		a.logger.Warnw("failed to generate token", "op", op, "error", err)
+
+		return models.OIDCLogin{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	a.logger.Infow("logged in with identity provider", "op", op, "provider", providerName, "user", user.ID)
+
+	a.audit.Record(ctx, models.AuditEvent{
+		Type:     models.AuditLoginSucceeded,
+		UserID:   user.ID,
+		Login:    user.Login,
+		AppID:    app.ID,
+		ClientIP: clientIP,
+		Details:  map[string]string{"provider": providerName},
+	})
+
+	return result, nil
+}
+
+// identityUser returns the user the provider account belongs to, linking the account if it isn't linked yet.
+// The returned flag reports whether the account was linked.
+func (a *Auth) identityUser(
+	ctx context.Context,
+	providerName string,
+	claims oidc.Claims,
+	linkUserID string,
+) (models.User, bool, error) {
+	identity, err := a.identities.Identity(ctx, providerName, claims.Subject)
+	if err == nil {
+		if linkUserID != "" && linkUserID != identity.UserID {
+			return models.User{ID: linkUserID}, false, ErrIdentityAlreadyLinked
+		}
+
+		user, err := a.usrProvider.UserByID(ctx, identity.UserID)
+		if err != nil {
+			return models.User{}, false, err
+		}
+
+		return user, false, nil
+	}
+	if !errors.Is(err, storagepkg.ErrIdentityNotFound) {
+		return models.User{}, false, err
+	}
+
+	var user models.User
+	switch {
+	case linkUserID != "":
+		user, err = a.usrProvider.UserByID(ctx, linkUserID)
+		if err != nil {
+			if errors.Is(err, storagepkg.ErrUserNotFound) {
+				return models.User{}, false, ErrUserNotFound
+			}
+
+			return models.User{}, false, err
+		}
+	case claims.Email == "" || !claims.EmailVerified:
+		// the provider account could otherwise take over the user with the same login
+		return models.User{Login: claims.Email}, false, ErrProviderEmailNotVerified
+	default:
+		user, err = a.oidcUser(ctx, claims.Email)
+		if err != nil {
+			return models.User{}, false, err
+		}
+	}
+
+	err = a.identities.SaveIdentity(ctx, models.Identity{
+		Provider:  providerName,
+		Subject:   claims.Subject,
+		UserID:    user.ID,
+		Email:     claims.Email,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		if errors.Is(err, storagepkg.ErrIdentityExists) {
+			return user, false, ErrIdentityAlreadyLinked
+		}
+
+		return models.User{}, false, err
+	}
+
+	// the provider has proven the user owns the address
+	if claims.EmailVerified && claims.Email == user.Login && !user.EmailVerified {
+		if err := a.usrSaver.SetEmailVerified(ctx, user.ID); err != nil {
+			a.logger.Warnw("failed to mark email as verified", "userID", user.ID, "error", err)
+		} else {
+			user.EmailVerified = true
+		}
+	}
+
+	return user, true, nil
+}
+
+// oidcUser returns the user with the given login, registering one if it doesn't exist.
+// Registered users get a random password, they can set their own with password reset.
+func (a *Auth) oidcUser(ctx context.Context, login string) (models.User, error) {
+	user, err := a.usrProvider.GetUser(ctx, login)
+	if err == nil {
+		return user, nil
+	}
+	if !errors.Is(err, storagepkg.ErrUserNotFound) {
+		return models.User{}, err
+	}
+
+	password, _, err := securetoken.New()
+	if err != nil {
+		return models.User{}, err
+	}
+
+	passHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	id, err := a.usrSaver.SaveUser(ctx, login, passHash)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	a.logger.Infow("user registered with identity provider", "userID", id)
+
+	a.audit.Record(ctx, models.AuditEvent{
+		Type:    models.AuditUserRegistered,
+		UserID:  id,
+		Login:   login,
+		Details: map[string]string{"identity_provider": "true"},
+	})
+
+	return models.User{ID: id, Login: login, PassHash: passHash}, nil
+}
@@ -0,0 +1,138 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zoninnik89/messenger/common"
+	pb "github.com/zoninnik89/messenger/common/api"
+	"github.com/zoninnik89/messenger/sso/internal/domain/models"
+	"github.com/zoninnik89/messenger/sso/internal/jwt"
+	"github.com/zoninnik89/messenger/sso/internal/lib/useragent"
+	storagepkg "github.com/zoninnik89/messenger/sso/internal/storage"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+// ListSessions returns active sessions of the user, most recently used first.
+func (a *Auth) ListSessions(ctx context.Context, userID string) ([]models.Session, error) {
+	const op = "auth.ListSessions"
+
+	sessions, err := a.sessions.Sessions(ctx, userID)
+	if err != nil {
+		a.logger.Errorw("failed to list sessions", "op", op, "userID", userID, "error", err)
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession signs the user out of the session. Tokens issued for the session stop passing TouchSession,
+// services holding live connections of the session are notified by account event.
+//
+// If the user has no such active session, returns ErrSessionNotFound.
+func (a *Auth) RevokeSession(ctx context.Context, userID string, sessionID string, clientIP string) error {
+	const op = "auth.RevokeSession"
+
+	now := time.Now()
+
+	if err := a.sessions.RevokeSession(ctx, userID, sessionID, now); err != nil {
+		if errors.Is(err, storagepkg.ErrSessionNotFound) {
+			a.logger.Infow("session not found", "op", op, "userID", userID, "sessionID", sessionID)
+
+			return fmt.Errorf("%s: %w", op, ErrSessionNotFound)
+		}
+
+		a.logger.Errorw("failed to revoke session", "op", op, "userID", userID, "error", err)
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	a.logger.Infow("session revoked", "op", op, "userID", userID, "sessionID", sessionID)
+
+	a.audit.Record(ctx, models.AuditEvent{
+		Type:     models.AuditSessionRevoked,
+		UserID:   userID,
+		ClientIP: clientIP,
+		Details:  map[string]string{"session_id": sessionID},
+	})
+
+	event := &pb.AccountEvent{
+		UserId:     userID,
+		Type:       pb.AccountEventType_ACCOUNT_EVENT_TYPE_SESSION_REVOKED,
+		OccurredAt: now.Unix(),
+		SessionId:  sessionID,
+	}
+
+	if err := a.events.Publish(ctx, common.AccountEventsTopic, userID, event); err != nil {
+		// the session is revoked anyway, live connections are closed once they check the session again
+		a.logger.Errorw("failed to publish session revoked event", "op", op, "userID", userID, "error", err)
+	}
+
+	return nil
+}
+
+// TouchSession records use of the session and returns ID of the user it belongs to.
+//
+// If session doesn't exist, is revoked, expired or belongs to a disabled app, returns ErrSessionNotFound.
+func (a *Auth) TouchSession(ctx context.Context, sessionID string, clientIP string) (string, error) {
+	const op = "auth.TouchSession"
+
+	session, err := a.sessions.TouchSession(ctx, sessionID, clientIP, time.Now())
+	if err != nil {
+		if errors.Is(err, storagepkg.ErrSessionNotFound) {
+			return "", fmt.Errorf("%s: %w", op, ErrSessionNotFound)
+		}
+
+		a.logger.Errorw("failed to touch session", "op", op, "sessionID", sessionID, "error", err)
+
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err := a.app(ctx, session.AppID); err != nil {
+		if errors.Is(err, ErrInvalidAppID) {
+			return "", fmt.Errorf("%s: %w", op, ErrSessionNotFound)
+		}
+
+		a.logger.Errorw("failed to get app of session", "op", op, "sessionID", sessionID, "error", err)
+
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return session.UserID, nil
+}
+
+// issueAuthToken starts a session of the user on the client device and issues auth token for it.
+// Device of the client is taken from metadata of the gRPC request.
+func (a *Auth) issueAuthToken(ctx context.Context, user models.User, app models.App, clientIP string) (string, error) {
+	ttl := a.appTokenTTL(app)
+	now := time.Now()
+
+	userAgent, deviceName := common.ClientDevice(ctx)
+	if deviceName == "" {
+		deviceName = useragent.DeviceName(userAgent)
+	}
+
+	session := models.Session{
+		ID:         uuid.New().String(),
+		UserID:     user.ID,
+		AppID:      app.ID,
+		DeviceName: useragent.Truncate(deviceName),
+		UserAgent:  useragent.TruncateUserAgent(userAgent),
+		IP:         clientIP,
+		CreatedAt:  now,
+		LastUsedAt: now,
+		// the token issued for the session expires together with it
+		ExpiresAt: now.Add(ttl),
+	}
+
+	if err := a.sessions.SaveSession(ctx, session); err != nil {
+		return "", fmt.Errorf("failed to save session: %w", err)
+	}
+
+	return jwt.NewToken(user, app, session.ID, ttl)
+}
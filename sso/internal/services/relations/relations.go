@@ -0,0 +1,170 @@
+package relations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/zoninnik89/messenger/sso/internal/domain/models"
+	storagepkg "github.com/zoninnik89/messenger/sso/internal/storage"
+	"github.com/zoninnik89/messenger/sso/internal/types"
+	"go.uber.org/zap"
+	"time"
+)
+
+type Relations struct {
+	logger      *zap.SugaredLogger
+	relSaver    types.RelationSaver
+	relProvider types.RelationProvider
+}
+
+var (
+	ErrSelfRelation     = errors.New("user cannot block or mute themselves")
+	ErrInvalidKind      = errors.New("invalid relation kind")
+	ErrRelationNotFound = errors.New("relation not found")
+)
+
+// NewRelationsService returns a new instance of the Relations service
+func NewRelationsService(
+	logger *zap.SugaredLogger,
+	relationSaver types.RelationSaver,
+	relationProvider types.RelationProvider,
+) *Relations {
+
+	return &Relations{
+		logger:      logger,
+		relSaver:    relationSaver,
+		relProvider: relationProvider,
+	}
+}
+
+// AddRelation blocks or mutes target user on behalf of the given user.
+//
+// Adding a relation which already exists is a no-op.
+func (r *Relations) AddRelation(
+	ctx context.Context,
+	userID string,
+	targetID string,
+	kind models.RelationKind,
+) error {
+	const op = "relations.AddRelation"
+
+	if err := validateRelation(userID, targetID, kind); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err := r.relSaver.SaveRelation(ctx, models.Relation{
+		UserID:    userID,
+		TargetID:  targetID,
+		Kind:      kind,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		r.logger.Errorw("failed to save relation", "op", op, "error", err)
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	r.logger.Infow("relation added", "op", op, "userID", userID, "targetID", targetID, "kind", kind)
+
+	return nil
+}
+
+// RemoveRelation unblocks or unmutes target user on behalf of the given user.
+//
+// If relation doesn't exist, returns error.
+func (r *Relations) RemoveRelation(
+	ctx context.Context,
+	userID string,
+	targetID string,
+	kind models.RelationKind,
+) error {
+	const op = "relations.RemoveRelation"
+
+	if err := validateRelation(userID, targetID, kind); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := r.relSaver.DeleteRelation(ctx, userID, targetID, kind); err != nil {
+		if errors.Is(err, storagepkg.ErrRelationNotFound) {
+			r.logger.Warnw("relation not found", "op", op, "error", err)
+
+			return fmt.Errorf("%s: %w", op, ErrRelationNotFound)
+		}
+
+		r.logger.Errorw("failed to delete relation", "op", op, "error", err)
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	r.logger.Infow("relation removed", "op", op, "userID", userID, "targetID", targetID, "kind", kind)
+
+	return nil
+}
+
+// ListRelations returns all blocks and mutes owned by the given user.
+func (r *Relations) ListRelations(ctx context.Context, userID string) ([]models.Relation, error) {
+	const op = "relations.ListRelations"
+
+	relations, err := r.relProvider.Relations(ctx, userID)
+	if err != nil {
+		r.logger.Errorw("failed to get relations", "op", op, "error", err)
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return relations, nil
+}
+
+// IsBlocked reports whether user has blocked target user.
+func (r *Relations) IsBlocked(ctx context.Context, userID string, targetID string) (bool, error) {
+	const op = "relations.IsBlocked"
+
+	blocked, err := r.relProvider.RelationExists(ctx, userID, targetID, models.RelationBlock)
+	if err != nil {
+		r.logger.Errorw("failed to check relation", "op", op, "error", err)
+
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return blocked, nil
+}
+
+// ListBlockers returns IDs of all users who have blocked the given user.
+func (r *Relations) ListBlockers(ctx context.Context, userID string) ([]string, error) {
+	const op = "relations.ListBlockers"
+
+	blockers, err := r.relProvider.RelatedUsers(ctx, userID, models.RelationBlock)
+	if err != nil {
+		r.logger.Errorw("failed to get blockers", "op", op, "error", err)
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return blockers, nil
+}
+
+// ListMuters returns IDs of all users who have muted the given user.
+func (r *Relations) ListMuters(ctx context.Context, userID string) ([]string, error) {
+	const op = "relations.ListMuters"
+
+	muters, err := r.relProvider.RelatedUsers(ctx, userID, models.RelationMute)
+	if err != nil {
+		r.logger.Errorw("failed to get muters", "op", op, "error", err)
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return muters, nil
+}
+
+func validateRelation(userID string, targetID string, kind models.RelationKind) error {
+	if userID == targetID {
+		return ErrSelfRelation
+	}
+
+	if kind != models.RelationBlock && kind != models.RelationMute {
+		return ErrInvalidKind
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package sqlite
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/google/uuid"
@@ -10,6 +11,8 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/zoninnik89/messenger/sso/internal/domain/models"
 	"github.com/zoninnik89/messenger/sso/internal/storage"
+	"strings"
+	"time"
 )
 
 type Storage struct {
@@ -54,7 +57,7 @@ func (s *Storage) SaveUser(ctx context.Context, login string, passHash []byte) (
 func (s *Storage) GetUser(ctx context.Context, login string) (models.User, error) {
 	const op = "storage.sqlite.GetUser"
 
-	stmt, err := s.db.Prepare("SELECT user_id, login, pass_hash FROM users WHERE login = ?")
+	stmt, err := s.db.Prepare("SELECT user_id, login, pass_hash, email_verified FROM users WHERE login = ?")
 	if err != nil {
 		return models.User{}, fmt.Errorf("%s: %w", op, err)
 	}
@@ -62,7 +65,7 @@ func (s *Storage) GetUser(ctx context.Context, login string) (models.User, error
 	row := stmt.QueryRowContext(ctx, login)
 
 	var user models.User
-	err = row.Scan(&user.ID, &user.Login, &user.PassHash)
+	err = row.Scan(&user.ID, &user.Login, &user.PassHash, &user.EmailVerified)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return models.User{}, storage.ErrUserNotFound
@@ -74,18 +77,138 @@ func (s *Storage) GetUser(ctx context.Context, login string) (models.User, error
 	return user, nil
 }
 
+func (s *Storage) UserByID(ctx context.Context, userID string) (models.User, error) {
+	const op = "storage.sqlite.UserByID"
+
+	stmt, err := s.db.Prepare("SELECT user_id, login, pass_hash, email_verified FROM users WHERE user_id = ?")
+	if err != nil {
+		return models.User{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	row := stmt.QueryRowContext(ctx, userID)
+
+	var user models.User
+	err = row.Scan(&user.ID, &user.Login, &user.PassHash, &user.EmailVerified)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.User{}, storage.ErrUserNotFound
+		}
+
+		return models.User{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return user, nil
+}
+
+func (s *Storage) SetEmailVerified(ctx context.Context, userID string) error {
+	const op = "storage.sqlite.SetEmailVerified"
+
+	stmt, err := s.db.Prepare("UPDATE users SET email_verified = 1 WHERE user_id = ?")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	res, err := stmt.ExecContext(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return userAffected(op, res)
+}
+
+func (s *Storage) UpdatePassword(ctx context.Context, userID string, passHash []byte) error {
+	const op = "storage.sqlite.UpdatePassword"
+
+	stmt, err := s.db.Prepare("UPDATE users SET pass_hash = ? WHERE user_id = ?")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	res, err := stmt.ExecContext(ctx, passHash, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return userAffected(op, res)
+}
+
+// DeleteUser deletes the user with relations, tokens, two-factor authentication settings,
+// linked provider accounts, sessions and lockout records of the user's login.
+//
+// If user doesn't exist, returns storage.ErrUserNotFound.
+func (s *Storage) DeleteUser(ctx context.Context, userID string) error {
+	const op = "storage.sqlite.DeleteUser"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var login string
+	err = tx.QueryRowContext(ctx, "DELETE FROM users WHERE user_id = ? RETURNING login", userID).Scan(&login)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM user_relations WHERE user_id = ? OR target_id = ?", userID, userID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	for _, query := range []string{
+		"DELETE FROM user_tokens WHERE user_id = ?",
+		"DELETE FROM user_recovery_codes WHERE user_id = ?",
+		"DELETE FROM user_mfa WHERE user_id = ?",
+		"DELETE FROM user_identities WHERE user_id = ?",
+		"DELETE FROM oidc_states WHERE link_user_id = ?",
+		"DELETE FROM sessions WHERE user_id = ?",
+	} {
+		if _, err := tx.ExecContext(ctx, query, userID); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM login_lockouts WHERE login = ?", login); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func userAffected(op string, res sql.Result) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+	}
+
+	return nil
+}
+
 func (s *Storage) GetApp(ctx context.Context, id int) (models.App, error) {
 	const op = "storage.sqlite.GetApp"
 
-	stmt, err := s.db.Prepare("SELECT id, name, secret FROM apps WHERE id = ?")
+	stmt, err := s.db.Prepare(
+		"SELECT id, name, secret, token_ttl, scopes, disabled_at, created_at FROM apps WHERE id = ?",
+	)
 	if err != nil {
 		return models.App{}, fmt.Errorf("%s: %w", op, err)
 	}
 
 	row := stmt.QueryRowContext(ctx, id)
 
-	var app models.App
-	err = row.Scan(&app.ID, &app.Name, &app.Secret)
+	app, err := scanApp(row)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return models.App{}, storage.ErrAppNotFound
@@ -96,3 +219,998 @@ func (s *Storage) GetApp(ctx context.Context, id int) (models.App, error) {
 
 	return app, nil
 }
+
+// Apps returns all registered apps including disabled ones.
+func (s *Storage) Apps(ctx context.Context) ([]models.App, error) {
+	const op = "storage.sqlite.Apps"
+
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT id, name, secret, token_ttl, scopes, disabled_at, created_at FROM apps ORDER BY id",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var apps []models.App
+	for rows.Next() {
+		app, err := scanApp(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		apps = append(apps, app)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return apps, nil
+}
+
+// SaveApp registers a new app and returns its ID.
+//
+// If app with the same name exists, returns storage.ErrAppExists.
+func (s *Storage) SaveApp(ctx context.Context, app models.App) (int, error) {
+	const op = "storage.sqlite.SaveApp"
+
+	res, err := s.db.ExecContext(ctx,
+		"INSERT INTO apps (name, secret, token_ttl, scopes, created_at) VALUES (?, ?, ?, ?, ?)",
+		app.Name, app.Secret, int64(app.TokenTTL.Seconds()), strings.Join(app.Scopes, " "), app.CreatedAt.Unix(),
+	)
+	if err != nil {
+		var sqliteErr sqlite3.Error
+
+		if errors.As(err, &sqliteErr) && errors.Is(sqliteErr.ExtendedCode, sqlite3.ErrConstraintUnique) {
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrAppExists)
+		}
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return int(id), nil
+}
+
+// UpdateAppSecret replaces secret of the app.
+//
+// If app doesn't exist, returns storage.ErrAppNotFound.
+func (s *Storage) UpdateAppSecret(ctx context.Context, id int, secret string) error {
+	const op = "storage.sqlite.UpdateAppSecret"
+
+	res, err := s.db.ExecContext(ctx, "UPDATE apps SET secret = ? WHERE id = ?", secret, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return appAffected(op, res)
+}
+
+// DisableApp marks the app as disabled, disabling already disabled app is a no-op.
+//
+// If app doesn't exist, returns storage.ErrAppNotFound.
+func (s *Storage) DisableApp(ctx context.Context, id int) error {
+	const op = "storage.sqlite.DisableApp"
+
+	res, err := s.db.ExecContext(ctx,
+		"UPDATE apps SET disabled_at = COALESCE(disabled_at, ?) WHERE id = ?",
+		time.Now().Unix(), id,
+	)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return appAffected(op, res)
+}
+
+func appAffected(op string, res sql.Result) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrAppNotFound)
+	}
+
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanApp(row scanner) (models.App, error) {
+	var app models.App
+	var tokenTTL, createdAt int64
+	var scopes string
+	var disabledAt sql.NullInt64
+
+	err := row.Scan(&app.ID, &app.Name, &app.Secret, &tokenTTL, &scopes, &disabledAt, &createdAt)
+	if err != nil {
+		return models.App{}, err
+	}
+
+	app.TokenTTL = time.Duration(tokenTTL) * time.Second
+	app.Scopes = strings.Fields(scopes)
+	if disabledAt.Valid {
+		app.DisabledAt = time.Unix(disabledAt.Int64, 0)
+	}
+	if createdAt != 0 {
+		app.CreatedAt = time.Unix(createdAt, 0)
+	}
+
+	return app, nil
+}
+
+func (s *Storage) SaveRelation(ctx context.Context, relation models.Relation) error {
+	const op = "storage.sqlite.SaveRelation"
+
+	stmt, err := s.db.Prepare(
+		"INSERT INTO user_relations (user_id, target_id, kind, created_at) values (?, ?, ?, ?) ON CONFLICT DO NOTHING",
+	)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = stmt.ExecContext(ctx, relation.UserID, relation.TargetID, string(relation.Kind), relation.CreatedAt.Unix())
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) DeleteRelation(ctx context.Context, userID string, targetID string, kind models.RelationKind) error {
+	const op = "storage.sqlite.DeleteRelation"
+
+	stmt, err := s.db.Prepare("DELETE FROM user_relations WHERE user_id = ? AND target_id = ? AND kind = ?")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	res, err := stmt.ExecContext(ctx, userID, targetID, string(kind))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrRelationNotFound)
+	}
+
+	return nil
+}
+
+func (s *Storage) Relations(ctx context.Context, userID string) ([]models.Relation, error) {
+	const op = "storage.sqlite.Relations"
+
+	stmt, err := s.db.Prepare("SELECT user_id, target_id, kind, created_at FROM user_relations WHERE user_id = ?")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := stmt.QueryContext(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var relations []models.Relation
+	for rows.Next() {
+		var relation models.Relation
+		var createdAt int64
+
+		if err := rows.Scan(&relation.UserID, &relation.TargetID, &relation.Kind, &createdAt); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		relation.CreatedAt = time.Unix(createdAt, 0)
+		relations = append(relations, relation)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return relations, nil
+}
+
+func (s *Storage) RelationExists(ctx context.Context, userID string, targetID string, kind models.RelationKind) (bool, error) {
+	const op = "storage.sqlite.RelationExists"
+
+	stmt, err := s.db.Prepare("SELECT 1 FROM user_relations WHERE user_id = ? AND target_id = ? AND kind = ?")
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var exists int
+	err = stmt.QueryRowContext(ctx, userID, targetID, string(kind)).Scan(&exists)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return true, nil
+}
+
+func (s *Storage) RelatedUsers(ctx context.Context, targetID string, kind models.RelationKind) ([]string, error) {
+	const op = "storage.sqlite.RelatedUsers"
+
+	stmt, err := s.db.Prepare("SELECT user_id FROM user_relations WHERE target_id = ? AND kind = ?")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := stmt.QueryContext(ctx, targetID, string(kind))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var userIDs []string
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		userIDs = append(userIDs, userID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return userIDs, nil
+}
+
+// SaveToken stores the token and invalidates all previously issued unused tokens
+// of the same user with the same purpose.
+func (s *Storage) SaveToken(ctx context.Context, token models.Token) error {
+	const op = "storage.sqlite.SaveToken"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE user_tokens SET used_at = ? WHERE user_id = ? AND purpose = ? AND used_at IS NULL",
+		time.Now().Unix(), token.UserID, string(token.Purpose),
+	)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO user_tokens (token_hash, user_id, purpose, expires_at, app_id) values (?, ?, ?, ?, ?)",
+		token.Hash, token.UserID, string(token.Purpose), token.ExpiresAt.Unix(), token.AppID,
+	)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ConsumeToken marks the token as used and returns it.
+//
+// If token doesn't exist, was already used or is expired, returns storage.ErrTokenNotFound.
+func (s *Storage) ConsumeToken(ctx context.Context, hash string, purpose models.TokenPurpose) (models.Token, error) {
+	const op = "storage.sqlite.ConsumeToken"
+
+	stmt, err := s.db.Prepare(
+		`UPDATE user_tokens SET used_at = ?
+		WHERE token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?
+		RETURNING token_hash, user_id, purpose, expires_at, app_id`,
+	)
+	if err != nil {
+		return models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	now := time.Now().Unix()
+	row := stmt.QueryRowContext(ctx, now, hash, string(purpose), now)
+
+	var token models.Token
+	var expiresAt int64
+	err = row.Scan(&token.Hash, &token.UserID, &token.Purpose, &expiresAt, &token.AppID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Token{}, fmt.Errorf("%s: %w", op, storage.ErrTokenNotFound)
+		}
+
+		return models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	token.ExpiresAt = time.Unix(expiresAt, 0)
+
+	return token, nil
+}
+
+// Token returns the token without using it up.
+//
+// If token doesn't exist, was already used or is expired, returns storage.ErrTokenNotFound.
+func (s *Storage) Token(ctx context.Context, hash string, purpose models.TokenPurpose) (models.Token, error) {
+	const op = "storage.sqlite.Token"
+
+	row := s.db.QueryRowContext(ctx,
+		`SELECT token_hash, user_id, purpose, expires_at, app_id FROM user_tokens
+		WHERE token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?`,
+		hash, string(purpose), time.Now().Unix(),
+	)
+
+	var token models.Token
+	var expiresAt int64
+	err := row.Scan(&token.Hash, &token.UserID, &token.Purpose, &expiresAt, &token.AppID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Token{}, fmt.Errorf("%s: %w", op, storage.ErrTokenNotFound)
+		}
+
+		return models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	token.ExpiresAt = time.Unix(expiresAt, 0)
+
+	return token, nil
+}
+
+// LoginAttempts returns failed login attempts counted for the given key.
+//
+// If there were no failed attempts, returns zero value with the Key set.
+func (s *Storage) LoginAttempts(ctx context.Context, key string) (models.LoginAttempts, error) {
+	const op = "storage.sqlite.LoginAttempts"
+
+	row := s.db.QueryRowContext(ctx,
+		"SELECT failures, last_failure_at, locked_until FROM login_attempts WHERE attempt_key = ?",
+		key,
+	)
+
+	attempts := models.LoginAttempts{Key: key}
+	var lastFailureAt, lockedUntil int64
+	err := row.Scan(&attempts.Failures, &lastFailureAt, &lockedUntil)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return attempts, nil
+		}
+
+		return models.LoginAttempts{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	attempts.LastFailureAt = unixMilliTime(lastFailureAt)
+	attempts.LockedUntil = unixMilliTime(lockedUntil)
+
+	return attempts, nil
+}
+
+// AddLoginFailure increments failed attempts counter of the given key and returns the new value.
+//
+// Failures which happened before windowStart are forgotten, so the counter starts over.
+func (s *Storage) AddLoginFailure(ctx context.Context, key string, at time.Time, windowStart time.Time) (int, error) {
+	const op = "storage.sqlite.AddLoginFailure"
+
+	row := s.db.QueryRowContext(ctx,
+		`INSERT INTO login_attempts (attempt_key, failures, last_failure_at) VALUES (?, 1, ?)
+		ON CONFLICT (attempt_key) DO UPDATE SET
+			failures = CASE WHEN last_failure_at < ? THEN 1 ELSE failures + 1 END,
+			last_failure_at = excluded.last_failure_at
+		RETURNING failures`,
+		key, at.UnixMilli(), windowStart.UnixMilli(),
+	)
+
+	var failures int
+	if err := row.Scan(&failures); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return failures, nil
+}
+
+// LockLogin locks the given key until the given time, resets its failures counter
+// and saves the lockout audit record.
+func (s *Storage) LockLogin(ctx context.Context, lockout models.Lockout) error {
+	const op = "storage.sqlite.LockLogin"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE login_attempts SET failures = 0, locked_until = ? WHERE attempt_key = ?",
+		lockout.LockedUntil.UnixMilli(), lockout.Key,
+	)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO login_lockouts (attempt_key, login, ip, failures, locked_until, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		lockout.Key, lockout.Login, lockout.IP, lockout.Failures, lockout.LockedUntil.Unix(), lockout.CreatedAt.Unix(),
+	)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ResetLoginAttempts forgets failed login attempts of the given key.
+func (s *Storage) ResetLoginAttempts(ctx context.Context, key string) error {
+	const op = "storage.sqlite.ResetLoginAttempts"
+
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM login_attempts WHERE attempt_key = ?", key); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func unixMilliTime(msec int64) time.Time {
+	if msec == 0 {
+		return time.Time{}
+	}
+
+	return time.UnixMilli(msec)
+}
+
+// MFA returns two-factor authentication settings of the user.
+//
+// If user hasn't enrolled, returns storage.ErrMFANotFound.
+func (s *Storage) MFA(ctx context.Context, userID string) (models.MFA, error) {
+	const op = "storage.sqlite.MFA"
+
+	row := s.db.QueryRowContext(ctx,
+		"SELECT user_id, totp_secret, last_used_step, confirmed_at, created_at FROM user_mfa WHERE user_id = ?",
+		userID,
+	)
+
+	var mfa models.MFA
+	var confirmedAt sql.NullInt64
+	var createdAt int64
+	err := row.Scan(&mfa.UserID, &mfa.TOTPSecret, &mfa.LastUsedStep, &confirmedAt, &createdAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.MFA{}, fmt.Errorf("%s: %w", op, storage.ErrMFANotFound)
+		}
+
+		return models.MFA{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if confirmedAt.Valid {
+		mfa.ConfirmedAt = time.Unix(confirmedAt.Int64, 0)
+	}
+	mfa.CreatedAt = time.Unix(createdAt, 0)
+
+	return mfa, nil
+}
+
+// SaveMFASecret saves a new unconfirmed TOTP secret of the user, replacing previous unconfirmed one.
+//
+// Secret of a confirmed enrollment is kept untouched.
+func (s *Storage) SaveMFASecret(ctx context.Context, userID string, secret string) error {
+	const op = "storage.sqlite.SaveMFASecret"
+
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO user_mfa (user_id, totp_secret, created_at) VALUES (?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET
+			totp_secret = excluded.totp_secret,
+			last_used_step = 0,
+			created_at = excluded.created_at
+		WHERE confirmed_at IS NULL`,
+		userID, secret, time.Now().Unix(),
+	)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ConfirmMFA enables two-factor authentication of the user, marks the TOTP step used for confirmation
+// and replaces recovery codes with the given ones.
+//
+// If there is no unconfirmed enrollment, returns storage.ErrMFANotFound.
+func (s *Storage) ConfirmMFA(ctx context.Context, userID string, step int64, recoveryCodeHashes []string) error {
+	const op = "storage.sqlite.ConfirmMFA"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		"UPDATE user_mfa SET confirmed_at = ?, last_used_step = ? WHERE user_id = ? AND confirmed_at IS NULL",
+		time.Now().Unix(), step, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrMFANotFound)
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM user_recovery_codes WHERE user_id = ?", userID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	for _, hash := range recoveryCodeHashes {
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO user_recovery_codes (user_id, code_hash) VALUES (?, ?)",
+			userID, hash,
+		)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// UseTOTPStep marks the TOTP time step as used by the user.
+//
+// If the step or a later one was already used, returns storage.ErrMFACodeUsed.
+func (s *Storage) UseTOTPStep(ctx context.Context, userID string, step int64) error {
+	const op = "storage.sqlite.UseTOTPStep"
+
+	res, err := s.db.ExecContext(ctx,
+		"UPDATE user_mfa SET last_used_step = ? WHERE user_id = ? AND last_used_step < ?",
+		step, userID, step,
+	)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrMFACodeUsed)
+	}
+
+	return nil
+}
+
+// UseRecoveryCode marks the recovery code of the user as used.
+//
+// If the code doesn't exist or was already used, returns storage.ErrRecoveryCodeNotFound.
+func (s *Storage) UseRecoveryCode(ctx context.Context, userID string, hash string) error {
+	const op = "storage.sqlite.UseRecoveryCode"
+
+	res, err := s.db.ExecContext(ctx,
+		"UPDATE user_recovery_codes SET used_at = ? WHERE user_id = ? AND code_hash = ? AND used_at IS NULL",
+		time.Now().Unix(), userID, hash,
+	)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrRecoveryCodeNotFound)
+	}
+
+	return nil
+}
+
+// DeleteMFA disables two-factor authentication of the user and deletes recovery codes.
+func (s *Storage) DeleteMFA(ctx context.Context, userID string) error {
+	const op = "storage.sqlite.DeleteMFA"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM user_recovery_codes WHERE user_id = ?", userID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM user_mfa WHERE user_id = ?", userID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// SaveAuditEvent appends the event to the audit log. Events with an already stored ID are ignored,
+// so the same event can be saved more than once.
+func (s *Storage) SaveAuditEvent(ctx context.Context, event models.AuditEvent) error {
+	const op = "storage.sqlite.SaveAuditEvent"
+
+	details, err := json.Marshal(event.Details)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = s.db.ExecContext(ctx,
+		`INSERT INTO audit_events (id, type, user_id, login, app_id, client_ip, source, occurred_at, details)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO NOTHING`,
+		event.ID,
+		string(event.Type),
+		event.UserID,
+		event.Login,
+		event.AppID,
+		event.ClientIP,
+		event.Source,
+		event.OccurredAt.UnixMilli(),
+		string(details),
+	)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// AuditEvents returns audit events selected by the filter, newest first.
+func (s *Storage) AuditEvents(ctx context.Context, filter models.AuditFilter) ([]models.AuditEvent, error) {
+	const op = "storage.sqlite.AuditEvents"
+
+	var conditions []string
+	var args []any
+
+	if filter.UserID != "" {
+		args = append(args, filter.UserID)
+		conditions = append(conditions, "user_id = ?")
+	}
+	if !filter.From.IsZero() {
+		args = append(args, filter.From.UnixMilli())
+		conditions = append(conditions, "occurred_at >= ?")
+	}
+	if !filter.To.IsZero() {
+		args = append(args, filter.To.UnixMilli())
+		conditions = append(conditions, "occurred_at < ?")
+	}
+
+	query := "SELECT id, type, user_id, login, app_id, client_ip, source, occurred_at, details FROM audit_events"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY occurred_at DESC, id"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += " LIMIT ?"
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var events []models.AuditEvent
+	for rows.Next() {
+		var event models.AuditEvent
+		var occurredAt int64
+		var details []byte
+
+		err := rows.Scan(
+			&event.ID,
+			&event.Type,
+			&event.UserID,
+			&event.Login,
+			&event.AppID,
+			&event.ClientIP,
+			&event.Source,
+			&occurredAt,
+			&details,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if err := json.Unmarshal(details, &event.Details); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		event.OccurredAt = time.UnixMilli(occurredAt)
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return events, nil
+}
+
+// SaveIdentity links the provider account to the user.
+//
+// If the provider account or another account of the same provider is already linked, returns storage.ErrIdentityExists.
+func (s *Storage) SaveIdentity(ctx context.Context, identity models.Identity) error {
+	const op = "storage.sqlite.SaveIdentity"
+
+	_, err := s.db.ExecContext(ctx,
+		"INSERT INTO user_identities (provider, subject, user_id, email, created_at) VALUES (?, ?, ?, ?, ?)",
+		identity.Provider, identity.Subject, identity.UserID, identity.Email, identity.CreatedAt.Unix(),
+	)
+	if err != nil {
+		var sqliteErr sqlite3.Error
+
+		if errors.As(err, &sqliteErr) && (errors.Is(sqliteErr.ExtendedCode, sqlite3.ErrConstraintPrimaryKey) ||
+			errors.Is(sqliteErr.ExtendedCode, sqlite3.ErrConstraintUnique)) {
+			return fmt.Errorf("%s: %w", op, storage.ErrIdentityExists)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Identity returns the link of the provider account with the given subject.
+//
+// If the provider account isn't linked to any user, returns storage.ErrIdentityNotFound.
+func (s *Storage) Identity(ctx context.Context, provider string, subject string) (models.Identity, error) {
+	const op = "storage.sqlite.Identity"
+
+	row := s.db.QueryRowContext(ctx,
+		"SELECT provider, subject, user_id, email, created_at FROM user_identities WHERE provider = ? AND subject = ?",
+		provider, subject,
+	)
+
+	var identity models.Identity
+	var createdAt int64
+	err := row.Scan(&identity.Provider, &identity.Subject, &identity.UserID, &identity.Email, &createdAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Identity{}, fmt.Errorf("%s: %w", op, storage.ErrIdentityNotFound)
+		}
+
+		return models.Identity{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	identity.CreatedAt = time.Unix(createdAt, 0)
+
+	return identity, nil
+}
+
+// SaveOIDCState stores the state of a started login and removes expired states of abandoned logins.
+func (s *Storage) SaveOIDCState(ctx context.Context, state models.OIDCState) error {
+	const op = "storage.sqlite.SaveOIDCState"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM oidc_states WHERE expires_at <= ?", time.Now().Unix()); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO oidc_states (state_hash, provider, code_verifier, nonce, app_id, link_user_id, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		state.Hash, state.Provider, state.CodeVerifier, state.Nonce, state.AppID, state.LinkUserID, state.ExpiresAt.Unix(),
+	)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ConsumeOIDCState deletes the state and returns it, so that the provider response can't be replayed.
+//
+// If state doesn't exist, was already used or is expired, returns storage.ErrOIDCStateNotFound.
+func (s *Storage) ConsumeOIDCState(ctx context.Context, hash string) (models.OIDCState, error) {
+	const op = "storage.sqlite.ConsumeOIDCState"
+
+	row := s.db.QueryRowContext(ctx,
+		`DELETE FROM oidc_states WHERE state_hash = ? AND expires_at > ?
+		RETURNING state_hash, provider, code_verifier, nonce, app_id, link_user_id, expires_at`,
+		hash, time.Now().Unix(),
+	)
+
+	var state models.OIDCState
+	var expiresAt int64
+	err := row.Scan(
+		&state.Hash,
+		&state.Provider,
+		&state.CodeVerifier,
+		&state.Nonce,
+		&state.AppID,
+		&state.LinkUserID,
+		&expiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.OIDCState{}, fmt.Errorf("%s: %w", op, storage.ErrOIDCStateNotFound)
+		}
+
+		return models.OIDCState{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	state.ExpiresAt = time.Unix(expiresAt, 0)
+
+	return state, nil
+}
+
+func (s *Storage) SaveSession(ctx context.Context, session models.Session) error {
+	const op = "storage.sqlite.SaveSession"
+
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO sessions (session_id, user_id, app_id, device_name, user_agent, ip, created_at, last_used_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		session.ID,
+		session.UserID,
+		session.AppID,
+		session.DeviceName,
+		session.UserAgent,
+		session.IP,
+		session.CreatedAt.Unix(),
+		session.LastUsedAt.Unix(),
+		session.ExpiresAt.Unix(),
+	)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Sessions returns sessions of the user which are neither revoked nor expired, most recently used first.
+func (s *Storage) Sessions(ctx context.Context, userID string) ([]models.Session, error) {
+	const op = "storage.sqlite.Sessions"
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT session_id, user_id, app_id, device_name, user_agent, ip, created_at, last_used_at, expires_at, revoked_at
+		FROM sessions
+		WHERE user_id = ? AND revoked_at IS NULL AND expires_at > ?
+		ORDER BY last_used_at DESC, created_at DESC`,
+		userID, time.Now().Unix(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var sessions []models.Session
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return sessions, nil
+}
+
+// TouchSession records use of the session from the given IP and returns the session.
+//
+// If session doesn't exist, is revoked or expired, returns storage.ErrSessionNotFound.
+func (s *Storage) TouchSession(ctx context.Context, sessionID string, ip string, at time.Time) (models.Session, error) {
+	const op = "storage.sqlite.TouchSession"
+
+	row := s.db.QueryRowContext(ctx,
+		`UPDATE sessions SET last_used_at = ?, ip = COALESCE(NULLIF(?, ''), ip)
+		WHERE session_id = ? AND revoked_at IS NULL AND expires_at > ?
+		RETURNING session_id, user_id, app_id, device_name, user_agent, ip, created_at, last_used_at, expires_at, revoked_at`,
+		at.Unix(), ip, sessionID, at.Unix(),
+	)
+
+	session, err := scanSession(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Session{}, fmt.Errorf("%s: %w", op, storage.ErrSessionNotFound)
+		}
+
+		return models.Session{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return session, nil
+}
+
+// RevokeSession revokes active session of the user.
+//
+// If the user has no such active session, returns storage.ErrSessionNotFound.
+func (s *Storage) RevokeSession(ctx context.Context, userID string, sessionID string, at time.Time) error {
+	const op = "storage.sqlite.RevokeSession"
+
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE sessions SET revoked_at = ?
+		WHERE session_id = ? AND user_id = ? AND revoked_at IS NULL AND expires_at > ?`,
+		at.Unix(), sessionID, userID, at.Unix(),
+	)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrSessionNotFound)
+	}
+
+	return nil
+}
+
+func scanSession(row scanner) (models.Session, error) {
+	var session models.Session
+	var createdAt, lastUsedAt, expiresAt int64
+	var revokedAt sql.NullInt64
+
+	err := row.Scan(
+		&session.ID,
+		&session.UserID,
+		&session.AppID,
+		&session.DeviceName,
+		&session.UserAgent,
+		&session.IP,
+		&createdAt,
+		&lastUsedAt,
+		&expiresAt,
+		&revokedAt,
+	)
+	if err != nil {
+		return models.Session{}, err
+	}
+
+	session.CreatedAt = time.Unix(createdAt, 0)
+	session.LastUsedAt = time.Unix(lastUsedAt, 0)
+	session.ExpiresAt = time.Unix(expiresAt, 0)
+	if revokedAt.Valid {
+		session.RevokedAt = time.Unix(revokedAt.Int64, 0)
+	}
+
+	return session, nil
+}
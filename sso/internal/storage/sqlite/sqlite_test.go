@@ -0,0 +1,23 @@
+package sqlite_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	_ "github.com/golang-migrate/migrate/v4/database/sqlite3"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/stretchr/testify/require"
+	"github.com/zoninnik89/messenger/sso/internal/storage/sqlite"
+	"github.com/zoninnik89/messenger/sso/internal/storage/storagetest"
+)
+
+func TestStorage(t *testing.T) {
+	storagePath := filepath.Join(t.TempDir(), "sso.db")
+
+	storagetest.Migrate(t, "../../../migrations/sqlite", "sqlite3://"+storagePath)
+
+	s, err := sqlite.NewStorage(storagePath)
+	require.NoError(t, err)
+
+	storagetest.Run(t, s)
+}
@@ -8,4 +8,18 @@ var (
 	ErrUserExists   = errors.New("user already exists")
 	ErrUserNotFound = errors.New("user not found")
 	ErrAppNotFound  = errors.New("app not found")
+	ErrAppExists    = errors.New("app already exists")
+
+	ErrRelationNotFound = errors.New("relation not found")
+	ErrTokenNotFound    = errors.New("token not found")
+
+	ErrMFANotFound          = errors.New("mfa not found")
+	ErrMFACodeUsed          = errors.New("mfa code already used")
+	ErrRecoveryCodeNotFound = errors.New("recovery code not found")
+
+	ErrIdentityNotFound  = errors.New("identity not found")
+	ErrIdentityExists    = errors.New("identity already exists")
+	ErrOIDCStateNotFound = errors.New("oidc state not found")
+
+	ErrSessionNotFound = errors.New("session not found")
 )
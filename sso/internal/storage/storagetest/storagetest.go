@@ -0,0 +1,668 @@
+// Package storagetest contains conformance tests which every storage backend of sso must pass.
+//
+// Tests don't expect an empty database: every test creates users, apps and keys with random names,
+// so the suite can be run repeatedly against the same database.
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zoninnik89/messenger/sso/internal/domain/models"
+	"github.com/zoninnik89/messenger/sso/internal/storage"
+	"github.com/zoninnik89/messenger/sso/internal/types"
+)
+
+// seededAppID is the ID of the app inserted by the first migration.
+const seededAppID = 1
+
+// Migrate applies migrations from migrationsPath to the database, drivers of the database
+// and the file source must be imported by the caller.
+func Migrate(t *testing.T, migrationsPath string, databaseURL string) {
+	t.Helper()
+
+	m, err := migrate.New("file://"+migrationsPath, databaseURL)
+	require.NoError(t, err)
+	defer m.Close()
+
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		require.NoError(t, err)
+	}
+}
+
+// Run runs the conformance tests against the storage with applied migrations.
+func Run(t *testing.T, s types.Storage) {
+	t.Run("Users", func(t *testing.T) { testUsers(t, s) })
+	t.Run("Apps", func(t *testing.T) { testApps(t, s) })
+	t.Run("Relations", func(t *testing.T) { testRelations(t, s) })
+	t.Run("Tokens", func(t *testing.T) { testTokens(t, s) })
+	t.Run("LoginAttempts", func(t *testing.T) { testLoginAttempts(t, s) })
+	t.Run("MFA", func(t *testing.T) { testMFA(t, s) })
+	t.Run("DeleteUser", func(t *testing.T) { testDeleteUser(t, s) })
+	t.Run("Audit", func(t *testing.T) { testAudit(t, s) })
+	t.Run("Identities", func(t *testing.T) { testIdentities(t, s) })
+	t.Run("OIDCStates", func(t *testing.T) { testOIDCStates(t, s) })
+	t.Run("Sessions", func(t *testing.T) { testSessions(t, s) })
+}
+
+func testUsers(t *testing.T, s types.Storage) {
+	ctx := context.Background()
+
+	login := gofakeit.Email()
+	passHash := []byte("pass-hash")
+
+	userID, err := s.SaveUser(ctx, login, passHash)
+	require.NoError(t, err)
+	assert.NotEmpty(t, userID)
+
+	_, err = s.SaveUser(ctx, login, passHash)
+	assert.ErrorIs(t, err, storage.ErrUserExists)
+
+	user, err := s.GetUser(ctx, login)
+	require.NoError(t, err)
+	assert.Equal(t, userID, user.ID)
+	assert.Equal(t, login, user.Login)
+	assert.Equal(t, passHash, user.PassHash)
+	assert.False(t, user.EmailVerified)
+
+	user, err = s.UserByID(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, login, user.Login)
+
+	_, err = s.GetUser(ctx, gofakeit.Email())
+	assert.ErrorIs(t, err, storage.ErrUserNotFound)
+
+	_, err = s.UserByID(ctx, uuid.New().String())
+	assert.ErrorIs(t, err, storage.ErrUserNotFound)
+
+	require.NoError(t, s.SetEmailVerified(ctx, userID))
+	require.NoError(t, s.UpdatePassword(ctx, userID, []byte("new-pass-hash")))
+
+	user, err = s.UserByID(ctx, userID)
+	require.NoError(t, err)
+	assert.True(t, user.EmailVerified)
+	assert.Equal(t, []byte("new-pass-hash"), user.PassHash)
+
+	assert.ErrorIs(t, s.SetEmailVerified(ctx, uuid.New().String()), storage.ErrUserNotFound)
+	assert.ErrorIs(t, s.UpdatePassword(ctx, uuid.New().String(), passHash), storage.ErrUserNotFound)
+}
+
+func testApps(t *testing.T, s types.Storage) {
+	ctx := context.Background()
+
+	_, err := s.GetApp(ctx, seededAppID)
+	require.NoError(t, err)
+
+	createdAt := time.Now()
+	app := models.App{
+		Name:      "app-" + uuid.New().String(),
+		Secret:    uuid.New().String(),
+		TokenTTL:  15 * time.Minute,
+		Scopes:    []string{"chat:read", "chat:write"},
+		CreatedAt: createdAt,
+	}
+
+	id, err := s.SaveApp(ctx, app)
+	require.NoError(t, err)
+	assert.NotZero(t, id)
+	assert.NotEqual(t, seededAppID, id)
+
+	_, err = s.SaveApp(ctx, models.App{Name: app.Name, Secret: uuid.New().String(), CreatedAt: createdAt})
+	assert.ErrorIs(t, err, storage.ErrAppExists)
+
+	saved, err := s.GetApp(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, app.Name, saved.Name)
+	assert.Equal(t, app.Secret, saved.Secret)
+	assert.Equal(t, app.TokenTTL, saved.TokenTTL)
+	assert.Equal(t, app.Scopes, saved.Scopes)
+	assert.Equal(t, createdAt.Unix(), saved.CreatedAt.Unix())
+	assert.False(t, saved.Disabled())
+
+	apps, err := s.Apps(ctx)
+	require.NoError(t, err)
+	assert.Contains(t, appIDs(apps), id)
+
+	require.NoError(t, s.UpdateAppSecret(ctx, id, "rotated-"+app.Secret))
+	require.NoError(t, s.DisableApp(ctx, id))
+
+	saved, err = s.GetApp(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "rotated-"+app.Secret, saved.Secret)
+	assert.True(t, saved.Disabled())
+
+	// disabling again keeps the original time
+	require.NoError(t, s.DisableApp(ctx, id))
+	disabled, err := s.GetApp(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, saved.DisabledAt, disabled.DisabledAt)
+
+	const missingID = 1 << 30
+
+	_, err = s.GetApp(ctx, missingID)
+	assert.ErrorIs(t, err, storage.ErrAppNotFound)
+	assert.ErrorIs(t, s.UpdateAppSecret(ctx, missingID, uuid.New().String()), storage.ErrAppNotFound)
+	assert.ErrorIs(t, s.DisableApp(ctx, missingID), storage.ErrAppNotFound)
+}
+
+func testRelations(t *testing.T, s types.Storage) {
+	ctx := context.Background()
+
+	userID := uuid.New().String()
+	targetID := uuid.New().String()
+
+	block := models.Relation{UserID: userID, TargetID: targetID, Kind: models.RelationBlock, CreatedAt: time.Now()}
+	require.NoError(t, s.SaveRelation(ctx, block))
+	// saving the same relation again is a no-op
+	require.NoError(t, s.SaveRelation(ctx, block))
+	require.NoError(t, s.SaveRelation(ctx, models.Relation{
+		UserID:    userID,
+		TargetID:  targetID,
+		Kind:      models.RelationMute,
+		CreatedAt: time.Now(),
+	}))
+
+	relations, err := s.Relations(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, relations, 2)
+	for _, relation := range relations {
+		assert.Equal(t, targetID, relation.TargetID)
+		assert.Equal(t, block.CreatedAt.Unix(), relation.CreatedAt.Unix())
+	}
+
+	exists, err := s.RelationExists(ctx, userID, targetID, models.RelationBlock)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	// relations are one-directional
+	exists, err = s.RelationExists(ctx, targetID, userID, models.RelationBlock)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	related, err := s.RelatedUsers(ctx, targetID, models.RelationBlock)
+	require.NoError(t, err)
+	assert.Equal(t, []string{userID}, related)
+
+	require.NoError(t, s.DeleteRelation(ctx, userID, targetID, models.RelationBlock))
+	assert.ErrorIs(t, s.DeleteRelation(ctx, userID, targetID, models.RelationBlock), storage.ErrRelationNotFound)
+
+	exists, err = s.RelationExists(ctx, userID, targetID, models.RelationBlock)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	related, err = s.RelatedUsers(ctx, targetID, models.RelationBlock)
+	require.NoError(t, err)
+	assert.Empty(t, related)
+}
+
+func testTokens(t *testing.T, s types.Storage) {
+	ctx := context.Background()
+
+	userID := uuid.New().String()
+
+	first := models.Token{
+		Hash:      uuid.New().String(),
+		UserID:    userID,
+		Purpose:   models.TokenMFAChallenge,
+		ExpiresAt: time.Now().Add(time.Hour),
+		AppID:     seededAppID,
+	}
+	require.NoError(t, s.SaveToken(ctx, first))
+
+	token, err := s.Token(ctx, first.Hash, models.TokenMFAChallenge)
+	require.NoError(t, err)
+	assert.Equal(t, userID, token.UserID)
+	assert.Equal(t, seededAppID, token.AppID)
+	assert.Equal(t, first.ExpiresAt.Unix(), token.ExpiresAt.Unix())
+
+	_, err = s.Token(ctx, first.Hash, models.TokenPasswordReset)
+	assert.ErrorIs(t, err, storage.ErrTokenNotFound)
+
+	// a new token of the same purpose invalidates the previous one
+	second := first
+	second.Hash = uuid.New().String()
+	require.NoError(t, s.SaveToken(ctx, second))
+
+	_, err = s.ConsumeToken(ctx, first.Hash, models.TokenMFAChallenge)
+	assert.ErrorIs(t, err, storage.ErrTokenNotFound)
+
+	token, err = s.ConsumeToken(ctx, second.Hash, models.TokenMFAChallenge)
+	require.NoError(t, err)
+	assert.Equal(t, userID, token.UserID)
+
+	_, err = s.ConsumeToken(ctx, second.Hash, models.TokenMFAChallenge)
+	assert.ErrorIs(t, err, storage.ErrTokenNotFound)
+
+	expired := models.Token{
+		Hash:      uuid.New().String(),
+		UserID:    uuid.New().String(),
+		Purpose:   models.TokenPasswordReset,
+		ExpiresAt: time.Now().Add(-time.Minute),
+	}
+	require.NoError(t, s.SaveToken(ctx, expired))
+
+	_, err = s.Token(ctx, expired.Hash, models.TokenPasswordReset)
+	assert.ErrorIs(t, err, storage.ErrTokenNotFound)
+
+	_, err = s.ConsumeToken(ctx, expired.Hash, models.TokenPasswordReset)
+	assert.ErrorIs(t, err, storage.ErrTokenNotFound)
+}
+
+func testLoginAttempts(t *testing.T, s types.Storage) {
+	ctx := context.Background()
+
+	key := "login:" + gofakeit.Email()
+
+	attempts, err := s.LoginAttempts(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, models.LoginAttempts{Key: key}, attempts)
+
+	now := time.Now()
+	windowStart := now.Add(-time.Minute)
+
+	failures, err := s.AddLoginFailure(ctx, key, now, windowStart)
+	require.NoError(t, err)
+	assert.Equal(t, 1, failures)
+
+	failures, err = s.AddLoginFailure(ctx, key, now.Add(time.Millisecond), windowStart)
+	require.NoError(t, err)
+	assert.Equal(t, 2, failures)
+
+	attempts, err = s.LoginAttempts(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, 2, attempts.Failures)
+	assert.Equal(t, now.Add(time.Millisecond).UnixMilli(), attempts.LastFailureAt.UnixMilli())
+	assert.True(t, attempts.LockedUntil.IsZero())
+
+	// failures before the window are forgotten
+	later := now.Add(time.Hour)
+	failures, err = s.AddLoginFailure(ctx, key, later, later.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 1, failures)
+
+	lockout := models.Lockout{
+		Key:         key,
+		Login:       key,
+		IP:          gofakeit.IPv4Address(),
+		Failures:    failures,
+		LockedUntil: later.Add(15 * time.Minute),
+		CreatedAt:   later,
+	}
+	require.NoError(t, s.LockLogin(ctx, lockout))
+
+	attempts, err = s.LoginAttempts(ctx, key)
+	require.NoError(t, err)
+	assert.Zero(t, attempts.Failures)
+	assert.Equal(t, lockout.LockedUntil.UnixMilli(), attempts.LockedUntil.UnixMilli())
+
+	require.NoError(t, s.ResetLoginAttempts(ctx, key))
+
+	attempts, err = s.LoginAttempts(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, models.LoginAttempts{Key: key}, attempts)
+}
+
+func testMFA(t *testing.T, s types.Storage) {
+	ctx := context.Background()
+
+	userID := uuid.New().String()
+
+	_, err := s.MFA(ctx, userID)
+	assert.ErrorIs(t, err, storage.ErrMFANotFound)
+	assert.ErrorIs(t, s.ConfirmMFA(ctx, userID, 1, nil), storage.ErrMFANotFound)
+
+	require.NoError(t, s.SaveMFASecret(ctx, userID, "FIRSTSECRET"))
+	// unconfirmed secret is replaced
+	require.NoError(t, s.SaveMFASecret(ctx, userID, "SECONDSECRET"))
+
+	mfa, err := s.MFA(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, "SECONDSECRET", mfa.TOTPSecret)
+	assert.False(t, mfa.Enabled())
+
+	require.NoError(t, s.ConfirmMFA(ctx, userID, 100, []string{"code-1", "code-2"}))
+	assert.ErrorIs(t, s.ConfirmMFA(ctx, userID, 101, nil), storage.ErrMFANotFound)
+
+	// confirmed secret is kept
+	require.NoError(t, s.SaveMFASecret(ctx, userID, "THIRDSECRET"))
+
+	mfa, err = s.MFA(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, "SECONDSECRET", mfa.TOTPSecret)
+	assert.True(t, mfa.Enabled())
+	assert.Equal(t, int64(100), mfa.LastUsedStep)
+
+	assert.ErrorIs(t, s.UseTOTPStep(ctx, userID, 100), storage.ErrMFACodeUsed)
+	require.NoError(t, s.UseTOTPStep(ctx, userID, 101))
+	assert.ErrorIs(t, s.UseTOTPStep(ctx, userID, 100), storage.ErrMFACodeUsed)
+
+	require.NoError(t, s.UseRecoveryCode(ctx, userID, "code-1"))
+	assert.ErrorIs(t, s.UseRecoveryCode(ctx, userID, "code-1"), storage.ErrRecoveryCodeNotFound)
+	assert.ErrorIs(t, s.UseRecoveryCode(ctx, userID, "unknown"), storage.ErrRecoveryCodeNotFound)
+
+	require.NoError(t, s.DeleteMFA(ctx, userID))
+
+	_, err = s.MFA(ctx, userID)
+	assert.ErrorIs(t, err, storage.ErrMFANotFound)
+	assert.ErrorIs(t, s.UseRecoveryCode(ctx, userID, "code-2"), storage.ErrRecoveryCodeNotFound)
+}
+
+func testDeleteUser(t *testing.T, s types.Storage) {
+	ctx := context.Background()
+
+	login := gofakeit.Email()
+	userID, err := s.SaveUser(ctx, login, []byte("pass-hash"))
+	require.NoError(t, err)
+
+	otherID := uuid.New().String()
+	require.NoError(t, s.SaveRelation(ctx, models.Relation{
+		UserID:    userID,
+		TargetID:  otherID,
+		Kind:      models.RelationBlock,
+		CreatedAt: time.Now(),
+	}))
+	require.NoError(t, s.SaveRelation(ctx, models.Relation{
+		UserID:    otherID,
+		TargetID:  userID,
+		Kind:      models.RelationMute,
+		CreatedAt: time.Now(),
+	}))
+
+	token := models.Token{
+		Hash:      uuid.New().String(),
+		UserID:    userID,
+		Purpose:   models.TokenPasswordReset,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	require.NoError(t, s.SaveToken(ctx, token))
+	require.NoError(t, s.SaveMFASecret(ctx, userID, "SECRET"))
+	require.NoError(t, s.ConfirmMFA(ctx, userID, 1, []string{"code"}))
+
+	identity := models.Identity{
+		Provider:  "test",
+		Subject:   uuid.New().String(),
+		UserID:    userID,
+		CreatedAt: time.Now(),
+	}
+	require.NoError(t, s.SaveIdentity(ctx, identity))
+
+	session := newSession(userID)
+	require.NoError(t, s.SaveSession(ctx, session))
+
+	require.NoError(t, s.DeleteUser(ctx, userID))
+	assert.ErrorIs(t, s.DeleteUser(ctx, userID), storage.ErrUserNotFound)
+
+	_, err = s.UserByID(ctx, userID)
+	assert.ErrorIs(t, err, storage.ErrUserNotFound)
+
+	// relations in both directions are removed
+	relations, err := s.Relations(ctx, userID)
+	require.NoError(t, err)
+	assert.Empty(t, relations)
+
+	relations, err = s.Relations(ctx, otherID)
+	require.NoError(t, err)
+	assert.Empty(t, relations)
+
+	_, err = s.Token(ctx, token.Hash, models.TokenPasswordReset)
+	assert.ErrorIs(t, err, storage.ErrTokenNotFound)
+
+	_, err = s.MFA(ctx, userID)
+	assert.ErrorIs(t, err, storage.ErrMFANotFound)
+	assert.ErrorIs(t, s.UseRecoveryCode(ctx, userID, "code"), storage.ErrRecoveryCodeNotFound)
+
+	_, err = s.Identity(ctx, identity.Provider, identity.Subject)
+	assert.ErrorIs(t, err, storage.ErrIdentityNotFound)
+
+	_, err = s.TouchSession(ctx, session.ID, "", time.Now())
+	assert.ErrorIs(t, err, storage.ErrSessionNotFound)
+
+	// the login is free again
+	_, err = s.SaveUser(ctx, login, []byte("pass-hash"))
+	require.NoError(t, err)
+}
+
+func testAudit(t *testing.T, s types.Storage) {
+	ctx := context.Background()
+
+	userID := uuid.New().String()
+	start := time.Now().Truncate(time.Millisecond)
+
+	failed := models.AuditEvent{
+		ID:         uuid.New().String(),
+		Type:       models.AuditLoginFailed,
+		UserID:     userID,
+		Login:      gofakeit.Email(),
+		AppID:      seededAppID,
+		ClientIP:   gofakeit.IPv4Address(),
+		Source:     "sso",
+		OccurredAt: start,
+		Details:    map[string]string{"reason": "invalid password"},
+	}
+	succeeded := failed
+	succeeded.ID = uuid.New().String()
+	succeeded.Type = models.AuditLoginSucceeded
+	succeeded.OccurredAt = start.Add(time.Second)
+	succeeded.Details = nil
+
+	require.NoError(t, s.SaveAuditEvent(ctx, failed))
+	require.NoError(t, s.SaveAuditEvent(ctx, succeeded))
+	// saving the same event again is a no-op
+	require.NoError(t, s.SaveAuditEvent(ctx, failed))
+	require.NoError(t, s.SaveAuditEvent(ctx, models.AuditEvent{
+		ID:         uuid.New().String(),
+		Type:       models.AuditLoginSucceeded,
+		UserID:     uuid.New().String(),
+		Source:     "sso",
+		OccurredAt: start,
+	}))
+
+	events, err := s.AuditEvents(ctx, models.AuditFilter{UserID: userID})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	// newest first
+	assert.Equal(t, succeeded.ID, events[0].ID)
+	assert.Equal(t, failed.ID, events[1].ID)
+
+	event := events[1]
+	assert.Equal(t, failed.Type, event.Type)
+	assert.Equal(t, failed.Login, event.Login)
+	assert.Equal(t, failed.AppID, event.AppID)
+	assert.Equal(t, failed.ClientIP, event.ClientIP)
+	assert.Equal(t, failed.Source, event.Source)
+	assert.Equal(t, failed.OccurredAt.UnixMilli(), event.OccurredAt.UnixMilli())
+	assert.Equal(t, failed.Details, event.Details)
+
+	// the range includes its start and excludes its end
+	events, err = s.AuditEvents(ctx, models.AuditFilter{UserID: userID, From: start, To: succeeded.OccurredAt})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, failed.ID, events[0].ID)
+
+	events, err = s.AuditEvents(ctx, models.AuditFilter{UserID: userID, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, succeeded.ID, events[0].ID)
+
+	events, err = s.AuditEvents(ctx, models.AuditFilter{From: start, Limit: 10})
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, len(events), 3)
+}
+
+func appIDs(apps []models.App) []int {
+	ids := make([]int, 0, len(apps))
+	for _, app := range apps {
+		ids = append(ids, app.ID)
+	}
+
+	return ids
+}
+
+func testIdentities(t *testing.T, s types.Storage) {
+	ctx := context.Background()
+
+	identity := models.Identity{
+		Provider:  "test",
+		Subject:   uuid.New().String(),
+		UserID:    uuid.New().String(),
+		Email:     gofakeit.Email(),
+		CreatedAt: time.Now().Truncate(time.Second),
+	}
+
+	_, err := s.Identity(ctx, identity.Provider, identity.Subject)
+	assert.ErrorIs(t, err, storage.ErrIdentityNotFound)
+
+	require.NoError(t, s.SaveIdentity(ctx, identity))
+
+	got, err := s.Identity(ctx, identity.Provider, identity.Subject)
+	require.NoError(t, err)
+	assert.Equal(t, identity.UserID, got.UserID)
+	assert.Equal(t, identity.Email, got.Email)
+	assert.True(t, identity.CreatedAt.Equal(got.CreatedAt))
+
+	// the provider account can't be linked to another user
+	other := identity
+	other.UserID = uuid.New().String()
+	assert.ErrorIs(t, s.SaveIdentity(ctx, other), storage.ErrIdentityExists)
+
+	// the user can't link another account of the same provider
+	other = identity
+	other.Subject = uuid.New().String()
+	assert.ErrorIs(t, s.SaveIdentity(ctx, other), storage.ErrIdentityExists)
+
+	// accounts of other providers are linked independently
+	other.Provider = "other"
+	require.NoError(t, s.SaveIdentity(ctx, other))
+}
+
+func testOIDCStates(t *testing.T, s types.Storage) {
+	ctx := context.Background()
+
+	state := models.OIDCState{
+		Hash:         uuid.New().String(),
+		Provider:     "test",
+		CodeVerifier: "verifier",
+		Nonce:        "nonce",
+		AppID:        seededAppID,
+		LinkUserID:   uuid.New().String(),
+		ExpiresAt:    time.Now().Add(time.Hour).Truncate(time.Second),
+	}
+	require.NoError(t, s.SaveOIDCState(ctx, state))
+
+	got, err := s.ConsumeOIDCState(ctx, state.Hash)
+	require.NoError(t, err)
+	assert.Equal(t, state.Provider, got.Provider)
+	assert.Equal(t, state.CodeVerifier, got.CodeVerifier)
+	assert.Equal(t, state.Nonce, got.Nonce)
+	assert.Equal(t, state.AppID, got.AppID)
+	assert.Equal(t, state.LinkUserID, got.LinkUserID)
+	assert.True(t, state.ExpiresAt.Equal(got.ExpiresAt))
+
+	// state is single-use
+	_, err = s.ConsumeOIDCState(ctx, state.Hash)
+	assert.ErrorIs(t, err, storage.ErrOIDCStateNotFound)
+
+	expired := state
+	expired.Hash = uuid.New().String()
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+	require.NoError(t, s.SaveOIDCState(ctx, expired))
+
+	_, err = s.ConsumeOIDCState(ctx, expired.Hash)
+	assert.ErrorIs(t, err, storage.ErrOIDCStateNotFound)
+}
+
+func testSessions(t *testing.T, s types.Storage) {
+	ctx := context.Background()
+
+	userID := uuid.New().String()
+
+	sessions, err := s.Sessions(ctx, userID)
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+
+	older := newSession(userID)
+	older.LastUsedAt = older.LastUsedAt.Add(-time.Hour)
+	require.NoError(t, s.SaveSession(ctx, older))
+
+	newer := newSession(userID)
+	require.NoError(t, s.SaveSession(ctx, newer))
+
+	expired := newSession(userID)
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+	require.NoError(t, s.SaveSession(ctx, expired))
+
+	require.NoError(t, s.SaveSession(ctx, newSession(uuid.New().String())))
+
+	// only active sessions of the user, most recently used first
+	sessions, err = s.Sessions(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, newer.ID, sessions[0].ID)
+	assert.Equal(t, older.ID, sessions[1].ID)
+	assert.Equal(t, older.DeviceName, sessions[1].DeviceName)
+	assert.Equal(t, older.UserAgent, sessions[1].UserAgent)
+	assert.Equal(t, older.AppID, sessions[1].AppID)
+	assert.True(t, older.CreatedAt.Equal(sessions[1].CreatedAt))
+	assert.True(t, sessions[1].RevokedAt.IsZero())
+
+	usedAt := time.Now().Add(time.Minute).Truncate(time.Second)
+	touched, err := s.TouchSession(ctx, older.ID, "10.0.0.2", usedAt)
+	require.NoError(t, err)
+	assert.Equal(t, userID, touched.UserID)
+	assert.Equal(t, "10.0.0.2", touched.IP)
+	assert.True(t, usedAt.Equal(touched.LastUsedAt))
+
+	// empty IP keeps the last known one
+	touched, err = s.TouchSession(ctx, older.ID, "", usedAt)
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.2", touched.IP)
+
+	sessions, err = s.Sessions(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, older.ID, sessions[0].ID)
+
+	_, err = s.TouchSession(ctx, expired.ID, "", time.Now())
+	assert.ErrorIs(t, err, storage.ErrSessionNotFound)
+
+	_, err = s.TouchSession(ctx, uuid.New().String(), "", time.Now())
+	assert.ErrorIs(t, err, storage.ErrSessionNotFound)
+
+	// sessions of other users can't be revoked
+	assert.ErrorIs(t, s.RevokeSession(ctx, uuid.New().String(), older.ID, time.Now()), storage.ErrSessionNotFound)
+	assert.ErrorIs(t, s.RevokeSession(ctx, userID, expired.ID, time.Now()), storage.ErrSessionNotFound)
+
+	require.NoError(t, s.RevokeSession(ctx, userID, older.ID, time.Now()))
+	assert.ErrorIs(t, s.RevokeSession(ctx, userID, older.ID, time.Now()), storage.ErrSessionNotFound)
+
+	_, err = s.TouchSession(ctx, older.ID, "", time.Now())
+	assert.ErrorIs(t, err, storage.ErrSessionNotFound)
+
+	sessions, err = s.Sessions(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, newer.ID, sessions[0].ID)
+}
+
+func newSession(userID string) models.Session {
+	now := time.Now().Truncate(time.Second)
+
+	return models.Session{
+		ID:         uuid.New().String(),
+		UserID:     userID,
+		AppID:      seededAppID,
+		DeviceName: "Firefox on Linux",
+		UserAgent:  "Mozilla/5.0 (X11; Linux x86_64; rv:131.0) Gecko/20100101 Firefox/131.0",
+		IP:         "10.0.0.1",
+		CreatedAt:  now,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(time.Hour),
+	}
+}
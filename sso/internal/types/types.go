@@ -3,22 +3,163 @@ package types
 import (
 	"context"
 	"github.com/zoninnik89/messenger/sso/internal/domain/models"
+	"google.golang.org/protobuf/proto"
+	"time"
 )
 
 type Auth interface {
-	Login(ctx context.Context, login string, password string, appID int) (token string, err error)
+	Login(
+		ctx context.Context,
+		login string,
+		password string,
+		clientIP string,
+		appID int,
+	) (token string, mfaToken string, err error)
+	VerifyMFA(ctx context.Context, mfaToken string, code string, clientIP string) (token string, err error)
+	EnrollTOTP(ctx context.Context, userID string) (secret string, provisioningURI string, err error)
+	ConfirmTOTP(ctx context.Context, userID string, code string) (recoveryCodes []string, err error)
+	DisableTOTP(ctx context.Context, userID string, code string) error
+	DeleteAccount(ctx context.Context, userID string, password string, clientIP string) error
+	ExportAccountData(ctx context.Context, userID string) (models.AccountData, error)
+	StartOIDCLogin(ctx context.Context, provider string, appID int, linkUserID string) (authorizationURL string, err error)
+	CompleteOIDCLogin(
+		ctx context.Context,
+		provider string,
+		state string,
+		code string,
+		clientIP string,
+	) (models.OIDCLogin, error)
+	ListSessions(ctx context.Context, userID string) ([]models.Session, error)
+	RevokeSession(ctx context.Context, userID string, sessionID string, clientIP string) error
+	TouchSession(ctx context.Context, sessionID string, clientIP string) (userID string, err error)
 	RegisterNewUser(ctx context.Context, login string, password string) (userID string, err error)
+	VerifyEmail(ctx context.Context, token string) (userID string, err error)
+	ResendVerificationEmail(ctx context.Context, login string) error
+	RequestPasswordReset(ctx context.Context, login string) error
+	ResetPassword(ctx context.Context, token string, newPassword string) error
+}
+
+type Relations interface {
+	AddRelation(ctx context.Context, userID string, targetID string, kind models.RelationKind) error
+	RemoveRelation(ctx context.Context, userID string, targetID string, kind models.RelationKind) error
+	ListRelations(ctx context.Context, userID string) ([]models.Relation, error)
+	IsBlocked(ctx context.Context, userID string, targetID string) (bool, error)
+	ListBlockers(ctx context.Context, userID string) ([]string, error)
+	ListMuters(ctx context.Context, userID string) ([]string, error)
+}
+
+type Apps interface {
+	CreateApp(ctx context.Context, name string, tokenTTL time.Duration, scopes []string) (app models.App, err error)
+	ListApps(ctx context.Context) ([]models.App, error)
+	RotateAppSecret(ctx context.Context, appID int) (secret string, err error)
+	DisableApp(ctx context.Context, appID int) error
 }
 
 type UserSaver interface {
 	SaveUser(ctx context.Context, login string, passHash []byte) (uid string, err error)
-	//UpdateUser(ctx context.Context, user models.User) error
+	SetEmailVerified(ctx context.Context, userID string) error
+	UpdatePassword(ctx context.Context, userID string, passHash []byte) error
+	DeleteUser(ctx context.Context, userID string) error
 }
 
 type UserProvider interface {
 	GetUser(ctx context.Context, login string) (models.User, error)
+	UserByID(ctx context.Context, userID string) (models.User, error)
 }
 
 type AppProvider interface {
 	GetApp(ctx context.Context, id int) (models.App, error)
+	Apps(ctx context.Context) ([]models.App, error)
+}
+
+type AppSaver interface {
+	SaveApp(ctx context.Context, app models.App) (id int, err error)
+	UpdateAppSecret(ctx context.Context, id int, secret string) error
+	DisableApp(ctx context.Context, id int) error
+}
+
+type RelationSaver interface {
+	SaveRelation(ctx context.Context, relation models.Relation) error
+	DeleteRelation(ctx context.Context, userID string, targetID string, kind models.RelationKind) error
+}
+
+type RelationProvider interface {
+	Relations(ctx context.Context, userID string) ([]models.Relation, error)
+	RelationExists(ctx context.Context, userID string, targetID string, kind models.RelationKind) (bool, error)
+	RelatedUsers(ctx context.Context, targetID string, kind models.RelationKind) ([]string, error)
+}
+
+type TokenStorage interface {
+	SaveToken(ctx context.Context, token models.Token) error
+	ConsumeToken(ctx context.Context, hash string, purpose models.TokenPurpose) (models.Token, error)
+	Token(ctx context.Context, hash string, purpose models.TokenPurpose) (models.Token, error)
+}
+
+type MFAStorage interface {
+	MFA(ctx context.Context, userID string) (models.MFA, error)
+	SaveMFASecret(ctx context.Context, userID string, secret string) error
+	ConfirmMFA(ctx context.Context, userID string, step int64, recoveryCodeHashes []string) error
+	UseTOTPStep(ctx context.Context, userID string, step int64) error
+	UseRecoveryCode(ctx context.Context, userID string, hash string) error
+	DeleteMFA(ctx context.Context, userID string) error
+}
+
+type LoginAttemptsStorage interface {
+	LoginAttempts(ctx context.Context, key string) (models.LoginAttempts, error)
+	AddLoginFailure(ctx context.Context, key string, at time.Time, windowStart time.Time) (failures int, err error)
+	LockLogin(ctx context.Context, lockout models.Lockout) error
+	ResetLoginAttempts(ctx context.Context, key string) error
+}
+
+type AuditStorage interface {
+	SaveAuditEvent(ctx context.Context, event models.AuditEvent) error
+	AuditEvents(ctx context.Context, filter models.AuditFilter) ([]models.AuditEvent, error)
+}
+
+type IdentityStorage interface {
+	SaveIdentity(ctx context.Context, identity models.Identity) error
+	Identity(ctx context.Context, provider string, subject string) (models.Identity, error)
+	SaveOIDCState(ctx context.Context, state models.OIDCState) error
+	ConsumeOIDCState(ctx context.Context, hash string) (models.OIDCState, error)
+}
+
+type SessionStorage interface {
+	SaveSession(ctx context.Context, session models.Session) error
+	Sessions(ctx context.Context, userID string) ([]models.Session, error)
+	TouchSession(ctx context.Context, sessionID string, ip string, at time.Time) (models.Session, error)
+	RevokeSession(ctx context.Context, userID string, sessionID string, at time.Time) error
+}
+
+// Storage is implemented by every storage backend of sso.
+type Storage interface {
+	UserSaver
+	UserProvider
+	AppSaver
+	AppProvider
+	RelationSaver
+	RelationProvider
+	TokenStorage
+	MFAStorage
+	LoginAttemptsStorage
+	AuditStorage
+	IdentityStorage
+	SessionStorage
+}
+
+type MailSender interface {
+	Send(ctx context.Context, mail models.Mail) error
+}
+
+type EventPublisher interface {
+	Publish(ctx context.Context, topic string, key string, event proto.Message) error
+}
+
+// AuditRecorder appends events to the audit log. Recording never fails the audited action,
+// errors are logged by the recorder.
+type AuditRecorder interface {
+	Record(ctx context.Context, event models.AuditEvent)
+}
+
+type Audit interface {
+	ListAuditEvents(ctx context.Context, filter models.AuditFilter) ([]models.AuditEvent, error)
 }
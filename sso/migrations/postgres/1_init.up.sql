@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS users
+(
+    id BIGSERIAL PRIMARY KEY,
+    login TEXT NOT NULL UNIQUE,
+    user_id TEXT NOT NULL UNIQUE,
+    pass_hash BYTEA NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_login ON users (login);
+
+CREATE TABLE IF NOT EXISTS apps
+(
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    secret TEXT NOT NULL UNIQUE
+);
+
+-- Insert a new record into the apps table after creating it
+
+INSERT INTO apps (id, name, secret)
+VALUES (1,'app', 'secret')
+ON CONFLICT DO NOTHING;
+
+-- the id was set explicitly, move the sequence past it so that new apps don't collide with it
+SELECT setval(pg_get_serial_sequence('apps', 'id'), (SELECT MAX(id) FROM apps));
@@ -0,0 +1,12 @@
+ALTER TABLE users ADD COLUMN email_verified BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS user_tokens
+(
+    token_hash TEXT PRIMARY KEY,
+    user_id    TEXT   NOT NULL,
+    purpose    TEXT   NOT NULL,
+    expires_at BIGINT NOT NULL,
+    used_at    BIGINT
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_tokens_user ON user_tokens (user_id, purpose);
@@ -0,0 +1,21 @@
+-- times of login attempts are stored in unix milliseconds, as backoff delays start at one second
+CREATE TABLE IF NOT EXISTS login_attempts
+(
+    attempt_key     TEXT PRIMARY KEY,
+    failures        INTEGER NOT NULL DEFAULT 0,
+    last_failure_at BIGINT  NOT NULL DEFAULT 0,
+    locked_until    BIGINT  NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS login_lockouts
+(
+    id           BIGSERIAL PRIMARY KEY,
+    attempt_key  TEXT    NOT NULL,
+    login        TEXT    NOT NULL,
+    ip           TEXT    NOT NULL,
+    failures     INTEGER NOT NULL,
+    locked_until BIGINT  NOT NULL,
+    created_at   BIGINT  NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_lockouts_login ON login_lockouts (login);
@@ -0,0 +1,18 @@
+ALTER TABLE user_tokens ADD COLUMN app_id INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS user_mfa
+(
+    user_id        TEXT PRIMARY KEY,
+    totp_secret    TEXT   NOT NULL,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    confirmed_at   BIGINT,
+    created_at     BIGINT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS user_recovery_codes
+(
+    user_id   TEXT NOT NULL,
+    code_hash TEXT NOT NULL,
+    used_at   BIGINT,
+    PRIMARY KEY (user_id, code_hash)
+);
@@ -0,0 +1,5 @@
+-- token_ttl is in seconds, 0 means default TTL of sso service; scopes are space separated
+ALTER TABLE apps ADD COLUMN token_ttl BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE apps ADD COLUMN scopes TEXT NOT NULL DEFAULT '';
+ALTER TABLE apps ADD COLUMN disabled_at BIGINT;
+ALTER TABLE apps ADD COLUMN created_at BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,28 @@
+-- occurred_at is in unix milliseconds; details is a JSON object
+CREATE TABLE IF NOT EXISTS audit_events
+(
+    id          TEXT PRIMARY KEY,
+    type        TEXT    NOT NULL,
+    user_id     TEXT    NOT NULL DEFAULT '',
+    login       TEXT    NOT NULL DEFAULT '',
+    app_id      INTEGER NOT NULL DEFAULT 0,
+    client_ip   TEXT    NOT NULL DEFAULT '',
+    source      TEXT    NOT NULL,
+    occurred_at BIGINT  NOT NULL,
+    details     JSONB   NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_user_id ON audit_events (user_id, occurred_at);
+CREATE INDEX IF NOT EXISTS idx_audit_events_occurred_at ON audit_events (occurred_at);
+
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS
+$$
+BEGIN
+    RAISE EXCEPTION 'audit events are append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_append_only
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW
+EXECUTE FUNCTION audit_events_append_only();
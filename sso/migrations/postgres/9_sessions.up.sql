@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS sessions
+(
+    session_id   TEXT PRIMARY KEY,
+    user_id      TEXT    NOT NULL,
+    app_id       INTEGER NOT NULL,
+    device_name  TEXT    NOT NULL DEFAULT '',
+    user_agent   TEXT    NOT NULL DEFAULT '',
+    ip           TEXT    NOT NULL DEFAULT '',
+    created_at   BIGINT  NOT NULL,
+    last_used_at BIGINT  NOT NULL,
+    expires_at   BIGINT  NOT NULL,
+    revoked_at   BIGINT
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions (user_id);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS user_relations
+(
+    user_id    TEXT    NOT NULL,
+    target_id  TEXT    NOT NULL,
+    kind       TEXT    NOT NULL,
+    created_at INTEGER NOT NULL,
+    PRIMARY KEY (user_id, target_id, kind)
+);
+
+CREATE INDEX IF NOT EXISTS idx_relations_target ON user_relations (target_id, kind);
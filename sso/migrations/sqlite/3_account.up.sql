@@ -0,0 +1,12 @@
+ALTER TABLE users ADD COLUMN email_verified INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS user_tokens
+(
+    token_hash TEXT PRIMARY KEY,
+    user_id    TEXT    NOT NULL,
+    purpose    TEXT    NOT NULL,
+    expires_at INTEGER NOT NULL,
+    used_at    INTEGER
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_tokens_user ON user_tokens (user_id, purpose);
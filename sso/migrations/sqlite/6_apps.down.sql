@@ -0,0 +1,4 @@
+ALTER TABLE apps DROP COLUMN created_at;
+ALTER TABLE apps DROP COLUMN disabled_at;
+ALTER TABLE apps DROP COLUMN scopes;
+ALTER TABLE apps DROP COLUMN token_ttl;
@@ -0,0 +1,28 @@
+-- occurred_at is in unix milliseconds; details is a JSON object
+CREATE TABLE IF NOT EXISTS audit_events
+(
+    id          TEXT PRIMARY KEY,
+    type        TEXT    NOT NULL,
+    user_id     TEXT    NOT NULL DEFAULT '',
+    login       TEXT    NOT NULL DEFAULT '',
+    app_id      INTEGER NOT NULL DEFAULT 0,
+    client_ip   TEXT    NOT NULL DEFAULT '',
+    source      TEXT    NOT NULL,
+    occurred_at INTEGER NOT NULL,
+    details     TEXT    NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_user_id ON audit_events (user_id, occurred_at);
+CREATE INDEX IF NOT EXISTS idx_audit_events_occurred_at ON audit_events (occurred_at);
+
+CREATE TRIGGER IF NOT EXISTS audit_events_no_update
+    BEFORE UPDATE ON audit_events
+BEGIN
+    SELECT RAISE(ABORT, 'audit events are append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS audit_events_no_delete
+    BEFORE DELETE ON audit_events
+BEGIN
+    SELECT RAISE(ABORT, 'audit events are append-only');
+END;
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS user_identities
+(
+    provider   TEXT    NOT NULL,
+    subject    TEXT    NOT NULL,
+    user_id    TEXT    NOT NULL,
+    email      TEXT    NOT NULL DEFAULT '',
+    created_at INTEGER NOT NULL,
+    PRIMARY KEY (provider, subject),
+    UNIQUE (user_id, provider)
+);
+
+CREATE TABLE IF NOT EXISTS oidc_states
+(
+    state_hash    TEXT PRIMARY KEY,
+    provider      TEXT    NOT NULL,
+    code_verifier TEXT    NOT NULL,
+    nonce         TEXT    NOT NULL,
+    app_id        INTEGER NOT NULL,
+    link_user_id  TEXT    NOT NULL DEFAULT '',
+    expires_at    INTEGER NOT NULL
+);
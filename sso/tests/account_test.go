@@ -0,0 +1,179 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/brianvoe/gofakeit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pb "github.com/zoninnik89/messenger/common/api"
+	suite "github.com/zoninnik89/messenger/sso/tests/suite"
+)
+
+// mailDir is the directory file mail sender of the local config writes emails to
+const mailDir = "../storage/mail"
+
+func TestAccount_Register_WeakPassword(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	_, err := st.AuthClient.Register(ctx, &pb.RegisterRequest{
+		Login:    gofakeit.Email(),
+		Password: "password",
+	})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "password must contain")
+}
+
+func TestAccount_VerifyEmail_HappyPath(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	email := gofakeit.Email()
+
+	respReg, err := st.AuthClient.Register(ctx, &pb.RegisterRequest{
+		Login:    email,
+		Password: randomFakePassword(),
+	})
+	require.NoError(t, err)
+
+	token := lastMailToken(t, email)
+
+	respVerify, err := st.AuthClient.VerifyEmail(ctx, &pb.VerifyEmailRequest{Token: token})
+	require.NoError(t, err)
+	assert.Equal(t, respReg.GetUserId(), respVerify.GetUserId())
+
+	// tokens are single-use
+	_, err = st.AuthClient.VerifyEmail(ctx, &pb.VerifyEmailRequest{Token: token})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "invalid or expired token")
+}
+
+func TestAccount_ResetPassword_HappyPath(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	email := gofakeit.Email()
+	newPassword := randomFakePassword()
+
+	_, err := st.AuthClient.Register(ctx, &pb.RegisterRequest{
+		Login:    email,
+		Password: randomFakePassword(),
+	})
+	require.NoError(t, err)
+
+	_, err = st.AuthClient.RequestPasswordReset(ctx, &pb.RequestPasswordResetRequest{Login: email})
+	require.NoError(t, err)
+
+	_, err = st.AuthClient.ResetPassword(ctx, &pb.ResetPasswordRequest{
+		Token:       lastMailToken(t, email),
+		NewPassword: newPassword,
+	})
+	require.NoError(t, err)
+
+	respLogin, err := st.AuthClient.Login(ctx, &pb.LoginRequest{
+		Login:    email,
+		Password: newPassword,
+		AppId:    appID,
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, respLogin.GetToken())
+}
+
+func TestAccount_RequestPasswordReset_UnknownUser(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	// unknown users get the same response, so registered emails can't be enumerated
+	_, err := st.AuthClient.RequestPasswordReset(ctx, &pb.RequestPasswordResetRequest{Login: gofakeit.Email()})
+	require.NoError(t, err)
+}
+
+func TestAccount_ResetPassword_InvalidToken(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	_, err := st.AuthClient.ResetPassword(ctx, &pb.ResetPasswordRequest{
+		Token:       "invalid-token",
+		NewPassword: randomFakePassword(),
+	})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "invalid or expired token")
+}
+
+func TestAccount_ExportAndDelete_HappyPath(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	email, pass, userID := registerCredentials(ctx, t, st)
+	targetID := registerUser(ctx, t, st)
+
+	_, err := st.RelationsClient.AddRelation(ctx, &pb.AddRelationRequest{
+		UserId:   targetID,
+		TargetId: userID,
+		Kind:     pb.RelationKind_RELATION_KIND_BLOCK,
+	})
+	require.NoError(t, err)
+
+	respExport, err := st.AuthClient.ExportAccountData(ctx, &pb.ExportAccountDataRequest{UserId: userID})
+	require.NoError(t, err)
+	assert.Equal(t, userID, respExport.GetUserId())
+	assert.Equal(t, email, respExport.GetLogin())
+	assert.False(t, respExport.GetMfaEnabled())
+
+	_, err = st.AuthClient.DeleteAccount(ctx, &pb.DeleteAccountRequest{UserId: userID, Password: pass})
+	require.NoError(t, err)
+
+	_, err = st.AuthClient.Login(ctx, &pb.LoginRequest{Login: email, Password: pass, AppId: appID})
+	require.Error(t, err)
+
+	_, err = st.AuthClient.ExportAccountData(ctx, &pb.ExportAccountDataRequest{UserId: userID})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "user not found")
+
+	// relations of other users with the deleted user are removed as well
+	respBlockers, err := st.RelationsClient.ListBlockers(ctx, &pb.ListBlockersRequest{UserId: userID})
+	require.NoError(t, err)
+	assert.Empty(t, respBlockers.GetUserIds())
+
+	// the login can be registered again
+	_, err = st.AuthClient.Register(ctx, &pb.RegisterRequest{Login: email, Password: pass})
+	require.NoError(t, err)
+}
+
+func TestAccount_Delete_WrongPassword(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	email, pass, userID := registerCredentials(ctx, t, st)
+
+	_, err := st.AuthClient.DeleteAccount(ctx, &pb.DeleteAccountRequest{UserId: userID, Password: pass + "wrong"})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "invalid password")
+
+	_, err = st.AuthClient.Login(ctx, &pb.LoginRequest{Login: email, Password: pass, AppId: appID})
+	require.Error(t, err)
+	// the failed attempt delays the next one like a failed login
+	assert.ErrorContains(t, err, "too many login attempts")
+}
+
+// lastMailToken returns the token from the latest email sent to the given address.
+func lastMailToken(t *testing.T, to string) string {
+	t.Helper()
+
+	files, err := filepath.Glob(filepath.Join(mailDir, "*-"+to+".txt"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files, "no emails sent to %s", to)
+
+	sort.Strings(files)
+
+	content, err := os.ReadFile(files[len(files)-1])
+	require.NoError(t, err)
+
+	for _, line := range strings.Split(string(content), "\n") {
+		if token, ok := strings.CutPrefix(line, "token: "); ok {
+			return token
+		}
+	}
+
+	t.Fatalf("no token in email sent to %s", to)
+
+	return ""
+}
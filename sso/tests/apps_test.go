@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pb "github.com/zoninnik89/messenger/common/api"
+	suite "github.com/zoninnik89/messenger/sso/tests/suite"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestApps_CreateApp_TokenTTLAndScopes(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	respCreate, err := st.AppClient.CreateApp(st.AdminContext(ctx), &pb.CreateAppRequest{
+		Name:            "mobile-" + gofakeit.UUID(),
+		TokenTtlSeconds: 600,
+		Scopes:          []string{"chat:read", "chat:write"},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, respCreate.GetSecret())
+
+	email, pass, userID := registerCredentials(ctx, t, st)
+
+	loginTime := time.Now()
+	respLogin, err := st.AuthClient.Login(ctx, &pb.LoginRequest{
+		Login:    email,
+		Password: pass,
+		AppId:    respCreate.GetApp().GetId(),
+	})
+	require.NoError(t, err)
+
+	tokenParsed, err := jwt.Parse(respLogin.GetToken(), func(token *jwt.Token) (interface{}, error) {
+		return []byte(respCreate.GetSecret()), nil
+	})
+	require.NoError(t, err)
+
+	claims, ok := tokenParsed.Claims.(jwt.MapClaims)
+	require.True(t, ok)
+
+	assert.Equal(t, userID, claims["uid"].(string))
+	assert.Equal(t, "chat:read chat:write", claims["scope"].(string))
+	assert.InDelta(t, loginTime.Add(10*time.Minute).Unix(), claims["exp"].(float64), 1)
+}
+
+func TestApps_RotateAndDisable(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	respCreate, err := st.AppClient.CreateApp(st.AdminContext(ctx), &pb.CreateAppRequest{Name: "web-" + gofakeit.UUID()})
+	require.NoError(t, err)
+	appID := respCreate.GetApp().GetId()
+
+	respRotate, err := st.AppClient.RotateAppSecret(st.AdminContext(ctx), &pb.RotateAppSecretRequest{AppId: appID})
+	require.NoError(t, err)
+	assert.NotEqual(t, respCreate.GetSecret(), respRotate.GetSecret())
+
+	email, pass, _ := registerCredentials(ctx, t, st)
+
+	respLogin, err := st.AuthClient.Login(ctx, &pb.LoginRequest{Login: email, Password: pass, AppId: appID})
+	require.NoError(t, err)
+
+	tokenParsed, err := jwt.Parse(respLogin.GetToken(), func(token *jwt.Token) (interface{}, error) {
+		return []byte(respRotate.GetSecret()), nil
+	})
+	require.NoError(t, err)
+	sessionID := tokenParsed.Claims.(jwt.MapClaims)["sid"].(string)
+
+	_, err = st.AuthClient.TouchSession(ctx, &pb.TouchSessionRequest{SessionId: sessionID})
+	require.NoError(t, err)
+
+	_, err = st.AppClient.DisableApp(st.AdminContext(ctx), &pb.DisableAppRequest{AppId: appID})
+	require.NoError(t, err)
+
+	// sessions of the app end with it, so services checking them reject tokens issued before
+	_, err = st.AuthClient.TouchSession(ctx, &pb.TouchSessionRequest{SessionId: sessionID})
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = st.AuthClient.Login(ctx, &pb.LoginRequest{Login: email, Password: pass, AppId: appID})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "invalid app id")
+
+	respList, err := st.AppClient.ListApps(st.AdminContext(ctx), &pb.ListAppsRequest{})
+	require.NoError(t, err)
+
+	var found bool
+	for _, app := range respList.GetApps() {
+		if app.GetId() == appID {
+			found = true
+			assert.True(t, app.GetDisabled())
+		}
+	}
+	assert.True(t, found)
+}
+
+func TestApps_RequireAdminToken(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	_, err := st.AppClient.ListApps(ctx, &pb.ListAppsRequest{})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = st.AppClient.CreateApp(ctx, &pb.CreateAppRequest{Name: "app-" + gofakeit.UUID()})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
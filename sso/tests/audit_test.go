@@ -0,0 +1,63 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pb "github.com/zoninnik89/messenger/common/api"
+	suite "github.com/zoninnik89/messenger/sso/tests/suite"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAudit_LoginEvents(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	start := time.Now().Add(-time.Second)
+
+	email, pass, userID := registerCredentials(ctx, t, st)
+
+	_, err := st.AuthClient.Login(ctx, &pb.LoginRequest{Login: email, Password: pass, AppId: appID})
+	require.NoError(t, err)
+
+	_, err = st.AuthClient.Login(ctx, &pb.LoginRequest{Login: email, Password: pass + "wrong", AppId: appID})
+	require.Error(t, err)
+
+	respEvents, err := st.AuditClient.ListAuditEvents(st.AdminContext(ctx), &pb.ListAuditEventsRequest{
+		UserId: userID,
+		From:   start.UnixMilli(),
+	})
+	require.NoError(t, err)
+
+	events := respEvents.GetEvents()
+	require.Len(t, events, 3)
+
+	// newest first
+	assert.Equal(t, pb.AuditEventType_AUDIT_EVENT_TYPE_LOGIN_FAILED, events[0].GetType())
+	assert.Equal(t, "invalid password", events[0].GetDetails()["reason"])
+	assert.Equal(t, pb.AuditEventType_AUDIT_EVENT_TYPE_LOGIN_SUCCEEDED, events[1].GetType())
+	assert.Equal(t, pb.AuditEventType_AUDIT_EVENT_TYPE_USER_REGISTERED, events[2].GetType())
+
+	for _, event := range events {
+		assert.NotEmpty(t, event.GetEventId())
+		assert.Equal(t, email, event.GetLogin())
+		assert.Equal(t, st.Cfg.GRPC.Name, event.GetSource())
+		assert.GreaterOrEqual(t, event.GetOccurredAt(), start.UnixMilli())
+	}
+	assert.Equal(t, int32(appID), events[1].GetAppId())
+	assert.NotEmpty(t, events[1].GetClientIp())
+}
+
+func TestAudit_RequireAdminToken(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	_, err := st.AuditClient.ListAuditEvents(ctx, &pb.ListAuditEventsRequest{})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = st.AuditClient.ListAuditEvents(st.AdminContext(ctx), &pb.ListAuditEventsRequest{From: 2, To: 1})
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
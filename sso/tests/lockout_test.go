@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/brianvoe/gofakeit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pb "github.com/zoninnik89/messenger/common/api"
+	suite "github.com/zoninnik89/messenger/sso/tests/suite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestLockout_Login_BackoffAfterFailure(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	email := gofakeit.Email()
+	pass := randomFakePassword()
+
+	_, err := st.AuthClient.Register(ctx, &pb.RegisterRequest{
+		Login:    email,
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	_, err = st.AuthClient.Login(ctx, &pb.LoginRequest{
+		Login:    email,
+		Password: randomFakePassword(),
+		AppId:    appID,
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// attempt right after the failure is rejected even with the correct password
+	var header metadata.MD
+	_, err = st.AuthClient.Login(ctx, &pb.LoginRequest{
+		Login:    email,
+		Password: pass,
+		AppId:    appID,
+	}, grpc.Header(&header))
+	require.Error(t, err)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	retryAfter := header.Get("retry-after")
+	require.Len(t, retryAfter, 1)
+
+	seconds, err := strconv.Atoi(retryAfter[0])
+	require.NoError(t, err)
+	assert.Positive(t, seconds)
+}
+
+func TestLockout_Login_UnknownUserIsThrottled(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	email := gofakeit.Email()
+
+	_, err := st.AuthClient.Login(ctx, &pb.LoginRequest{
+		Login:    email,
+		Password: randomFakePassword(),
+		AppId:    appID,
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// unknown logins are throttled the same way, so they can't be told apart from existing ones
+	_, err = st.AuthClient.Login(ctx, &pb.LoginRequest{
+		Login:    email,
+		Password: randomFakePassword(),
+		AppId:    appID,
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
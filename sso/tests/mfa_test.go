@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pb "github.com/zoninnik89/messenger/common/api"
+	"github.com/zoninnik89/messenger/sso/internal/lib/totp"
+	suite "github.com/zoninnik89/messenger/sso/tests/suite"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMFA_TOTPLogin_HappyPath(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	email, pass, userID := registerCredentials(ctx, t, st)
+	secret, recoveryCodes := enableTOTP(ctx, t, st, userID)
+	require.Len(t, recoveryCodes, 10)
+
+	respLogin, err := st.AuthClient.Login(ctx, &pb.LoginRequest{Login: email, Password: pass, AppId: appID})
+	require.NoError(t, err)
+	assert.True(t, respLogin.GetMfaRequired())
+	assert.Empty(t, respLogin.GetToken())
+	require.NotEmpty(t, respLogin.GetMfaToken())
+
+	// code of the confirmation step was used up, so the next period's code is used
+	code, err := totp.Code(secret, time.Now().Add(30*time.Second))
+	require.NoError(t, err)
+
+	respVerify, err := st.AuthClient.VerifyMFA(ctx, &pb.VerifyMFARequest{MfaToken: respLogin.GetMfaToken(), Code: code})
+	require.NoError(t, err)
+	assert.NotEmpty(t, respVerify.GetToken())
+
+	// challenge is single-use
+	_, err = st.AuthClient.VerifyMFA(ctx, &pb.VerifyMFARequest{MfaToken: respLogin.GetMfaToken(), Code: code})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "invalid or expired mfa token")
+}
+
+func TestMFA_RecoveryCode_SingleUse(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	email, pass, userID := registerCredentials(ctx, t, st)
+	_, recoveryCodes := enableTOTP(ctx, t, st, userID)
+
+	respLogin, err := st.AuthClient.Login(ctx, &pb.LoginRequest{Login: email, Password: pass, AppId: appID})
+	require.NoError(t, err)
+
+	respVerify, err := st.AuthClient.VerifyMFA(ctx, &pb.VerifyMFARequest{
+		MfaToken: respLogin.GetMfaToken(),
+		Code:     recoveryCodes[0],
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, respVerify.GetToken())
+
+	respLogin, err = st.AuthClient.Login(ctx, &pb.LoginRequest{Login: email, Password: pass, AppId: appID})
+	require.NoError(t, err)
+
+	_, err = st.AuthClient.VerifyMFA(ctx, &pb.VerifyMFARequest{
+		MfaToken: respLogin.GetMfaToken(),
+		Code:     recoveryCodes[0],
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.ErrorContains(t, err, "invalid code")
+}
+
+func TestMFA_Disable(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	email, pass, userID := registerCredentials(ctx, t, st)
+	_, recoveryCodes := enableTOTP(ctx, t, st, userID)
+
+	_, err := st.AuthClient.DisableTOTP(ctx, &pb.DisableTOTPRequest{UserId: userID, Code: recoveryCodes[0]})
+	require.NoError(t, err)
+
+	respLogin, err := st.AuthClient.Login(ctx, &pb.LoginRequest{Login: email, Password: pass, AppId: appID})
+	require.NoError(t, err)
+	assert.False(t, respLogin.GetMfaRequired())
+	assert.NotEmpty(t, respLogin.GetToken())
+}
+
+func TestMFA_Confirm_InvalidCode(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	_, _, userID := registerCredentials(ctx, t, st)
+
+	_, err := st.AuthClient.EnrollTOTP(ctx, &pb.EnrollTOTPRequest{UserId: userID})
+	require.NoError(t, err)
+
+	_, err = st.AuthClient.ConfirmTOTP(ctx, &pb.ConfirmTOTPRequest{UserId: userID, Code: "000000x"})
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func registerCredentials(ctx context.Context, t *testing.T, st *suite.Suite) (email string, pass string, userID string) {
+	t.Helper()
+
+	email = gofakeit.Email()
+	pass = randomFakePassword()
+
+	respReg, err := st.AuthClient.Register(ctx, &pb.RegisterRequest{Login: email, Password: pass})
+	require.NoError(t, err)
+
+	return email, pass, respReg.GetUserId()
+}
+
+func enableTOTP(ctx context.Context, t *testing.T, st *suite.Suite, userID string) (secret string, recoveryCodes []string) {
+	t.Helper()
+
+	respEnroll, err := st.AuthClient.EnrollTOTP(ctx, &pb.EnrollTOTPRequest{UserId: userID})
+	require.NoError(t, err)
+	assert.Contains(t, respEnroll.GetProvisioningUri(), "otpauth://totp/")
+
+	code, err := totp.Code(respEnroll.GetSecret(), time.Now())
+	require.NoError(t, err)
+
+	respConfirm, err := st.AuthClient.ConfirmTOTP(ctx, &pb.ConfirmTOTPRequest{UserId: userID, Code: code})
+	require.NoError(t, err)
+
+	return respEnroll.GetSecret(), respConfirm.GetRecoveryCodes()
+}
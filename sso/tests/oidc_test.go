@@ -0,0 +1,201 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/brianvoe/gofakeit"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pb "github.com/zoninnik89/messenger/common/api"
+	suite "github.com/zoninnik89/messenger/sso/tests/suite"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestOIDC_Login_RegistersNewUser(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	email := gofakeit.Email()
+
+	respFirst := oidcLogin(ctx, t, st, email, true, "")
+	assert.True(t, respFirst.GetLinked())
+	require.NotEmpty(t, respFirst.GetToken())
+	assert.Equal(t, respFirst.GetUserId(), tokenUserID(t, respFirst.GetToken()))
+
+	// the provider account is linked, so the same user logs in
+	respSecond := oidcLogin(ctx, t, st, email, true, "")
+	assert.False(t, respSecond.GetLinked())
+	assert.Equal(t, respFirst.GetUserId(), respSecond.GetUserId())
+
+	// email verified by the provider is verified in sso too
+	respExport, err := st.AuthClient.ExportAccountData(ctx, &pb.ExportAccountDataRequest{UserId: respFirst.GetUserId()})
+	require.NoError(t, err)
+	assert.Equal(t, email, respExport.GetLogin())
+	assert.True(t, respExport.GetEmailVerified())
+}
+
+func TestOIDC_Login_LinksExistingUserByVerifiedEmail(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	email, pass, userID := registerCredentials(ctx, t, st)
+
+	resp := oidcLogin(ctx, t, st, email, true, "")
+	assert.True(t, resp.GetLinked())
+	assert.Equal(t, userID, resp.GetUserId())
+
+	// password login keeps working
+	_, err := st.AuthClient.Login(ctx, &pb.LoginRequest{Login: email, Password: pass, AppId: appID})
+	require.NoError(t, err)
+}
+
+func TestOIDC_Login_UnverifiedEmail(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	email, _, _ := registerCredentials(ctx, t, st)
+
+	state, code := startOIDCLogin(ctx, t, st, email, false, "")
+
+	_, err := st.AuthClient.CompleteOIDCLogin(ctx, &pb.CompleteOIDCLoginRequest{
+		Provider: suite.StubIdPName,
+		State:    state,
+		Code:     code,
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.ErrorContains(t, err, "email is not verified by identity provider")
+}
+
+func TestOIDC_Link_LoggedInUser(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	_, _, userID := registerCredentials(ctx, t, st)
+	_, _, otherUserID := registerCredentials(ctx, t, st)
+
+	// the provider account may have another email than the user
+	providerEmail := gofakeit.Email()
+
+	resp := oidcLogin(ctx, t, st, providerEmail, false, userID)
+	assert.True(t, resp.GetLinked())
+	assert.Equal(t, userID, resp.GetUserId())
+
+	// login with the linked account
+	resp = oidcLogin(ctx, t, st, providerEmail, false, "")
+	assert.Equal(t, userID, resp.GetUserId())
+
+	// the account can't be linked to another user
+	state, code := startOIDCLogin(ctx, t, st, providerEmail, false, otherUserID)
+
+	_, err := st.AuthClient.CompleteOIDCLogin(ctx, &pb.CompleteOIDCLoginRequest{
+		Provider: suite.StubIdPName,
+		State:    state,
+		Code:     code,
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+}
+
+func TestOIDC_Login_MFARequired(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	email, _, userID := registerCredentials(ctx, t, st)
+	enableTOTP(ctx, t, st, userID)
+
+	resp := oidcLogin(ctx, t, st, email, true, "")
+	assert.True(t, resp.GetMfaRequired())
+	assert.Empty(t, resp.GetToken())
+	assert.NotEmpty(t, resp.GetMfaToken())
+}
+
+func TestOIDC_FailCases(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	_, err := st.AuthClient.StartOIDCLogin(ctx, &pb.StartOIDCLoginRequest{Provider: "unknown", AppId: appID})
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = st.AuthClient.StartOIDCLogin(ctx, &pb.StartOIDCLoginRequest{Provider: suite.StubIdPName, AppId: 1000})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "invalid app id")
+
+	email := gofakeit.Email()
+	state, code := startOIDCLogin(ctx, t, st, email, true, "")
+
+	// the code is bound to the PKCE verifier of the state
+	_, otherCode := startOIDCLogin(ctx, t, st, email, true, "")
+
+	_, err = st.AuthClient.CompleteOIDCLogin(ctx, &pb.CompleteOIDCLoginRequest{
+		Provider: suite.StubIdPName,
+		State:    state,
+		Code:     otherCode,
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// state is single-use
+	_, err = st.AuthClient.CompleteOIDCLogin(ctx, &pb.CompleteOIDCLoginRequest{
+		Provider: suite.StubIdPName,
+		State:    state,
+		Code:     code,
+	})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "invalid or expired state")
+}
+
+// startOIDCLogin starts login with the stub provider and returns state and code it redirects back with.
+func startOIDCLogin(
+	ctx context.Context,
+	t *testing.T,
+	st *suite.Suite,
+	email string,
+	emailVerified bool,
+	linkUserID string,
+) (state string, code string) {
+	t.Helper()
+
+	idp := st.StubIdP()
+
+	respStart, err := st.AuthClient.StartOIDCLogin(ctx, &pb.StartOIDCLoginRequest{
+		Provider:   suite.StubIdPName,
+		AppId:      appID,
+		LinkUserId: linkUserID,
+	})
+	require.NoError(t, err)
+
+	return idp.Authorize(t, respStart.GetAuthorizationUrl(), email, emailVerified)
+}
+
+func oidcLogin(
+	ctx context.Context,
+	t *testing.T,
+	st *suite.Suite,
+	email string,
+	emailVerified bool,
+	linkUserID string,
+) *pb.CompleteOIDCLoginResponse {
+	t.Helper()
+
+	state, code := startOIDCLogin(ctx, t, st, email, emailVerified, linkUserID)
+
+	resp, err := st.AuthClient.CompleteOIDCLogin(ctx, &pb.CompleteOIDCLoginRequest{
+		Provider: suite.StubIdPName,
+		State:    state,
+		Code:     code,
+	})
+	require.NoError(t, err)
+
+	return resp
+}
+
+func tokenUserID(t *testing.T, token string) string {
+	t.Helper()
+
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+	require.NoError(t, err)
+
+	claims, ok := parsed.Claims.(jwt.MapClaims)
+	require.True(t, ok)
+
+	return claims["uid"].(string)
+}
@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/brianvoe/gofakeit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pb "github.com/zoninnik89/messenger/common/api"
+	suite "github.com/zoninnik89/messenger/sso/tests/suite"
+)
+
+func TestRelations_BlockUnblock_HappyPath(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	userID := registerUser(ctx, t, st)
+	targetID := registerUser(ctx, t, st)
+
+	_, err := st.RelationsClient.AddRelation(ctx, &pb.AddRelationRequest{
+		UserId:   userID,
+		TargetId: targetID,
+		Kind:     pb.RelationKind_RELATION_KIND_BLOCK,
+	})
+	require.NoError(t, err)
+
+	respBlocked, err := st.RelationsClient.IsBlocked(ctx, &pb.IsBlockedRequest{UserId: userID, TargetId: targetID})
+	require.NoError(t, err)
+	assert.True(t, respBlocked.GetBlocked())
+
+	respBlockers, err := st.RelationsClient.ListBlockers(ctx, &pb.ListBlockersRequest{UserId: targetID})
+	require.NoError(t, err)
+	assert.Contains(t, respBlockers.GetUserIds(), userID)
+
+	// blocking is one-directional
+	respBlocked, err = st.RelationsClient.IsBlocked(ctx, &pb.IsBlockedRequest{UserId: targetID, TargetId: userID})
+	require.NoError(t, err)
+	assert.False(t, respBlocked.GetBlocked())
+
+	_, err = st.RelationsClient.RemoveRelation(ctx, &pb.RemoveRelationRequest{
+		UserId:   userID,
+		TargetId: targetID,
+		Kind:     pb.RelationKind_RELATION_KIND_BLOCK,
+	})
+	require.NoError(t, err)
+
+	respBlocked, err = st.RelationsClient.IsBlocked(ctx, &pb.IsBlockedRequest{UserId: userID, TargetId: targetID})
+	require.NoError(t, err)
+	assert.False(t, respBlocked.GetBlocked())
+}
+
+func TestRelations_Mute_DoesNotBlock(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	userID := registerUser(ctx, t, st)
+	targetID := registerUser(ctx, t, st)
+
+	_, err := st.RelationsClient.AddRelation(ctx, &pb.AddRelationRequest{
+		UserId:   userID,
+		TargetId: targetID,
+		Kind:     pb.RelationKind_RELATION_KIND_MUTE,
+	})
+	require.NoError(t, err)
+
+	respBlocked, err := st.RelationsClient.IsBlocked(ctx, &pb.IsBlockedRequest{UserId: userID, TargetId: targetID})
+	require.NoError(t, err)
+	assert.False(t, respBlocked.GetBlocked())
+
+	respList, err := st.RelationsClient.ListRelations(ctx, &pb.ListRelationsRequest{UserId: userID})
+	require.NoError(t, err)
+	require.Len(t, respList.GetRelations(), 1)
+	assert.Equal(t, targetID, respList.GetRelations()[0].GetTargetId())
+	assert.Equal(t, pb.RelationKind_RELATION_KIND_MUTE, respList.GetRelations()[0].GetKind())
+
+	respMuters, err := st.RelationsClient.ListMuters(ctx, &pb.ListMutersRequest{UserId: targetID})
+	require.NoError(t, err)
+	assert.Equal(t, []string{userID}, respMuters.GetUserIds())
+
+	respBlockers, err := st.RelationsClient.ListBlockers(ctx, &pb.ListBlockersRequest{UserId: targetID})
+	require.NoError(t, err)
+	assert.Empty(t, respBlockers.GetUserIds())
+}
+
+func TestRelations_FailCases(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	userID := registerUser(ctx, t, st)
+	targetID := registerUser(ctx, t, st)
+
+	_, err := st.RelationsClient.AddRelation(ctx, &pb.AddRelationRequest{
+		UserId:   userID,
+		TargetId: userID,
+		Kind:     pb.RelationKind_RELATION_KIND_BLOCK,
+	})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "cannot block or mute themselves")
+
+	_, err = st.RelationsClient.AddRelation(ctx, &pb.AddRelationRequest{
+		UserId:   userID,
+		TargetId: targetID,
+	})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "relation kind required")
+
+	_, err = st.RelationsClient.RemoveRelation(ctx, &pb.RemoveRelationRequest{
+		UserId:   userID,
+		TargetId: targetID,
+		Kind:     pb.RelationKind_RELATION_KIND_BLOCK,
+	})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "relation not found")
+}
+
+func registerUser(ctx context.Context, t *testing.T, st *suite.Suite) string {
+	t.Helper()
+
+	respReg, err := st.AuthClient.Register(ctx, &pb.RegisterRequest{
+		Login:    gofakeit.Email(),
+		Password: randomFakePassword(),
+	})
+	require.NoError(t, err)
+
+	return respReg.GetUserId()
+}
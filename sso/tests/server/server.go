@@ -0,0 +1,105 @@
+// Package server runs sso service in the process of a test, with a SQLite database in a directory of the test.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"path/filepath"
+	"runtime"
+	"strconv"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/sqlite3"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/zoninnik89/messenger/common/discovery"
+	"github.com/zoninnik89/messenger/sso/internal/app"
+	"github.com/zoninnik89/messenger/sso/internal/config"
+	"github.com/zoninnik89/messenger/sso/internal/logging"
+)
+
+const host = "127.0.0.1"
+
+// Server is sso service listening on an ephemeral port.
+type Server struct {
+	// Addr is the address of the gRPC server
+	Addr string
+	Cfg  *config.Config
+
+	app        *app.App
+	registry   discovery.Registry
+	instanceID string
+}
+
+// Start migrates a new database in storageDir and starts the service with the local config, except that
+// mail and events are only logged and OIDC providers are disabled. The service is registered in the registry.
+func Start(registry discovery.Registry, storageDir string) (*Server, error) {
+	const op = "server.Start"
+
+	cfg := config.MustLoadByPath(filepath.Join(moduleDir(), "config", "local.yaml"))
+	cfg.StoragePath = filepath.Join(storageDir, "sso.db")
+	cfg.Mail.Sender = "log"
+	cfg.Events.Publisher = "log"
+	cfg.Audit.Ingest = false
+	cfg.OIDC.Providers = nil
+
+	if err := migrateStorage(cfg.StoragePath); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	l, err := net.Listen("tcp", net.JoinHostPort(host, "0"))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	port := l.Addr().(*net.TCPAddr).Port
+	cfg.GRPC.Port = port
+
+	application := app.NewApp(logging.InitLogger().Sugar(), cfg)
+	go func() {
+		_ = application.GRPCsrv.Serve(l)
+	}()
+
+	s := &Server{
+		Addr:       net.JoinHostPort(host, strconv.Itoa(port)),
+		Cfg:        cfg,
+		app:        application,
+		registry:   registry,
+		instanceID: discovery.GenerateInstanceID(cfg.GRPC.Name),
+	}
+
+	if err := registry.Register(context.Background(), s.instanceID, host, port, cfg.GRPC.Name); err != nil {
+		application.GRPCsrv.Stop()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return s, nil
+}
+
+// Stop deregisters the service and stops it gracefully.
+func (s *Server) Stop() {
+	_ = s.registry.Deregister(context.Background(), s.instanceID)
+	s.app.GRPCsrv.Stop()
+}
+
+// migrateStorage applies migrations of the service, they seed the app the facade logs users in to
+func migrateStorage(storagePath string) error {
+	m, err := migrate.New("file://"+filepath.Join(moduleDir(), "migrations", "sqlite"), "sqlite3://"+storagePath)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+
+	return nil
+}
+
+// moduleDir returns the root directory of sso module, so the server starts the same way from any test package
+func moduleDir() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..")
+}
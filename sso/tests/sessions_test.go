@@ -0,0 +1,140 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zoninnik89/messenger/common"
+	pb "github.com/zoninnik89/messenger/common/api"
+	suite "github.com/zoninnik89/messenger/sso/tests/suite"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const firefoxUserAgent = "Mozilla/5.0 (X11; Linux x86_64; rv:131.0) Gecko/20100101 Firefox/131.0"
+
+func TestSessions_ListAndRevoke_HappyPath(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	email, pass, userID := registerCredentials(ctx, t, st)
+
+	laptopToken := loginWithDevice(ctx, t, st, email, pass, firefoxUserAgent, "")
+	phoneToken := loginWithDevice(ctx, t, st, email, pass, "", "My phone")
+
+	laptopSessionID := tokenSessionID(t, laptopToken)
+	phoneSessionID := tokenSessionID(t, phoneToken)
+	require.NotEmpty(t, laptopSessionID)
+	assert.NotEqual(t, laptopSessionID, phoneSessionID)
+
+	respTouch, err := st.AuthClient.TouchSession(ctx, &pb.TouchSessionRequest{SessionId: laptopSessionID})
+	require.NoError(t, err)
+	assert.Equal(t, userID, respTouch.GetUserId())
+
+	respList, err := st.AuthClient.ListSessions(ctx, &pb.ListSessionsRequest{UserId: userID})
+	require.NoError(t, err)
+	require.Len(t, respList.GetSessions(), 2)
+
+	devices := make(map[string]*pb.Session)
+	for _, session := range respList.GetSessions() {
+		devices[session.GetSessionId()] = session
+	}
+
+	require.Contains(t, devices, laptopSessionID)
+	assert.Equal(t, "Firefox on Linux", devices[laptopSessionID].GetDeviceName())
+	assert.Equal(t, firefoxUserAgent, devices[laptopSessionID].GetUserAgent())
+	assert.Equal(t, int32(appID), devices[laptopSessionID].GetAppId())
+	assert.NotZero(t, devices[laptopSessionID].GetCreatedAt())
+	assert.Greater(t, devices[laptopSessionID].GetExpiresAt(), devices[laptopSessionID].GetLastUsedAt())
+
+	require.Contains(t, devices, phoneSessionID)
+	assert.Equal(t, "My phone", devices[phoneSessionID].GetDeviceName())
+
+	_, err = st.AuthClient.RevokeSession(ctx, &pb.RevokeSessionRequest{UserId: userID, SessionId: phoneSessionID})
+	require.NoError(t, err)
+
+	_, err = st.AuthClient.TouchSession(ctx, &pb.TouchSessionRequest{SessionId: phoneSessionID})
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	respList, err = st.AuthClient.ListSessions(ctx, &pb.ListSessionsRequest{UserId: userID})
+	require.NoError(t, err)
+	require.Len(t, respList.GetSessions(), 1)
+	assert.Equal(t, laptopSessionID, respList.GetSessions()[0].GetSessionId())
+
+	// revoking twice fails
+	_, err = st.AuthClient.RevokeSession(ctx, &pb.RevokeSessionRequest{UserId: userID, SessionId: phoneSessionID})
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestSessions_RevokeSessionOfOtherUser(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	email, pass, _ := registerCredentials(ctx, t, st)
+	_, _, otherUserID := registerCredentials(ctx, t, st)
+
+	sessionID := tokenSessionID(t, loginWithDevice(ctx, t, st, email, pass, firefoxUserAgent, ""))
+
+	_, err := st.AuthClient.RevokeSession(ctx, &pb.RevokeSessionRequest{UserId: otherUserID, SessionId: sessionID})
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = st.AuthClient.TouchSession(ctx, &pb.TouchSessionRequest{SessionId: sessionID})
+	require.NoError(t, err)
+}
+
+func TestSessions_FailCases(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	_, err := st.AuthClient.ListSessions(ctx, &pb.ListSessionsRequest{})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "user id required")
+
+	_, err = st.AuthClient.RevokeSession(ctx, &pb.RevokeSessionRequest{UserId: "user"})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "session id required")
+
+	_, err = st.AuthClient.TouchSession(ctx, &pb.TouchSessionRequest{SessionId: "unknown"})
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+// loginWithDevice logs in from the client with the given user agent and device name and returns auth token.
+func loginWithDevice(
+	ctx context.Context,
+	t *testing.T,
+	st *suite.Suite,
+	email string,
+	pass string,
+	userAgent string,
+	deviceName string,
+) string {
+	t.Helper()
+
+	respLogin, err := st.AuthClient.Login(common.WithClientDevice(ctx, userAgent, deviceName), &pb.LoginRequest{
+		Login:    email,
+		Password: pass,
+		AppId:    appID,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, respLogin.GetToken())
+
+	return respLogin.GetToken()
+}
+
+func tokenSessionID(t *testing.T, token string) string {
+	t.Helper()
+
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+	require.NoError(t, err)
+
+	claims, ok := parsed.Claims.(jwt.MapClaims)
+	require.True(t, ok)
+
+	sessionID, _ := claims["sid"].(string)
+
+	return sessionID
+}
@@ -0,0 +1,272 @@
+package suite
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/zoninnik89/messenger/sso/internal/config"
+	"github.com/zoninnik89/messenger/sso/internal/lib/oidc"
+)
+
+// StubIdPName is the name of the stub provider in the local config.
+const StubIdPName = "stub"
+
+// StubIdP is a minimal OpenID Connect provider which logs in whoever it is told to by the
+// "login_hint" parameter of the authorization request, without asking for credentials.
+// Set "email_verified=false" to simulate an account with unverified email.
+type StubIdP struct {
+	cfg config.OIDCProviderConfig
+	key *rsa.PrivateKey
+	// kid is random, so that sso refetches keys of a new test run
+	kid string
+
+	mu     sync.Mutex
+	grants map[string]stubGrant
+}
+
+type stubGrant struct {
+	redirectURI   string
+	codeChallenge string
+	nonce         string
+	email         string
+	emailVerified bool
+}
+
+var (
+	stubIdP     *StubIdP
+	stubIdPErr  error
+	stubIdPOnce sync.Once
+)
+
+// StubIdP starts the stub provider configured in the local config on the first call,
+// sso fetches its discovery document and keys when the provider is first used.
+func (s *Suite) StubIdP() *StubIdP {
+	s.Helper()
+
+	stubIdPOnce.Do(func() {
+		stubIdP, stubIdPErr = startStubIdP(s.Cfg)
+	})
+	if stubIdPErr != nil {
+		s.Fatalf("failed to start stub identity provider: %v", stubIdPErr)
+	}
+
+	return stubIdP
+}
+
+// Authorize follows the authorization URL returned by sso as the user with the given email would
+// and returns state and code the provider redirects back with.
+func (p *StubIdP) Authorize(t *testing.T, authorizationURL string, email string, emailVerified bool) (state string, code string) {
+	t.Helper()
+
+	authURL, err := url.Parse(authorizationURL)
+	if err != nil {
+		t.Fatalf("invalid authorization url: %v", err)
+	}
+
+	query := authURL.Query()
+	query.Set("login_hint", email)
+	if !emailVerified {
+		query.Set("email_verified", "false")
+	}
+	authURL.RawQuery = query.Encode()
+
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	res, err := client.Get(authURL.String())
+	if err != nil {
+		t.Fatalf("authorization request failed: %v", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusFound {
+		t.Fatalf("authorization request failed with status %d", res.StatusCode)
+	}
+
+	location, err := url.Parse(res.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("invalid redirect: %v", err)
+	}
+
+	return location.Query().Get("state"), location.Query().Get("code")
+}
+
+// Subject returns the subject the provider issues ID tokens for the email with.
+func (p *StubIdP) Subject(email string) string {
+	return "stub|" + email
+}
+
+func startStubIdP(cfg *config.Config) (*StubIdP, error) {
+	var providerCfg *config.OIDCProviderConfig
+	for i := range cfg.OIDC.Providers {
+		if cfg.OIDC.Providers[i].Name == StubIdPName {
+			providerCfg = &cfg.OIDC.Providers[i]
+		}
+	}
+	if providerCfg == nil {
+		return nil, errors.New("stub provider is not configured")
+	}
+
+	issuer, err := url.Parse(providerCfg.Issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &StubIdP{
+		cfg:    *providerCfg,
+		key:    key,
+		kid:    uuid.New().String(),
+		grants: make(map[string]stubGrant),
+	}
+
+	listener, err := net.Listen("tcp", issuer.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("GET /jwks", p.jwks)
+	mux.HandleFunc("GET /authorize", p.authorize)
+	mux.HandleFunc("POST /token", p.token)
+
+	go func() {
+		_ = http.Serve(listener, mux)
+	}()
+
+	return p, nil
+}
+
+func (p *StubIdP) discovery(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 p.cfg.Issuer,
+		"authorization_endpoint": p.cfg.Issuer + "/authorize",
+		"token_endpoint":         p.cfg.Issuer + "/token",
+		"jwks_uri":               p.cfg.Issuer + "/jwks",
+	})
+}
+
+func (p *StubIdP) jwks(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"keys": []map[string]string{{
+			"kid": p.kid,
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(p.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.key.E)).Bytes()),
+		}},
+	})
+}
+
+func (p *StubIdP) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if query.Get("response_type") != "code" ||
+		query.Get("client_id") != p.cfg.ClientID ||
+		query.Get("redirect_uri") != p.cfg.RedirectURL ||
+		query.Get("code_challenge_method") != "S256" ||
+		query.Get("code_challenge") == "" ||
+		query.Get("login_hint") == "" {
+		http.Error(w, "invalid authorization request", http.StatusBadRequest)
+		return
+	}
+
+	code := uuid.New().String()
+
+	p.mu.Lock()
+	p.grants[code] = stubGrant{
+		redirectURI:   query.Get("redirect_uri"),
+		codeChallenge: query.Get("code_challenge"),
+		nonce:         query.Get("nonce"),
+		email:         query.Get("login_hint"),
+		emailVerified: query.Get("email_verified") != "false",
+	}
+	p.mu.Unlock()
+
+	redirect, _ := url.Parse(query.Get("redirect_uri"))
+	redirectQuery := redirect.Query()
+	redirectQuery.Set("code", code)
+	redirectQuery.Set("state", query.Get("state"))
+	redirect.RawQuery = redirectQuery.Encode()
+
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (p *StubIdP) token(w http.ResponseWriter, r *http.Request) {
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok || clientID != p.cfg.ClientID || clientSecret != p.cfg.ClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	// codes are single-use
+	p.mu.Lock()
+	grant, ok := p.grants[r.PostForm.Get("code")]
+	delete(p.grants, r.PostForm.Get("code"))
+	p.mu.Unlock()
+
+	if !ok ||
+		grant.redirectURI != r.PostForm.Get("redirect_uri") ||
+		grant.codeChallenge != oidc.CodeChallenge(r.PostForm.Get("code_verifier")) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            p.cfg.Issuer,
+		"sub":            p.Subject(grant.email),
+		"aud":            p.cfg.ClientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Minute).Unix(),
+		"nonce":          grant.nonce,
+		"email":          grant.email,
+		"email_verified": grant.emailVerified,
+	})
+	idToken.Header["kid"] = p.kid
+
+	signed, err := idToken.SignedString(p.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": uuid.New().String(),
+		"token_type":   "Bearer",
+		"expires_in":   60,
+		"id_token":     signed,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
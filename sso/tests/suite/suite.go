@@ -6,6 +6,7 @@ import (
 	"github.com/zoninnik89/messenger/sso/internal/config"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 	"net"
 	"strconv"
 	"testing"
@@ -17,8 +18,11 @@ const (
 
 type Suite struct {
 	*testing.T
-	Cfg        *config.Config
-	AuthClient pb.AuthServiceClient
+	Cfg             *config.Config
+	AuthClient      pb.AuthServiceClient
+	RelationsClient pb.RelationsServiceClient
+	AppClient       pb.AppServiceClient
+	AuditClient     pb.AuditServiceClient
 }
 
 func New(t *testing.T) (context.Context, *Suite) {
@@ -40,12 +44,20 @@ func New(t *testing.T) (context.Context, *Suite) {
 	}
 
 	return ctx, &Suite{
-		T:          t,
-		Cfg:        cfg,
-		AuthClient: pb.NewAuthServiceClient(cc),
+		T:               t,
+		Cfg:             cfg,
+		AuthClient:      pb.NewAuthServiceClient(cc),
+		RelationsClient: pb.NewRelationsServiceClient(cc),
+		AppClient:       pb.NewAppServiceClient(cc),
+		AuditClient:     pb.NewAuditServiceClient(cc),
 	}
 }
 
+// AdminContext returns context authorized to call admin APIs.
+func (s *Suite) AdminContext(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+s.Cfg.Admin.Token)
+}
+
 func grpcAddress(cfg *config.Config) string {
 	return net.JoinHostPort(grpcHost, strconv.Itoa(cfg.GRPC.Port))
 }